@@ -2,8 +2,14 @@ package handler
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
+	"bitbucket.org/non-pn/mini-redis-go/internal/db"
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
 	"bitbucket.org/non-pn/mini-redis-go/internal/network"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/redis"
 	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
 )
 
@@ -26,25 +32,745 @@ func HandleClientSet(cli *network.Client, k string, v string) {
 	fmt.Println("Response:", resp)
 }
 
-func HandleClientSub(cli *network.Client, topic string) {
-	sub, err := cli.Sub(topic)
+func HandleClientSetEx(cli *network.Client, k string, v string, ttl time.Duration) {
+	s := tlv.String(v)
+	resp, err := cli.SetEx(k, &s, ttl)
 	if err != nil {
 		panic(err)
 	}
 
-	fmt.Println("Subscribed to topic:", topic)
-	err = sub.Subscribe(func(s string) {
-		fmt.Println("incoming msg:", s)
-	})
+	fmt.Println("Response:", resp)
+}
+
+func HandleClientSetArgs(cli *network.Client, k string, v string, opts redis.SetOptions) {
+	s := tlv.String(v)
+	set, prev, err := cli.SetArgs(k, &s, opts)
 	if err != nil {
 		panic(err)
 	}
+
+	fmt.Println("Response:", set)
+	if opts.Get && prev != nil {
+		fmt.Println("Previous:", prev.String())
+	}
 }
 
-func HandleClientPub(cli *network.Client, topic string, msg string) {
-	resp, err := cli.Pub(topic, msg)
+func HandleClientMGet(cli *network.Client, keys []string) {
+	vals, err := cli.MGet(keys...)
+	if err != nil {
+		panic(err)
+	}
+
+	for i, v := range vals {
+		if v == nil {
+			fmt.Println(keys[i]+":", "(nil)")
+			continue
+		}
+		fmt.Println(keys[i]+":", v.String())
+	}
+}
+
+// HandleClientMSet set pairs of key and value, only when none of keys exists if nx is set
+func HandleClientMSet(cli *network.Client, pairs []string, nx bool) {
+	vals := make(map[string]tlv.TLVCompatible, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		s := tlv.String(pairs[i+1])
+		vals[pairs[i]] = &s
+	}
+
+	if nx {
+		ok, err := cli.MSetNX(vals)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println("Response:", ok)
+		return
+	}
+
+	resp, err := cli.MSet(vals)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", resp)
+}
+
+func HandleClientExpire(cli *network.Client, k string, ttl time.Duration) {
+	ok, err := cli.Expire(k, ttl)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", ok)
+}
+
+func HandleClientTTL(cli *network.Client, k string) {
+	ttl, err := cli.TTL(k)
+	if err != nil {
+		panic(err)
+	}
+
+	switch ttl {
+	case db.KeyNotFoundTTL:
+		fmt.Println("Response: key not found")
+	case db.NoExpireTTL:
+		fmt.Println("Response: no expiration")
+	default:
+		fmt.Println("Response:", ttl)
+	}
+}
+
+func HandleClientPersist(cli *network.Client, k string) {
+	ok, err := cli.Persist(k)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", ok)
+}
+
+func HandleClientDel(cli *network.Client, keys []string) {
+	n, err := cli.Del(keys...)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", n)
+}
+
+func HandleClientExists(cli *network.Client, keys []string) {
+	n, err := cli.Exists(keys...)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", n)
+}
+
+func HandleClientScan(cli *network.Client, match string) {
+	cursor := ""
+	for {
+		next, keys, err := cli.Scan(cursor, match, 0)
+		if err != nil {
+			panic(err)
+		}
+
+		for _, k := range keys {
+			fmt.Println(k)
+		}
+
+		if next == "" {
+			return
+		}
+		cursor = next
+	}
+}
+
+func HandleClientIncrBy(cli *network.Client, k string, delta int64) {
+	n, err := cli.IncrBy(k, delta)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", n)
+}
+
+func HandleClientIncrByFloat(cli *network.Client, k string, delta float64) {
+	f, err := cli.IncrByFloat(k, delta)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", f)
+}
+
+func HandleClientPush(cli *network.Client, k string, vals []string, left bool) {
+	elems := make([]tlv.TLVCompatible, 0, len(vals))
+	for _, v := range vals {
+		s := tlv.String(v)
+		elems = append(elems, &s)
+	}
+
+	var (
+		n   int64
+		err error
+	)
+	if left {
+		n, err = cli.LPush(k, elems...)
+	} else {
+		n, err = cli.RPush(k, elems...)
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", n)
+}
+
+func HandleClientPop(cli *network.Client, k string, left bool) {
+	var (
+		val tlv.TLVCompatible
+		err error
+	)
+	if left {
+		val, err = cli.LPop(k)
+	} else {
+		val, err = cli.RPop(k)
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	if val == nil {
+		fmt.Println("Response: empty list")
+		return
+	}
+	fmt.Println("Response:", val.String())
+}
+
+func HandleClientLRange(cli *network.Client, k string, start int64, stop int64) {
+	vals, err := cli.LRange(k, start, stop)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, v := range vals {
+		if v == nil {
+			fmt.Println("")
+			continue
+		}
+		fmt.Println(v.String())
+	}
+}
+
+func HandleClientLLen(cli *network.Client, k string) {
+	n, err := cli.LLen(k)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", n)
+}
+
+func HandleClientBLPop(cli *network.Client, keys []string, timeout time.Duration) {
+	k, val, err := cli.BLPop(timeout, keys...)
+	if err != nil {
+		panic(err)
+	}
+
+	if val == nil {
+		fmt.Println("Response: timeout")
+		return
+	}
+	fmt.Println("Response:", k, val.String())
+}
+
+func HandleClientHSet(cli *network.Client, k string, pairs []string) {
+	fields := make(map[string]tlv.TLVCompatible, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		s := tlv.String(pairs[i+1])
+		fields[pairs[i]] = &s
+	}
+
+	n, err := cli.HSet(k, fields)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", n)
+}
+
+func HandleClientHGet(cli *network.Client, k string, field string) {
+	val, err := cli.HGet(k, field)
+	if err != nil {
+		panic(err)
+	}
+
+	if val == nil {
+		fmt.Println("Response: field not found")
+		return
+	}
+	fmt.Println("Response:", val.String())
+}
+
+func HandleClientHDel(cli *network.Client, k string, fields []string) {
+	n, err := cli.HDel(k, fields...)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", n)
+}
+
+func HandleClientHGetAll(cli *network.Client, k string) {
+	fields, err := cli.HGetAll(k)
+	if err != nil {
+		panic(err)
+	}
+
+	names := make([]string, 0, len(fields))
+	for f := range fields {
+		names = append(names, f)
+	}
+	sort.Strings(names)
+
+	for _, f := range names {
+		if fields[f] == nil {
+			fmt.Println(f + ":")
+			continue
+		}
+		fmt.Println(f+":", fields[f].String())
+	}
+}
+
+func HandleClientHIncrBy(cli *network.Client, k string, field string, delta int64) {
+	n, err := cli.HIncrBy(k, field, delta)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", n)
+}
+
+func HandleClientHLen(cli *network.Client, k string) {
+	n, err := cli.HLen(k)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", n)
+}
+
+func HandleClientSAdd(cli *network.Client, k string, members []string) {
+	n, err := cli.SAdd(k, members...)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", n)
+}
+
+func HandleClientSRem(cli *network.Client, k string, members []string) {
+	n, err := cli.SRem(k, members...)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", n)
+}
+
+func HandleClientSIsMember(cli *network.Client, k string, member string) {
+	ok, err := cli.SIsMember(k, member)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", ok)
+}
+
+func HandleClientSMembers(cli *network.Client, k string) {
+	members, err := cli.SMembers(k)
+	if err != nil {
+		panic(err)
+	}
+
+	printMembers(members)
+}
+
+func HandleClientSInter(cli *network.Client, keys []string) {
+	members, err := cli.SInter(keys...)
+	if err != nil {
+		panic(err)
+	}
+
+	printMembers(members)
+}
+
+func HandleClientSUnion(cli *network.Client, keys []string) {
+	members, err := cli.SUnion(keys...)
+	if err != nil {
+		panic(err)
+	}
+
+	printMembers(members)
+}
+
+func HandleClientSDiff(cli *network.Client, keys []string) {
+	members, err := cli.SDiff(keys...)
+	if err != nil {
+		panic(err)
+	}
+
+	printMembers(members)
+}
+
+func HandleClientZAdd(cli *network.Client, k string, members map[string]float64) {
+	n, err := cli.ZAdd(k, members)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", n)
+}
+
+func HandleClientZRem(cli *network.Client, k string, members []string) {
+	n, err := cli.ZRem(k, members...)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", n)
+}
+
+func HandleClientZScore(cli *network.Client, k string, member string) {
+	score, ok, err := cli.ZScore(k, member)
+	if err != nil {
+		panic(err)
+	}
+
+	if !ok {
+		fmt.Println("Response: member not found")
+		return
+	}
+	fmt.Println("Response:", score)
+}
+
+func HandleClientZRange(cli *network.Client, k string, start int64, stop int64) {
+	members, err := cli.ZRange(k, start, stop)
+	if err != nil {
+		panic(err)
+	}
+
+	printZMembers(members)
+}
+
+func HandleClientZRangeByScore(cli *network.Client, k string, min float64, max float64) {
+	members, err := cli.ZRangeByScore(k, min, max)
+	if err != nil {
+		panic(err)
+	}
+
+	printZMembers(members)
+}
+
+func HandleClientZRank(cli *network.Client, k string, member string) {
+	rank, ok, err := cli.ZRank(k, member)
+	if err != nil {
+		panic(err)
+	}
+
+	if !ok {
+		fmt.Println("Response: member not found")
+		return
+	}
+	fmt.Println("Response:", rank)
+}
+
+func HandleClientZIncrBy(cli *network.Client, k string, member string, delta float64) {
+	score, err := cli.ZIncrBy(k, member, delta)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", score)
+}
+
+func HandleClientXAdd(cli *network.Client, k string, id string, pairs []string, maxlen int) {
+	fields := make(map[string]tlv.TLVCompatible, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		s := tlv.String(pairs[i+1])
+		fields[pairs[i]] = &s
+	}
+
+	resp, err := cli.XAdd(k, id, fields, model.StreamTrim{MaxLen: maxlen})
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", resp)
+}
+
+func HandleClientXRange(cli *network.Client, k string, start string, end string, count int64) {
+	entries, err := cli.XRange(k, start, end, count)
+	if err != nil {
+		panic(err)
+	}
+
+	printStreamEntries(entries)
+}
+
+func HandleClientXRead(cli *network.Client, keys []string, ids []string, block bool, timeout time.Duration) {
+	var (
+		res []redis.StreamRead
+		err error
+	)
+	if block {
+		res, err = cli.XReadBlock(timeout, 0, keys, ids)
+	} else {
+		res, err = cli.XRead(0, keys, ids)
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	if res == nil {
+		fmt.Println("Response: empty")
+		return
+	}
+	for _, r := range res {
+		fmt.Println(r.Key)
+		printStreamEntries(r.Entries)
+	}
+}
+
+func HandleClientXLen(cli *network.Client, k string) {
+	n, err := cli.XLen(k)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", n)
+}
+
+func HandleClientXTrim(cli *network.Client, k string, maxlen int) {
+	n, err := cli.XTrim(k, model.StreamTrim{MaxLen: maxlen})
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", n)
+}
+
+func HandleClientXGroupCreate(cli *network.Client, k string, group string, id string) {
+	resp, err := cli.XGroupCreate(k, group, id)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", resp)
+}
+
+func HandleClientXGroupDestroy(cli *network.Client, k string, group string) {
+	ok, err := cli.XGroupDestroy(k, group)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", ok)
+}
+
+func HandleClientXReadGroup(cli *network.Client, group string, consumer string, keys []string, ids []string, block bool, timeout time.Duration) {
+	var (
+		res []redis.StreamRead
+		err error
+	)
+	if block {
+		res, err = cli.XReadGroupBlock(timeout, group, consumer, 0, keys, ids)
+	} else {
+		res, err = cli.XReadGroup(group, consumer, 0, keys, ids)
+	}
+	if err != nil {
+		panic(err)
+	}
+
+	if res == nil {
+		fmt.Println("Response: empty")
+		return
+	}
+	for _, r := range res {
+		fmt.Println(r.Key)
+		printStreamEntries(r.Entries)
+	}
+}
+
+func HandleClientXAck(cli *network.Client, k string, group string, ids []string) {
+	n, err := cli.XAck(k, group, ids...)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", n)
+}
+
+func HandleClientXPending(cli *network.Client, k string, group string, consumer string) {
+	pending, err := cli.XPending(k, group, consumer)
+	if err != nil {
+		panic(err)
+	}
+
+	if len(pending) == 0 {
+		fmt.Println("Response: empty")
+		return
+	}
+	for _, p := range pending {
+		fmt.Println(p.ID, p.Consumer, p.Idle, p.Count)
+	}
+}
+
+func HandleClientXClaim(cli *network.Client, k string, group string, consumer string, minIdle time.Duration, ids []string) {
+	entries, err := cli.XClaim(k, group, consumer, minIdle, 0, ids...)
+	if err != nil {
+		panic(err)
+	}
+
+	printStreamEntries(entries)
+}
+
+func HandleClientBGRewriteAOF(cli *network.Client) {
+	resp, err := cli.BGRewriteAOF()
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", resp)
+}
+
+func HandleClientSave(cli *network.Client) {
+	resp, err := cli.Save()
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", resp)
+}
+
+func HandleClientBGSave(cli *network.Client) {
+	resp, err := cli.BGSave()
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", resp)
+}
+
+func HandleClientList(cli *network.Client) {
+	resp, err := cli.ClientList()
 	if err != nil {
 		panic(err)
 	}
+
 	fmt.Println(resp)
 }
+
+func HandleClientKill(cli *network.Client, filter string, value string) {
+	n, err := cli.ClientKill(filter, value)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", n)
+}
+
+func HandleClientSetName(cli *network.Client, name string) {
+	resp, err := cli.ClientSetName(name)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println("Response:", resp)
+}
+
+func HandleClientGetName(cli *network.Client) {
+	name, err := cli.ClientGetName()
+	if err != nil {
+		panic(err)
+	}
+
+	if name == "" {
+		fmt.Println("Response: no name set")
+		return
+	}
+	fmt.Println("Response:", name)
+}
+
+func printMembers(members []string) {
+	for _, m := range members {
+		fmt.Println(m)
+	}
+}
+
+func printZMembers(members []model.ZMember) {
+	for _, m := range members {
+		fmt.Println(m.Member+":", m.Score)
+	}
+}
+
+func printStreamEntries(entries []redis.StreamEntry) {
+	for _, e := range entries {
+		names := make([]string, 0, len(e.Fields))
+		for f := range e.Fields {
+			names = append(names, f)
+		}
+		sort.Strings(names)
+
+		pairs := make([]string, 0, len(names))
+		for _, f := range names {
+			val := ""
+			if e.Fields[f] != nil {
+				val = e.Fields[f].String()
+			}
+			pairs = append(pairs, f+"="+val)
+		}
+		fmt.Println(e.ID+":", strings.Join(pairs, " "))
+	}
+}
+
+// HandleClientSub stay subscribed to topics until process is stopped, reconnecting whenever
+// connection to server is lost
+func HandleClientSub(cli *network.Client, topics ...string) {
+	sub := network.NewResilientSubscriber(cli.Transport, network.DefaultBackoff(), topics...)
+	defer sub.Close()
+
+	printSubscriber(sub, "Subscribed to topics:", topics)
+}
+
+// HandleClientPSub is HandleClientSub for patterns
+func HandleClientPSub(cli *network.Client, patterns ...string) {
+	sub := network.NewResilientPSubscriber(cli.Transport, network.DefaultBackoff(), patterns...)
+	defer sub.Close()
+
+	printSubscriber(sub, "Subscribed to patterns:", patterns)
+}
+
+// printSubscriber print events and messages of subscriber until it is closed
+func printSubscriber(sub *network.ResilientSubscriber, subscribed string, names []string) {
+	events := sub.Events()
+	messages := sub.Messages()
+	for events != nil || messages != nil {
+		select {
+		case ev, ok := <-events:
+			if !ok {
+				events = nil
+				break
+			}
+			switch ev.State {
+			case network.SubscriberSubscribed:
+				fmt.Println(subscribed, strings.Join(names, ", "))
+			case network.SubscriberDisconnected:
+				fmt.Println("Disconnected:", ev.Err)
+			case network.SubscriberConnecting:
+				if ev.Attempt > 0 {
+					fmt.Println("Reconnecting, attempt", ev.Attempt)
+				}
+			}
+		case msg, ok := <-messages:
+			if !ok {
+				messages = nil
+				break
+			}
+			if msg.Pattern != "" {
+				fmt.Printf("incoming msg on %s (%s): %s\n", msg.Topic, msg.Pattern, msg.Payload)
+			} else {
+				fmt.Printf("incoming msg on %s: %s\n", msg.Topic, msg.Payload)
+			}
+		}
+	}
+}
+
+func HandleClientPub(cli *network.Client, topic string, msg string) {
+	n, err := cli.Pub(topic, msg)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println("Response:", n)
+}
@@ -5,17 +5,92 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"strconv"
+	"strings"
+	"time"
 
 	"bitbucket.org/non-pn/mini-redis-go/cmd/client/internal/handler"
 	"bitbucket.org/non-pn/mini-redis-go/internal/constant"
 	"bitbucket.org/non-pn/mini-redis-go/internal/network"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/redis"
 )
 
 const (
-	cliGetCmd = "get"
-	cliSetCmd = "set"
-	cliSubCmd = "sub"
-	cliPubCmd = "pub"
+	cliGetCmd  = "get"
+	cliSetCmd  = "set"
+	cliSubCmd  = "sub"
+	cliPSubCmd = "psub"
+	cliPubCmd  = "pub"
+
+	cliSetNXCmd  = "setnx"
+	cliGetSetCmd = "getset"
+
+	cliMGetCmd   = "mget"
+	cliMSetCmd   = "mset"
+	cliMSetNXCmd = "msetnx"
+
+	cliExpireCmd  = "expire"
+	cliTTLCmd     = "ttl"
+	cliPersistCmd = "persist"
+
+	cliDelCmd    = "del"
+	cliExistsCmd = "exists"
+	cliScanCmd   = "scan"
+
+	cliIncrCmd        = "incr"
+	cliDecrCmd        = "decr"
+	cliIncrByCmd      = "incrby"
+	cliDecrByCmd      = "decrby"
+	cliIncrByFloatCmd = "incrbyfloat"
+
+	cliLPushCmd  = "lpush"
+	cliRPushCmd  = "rpush"
+	cliLPopCmd   = "lpop"
+	cliRPopCmd   = "rpop"
+	cliLRangeCmd = "lrange"
+	cliLLenCmd   = "llen"
+	cliBLPopCmd  = "blpop"
+
+	cliHSetCmd    = "hset"
+	cliHGetCmd    = "hget"
+	cliHDelCmd    = "hdel"
+	cliHGetAllCmd = "hgetall"
+	cliHIncrByCmd = "hincrby"
+	cliHLenCmd    = "hlen"
+
+	cliSAddCmd      = "sadd"
+	cliSRemCmd      = "srem"
+	cliSIsMemberCmd = "sismember"
+	cliSMembersCmd  = "smembers"
+	cliSInterCmd    = "sinter"
+	cliSUnionCmd    = "sunion"
+	cliSDiffCmd     = "sdiff"
+
+	cliZAddCmd          = "zadd"
+	cliZRemCmd          = "zrem"
+	cliZScoreCmd        = "zscore"
+	cliZRangeCmd        = "zrange"
+	cliZRangeByScoreCmd = "zrangebyscore"
+	cliZRankCmd         = "zrank"
+	cliZIncrByCmd       = "zincrby"
+
+	cliXAddCmd   = "xadd"
+	cliXRangeCmd = "xrange"
+	cliXReadCmd  = "xread"
+	cliXLenCmd   = "xlen"
+	cliXTrimCmd  = "xtrim"
+
+	cliXGroupCmd     = "xgroup"
+	cliXReadGroupCmd = "xreadgroup"
+	cliXAckCmd       = "xack"
+	cliXPendingCmd   = "xpending"
+	cliXClaimCmd     = "xclaim"
+
+	cliBGRewriteAOFCmd = "bgrewriteaof"
+	cliSaveCmd         = "save"
+	cliBGSaveCmd       = "bgsave"
+
+	cliClientCmd = "client"
 )
 
 func handleClientCommand(cli *network.Client, cmd string, vals []string) error {
@@ -36,14 +111,43 @@ func handleClientCommand(cli *network.Client, cmd string, vals []string) error {
 			return errors.New("Error: Get cmd require two argument")
 		}
 		k, v := vals[0], vals[1]
-		handler.HandleClientSet(cli, k, v)
+		opts, conditional, err := parseSetOptions(vals[2:])
+		if err != nil {
+			return err
+		}
+		if conditional {
+			handler.HandleClientSetArgs(cli, k, v, opts)
+			break
+		}
+		if opts.TTL == 0 {
+			handler.HandleClientSet(cli, k, v)
+			break
+		}
+		handler.HandleClientSetEx(cli, k, v, opts.TTL)
+		break
+	case cliSetNXCmd:
+		if len(vals) < 2 {
+			return errors.New("Error: SetNX cmd require key and value")
+		}
+		handler.HandleClientSetArgs(cli, vals[0], vals[1], redis.SetOptions{NX: true})
+		break
+	case cliGetSetCmd:
+		if len(vals) < 2 {
+			return errors.New("Error: GetSet cmd require key and value")
+		}
+		handler.HandleClientSetArgs(cli, vals[0], vals[1], redis.SetOptions{Get: true})
 		break
 	case cliSubCmd:
 		if len(vals) == 0 {
 			return errors.New("Error: Sub cmd require at least one argument")
 		}
-		topic := vals[0]
-		handler.HandleClientSub(cli, topic)
+		handler.HandleClientSub(cli, vals...)
+		break
+	case cliPSubCmd:
+		if len(vals) == 0 {
+			return errors.New("Error: PSub cmd require at least one argument")
+		}
+		handler.HandleClientPSub(cli, vals...)
 		break
 	case cliPubCmd:
 		if len(vals) < 2 {
@@ -52,6 +156,447 @@ func handleClientCommand(cli *network.Client, cmd string, vals []string) error {
 		topic, message := vals[0], vals[1]
 		handler.HandleClientPub(cli, topic, message)
 		break
+	case cliMGetCmd:
+		if len(vals) == 0 {
+			return errors.New("Error: MGet cmd require at least one key")
+		}
+		handler.HandleClientMGet(cli, vals)
+		break
+	case cliMSetCmd, cliMSetNXCmd:
+		if len(vals) < 2 || len(vals)%2 != 0 {
+			return errors.New("Error: MSet cmd require key value pairs")
+		}
+		handler.HandleClientMSet(cli, vals, cmd == cliMSetNXCmd)
+		break
+	case cliExpireCmd:
+		if len(vals) < 2 {
+			return errors.New("Error: Expire cmd require key and seconds")
+		}
+		ttl, err := parseSeconds(vals[1])
+		if err != nil {
+			return err
+		}
+		handler.HandleClientExpire(cli, vals[0], ttl)
+		break
+	case cliTTLCmd:
+		if len(vals) == 0 {
+			return errors.New("Error: TTL cmd require at least one argument")
+		}
+		handler.HandleClientTTL(cli, vals[0])
+		break
+	case cliPersistCmd:
+		if len(vals) == 0 {
+			return errors.New("Error: Persist cmd require at least one argument")
+		}
+		handler.HandleClientPersist(cli, vals[0])
+		break
+	case cliDelCmd:
+		if len(vals) == 0 {
+			return errors.New("Error: Del cmd require at least one argument")
+		}
+		handler.HandleClientDel(cli, vals)
+		break
+	case cliExistsCmd:
+		if len(vals) == 0 {
+			return errors.New("Error: Exists cmd require at least one argument")
+		}
+		handler.HandleClientExists(cli, vals)
+		break
+	case cliScanCmd:
+		match := ""
+		if len(vals) > 0 {
+			match = vals[0]
+		}
+		handler.HandleClientScan(cli, match)
+		break
+	case cliIncrCmd, cliDecrCmd:
+		if len(vals) == 0 {
+			return errors.New("Error: Incr cmd require at least one argument")
+		}
+		delta := int64(1)
+		if cmd == cliDecrCmd {
+			delta = -1
+		}
+		handler.HandleClientIncrBy(cli, vals[0], delta)
+		break
+	case cliIncrByCmd, cliDecrByCmd:
+		if len(vals) < 2 {
+			return errors.New("Error: Incrby cmd require key and increment")
+		}
+		delta, err := strconv.ParseInt(vals[1], 10, 64)
+		if err != nil {
+			return errors.New("Error: Invalid increment " + vals[1])
+		}
+		if cmd == cliDecrByCmd {
+			delta = -delta
+		}
+		handler.HandleClientIncrBy(cli, vals[0], delta)
+		break
+	case cliIncrByFloatCmd:
+		if len(vals) < 2 {
+			return errors.New("Error: Incrbyfloat cmd require key and increment")
+		}
+		delta, err := strconv.ParseFloat(vals[1], 64)
+		if err != nil {
+			return errors.New("Error: Invalid increment " + vals[1])
+		}
+		handler.HandleClientIncrByFloat(cli, vals[0], delta)
+		break
+	case cliLPushCmd, cliRPushCmd:
+		if len(vals) < 2 {
+			return errors.New("Error: Push cmd require key and at least one value")
+		}
+		handler.HandleClientPush(cli, vals[0], vals[1:], cmd == cliLPushCmd)
+		break
+	case cliLPopCmd, cliRPopCmd:
+		if len(vals) == 0 {
+			return errors.New("Error: Pop cmd require at least one argument")
+		}
+		handler.HandleClientPop(cli, vals[0], cmd == cliLPopCmd)
+		break
+	case cliLRangeCmd:
+		if len(vals) < 3 {
+			return errors.New("Error: Lrange cmd require key, start and stop")
+		}
+		start, err := strconv.ParseInt(vals[1], 10, 64)
+		if err != nil {
+			return errors.New("Error: Invalid index " + vals[1])
+		}
+		stop, err := strconv.ParseInt(vals[2], 10, 64)
+		if err != nil {
+			return errors.New("Error: Invalid index " + vals[2])
+		}
+		handler.HandleClientLRange(cli, vals[0], start, stop)
+		break
+	case cliLLenCmd:
+		if len(vals) == 0 {
+			return errors.New("Error: Llen cmd require at least one argument")
+		}
+		handler.HandleClientLLen(cli, vals[0])
+		break
+	case cliBLPopCmd:
+		if len(vals) < 2 {
+			return errors.New("Error: Blpop cmd require keys and timeout seconds")
+		}
+		timeout, err := parseSeconds(vals[len(vals)-1])
+		if err != nil {
+			return err
+		}
+		handler.HandleClientBLPop(cli, vals[:len(vals)-1], timeout)
+		break
+	case cliHSetCmd:
+		if len(vals) < 3 || len(vals)%2 == 0 {
+			return errors.New("Error: Hset cmd require key and field value pairs")
+		}
+		handler.HandleClientHSet(cli, vals[0], vals[1:])
+		break
+	case cliHGetCmd:
+		if len(vals) < 2 {
+			return errors.New("Error: Hget cmd require key and field")
+		}
+		handler.HandleClientHGet(cli, vals[0], vals[1])
+		break
+	case cliHDelCmd:
+		if len(vals) < 2 {
+			return errors.New("Error: Hdel cmd require key and at least one field")
+		}
+		handler.HandleClientHDel(cli, vals[0], vals[1:])
+		break
+	case cliHGetAllCmd:
+		if len(vals) == 0 {
+			return errors.New("Error: Hgetall cmd require at least one argument")
+		}
+		handler.HandleClientHGetAll(cli, vals[0])
+		break
+	case cliHIncrByCmd:
+		if len(vals) < 3 {
+			return errors.New("Error: Hincrby cmd require key, field and increment")
+		}
+		delta, err := strconv.ParseInt(vals[2], 10, 64)
+		if err != nil {
+			return errors.New("Error: Invalid increment " + vals[2])
+		}
+		handler.HandleClientHIncrBy(cli, vals[0], vals[1], delta)
+		break
+	case cliHLenCmd:
+		if len(vals) == 0 {
+			return errors.New("Error: Hlen cmd require at least one argument")
+		}
+		handler.HandleClientHLen(cli, vals[0])
+		break
+	case cliSAddCmd:
+		if len(vals) < 2 {
+			return errors.New("Error: Sadd cmd require key and at least one member")
+		}
+		handler.HandleClientSAdd(cli, vals[0], vals[1:])
+		break
+	case cliSRemCmd:
+		if len(vals) < 2 {
+			return errors.New("Error: Srem cmd require key and at least one member")
+		}
+		handler.HandleClientSRem(cli, vals[0], vals[1:])
+		break
+	case cliSIsMemberCmd:
+		if len(vals) < 2 {
+			return errors.New("Error: Sismember cmd require key and member")
+		}
+		handler.HandleClientSIsMember(cli, vals[0], vals[1])
+		break
+	case cliSMembersCmd:
+		if len(vals) == 0 {
+			return errors.New("Error: Smembers cmd require at least one argument")
+		}
+		handler.HandleClientSMembers(cli, vals[0])
+		break
+	case cliSInterCmd:
+		if len(vals) == 0 {
+			return errors.New("Error: Sinter cmd require at least one key")
+		}
+		handler.HandleClientSInter(cli, vals)
+		break
+	case cliSUnionCmd:
+		if len(vals) == 0 {
+			return errors.New("Error: Sunion cmd require at least one key")
+		}
+		handler.HandleClientSUnion(cli, vals)
+		break
+	case cliSDiffCmd:
+		if len(vals) == 0 {
+			return errors.New("Error: Sdiff cmd require at least one key")
+		}
+		handler.HandleClientSDiff(cli, vals)
+		break
+	case cliZAddCmd:
+		if len(vals) < 3 || len(vals)%2 == 0 {
+			return errors.New("Error: Zadd cmd require key and score member pairs")
+		}
+		members := make(map[string]float64, len(vals)/2)
+		for i := 1; i+1 < len(vals); i += 2 {
+			score, err := parseFloat(vals[i])
+			if err != nil {
+				return err
+			}
+			members[vals[i+1]] = score
+		}
+		handler.HandleClientZAdd(cli, vals[0], members)
+		break
+	case cliZRemCmd:
+		if len(vals) < 2 {
+			return errors.New("Error: Zrem cmd require key and at least one member")
+		}
+		handler.HandleClientZRem(cli, vals[0], vals[1:])
+		break
+	case cliZScoreCmd:
+		if len(vals) < 2 {
+			return errors.New("Error: Zscore cmd require key and member")
+		}
+		handler.HandleClientZScore(cli, vals[0], vals[1])
+		break
+	case cliZRangeCmd:
+		if len(vals) < 3 {
+			return errors.New("Error: Zrange cmd require key, start and stop")
+		}
+		start, err := strconv.ParseInt(vals[1], 10, 64)
+		if err != nil {
+			return errors.New("Error: Invalid start " + vals[1])
+		}
+		stop, err := strconv.ParseInt(vals[2], 10, 64)
+		if err != nil {
+			return errors.New("Error: Invalid stop " + vals[2])
+		}
+		handler.HandleClientZRange(cli, vals[0], start, stop)
+		break
+	case cliZRangeByScoreCmd:
+		if len(vals) < 3 {
+			return errors.New("Error: Zrangebyscore cmd require key, min and max")
+		}
+		min, err := parseFloat(vals[1])
+		if err != nil {
+			return err
+		}
+		max, err := parseFloat(vals[2])
+		if err != nil {
+			return err
+		}
+		handler.HandleClientZRangeByScore(cli, vals[0], min, max)
+		break
+	case cliZRankCmd:
+		if len(vals) < 2 {
+			return errors.New("Error: Zrank cmd require key and member")
+		}
+		handler.HandleClientZRank(cli, vals[0], vals[1])
+		break
+	case cliZIncrByCmd:
+		if len(vals) < 3 {
+			return errors.New("Error: Zincrby cmd require key, increment and member")
+		}
+		delta, err := parseFloat(vals[1])
+		if err != nil {
+			return err
+		}
+		handler.HandleClientZIncrBy(cli, vals[0], vals[2], delta)
+		break
+	case cliXAddCmd:
+		// Optional maxlen limit come before id
+		maxlen := 0
+		if len(vals) >= 3 && strings.ToLower(vals[1]) == "maxlen" {
+			n, err := strconv.Atoi(vals[2])
+			if err != nil || n < 0 {
+				return errors.New("Error: Invalid maxlen " + vals[2])
+			}
+			maxlen = n
+			vals = append([]string{vals[0]}, vals[3:]...)
+		}
+		if len(vals) < 4 || len(vals)%2 != 0 {
+			return errors.New("Error: Xadd cmd require key, id and field value pairs")
+		}
+		handler.HandleClientXAdd(cli, vals[0], vals[1], vals[2:], maxlen)
+		break
+	case cliXRangeCmd:
+		if len(vals) < 3 {
+			return errors.New("Error: Xrange cmd require key, start and end")
+		}
+		count := int64(0)
+		if len(vals) > 3 {
+			n, err := strconv.ParseInt(vals[3], 10, 64)
+			if err != nil {
+				return errors.New("Error: Invalid count " + vals[3])
+			}
+			count = n
+		}
+		handler.HandleClientXRange(cli, vals[0], vals[1], vals[2], count)
+		break
+	case cliXReadCmd:
+		// Optional block timeout come before keys, keys are followed by as many ids
+		block := false
+		var timeout time.Duration
+		if len(vals) >= 2 && strings.ToLower(vals[0]) == "block" {
+			d, err := parseSeconds(vals[1])
+			if err != nil {
+				return err
+			}
+			block, timeout = true, d
+			vals = vals[2:]
+		}
+		if len(vals) < 2 || len(vals)%2 != 0 {
+			return errors.New("Error: Xread cmd require keys followed by one id for each key")
+		}
+		handler.HandleClientXRead(cli, vals[:len(vals)/2], vals[len(vals)/2:], block, timeout)
+		break
+	case cliXLenCmd:
+		if len(vals) == 0 {
+			return errors.New("Error: Xlen cmd require at least one argument")
+		}
+		handler.HandleClientXLen(cli, vals[0])
+		break
+	case cliXTrimCmd:
+		if len(vals) < 2 {
+			return errors.New("Error: Xtrim cmd require key and maxlen")
+		}
+		maxlen, err := strconv.Atoi(vals[1])
+		if err != nil || maxlen < 0 {
+			return errors.New("Error: Invalid maxlen " + vals[1])
+		}
+		handler.HandleClientXTrim(cli, vals[0], maxlen)
+		break
+	case cliXGroupCmd:
+		if len(vals) < 3 {
+			return errors.New("Error: Xgroup cmd require create or destroy, key and group")
+		}
+		switch strings.ToLower(vals[0]) {
+		case "create":
+			if len(vals) < 4 {
+				return errors.New("Error: Xgroup create require key, group and id")
+			}
+			handler.HandleClientXGroupCreate(cli, vals[1], vals[2], vals[3])
+			break
+		case "destroy":
+			handler.HandleClientXGroupDestroy(cli, vals[1], vals[2])
+			break
+		default:
+			return errors.New("Error: Invalid xgroup subcommand " + vals[0])
+		}
+		break
+	case cliXReadGroupCmd:
+		// Optional block timeout come before group and consumer, keys are followed by as many ids
+		block := false
+		var timeout time.Duration
+		if len(vals) >= 2 && strings.ToLower(vals[0]) == "block" {
+			d, err := parseSeconds(vals[1])
+			if err != nil {
+				return err
+			}
+			block, timeout = true, d
+			vals = vals[2:]
+		}
+		if len(vals) < 4 || len(vals)%2 != 0 {
+			return errors.New("Error: Xreadgroup cmd require group, consumer and keys followed by one id for each key")
+		}
+		streams := vals[2:]
+		handler.HandleClientXReadGroup(cli, vals[0], vals[1], streams[:len(streams)/2], streams[len(streams)/2:], block, timeout)
+		break
+	case cliXAckCmd:
+		if len(vals) < 3 {
+			return errors.New("Error: Xack cmd require key, group and ids")
+		}
+		handler.HandleClientXAck(cli, vals[0], vals[1], vals[2:])
+		break
+	case cliXPendingCmd:
+		if len(vals) < 2 {
+			return errors.New("Error: Xpending cmd require key and group")
+		}
+		consumer := ""
+		if len(vals) > 2 {
+			consumer = vals[2]
+		}
+		handler.HandleClientXPending(cli, vals[0], vals[1], consumer)
+		break
+	case cliXClaimCmd:
+		if len(vals) < 4 {
+			return errors.New("Error: Xclaim cmd require key, group, consumer and min idle seconds")
+		}
+		minIdle, err := parseSeconds(vals[3])
+		if err != nil {
+			return err
+		}
+		handler.HandleClientXClaim(cli, vals[0], vals[1], vals[2], minIdle, vals[4:])
+		break
+	case cliBGRewriteAOFCmd:
+		handler.HandleClientBGRewriteAOF(cli)
+		break
+	case cliSaveCmd:
+		handler.HandleClientSave(cli)
+		break
+	case cliBGSaveCmd:
+		handler.HandleClientBGSave(cli)
+		break
+	case cliClientCmd:
+		if len(vals) == 0 {
+			return errors.New("Error: Client cmd require list, kill, setname or getname")
+		}
+		switch strings.ToLower(vals[0]) {
+		case "list":
+			handler.HandleClientList(cli)
+			break
+		case "kill":
+			if len(vals) < 3 {
+				return errors.New("Error: Client kill require filter id or addr and its value")
+			}
+			handler.HandleClientKill(cli, vals[1], vals[2])
+			break
+		case "setname":
+			if len(vals) < 2 {
+				return errors.New("Error: Client setname require name")
+			}
+			handler.HandleClientSetName(cli, vals[1])
+			break
+		case "getname":
+			handler.HandleClientGetName(cli)
+			break
+		default:
+			return errors.New("Error: Invalid client subcommand " + vals[0])
+		}
+		break
 	default:
 		fmt.Println("Invalid command")
 		break
@@ -60,6 +605,63 @@ func handleClientCommand(cli *network.Client, cmd string, vals []string) error {
 	return nil
 }
 
+// parseFloat accept inf and -inf so they can be used as score bounds
+func parseFloat(s string) (float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, errors.New("Error: Invalid number " + s)
+	}
+
+	return f, nil
+}
+
+func parseSeconds(s string) (time.Duration, error) {
+	sec, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, errors.New("Error: Invalid seconds " + s)
+	}
+
+	return time.Duration(sec) * time.Second, nil
+}
+
+// parseSetOptions read ex seconds, nx, xx and get following set value, conditional is true
+// when any of nx, xx and get is given
+func parseSetOptions(vals []string) (redis.SetOptions, bool, error) {
+	opts := redis.SetOptions{}
+	conditional := false
+	for i := 0; i < len(vals); i++ {
+		switch strings.ToLower(vals[i]) {
+		case "ex":
+			if i+1 >= len(vals) {
+				return opts, false, errors.New("Error: Set ex require seconds")
+			}
+			ttl, err := parseSeconds(vals[i+1])
+			if err != nil {
+				return opts, false, err
+			}
+			opts.TTL = ttl
+			i++
+			break
+		case "nx":
+			opts.NX = true
+			conditional = true
+			break
+		case "xx":
+			opts.XX = true
+			conditional = true
+			break
+		case "get":
+			opts.Get = true
+			conditional = true
+			break
+		default:
+			return opts, false, errors.New("Error: Invalid set option " + vals[i])
+		}
+	}
+
+	return opts, conditional, nil
+}
+
 func main() {
 	var (
 		host string
@@ -68,12 +670,17 @@ func main() {
 		key  string
 		cmd  string
 		vals []string
+
+		dialtimeout time.Duration
+		timeout     time.Duration
 	)
 	flag.StringVar(&host, "h", constant.DefaultServerHost, "host for client to connect to")
 	flag.StringVar(&port, "p", constant.DefaultServerPort, "port for client to connect to")
 	flag.StringVar(&cert, "cert", "", "absolute path to cert file for ssl")
 	flag.StringVar(&key, "key", "", "absolute path to key file for ssl")
 	flag.StringVar(&cmd, "c", "get", "command to use")
+	flag.DurationVar(&dialtimeout, "dial-timeout", constant.DefaultDialTimeout, "how long to wait for connection to server")
+	flag.DurationVar(&timeout, "timeout", 0, "how long to wait for each read and write, 0 to wait as long as it take")
 
 	flag.Parse()
 	vals = flag.Args()
@@ -81,6 +688,11 @@ func main() {
 	// Init client and try connect to host
 	port = ":" + port
 	client := network.NewClient(constant.Protocol, host, port, cert, key)
+	if transport, ok := client.Transport.(*network.TcpTransport); ok {
+		transport.DialTimeout = dialtimeout
+		transport.ReadTimeout = timeout
+		transport.WriteTimeout = timeout
+	}
 	log.Println("Start redis client, try connecting to:", host+port)
 
 	err := client.Connect()
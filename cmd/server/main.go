@@ -1,11 +1,19 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"bitbucket.org/non-pn/mini-redis-go/internal/constant"
+	"bitbucket.org/non-pn/mini-redis-go/internal/db"
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
 	"bitbucket.org/non-pn/mini-redis-go/internal/network"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
 )
 
 func main() {
@@ -13,24 +21,99 @@ func main() {
 		port string
 		cert string
 		key  string
+
+		maxkeylen uint
+
+		appendonly  bool
+		appendfile  string
+		appendfsync string
+
+		dbfilename string
+		save       string
+
+		shutdowntimeout time.Duration
+
+		subqueue    int
+		suboverflow string
 	)
 
 	flag.StringVar(&port, "p", constant.DefaultServerPort, "port that server will listen on")
 	flag.StringVar(&cert, "cert", "", "absolute path to cert file for ssl")
 	flag.StringVar(&key, "key", "", "absolute path to key file for ssl")
+	flag.UintVar(&maxkeylen, "max-key-length", uint(constant.DefaultMaxKeyLength), "longest key or topic accepted in bytes")
+	flag.BoolVar(&appendonly, "appendonly", false, "log every write to append only file and replay it on start")
+	flag.StringVar(&appendfile, "appendfilename", constant.DefaultAOFPath, "path to append only file")
+	flag.StringVar(&appendfsync, "appendfsync", constant.DefaultAOFFsyncPolicy, "fsync policy of append only file: always, everysec or no")
+	flag.StringVar(&dbfilename, "dbfilename", constant.DefaultSnapshotPath, "path to snapshot file, empty to disable snapshot")
+	flag.StringVar(&save, "save", constant.DefaultSavePolicy, "pairs of seconds and changes after which snapshot is written, empty to disable")
+	flag.DurationVar(&shutdowntimeout, "shutdown-timeout", constant.DefaultShutdownTimeout, "how long in-flight requests are waited for on shutdown")
+	flag.IntVar(&subqueue, "subscriber-queue", constant.DefaultSubscriberQueueSize, "pushes queued for each subscriber before overflow policy apply")
+	flag.StringVar(&suboverflow, "subscriber-overflow", constant.DefaultSubscriberOverflowPolicy, "what happen once subscriber queue is full: drop-oldest, drop-newest or disconnect")
 	flag.Parse()
 
-	serv, err := network.NewServer(constant.Protocol, ":"+port, cert, key)
+	payload.MaxKeyLength = uint32(maxkeylen)
+
+	opts := network.ServerOptions{}
+	if appendonly {
+		policy, err := db.ParseFsyncPolicy(appendfsync)
+		if err != nil {
+			log.Fatal(err)
+			return
+		}
+		opts.AOFPath = appendfile
+		opts.AOFFsyncPolicy = policy
+	}
+
+	policies, err := db.ParseSavePolicies(save)
 	if err != nil {
 		log.Fatal(err)
 		return
 	}
+	opts.SnapshotPath = dbfilename
+	if dbfilename != "" {
+		opts.SavePolicies = policies
+	}
+
+	overflow, err := model.ParseOverflowPolicy(suboverflow)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+	opts.SubscriberQueueSize = subqueue
+	opts.SubscriberOverflowPolicy = overflow
+
+	serv, err := network.NewServer(constant.Protocol, ":"+port, cert, key, opts)
+	if err != nil {
+		log.Fatal(err)
+		return
+	}
+
+	// Shutdown make Start return, main wait for it to finish flushing before exit
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+		log.Println("Received", <-sig, "shutting down")
+		signal.Stop(sig)
+
+		ctx, cancel := context.WithTimeout(context.Background(), shutdowntimeout)
+		defer cancel()
+
+		err := serv.Shutdown(ctx)
+		if err != nil {
+			log.Println("Shutdown:", err)
+		}
+	}()
 
 	log.Println("Start redis server at port", port)
 	err = serv.Start()
-	if err != nil {
+	if err != nil && err != network.ErrServerClosed {
 		log.Fatal(err)
 		return
 	}
-	defer serv.Stop()
+
+	<-done
+	log.Println("Server stopped")
 }
@@ -2,12 +2,15 @@ module bitbucket.org/non-pn/mini-redis-go
 
 go 1.19
 
+require (
+	github.com/stretchr/testify v1.8.4
+	go.uber.org/mock v0.2.0
+)
+
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/golang/mock v1.6.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/stretchr/objx v0.5.0 // indirect
-	github.com/stretchr/testify v1.8.4 // indirect
-	go.uber.org/mock v0.2.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -1,5 +1,17 @@
 package constant
 
+import "time"
+
 const (
-	DefaultRedisCachePath string = "./tmp/kv_store_cache.json"
+	DefaultRedisCachePath      string        = "./tmp/kv_store_cache.json"
+	DefaultExpireSweepInterval time.Duration = 100 * time.Millisecond
+	DefaultMaxKeyLength        uint32        = 1024
+	DefaultAOFPath             string        = "./tmp/appendonly.aof"
+	DefaultAOFFsyncPolicy      string        = "everysec"
+	// Append only file smaller than this is never rewritten
+	DefaultAOFRewriteMinSize int64  = 64 * 1024 * 1024
+	DefaultSnapshotPath      string = "./tmp/dump.mrdb"
+	// Pairs of seconds and changes, snapshot is taken when any pair is met
+	DefaultSavePolicy         string        = "900 1 300 10 60 10000"
+	DefaultSavePolicyInterval time.Duration = time.Second
 )
@@ -1,8 +1,33 @@
 package constant
 
+import "time"
+
 const (
 	Protocol          = "tcp"
 	DefaultServerPort = "6377"
 	DefaultServerHost = "127.0.0.1"
 	DefaultServerUrl  = DefaultServerHost + ":" + DefaultServerPort
+
+	// How long in-flight requests are waited for on shutdown
+	DefaultShutdownTimeout time.Duration = 10 * time.Second
+	ShutdownPollInterval   time.Duration = 10 * time.Millisecond
+	// Requests of a connection read ahead of the one being handled
+	DefaultPendingRequests = 16
+	// Replies are batched up to this size before written to connection
+	DefaultWriteBufferSize = 16 * 1024
+	DefaultReadBufferSize  = 16 * 1024
+	// How long client wait for connection to be established
+	DefaultDialTimeout time.Duration = 5 * time.Second
+	// How often pool close expired idle connections and open ones up to its minimum
+	DefaultPoolMaintainInterval time.Duration = time.Second
+	// Delay between reconnects of subscriber grow by factor, jitter take a random fraction off
+	DefaultReconnectMinDelay time.Duration = 100 * time.Millisecond
+	DefaultReconnectMaxDelay time.Duration = 10 * time.Second
+	DefaultReconnectFactor                 = 2.0
+	DefaultReconnectJitter                 = 0.2
+	// State events of subscriber kept until read
+	DefaultSubscriberEvents = 16
+	// Pushes queued for subscriber before overflow policy apply
+	DefaultSubscriberQueueSize             = 1024
+	DefaultSubscriberOverflowPolicy string = "drop-oldest"
 )
@@ -0,0 +1,581 @@
+package db
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/constant"
+)
+
+// FsyncPolicy decide how often appended records are flushed to disk
+type FsyncPolicy uint8
+
+const (
+	// Sync after every write, slowest but lose nothing
+	FsyncAlways FsyncPolicy = iota
+	// Sync once per second from background, lose at most one second on power failure
+	FsyncEverySec
+	// Leave syncing to operating system
+	FsyncNo
+)
+
+var (
+	ErrAOFNotEnabled    = errors.New("Append only file is not enabled")
+	ErrAOFRewriteActive = errors.New("Append only file rewrite already in progress")
+)
+
+func ParseFsyncPolicy(s string) (FsyncPolicy, error) {
+	switch s {
+	case "always":
+		return FsyncAlways, nil
+	case "everysec":
+		return FsyncEverySec, nil
+	case "no":
+		return FsyncNo, nil
+	default:
+		return 0, errors.New("Invalid fsync policy " + s)
+	}
+}
+
+type aofOp uint8
+
+const (
+	// Set value and expiration, no expiration remove existing one
+	aofSetOp aofOp = iota
+	// Replace value and keep expiration
+	aofUpdateOp
+	aofDeleteOp
+	aofExpireOp
+	aofPersistOp
+	// Apply command to value and keep expiration
+	aofCommandOp
+)
+
+// aofRecord is the result of one write on a key. Records carry absolute values and
+// expiration so replaying them give the same keyspace regardless of when it is done.
+// Command carry everything it depend on, time included, so it is replayed the same way.
+type aofRecord[T any] struct {
+	Op  aofOp  `json:"op"`
+	Key string `json:"key"`
+	// Unix milliseconds, zero when key has no expiration
+	ExpireAt int64           `json:"expire_at,omitempty"`
+	Value    T               `json:"value,omitempty"`
+	Command  json.RawMessage `json:"command,omitempty"`
+}
+
+// CommandCodec encode commands of store in append only file
+type CommandCodec[T any] struct {
+	Marshal   func(cmd Command[T]) ([]byte, error)
+	Unmarshal func(data []byte) (Command[T], error)
+}
+
+// aofHeaderLen is length of record frame header, record is framed as
+// [json length u32][crc32 of json u32][json]
+const aofHeaderLen = 8
+
+// aof is an append only log of writes to KVStore. Records are appended while store
+// write lock is held so the log order is the order writes were applied.
+type aof struct {
+	sync.Mutex
+	path   string
+	file   *os.File
+	policy FsyncPolicy
+	size   int64
+	// Size right after last rewrite, log is rewritten once it grow past double of it
+	baseSize int64
+	dirty    bool
+	closed   bool
+
+	// Records appended while rewrite is running, added to the end of rewritten log
+	rewriting  bool
+	rewriteBuf []byte
+
+	quit chan struct{}
+	done chan struct{}
+}
+
+func openAOF(path string, policy FsyncPolicy, size int64) (*aof, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	a := &aof{
+		path:     path,
+		file:     f,
+		policy:   policy,
+		size:     size,
+		baseSize: size,
+		quit:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go a.syncEverySec()
+
+	return a, nil
+}
+
+func (a *aof) append(frame []byte) error {
+	a.Lock()
+	defer a.Unlock()
+
+	if a.rewriting {
+		a.rewriteBuf = append(a.rewriteBuf, frame...)
+	}
+
+	n, err := a.file.Write(frame)
+	a.size += int64(n)
+	if err != nil {
+		return err
+	}
+
+	if a.policy == FsyncAlways {
+		return a.file.Sync()
+	}
+	a.dirty = true
+	return nil
+}
+
+// shouldRewrite report whether log has grown enough since last rewrite to be compacted
+func (a *aof) shouldRewrite() bool {
+	a.Lock()
+	defer a.Unlock()
+
+	return !a.rewriting && a.size >= constant.DefaultAOFRewriteMinSize && a.size >= 2*a.baseSize
+}
+
+func (a *aof) startRewrite() bool {
+	a.Lock()
+	defer a.Unlock()
+
+	if a.rewriting {
+		return false
+	}
+	a.rewriting = true
+	a.rewriteBuf = nil
+	return true
+}
+
+// finishRewrite append records written during rewrite to tmp and replace the log with it
+func (a *aof) finishRewrite(tmp *os.File, tmpSize int64) error {
+	a.Lock()
+	defer a.Unlock()
+	defer func() {
+		a.rewriting = false
+		a.rewriteBuf = nil
+	}()
+
+	if a.closed {
+		return ErrAOFNotEnabled
+	}
+
+	n, err := tmp.Write(a.rewriteBuf)
+	if err != nil {
+		return err
+	}
+	err = tmp.Sync()
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmp.Name(), a.path)
+	if err != nil {
+		return err
+	}
+
+	// Old handle point to replaced file, later records go to the rewritten one
+	a.file.Close()
+	a.file = tmp
+	a.size = tmpSize + int64(n)
+	a.baseSize = a.size
+	a.dirty = false
+	return nil
+}
+
+func (a *aof) abortRewrite() {
+	a.Lock()
+	defer a.Unlock()
+
+	a.rewriting = false
+	a.rewriteBuf = nil
+}
+
+func (a *aof) syncEverySec() {
+	defer close(a.done)
+	if a.policy != FsyncEverySec {
+		<-a.quit
+		return
+	}
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-a.quit:
+			return
+		case <-ticker.C:
+			a.Lock()
+			if a.dirty {
+				err := a.file.Sync()
+				if err != nil {
+					log.Println("aof:", err)
+				}
+				a.dirty = false
+			}
+			a.Unlock()
+		}
+	}
+}
+
+func (a *aof) close() error {
+	close(a.quit)
+	<-a.done
+
+	a.Lock()
+	defer a.Unlock()
+
+	a.closed = true
+	err := a.file.Sync()
+	if err != nil {
+		a.file.Close()
+		return err
+	}
+	return a.file.Close()
+}
+
+func encodeAOFRecord[T any](rec *aofRecord[T]) ([]byte, error) {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return nil, err
+	}
+
+	frame := make([]byte, aofHeaderLen, aofHeaderLen+len(data))
+	binary.BigEndian.PutUint32(frame[0:4], uint32(len(data)))
+	binary.BigEndian.PutUint32(frame[4:8], crc32.ChecksumIEEE(data))
+	return append(frame, data...), nil
+}
+
+// readAOF call fn with every record of log at path and return size of its valid part.
+// Incomplete record at the end, as left by crash in the middle of write, end the log
+// without error. Damaged record anywhere else is an error.
+func readAOF[T any](path string, fn func(rec *aofRecord[T]) error) (int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+	size := info.Size()
+
+	r := bufio.NewReader(f)
+	header := make([]byte, aofHeaderLen)
+	var off int64
+	for off < size {
+		if size-off < aofHeaderLen {
+			return off, nil
+		}
+		_, err = io.ReadFull(r, header)
+		if err != nil {
+			return off, err
+		}
+
+		n := int64(binary.BigEndian.Uint32(header[0:4]))
+		if size-off-aofHeaderLen < n {
+			return off, nil
+		}
+
+		data := make([]byte, n)
+		_, err = io.ReadFull(r, data)
+		if err != nil {
+			return off, err
+		}
+
+		last := off+aofHeaderLen+n == size
+		if crc32.ChecksumIEEE(data) != binary.BigEndian.Uint32(header[4:8]) {
+			if last {
+				return off, nil
+			}
+			return off, fmt.Errorf("Append only file is corrupted at offset %d", off)
+		}
+
+		rec := new(aofRecord[T])
+		err = json.Unmarshal(data, rec)
+		if err != nil {
+			return off, fmt.Errorf("Append only file is corrupted at offset %d: %w", off, err)
+		}
+
+		err = fn(rec)
+		if err != nil {
+			return off, fmt.Errorf("Append only file is corrupted at offset %d: %w", off, err)
+		}
+		off += aofHeaderLen + n
+	}
+
+	return off, nil
+}
+
+// OpenAOF replay log at path into the store and append every later write to it.
+// Incomplete record left at the end of log is cut off.
+func (kv *KVStore[T]) OpenAOF(path string, policy FsyncPolicy) error {
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	kv.Lock()
+	defer kv.Unlock()
+
+	n := 0
+	size, err := readAOF(path, func(rec *aofRecord[T]) error {
+		n++
+		return kv.applyAOFRecord(rec)
+	})
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	if err == nil && info.Size() > size {
+		log.Println("aof: cut off incomplete record at offset", size)
+		err = os.Truncate(path, size)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Records of keys that expired while server was down are not needed
+	now := time.Now()
+	for k := range kv.Expires {
+		if kv.isExpired(k, now) {
+			kv.delete(k)
+		}
+	}
+
+	a, err := openAOF(path, policy, size)
+	if err != nil {
+		return err
+	}
+	kv.aof = a
+
+	log.Println("aof: replayed", n, "records from", path)
+	return nil
+}
+
+// CloseAOF flush and close the log, later writes are no longer logged
+func (kv *KVStore[T]) CloseAOF() error {
+	kv.Lock()
+	a := kv.aof
+	kv.aof = nil
+	kv.Unlock()
+
+	if a == nil {
+		return ErrAOFNotEnabled
+	}
+	return a.close()
+}
+
+// RewriteAOF replace the log with the smallest one giving current keyspace. Store is
+// only locked shortly for each key so writes go on while log is rewritten.
+func (kv *KVStore[T]) RewriteAOF() error {
+	a, keys, err := kv.beginAOFRewrite()
+	if err != nil {
+		return err
+	}
+
+	return kv.rewriteAOF(a, keys)
+}
+
+// BackgroundRewriteAOF run RewriteAOF in background, error is returned only when it cannot start
+func (kv *KVStore[T]) BackgroundRewriteAOF() error {
+	a, keys, err := kv.beginAOFRewrite()
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		err := kv.rewriteAOF(a, keys)
+		if err != nil {
+			log.Println("aof:", err)
+		}
+	}()
+	return nil
+}
+
+// beginAOFRewrite start keeping appended records and return keys existing at that moment
+func (kv *KVStore[T]) beginAOFRewrite() (*aof, []string, error) {
+	kv.RLock()
+	defer kv.RUnlock()
+
+	a := kv.aof
+	if a == nil {
+		return nil, nil, ErrAOFNotEnabled
+	}
+	if !a.startRewrite() {
+		return nil, nil, ErrAOFRewriteActive
+	}
+
+	keys := make([]string, 0, len(kv.Storage))
+	for k := range kv.Storage {
+		keys = append(keys, k)
+	}
+	return a, keys, nil
+}
+
+func (kv *KVStore[T]) rewriteAOF(a *aof, keys []string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(a.path), filepath.Base(a.path)+".rewrite-*")
+	if err != nil {
+		a.abortRewrite()
+		return err
+	}
+
+	size, err := kv.writeAOFSnapshot(tmp, keys)
+	if err == nil {
+		err = tmp.Chmod(0644)
+	}
+	if err == nil {
+		err = a.finishRewrite(tmp, size)
+	}
+	if err != nil {
+		a.abortRewrite()
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+
+	log.Println("aof: rewrite done")
+	return nil
+}
+
+// writeAOFSnapshot write one record for each of keys that still exist. Value of a key
+// may be newer than rewrite start, the records kept during rewrite bring it up to date.
+func (kv *KVStore[T]) writeAOFSnapshot(w io.Writer, keys []string) (int64, error) {
+	bw := bufio.NewWriter(w)
+	var size int64
+	for _, k := range keys {
+		kv.RLock()
+		var frame []byte
+		var err error
+		if kv.exists(k, time.Now()) {
+			frame, err = encodeAOFRecord(kv.setRecord(k))
+		}
+		kv.RUnlock()
+		if err != nil {
+			return 0, err
+		}
+
+		n, err := bw.Write(frame)
+		size += int64(n)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return size, bw.Flush()
+}
+
+// commandRecord describe write made by cmd, resulting value is logged instead when store
+// has no codec for commands
+func (kv *KVStore[T]) commandRecord(k string, cmd Command[T], nv T) *aofRecord[T] {
+	if kv.aof == nil || kv.Commands.Marshal == nil || kv.Commands.Unmarshal == nil {
+		return &aofRecord[T]{Op: aofUpdateOp, Key: k, Value: nv}
+	}
+
+	data, err := kv.Commands.Marshal(cmd)
+	if err != nil {
+		log.Println("aof:", err)
+		return &aofRecord[T]{Op: aofUpdateOp, Key: k, Value: nv}
+	}
+	return &aofRecord[T]{Op: aofCommandOp, Key: k, Command: data}
+}
+
+func (kv *KVStore[T]) setRecord(k string) *aofRecord[T] {
+	rec := &aofRecord[T]{
+		Op:    aofSetOp,
+		Key:   k,
+		Value: kv.Storage[k],
+	}
+	if at, ok := kv.Expires[k]; ok {
+		rec.ExpireAt = at.UnixMilli()
+	}
+	return rec
+}
+
+// logAOF append record of a write, must be called with write lock held
+func (kv *KVStore[T]) logAOF(rec *aofRecord[T]) {
+	if kv.aof == nil {
+		return
+	}
+
+	frame, err := encodeAOFRecord(rec)
+	if err == nil {
+		err = kv.aof.append(frame)
+	}
+	if err != nil {
+		log.Println("aof:", err)
+		return
+	}
+
+	// Write lock is held here, rewrite has to wait for it in its own goroutine
+	if kv.aof.shouldRewrite() {
+		go func() {
+			err := kv.RewriteAOF()
+			if err != nil && err != ErrAOFRewriteActive {
+				log.Println("aof:", err)
+			}
+		}()
+	}
+}
+
+func (kv *KVStore[T]) applyAOFRecord(rec *aofRecord[T]) error {
+	switch rec.Op {
+	case aofSetOp:
+		kv.Storage[rec.Key] = rec.Value
+		delete(kv.Expires, rec.Key)
+		if rec.ExpireAt != 0 {
+			kv.setExpire(rec.Key, time.UnixMilli(rec.ExpireAt))
+		}
+		break
+	case aofUpdateOp:
+		kv.Storage[rec.Key] = rec.Value
+		break
+	case aofDeleteOp:
+		kv.delete(rec.Key)
+		break
+	case aofExpireOp:
+		kv.setExpire(rec.Key, time.UnixMilli(rec.ExpireAt))
+		break
+	case aofPersistOp:
+		delete(kv.Expires, rec.Key)
+		break
+	case aofCommandOp:
+		if kv.Commands.Unmarshal == nil {
+			return errors.New("No codec for command")
+		}
+		cmd, err := kv.Commands.Unmarshal(rec.Command)
+		if err != nil {
+			return err
+		}
+		// Key was not expired when command was run, expiration is checked after replay
+		_, err = kv.apply(rec.Key, cmd.Apply, nil)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,245 @@
+package db
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func openTestAOF(t *testing.T, path string) *KVStore[int] {
+	kvstore := NewKVStore[int](nil)
+	err := kvstore.OpenAOF(path, FsyncAlways)
+	assert.Nil(t, err)
+
+	return kvstore
+}
+
+func TestParseFsyncPolicy(t *testing.T) {
+	policy, err := ParseFsyncPolicy("everysec")
+	assert.Nil(t, err)
+	assert.Equal(t, FsyncEverySec, policy)
+
+	_, err = ParseFsyncPolicy("sometimes")
+	assert.NotNil(t, err)
+}
+
+func TestKVStore_AOFReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "appendonly.aof")
+	kvstore := openTestAOF(t, path)
+
+	kvstore.Set("a", 1)
+	kvstore.SetWithTTL("b", 2, time.Hour)
+	kvstore.Set("c", 3)
+	kvstore.Update("a", func(v int, ok bool) (int, error) {
+		return v + 10, nil
+	})
+	kvstore.Delete("c")
+	kvstore.Set("d", 4)
+	kvstore.Expire("d", time.Hour)
+	kvstore.Persist("d")
+	kvstore.SetWithTTL("e", 5, time.Hour)
+	kvstore.Update("e", func(v int, ok bool) (int, error) {
+		return v + 1, nil
+	})
+	assert.Nil(t, kvstore.CloseAOF())
+
+	replayed := openTestAOF(t, path)
+	defer replayed.CloseAOF()
+
+	assert.Equal(t, 11, replayed.Get("a"))
+	assert.Equal(t, 2, replayed.Get("b"))
+	assert.Equal(t, 0, replayed.Exists("c"))
+	assert.Equal(t, NoExpireTTL, replayed.TTL("d"))
+	assert.Equal(t, 6, replayed.Get("e"))
+	assert.True(t, replayed.TTL("b") > 59*time.Minute)
+	assert.True(t, replayed.TTL("e") > 59*time.Minute)
+}
+
+// addCommand add N to value of key
+type addCommand struct {
+	N int `json:"n"`
+}
+
+func (c *addCommand) Apply(v int, ok bool) (int, bool, error) {
+	return v + c.N, true, nil
+}
+
+func TestKVStore_AOFReplayCommands(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "appendonly.aof")
+	codec := CommandCodec[int]{
+		Marshal: func(cmd Command[int]) ([]byte, error) {
+			return json.Marshal(cmd)
+		},
+		Unmarshal: func(data []byte) (Command[int], error) {
+			cmd := new(addCommand)
+			err := json.Unmarshal(data, cmd)
+			return cmd, err
+		},
+	}
+	kvstore := NewKVStore[int](nil)
+	kvstore.Commands = codec
+	assert.Nil(t, kvstore.OpenAOF(path, FsyncAlways))
+
+	kvstore.SetWithTTL("a", 1000, time.Hour)
+	kvstore.Exec("a", &addCommand{N: 2})
+	kvstore.Exec("b", &addCommand{N: 3})
+	assert.Nil(t, kvstore.CloseAOF())
+
+	// Commands are logged instead of values they give
+	ops := []aofOp{}
+	readAOF(path, func(rec *aofRecord[int]) error {
+		ops = append(ops, rec.Op)
+		if rec.Op == aofCommandOp {
+			assert.Equal(t, 0, rec.Value)
+		}
+		return nil
+	})
+	assert.Equal(t, []aofOp{aofSetOp, aofCommandOp, aofCommandOp}, ops)
+
+	replayed := NewKVStore[int](nil)
+	replayed.Commands = codec
+	assert.Nil(t, replayed.OpenAOF(path, FsyncAlways))
+	defer replayed.CloseAOF()
+
+	assert.Equal(t, 1002, replayed.Get("a"))
+	assert.Equal(t, 3, replayed.Get("b"))
+	assert.True(t, replayed.TTL("a") > 59*time.Minute)
+}
+
+func TestKVStore_AOFReplayCommandsWithoutCodec(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "appendonly.aof")
+	kvstore := openTestAOF(t, path)
+	kvstore.Exec("a", &addCommand{N: 2})
+	assert.Nil(t, kvstore.CloseAOF())
+
+	// Value is logged when store cannot encode commands
+	replayed := openTestAOF(t, path)
+	defer replayed.CloseAOF()
+
+	assert.Equal(t, 2, replayed.Get("a"))
+}
+
+func TestKVStore_AOFReplayDropExpired(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "appendonly.aof")
+	kvstore := openTestAOF(t, path)
+
+	kvstore.SetWithTTL("a", 1, 10*time.Millisecond)
+	assert.Nil(t, kvstore.CloseAOF())
+	time.Sleep(20 * time.Millisecond)
+
+	replayed := openTestAOF(t, path)
+	defer replayed.CloseAOF()
+
+	assert.Equal(t, 0, len(replayed.Storage))
+}
+
+func TestKVStore_AOFTruncatedTail(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "appendonly.aof")
+	kvstore := openTestAOF(t, path)
+	kvstore.Set("a", 1)
+	kvstore.Set("b", 2)
+	assert.Nil(t, kvstore.CloseAOF())
+
+	info, _ := os.Stat(path)
+	valid := info.Size()
+
+	// Crash in the middle of writing a record leave part of it
+	frame, _ := encodeAOFRecord(&aofRecord[int]{Op: aofSetOp, Key: "c", Value: 3})
+	f, _ := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	f.Write(frame[:len(frame)-2])
+	f.Close()
+
+	replayed := openTestAOF(t, path)
+	defer replayed.CloseAOF()
+
+	assert.Equal(t, 1, replayed.Get("a"))
+	assert.Equal(t, 2, replayed.Get("b"))
+	assert.Equal(t, 0, replayed.Exists("c"))
+
+	info, _ = os.Stat(path)
+	assert.Equal(t, valid, info.Size())
+}
+
+func TestKVStore_AOFCorrupted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "appendonly.aof")
+	kvstore := openTestAOF(t, path)
+	kvstore.Set("a", 1)
+	kvstore.Set("b", 2)
+	assert.Nil(t, kvstore.CloseAOF())
+
+	raw, _ := os.ReadFile(path)
+	raw[aofHeaderLen+2] ^= 0xff
+	os.WriteFile(path, raw, 0644)
+
+	err := NewKVStore[int](nil).OpenAOF(path, FsyncNo)
+
+	assert.NotNil(t, err)
+}
+
+func TestKVStore_RewriteAOF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "appendonly.aof")
+	kvstore := openTestAOF(t, path)
+
+	for i := 0; i < 100; i++ {
+		kvstore.Set("a", i)
+	}
+	kvstore.SetWithTTL("b", 1, time.Hour)
+	kvstore.Set("c", 1)
+	kvstore.Delete("c")
+
+	info, _ := os.Stat(path)
+	before := info.Size()
+
+	err := kvstore.RewriteAOF()
+	assert.Nil(t, err)
+
+	info, _ = os.Stat(path)
+	assert.Less(t, info.Size(), before)
+
+	// Writes after rewrite go to the rewritten log
+	kvstore.Set("d", 4)
+	assert.Nil(t, kvstore.CloseAOF())
+
+	replayed := openTestAOF(t, path)
+	defer replayed.CloseAOF()
+
+	assert.Equal(t, 99, replayed.Get("a"))
+	assert.Equal(t, 1, replayed.Get("b"))
+	assert.True(t, replayed.TTL("b") > 59*time.Minute)
+	assert.Equal(t, 0, replayed.Exists("c"))
+	assert.Equal(t, 4, replayed.Get("d"))
+}
+
+func TestKVStore_RewriteAOFKeepConcurrentWrites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "appendonly.aof")
+	kvstore := openTestAOF(t, path)
+	kvstore.Set("a", 1)
+
+	a, keys, err := kvstore.beginAOFRewrite()
+	assert.Nil(t, err)
+
+	// Written after rewrite started, must survive the rewrite
+	kvstore.Set("b", 2)
+	kvstore.Delete("a")
+
+	err = kvstore.rewriteAOF(a, keys)
+	assert.Nil(t, err)
+	assert.Nil(t, kvstore.CloseAOF())
+
+	replayed := openTestAOF(t, path)
+	defer replayed.CloseAOF()
+
+	assert.Equal(t, 0, replayed.Exists("a"))
+	assert.Equal(t, 2, replayed.Get("b"))
+}
+
+func TestKVStore_RewriteAOFNotEnabled(t *testing.T) {
+	kvstore := NewKVStore[int](nil)
+
+	assert.Equal(t, ErrAOFNotEnabled, kvstore.RewriteAOF())
+	assert.Equal(t, ErrAOFNotEnabled, kvstore.BackgroundRewriteAOF())
+}
@@ -4,10 +4,21 @@ import (
 	"encoding/json"
 	"log"
 	"os"
-	"strings"
+	"sort"
 	"sync"
+	"time"
 
-	"bitbucket.org/non-pn/mini-redis-go/internal/constant"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/glob"
+)
+
+const (
+	// Remaining ttl reported for a key that does not exist
+	KeyNotFoundTTL time.Duration = -2
+	// Remaining ttl reported for a key without expiration
+	NoExpireTTL time.Duration = -1
+
+	// Number of keys examined by one scan when count is not given
+	DefaultScanCount int = 10
 )
 
 type IKVStore[T any] interface {
@@ -17,14 +28,51 @@ type IKVStore[T any] interface {
 
 type KVStore[T any] struct {
 	sync.RWMutex
-	Storage   map[string]T
-	CachePath *string
+	Storage map[string]T
+	Expires map[string]time.Time
+	// Where Save write snapshot, values are encoded with Codec
+	SnapshotPath string
+	Codec        ValueCodec[T]
+	// Commands given to Exec are logged with it, without it their resulting value is
+	Commands CommandCodec[T]
+
+	waiters keyWaiters
+	aof     *aof
+
+	// Version of each key, taken from clock on every write. Deleted key keep its version
+	// while it is watched so watcher see it changed.
+	versions map[string]uint64
+	clock    uint64
+	watched  map[string]int
+
+	snapshot      *snapshotState
+	snapshotStart time.Time
+	// Writes since last snapshot
+	dirty    int64
+	lastSave time.Time
+}
+
+type cacheSnapshot[T any] struct {
+	Storage map[string]T     `json:"storage"`
+	Expires map[string]int64 `json:"expires,omitempty"`
 }
 
 func (kv *KVStore[T]) Get(k string) T {
 	kv.RLock()
-	defer kv.RUnlock()
 	v := kv.Storage[k]
+	expired := kv.isExpired(k, time.Now())
+	kv.RUnlock()
+
+	if expired {
+		kv.Lock()
+		defer kv.Unlock()
+		// Key might be overwritten between the two locks
+		if kv.isExpired(k, time.Now()) {
+			kv.delete(k)
+		}
+		var zero T
+		return zero
+	}
 
 	return v
 }
@@ -32,64 +80,377 @@ func (kv *KVStore[T]) Get(k string) T {
 func (kv *KVStore[T]) Set(k string, v T) {
 	kv.Lock()
 	defer kv.Unlock()
+	kv.set(k, v)
+}
+
+func (kv *KVStore[T]) set(k string, v T) {
+	kv.preserve(k)
 	kv.Storage[k] = v
+	delete(kv.Expires, k)
+	kv.written(&aofRecord[T]{Op: aofSetOp, Key: k, Value: v})
+	kv.waiters.notify(k)
 }
 
-func (kv *KVStore[T]) Delete(k string) {
+func (kv *KVStore[T]) SetWithTTL(k string, v T, ttl time.Duration) {
 	kv.Lock()
 	defer kv.Unlock()
-	delete(kv.Storage, k)
+	kv.setWithTTL(k, v, ttl)
 }
 
-func (kv *KVStore[T]) CacheStorage() error {
-	var cachepath string
-	if kv.CachePath == nil {
-		cachepath = constant.DefaultRedisCachePath
-	} else {
-		cachepath = *kv.CachePath
+func (kv *KVStore[T]) setWithTTL(k string, v T, ttl time.Duration) {
+	kv.preserve(k)
+	kv.Storage[k] = v
+	kv.setExpire(k, time.Now().Add(ttl))
+	kv.written(kv.setRecord(k))
+	kv.waiters.notify(k)
+}
+
+// Update replace value of key with result of fn under write lock, ok is false when key does not exist.
+// Time to live of existing key is kept. Value is left unchanged when fn return error.
+func (kv *KVStore[T]) Update(k string, fn func(v T, ok bool) (T, error)) (T, error) {
+	return kv.Compute(k, func(v T, ok bool) (T, bool, error) {
+		nv, err := fn(v, ok)
+		return nv, true, err
+	})
+}
+
+// Compute is Update that can also remove the key, key is deleted when fn return keep as false
+func (kv *KVStore[T]) Compute(k string, fn func(v T, ok bool) (T, bool, error)) (T, error) {
+	kv.Lock()
+	defer kv.Unlock()
+	return kv.compute(k, fn)
+}
+
+func (kv *KVStore[T]) compute(k string, fn func(v T, ok bool) (T, bool, error)) (T, error) {
+	if kv.isExpired(k, time.Now()) {
+		kv.delete(k)
 	}
 
-	cachedirarr := strings.Split(cachepath, "/")
-	if len(cachedirarr) > 0 {
-		cachedirarr = cachedirarr[:len(cachedirarr)-1]
+	return kv.apply(k, fn, func(nv T) *aofRecord[T] {
+		return &aofRecord[T]{Op: aofUpdateOp, Key: k, Value: nv}
+	})
+}
+
+// Command is a write on value of one key, Apply work like fn of Compute. Only the command
+// is logged so Apply must give the same result when replayed on the same value.
+type Command[T any] interface {
+	Apply(v T, ok bool) (T, bool, error)
+}
+
+// Exec is Compute that log cmd instead of the value it give
+func (kv *KVStore[T]) Exec(k string, cmd Command[T]) (T, error) {
+	kv.Lock()
+	defer kv.Unlock()
+	return kv.exec(k, cmd)
+}
+
+func (kv *KVStore[T]) exec(k string, cmd Command[T]) (T, error) {
+	if kv.isExpired(k, time.Now()) {
+		kv.delete(k)
 	}
-	cachedir := strings.Join(cachedirarr, "/")
 
-	err := os.MkdirAll(cachedir, os.ModePerm)
+	return kv.apply(k, cmd.Apply, func(nv T) *aofRecord[T] {
+		return kv.commandRecord(k, cmd, nv)
+	})
+}
+
+// apply replace value of key with result of fn, change is logged with record made by rec
+// and is not logged when rec is nil
+func (kv *KVStore[T]) apply(k string, fn func(v T, ok bool) (T, bool, error), rec func(nv T) *aofRecord[T]) (T, error) {
+	// fn may change value in place
+	kv.preserve(k)
+	v, ok := kv.Storage[k]
+	nv, keep, err := fn(v, ok)
 	if err != nil {
-		log.Println(err)
+		return v, err
 	}
 
-	json, err := json.Marshal(kv.Storage)
-	if err != nil {
-		log.Println(err)
-		return err
+	if !keep {
+		kv.delete(k)
+		return nv, nil
 	}
 
-	err = os.WriteFile(cachepath, []byte(json), os.ModePerm)
-	if err != nil {
-		log.Println(err)
-		return err
+	kv.Storage[k] = nv
+	if rec != nil {
+		kv.written(rec(nv))
 	}
+	kv.waiters.notify(k)
+	return nv, nil
+}
+
+// View call fn with value of key under read lock, fn must not keep or modify v outside the call
+func (kv *KVStore[T]) View(k string, fn func(v T, ok bool) error) error {
+	kv.RLock()
+	defer kv.RUnlock()
+	return kv.view(k, fn)
+}
+
+func (kv *KVStore[T]) view(k string, fn func(v T, ok bool) error) error {
+	if !kv.exists(k, time.Now()) {
+		var zero T
+		return fn(zero, false)
+	}
+
+	return fn(kv.Storage[k], true)
+}
+
+// ViewKeys is View over several keys read under one read lock, missing key is given as zero value
+func (kv *KVStore[T]) ViewKeys(keys []string, fn func(vs []T) error) error {
+	kv.RLock()
+	defer kv.RUnlock()
+	return kv.viewKeys(keys, fn)
+}
+
+func (kv *KVStore[T]) viewKeys(keys []string, fn func(vs []T) error) error {
+	now := time.Now()
+	vs := make([]T, len(keys))
+	for i, k := range keys {
+		if kv.exists(k, now) {
+			vs[i] = kv.Storage[k]
+		}
+	}
+
+	return fn(vs)
+}
+
+// WaitKeys return channel closed on next write to any of keys, cancel must be called once waiting is over.
+// Register before checking the keys so a write between check and wait is not missed.
+func (kv *KVStore[T]) WaitKeys(keys ...string) (<-chan struct{}, func()) {
+	return kv.waiters.wait(keys)
+}
+
+// Delete remove keys and return number of keys that existed
+func (kv *KVStore[T]) Delete(keys ...string) int {
+	kv.Lock()
+	defer kv.Unlock()
+	return kv.deleteKeys(keys)
+}
+
+func (kv *KVStore[T]) deleteKeys(keys []string) int {
+	n := 0
+	now := time.Now()
+	for _, k := range keys {
+		if kv.exists(k, now) {
+			n++
+		}
+		kv.delete(k)
+	}
+
+	return n
+}
 
-	return nil
+// Exists return number of given keys that exist, a key given twice is counted twice
+func (kv *KVStore[T]) Exists(keys ...string) int {
+	kv.RLock()
+	defer kv.RUnlock()
+	return kv.countExisting(keys)
+}
+
+func (kv *KVStore[T]) countExisting(keys []string) int {
+	n := 0
+	now := time.Now()
+	for _, k := range keys {
+		if kv.exists(k, now) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Scan examine up to count keys in lexical order after cursor and return the ones matching glob pattern.
+// Empty cursor start the iteration and returned empty cursor mean the iteration is done.
+// Key existing for the whole iteration is returned exactly once.
+func (kv *KVStore[T]) Scan(cursor string, match string, count int) (string, []string) {
+	kv.RLock()
+	candidates := kv.scanCandidates(cursor)
+	kv.RUnlock()
+
+	return scanPage(candidates, match, count)
+}
+
+// scanCandidates return keys after cursor in no particular order
+func (kv *KVStore[T]) scanCandidates(cursor string) []string {
+	now := time.Now()
+	candidates := make([]string, 0)
+	for k := range kv.Storage {
+		if (cursor == "" || k > cursor) && !kv.isExpired(k, now) {
+			candidates = append(candidates, k)
+		}
+	}
+
+	return candidates
+}
+
+// scanPage keep first count candidates in lexical order and filter them by match
+func scanPage(candidates []string, match string, count int) (string, []string) {
+	if count <= 0 {
+		count = DefaultScanCount
+	}
+
+	sort.Strings(candidates)
+
+	next := ""
+	if len(candidates) > count {
+		candidates = candidates[:count]
+		next = candidates[count-1]
+	}
+
+	keys := make([]string, 0, len(candidates))
+	for _, k := range candidates {
+		if match == "" || glob.Match(match, k) {
+			keys = append(keys, k)
+		}
+	}
+
+	return next, keys
+}
+
+// Expire set time to live of existing key, non-positive ttl delete the key immediately
+func (kv *KVStore[T]) Expire(k string, ttl time.Duration) bool {
+	kv.Lock()
+	defer kv.Unlock()
+	return kv.expire(k, ttl)
+}
+
+func (kv *KVStore[T]) expire(k string, ttl time.Duration) bool {
+	now := time.Now()
+	if !kv.exists(k, now) {
+		return false
+	}
+
+	if ttl <= 0 {
+		kv.delete(k)
+		return true
+	}
+
+	at := now.Add(ttl)
+	kv.preserve(k)
+	kv.setExpire(k, at)
+	kv.written(&aofRecord[T]{Op: aofExpireOp, Key: k, ExpireAt: at.UnixMilli()})
+	return true
+}
+
+// TTL return remaining time to live of key, KeyNotFoundTTL or NoExpireTTL
+func (kv *KVStore[T]) TTL(k string) time.Duration {
+	kv.RLock()
+	defer kv.RUnlock()
+	return kv.ttl(k)
+}
+
+func (kv *KVStore[T]) ttl(k string) time.Duration {
+	now := time.Now()
+	if !kv.exists(k, now) {
+		return KeyNotFoundTTL
+	}
+
+	at, ok := kv.Expires[k]
+	if !ok {
+		return NoExpireTTL
+	}
+
+	return at.Sub(now)
+}
+
+// Persist remove time to live of key, return false if key has no ttl
+func (kv *KVStore[T]) Persist(k string) bool {
+	kv.Lock()
+	defer kv.Unlock()
+	return kv.persist(k)
+}
+
+func (kv *KVStore[T]) persist(k string) bool {
+	if !kv.exists(k, time.Now()) {
+		return false
+	}
+
+	if _, ok := kv.Expires[k]; !ok {
+		return false
+	}
+
+	kv.preserve(k)
+	delete(kv.Expires, k)
+	kv.written(&aofRecord[T]{Op: aofPersistOp, Key: k})
+	return true
+}
+
+// DeleteExpired remove every expired key and return number of removed keys
+func (kv *KVStore[T]) DeleteExpired() int {
+	kv.Lock()
+	defer kv.Unlock()
+
+	n := 0
+	now := time.Now()
+	for k := range kv.Expires {
+		if kv.isExpired(k, now) {
+			kv.delete(k)
+			n++
+		}
+	}
+
+	return n
+}
+
+func (kv *KVStore[T]) exists(k string, now time.Time) bool {
+	_, ok := kv.Storage[k]
+	return ok && !kv.isExpired(k, now)
+}
+
+func (kv *KVStore[T]) isExpired(k string, now time.Time) bool {
+	at, ok := kv.Expires[k]
+	return ok && !now.Before(at)
+}
+
+func (kv *KVStore[T]) setExpire(k string, at time.Time) {
+	if kv.Expires == nil {
+		kv.Expires = make(map[string]time.Time)
+	}
+	kv.Expires[k] = at
+}
+
+func (kv *KVStore[T]) delete(k string) {
+	if _, ok := kv.Storage[k]; !ok {
+		return
+	}
+
+	kv.preserve(k)
+	delete(kv.Storage, k)
+	delete(kv.Expires, k)
+	kv.written(&aofRecord[T]{Op: aofDeleteOp, Key: k})
+	if kv.watched[k] == 0 {
+		delete(kv.versions, k)
+	}
+}
+
+// written account a change described by rec, must be called with write lock held after the change
+func (kv *KVStore[T]) written(rec *aofRecord[T]) {
+	kv.dirty++
+	kv.clock++
+	if kv.versions == nil {
+		kv.versions = make(map[string]uint64)
+	}
+	kv.versions[rec.Key] = kv.clock
+	kv.logAOF(rec)
 }
 
 func NewKVStore[T any](cachepath *string) *KVStore[T] {
-	storage := loadStorageFromFile[T](cachepath)
+	storage, expires := loadStorageFromFile[T](cachepath)
 
 	return &KVStore[T]{
-		Storage: storage,
+		Storage:  storage,
+		Expires:  expires,
+		lastSave: time.Now(),
 	}
 }
 
-func loadStorageFromFile[T any](path *string) map[string]T {
+func loadStorageFromFile[T any](path *string) (map[string]T, map[string]time.Time) {
 	var (
 		cachepath string
 		storage   = make(map[string]T)
+		expires   = make(map[string]time.Time)
 	)
 	if path == nil {
-		return storage
+		return storage, expires
 	} else {
 		cachepath = *path
 	}
@@ -97,13 +458,31 @@ func loadStorageFromFile[T any](path *string) map[string]T {
 	dat, err := os.ReadFile(cachepath)
 	if err != nil {
 		log.Println("There is a problem load from file, start with empty storage")
-		return storage
+		return storage, expires
 	}
 
-	err = json.Unmarshal(dat, &storage)
-	if err != nil {
-		return storage
+	snapshot := cacheSnapshot[T]{}
+	err = json.Unmarshal(dat, &snapshot)
+	if err != nil || snapshot.Storage == nil {
+		// Fallback to cache written before ttl support, a plain key value map
+		storage = make(map[string]T)
+		err = json.Unmarshal(dat, &storage)
+		if err != nil {
+			return make(map[string]T), expires
+		}
+
+		return storage, expires
+	}
+
+	now := time.Now()
+	for k, ms := range snapshot.Expires {
+		at := time.UnixMilli(ms)
+		if !now.Before(at) {
+			delete(snapshot.Storage, k)
+			continue
+		}
+		expires[k] = at
 	}
 
-	return storage
+	return snapshot.Storage, expires
 }
@@ -1,8 +1,10 @@
 package db
 
 import (
+	"errors"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -40,6 +42,117 @@ func TestKVStore_Set(t *testing.T) {
 	assert.Equal(t, testval, val)
 }
 
+func TestKVStore_Update(t *testing.T) {
+	testkey := "test_key"
+	kvstore := NewKVStore[int](nil)
+	incr := func(v int, ok bool) (int, error) {
+		return v + 1, nil
+	}
+
+	val, err := kvstore.Update(testkey, incr)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, val)
+
+	val, err = kvstore.Update(testkey, incr)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, val)
+	assert.Equal(t, 2, kvstore.Get(testkey))
+}
+
+func TestKVStore_UpdateError(t *testing.T) {
+	testkey := "test_key"
+	kvstore := NewKVStore[int](nil)
+	kvstore.Set(testkey, 1)
+
+	_, err := kvstore.Update(testkey, func(v int, ok bool) (int, error) {
+		return 0, errors.New("test")
+	})
+
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, kvstore.Get(testkey))
+}
+
+func TestKVStore_UpdateKeepTTL(t *testing.T) {
+	testkey := "test_key"
+	kvstore := NewKVStore[int](nil)
+	kvstore.SetWithTTL(testkey, 1, time.Minute)
+
+	kvstore.Update(testkey, func(v int, ok bool) (int, error) {
+		return v + 1, nil
+	})
+
+	assert.Greater(t, kvstore.TTL(testkey), time.Duration(0))
+}
+
+func TestKVStore_ComputeDelete(t *testing.T) {
+	testkey := "test_key"
+	kvstore := NewKVStore[int](nil)
+	kvstore.Set(testkey, 1)
+
+	_, err := kvstore.Compute(testkey, func(v int, ok bool) (int, bool, error) {
+		return 0, false, nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, kvstore.Exists(testkey))
+}
+
+func TestKVStore_View(t *testing.T) {
+	testkey := "test_key"
+	kvstore := NewKVStore[int](nil)
+	kvstore.Set(testkey, 1)
+
+	var got int
+	err := kvstore.View(testkey, func(v int, ok bool) error {
+		assert.True(t, ok)
+		got = v
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, got)
+}
+
+func TestKVStore_ViewKeys(t *testing.T) {
+	kvstore := NewKVStore[int](nil)
+	kvstore.Set("a", 1)
+	kvstore.SetWithTTL("b", 2, -time.Second)
+
+	var got []int
+	err := kvstore.ViewKeys([]string{"a", "b", "c"}, func(vs []int) error {
+		got = append(got, vs...)
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []int{1, 0, 0}, got)
+}
+
+func TestKVStore_WaitKeys(t *testing.T) {
+	kvstore := NewKVStore[int](nil)
+
+	wake, cancel := kvstore.WaitKeys("a", "b")
+	defer cancel()
+
+	kvstore.Set("c", 1)
+	select {
+	case <-wake:
+		t.Errorf("Woken by write to other key")
+	default:
+	}
+
+	kvstore.Update("b", func(v int, ok bool) (int, error) {
+		return 1, nil
+	})
+	select {
+	case <-wake:
+	case <-time.After(time.Second):
+		t.Errorf("Not woken by write to waited key")
+	}
+}
+
 func TestKVStore_Delete(t *testing.T) {
 	testkey := "test_key"
 	testval := "test_val"
@@ -56,10 +169,173 @@ func TestKVStore_Delete(t *testing.T) {
 	assert.Equal(t, "", val)
 }
 
+func TestKVStore_DeleteMultipleKeys(t *testing.T) {
+	kvstore := NewKVStore[string](nil)
+	kvstore.Set("a", "a")
+	kvstore.Set("b", "b")
+	kvstore.Set("c", "c")
+
+	n := kvstore.Delete("a", "b", "unset")
+
+	assert.Equal(t, 2, n)
+	assert.Equal(t, 1, len(kvstore.Storage))
+}
+
+func TestKVStore_Exists(t *testing.T) {
+	kvstore := NewKVStore[string](nil)
+	kvstore.Set("a", "a")
+	kvstore.SetWithTTL("expired", "expired", time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+
+	assert.Equal(t, 1, kvstore.Exists("a"))
+	assert.Equal(t, 2, kvstore.Exists("a", "a", "unset"))
+	assert.Equal(t, 0, kvstore.Exists("expired"))
+}
+
+func TestKVStore_Scan(t *testing.T) {
+	kvstore := NewKVStore[string](nil)
+	for _, k := range []string{"user:1", "user:2", "user:3", "session:1", "session:2"} {
+		kvstore.Set(k, k)
+	}
+
+	cursor, keys := kvstore.Scan("", "", 2)
+
+	assert.Equal(t, "session:2", cursor)
+	assert.Equal(t, []string{"session:1", "session:2"}, keys)
+
+	cursor, keys = kvstore.Scan(cursor, "", 2)
+
+	assert.Equal(t, "user:2", cursor)
+	assert.Equal(t, []string{"user:1", "user:2"}, keys)
+
+	cursor, keys = kvstore.Scan(cursor, "", 2)
+
+	assert.Equal(t, "", cursor)
+	assert.Equal(t, []string{"user:3"}, keys)
+}
+
+func TestKVStore_ScanMatch(t *testing.T) {
+	kvstore := NewKVStore[string](nil)
+	for _, k := range []string{"user:1", "user:2", "session:1"} {
+		kvstore.Set(k, k)
+	}
+	kvstore.SetWithTTL("user:expired", "expired", time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+
+	found := []string{}
+	cursor := ""
+	for {
+		next, keys := kvstore.Scan(cursor, "user:*", 1)
+		found = append(found, keys...)
+		if next == "" {
+			break
+		}
+		cursor = next
+	}
+
+	assert.Equal(t, []string{"user:1", "user:2"}, found)
+}
+
+func TestKVStore_SetWithTTL(t *testing.T) {
+	testkey := "test_key"
+	testval := "test_val"
+	kvstore := NewKVStore[string](nil)
+
+	kvstore.SetWithTTL(testkey, testval, time.Minute)
+
+	assert.Equal(t, testval, kvstore.Get(testkey))
+	assert.Greater(t, kvstore.TTL(testkey), time.Duration(0))
+	assert.LessOrEqual(t, kvstore.TTL(testkey), time.Minute)
+}
+
+func TestKVStore_GetExpired(t *testing.T) {
+	testkey := "test_key"
+	testval := "test_val"
+	kvstore := NewKVStore[string](nil)
+
+	kvstore.SetWithTTL(testkey, testval, time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+
+	assert.Equal(t, "", kvstore.Get(testkey))
+	assert.Equal(t, 0, len(kvstore.Storage))
+	assert.Equal(t, 0, len(kvstore.Expires))
+}
+
+func TestKVStore_SetClearTTL(t *testing.T) {
+	testkey := "test_key"
+	kvstore := NewKVStore[string](nil)
+
+	kvstore.SetWithTTL(testkey, "a", time.Minute)
+	kvstore.Set(testkey, "b")
+
+	assert.Equal(t, NoExpireTTL, kvstore.TTL(testkey))
+}
+
+func TestKVStore_Expire(t *testing.T) {
+	testkey := "test_key"
+	kvstore := NewKVStore[string](nil)
+
+	assert.False(t, kvstore.Expire(testkey, time.Minute))
+
+	kvstore.Set(testkey, "test_val")
+
+	assert.True(t, kvstore.Expire(testkey, time.Minute))
+	assert.Greater(t, kvstore.TTL(testkey), time.Duration(0))
+}
+
+func TestKVStore_ExpireNonPositive(t *testing.T) {
+	testkey := "test_key"
+	kvstore := NewKVStore[string](nil)
+
+	kvstore.Set(testkey, "test_val")
+
+	assert.True(t, kvstore.Expire(testkey, 0))
+	assert.Equal(t, KeyNotFoundTTL, kvstore.TTL(testkey))
+}
+
+func TestKVStore_TTL(t *testing.T) {
+	kvstore := NewKVStore[string](nil)
+	kvstore.Set("no_ttl", "test_val")
+
+	assert.Equal(t, KeyNotFoundTTL, kvstore.TTL("unset"))
+	assert.Equal(t, NoExpireTTL, kvstore.TTL("no_ttl"))
+}
+
+func TestKVStore_Persist(t *testing.T) {
+	testkey := "test_key"
+	kvstore := NewKVStore[string](nil)
+
+	assert.False(t, kvstore.Persist(testkey))
+
+	kvstore.Set(testkey, "test_val")
+
+	assert.False(t, kvstore.Persist(testkey))
+
+	kvstore.Expire(testkey, time.Minute)
+
+	assert.True(t, kvstore.Persist(testkey))
+	assert.Equal(t, NoExpireTTL, kvstore.TTL(testkey))
+}
+
+func TestKVStore_DeleteExpired(t *testing.T) {
+	kvstore := NewKVStore[string](nil)
+	kvstore.SetWithTTL("a", "a", time.Millisecond)
+	kvstore.SetWithTTL("b", "b", time.Minute)
+	kvstore.Set("c", "c")
+	time.Sleep(2 * time.Millisecond)
+
+	n := kvstore.DeleteExpired()
+
+	assert.Equal(t, 1, n)
+	assert.Equal(t, 2, len(kvstore.Storage))
+	assert.Equal(t, 1, len(kvstore.Expires))
+}
+
 func TestLoadFromNilFile(t *testing.T) {
-	storage := loadStorageFromFile[string](nil)
+	storage, expires := loadStorageFromFile[string](nil)
 
 	assert.Equal(t, 0, len(storage))
+	assert.Equal(t, 0, len(expires))
 }
 
 func TestLoadFromFile(t *testing.T) {
@@ -67,29 +343,28 @@ func TestLoadFromFile(t *testing.T) {
 
 	assert.Nil(t, err)
 
-	storage := loadStorageFromFile[string](&path)
+	storage, _ := loadStorageFromFile[string](&path)
 
 	assert.Greater(t, len(storage), 0)
 }
 
-func TestLoadFromInvalidFile(t *testing.T) {
-	path, err := filepath.Abs("./test/test_invalid_cache.js")
+func TestLoadFromLegacyFile(t *testing.T) {
+	path, err := filepath.Abs("./test/test_legacy_cache.json")
 
 	assert.Nil(t, err)
 
-	storage := loadStorageFromFile[string](&path)
+	storage, expires := loadStorageFromFile[string](&path)
 
-	assert.Equal(t, 0, len(storage))
+	assert.Equal(t, 3, len(storage))
+	assert.Equal(t, 0, len(expires))
 }
 
-func TestCacheStorageToFile(t *testing.T) {
-	path, _ := filepath.Abs("./test/test_cache.json")
-	kvstore := &KVStore[string]{
-		Storage:   map[string]string{"test": "test", "a": "a", "b": "b"},
-		CachePath: &path,
-	}
-
-	err := kvstore.CacheStorage()
+func TestLoadFromInvalidFile(t *testing.T) {
+	path, err := filepath.Abs("./test/test_invalid_cache.js")
 
 	assert.Nil(t, err)
+
+	storage, _ := loadStorageFromFile[string](&path)
+
+	assert.Equal(t, 0, len(storage))
 }
@@ -0,0 +1,291 @@
+package model
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/constant"
+)
+
+// Client is a connection known to server, its context is cancelled once it is closed
+// so requests blocked on its behalf let go
+type Client struct {
+	ID        uint64
+	Conn      net.Conn
+	CreatedAt time.Time
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	name      string
+	lastCmd   uint8
+	lastCmdAt time.Time
+	topics    map[string]struct{}
+	patterns  map[string]struct{}
+	// Nil when client is not between MULTI and EXEC, see Transaction
+	tx *Transaction
+	// Version of each watched key when it was watched
+	watched map[string]uint64
+
+	// Started on first push, see Outbox
+	outbox       *Outbox
+	outboxSize   int
+	outboxPolicy OverflowPolicy
+}
+
+func (c *Client) Context() context.Context {
+	return c.ctx
+}
+
+func (c *Client) Done() <-chan struct{} {
+	return c.ctx.Done()
+}
+
+// Close cancel context of client and close its connection, closing twice is harmless
+func (c *Client) Close() error {
+	c.cancel()
+	return c.Conn.Close()
+}
+
+// Cancel release requests blocked on behalf of client without closing connection
+func (c *Client) Cancel() {
+	c.cancel()
+}
+
+// Outbox return queue of pushes to client, its writer run until client is closed. Client is
+// closed too when its outbox overflow under Disconnect policy.
+func (c *Client) Outbox() *Outbox {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.outbox == nil {
+		c.outbox = newOutbox(c.outboxSize, c.outboxPolicy, func() {
+			c.Close()
+		})
+		go c.outbox.run(c.ctx.Done())
+	}
+	return c.outbox
+}
+
+func (c *Client) Name() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.name
+}
+
+func (c *Client) SetName(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.name = name
+}
+
+// Touch record command client sent last and when
+func (c *Client) Touch(cmd uint8, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lastCmd = cmd
+	c.lastCmdAt = now
+}
+
+func (c *Client) LastCommand() (uint8, time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lastCmd, c.lastCmdAt
+}
+
+func (c *Client) Subscribe(topic string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.topics[topic] = struct{}{}
+}
+
+func (c *Client) Unsubscribe(topic string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.topics, topic)
+}
+
+// Topics return topics client is subscribed to in sorted order
+func (c *Client) Topics() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	topics := make([]string, 0, len(c.topics))
+	for t := range c.topics {
+		topics = append(topics, t)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+func (c *Client) PSubscribe(pattern string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.patterns[pattern] = struct{}{}
+}
+
+func (c *Client) PUnsubscribe(pattern string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.patterns, pattern)
+}
+
+// Patterns return patterns client is subscribed to in sorted order
+func (c *Client) Patterns() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	patterns := make([]string, 0, len(c.patterns))
+	for p := range c.patterns {
+		patterns = append(patterns, p)
+	}
+	sort.Strings(patterns)
+	return patterns
+}
+
+// Subscriptions return number of topics and patterns client is subscribed to
+func (c *Client) Subscriptions() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.topics) + len(c.patterns)
+}
+
+// Info describe client on one line as space separated key=value pairs, age and idle are in seconds
+func (c *Client) Info(now time.Time) string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	idle := c.CreatedAt
+	if !c.lastCmdAt.IsZero() {
+		idle = c.lastCmdAt
+	}
+
+	return fmt.Sprintf("id=%d addr=%s name=%s age=%d idle=%d sub=%d psub=%d",
+		c.ID,
+		c.Conn.RemoteAddr().String(),
+		c.name,
+		int64(now.Sub(c.CreatedAt)/time.Second),
+		int64(now.Sub(idle)/time.Second),
+		len(c.topics),
+		len(c.patterns),
+	)
+}
+
+// ClientRegistry keep connected clients by ID, IDs are never reused
+type ClientRegistry struct {
+	sync.RWMutex
+	clients map[uint64]*Client
+	lastID  uint64
+
+	// Outbox of clients registered from now on
+	OutboxSize   int
+	OutboxPolicy OverflowPolicy
+}
+
+// Register add client for conn, its context is derived from ctx
+func (r *ClientRegistry) Register(ctx context.Context, conn net.Conn, now time.Time) *Client {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	cctx, cancel := context.WithCancel(ctx)
+
+	r.Lock()
+	defer r.Unlock()
+
+	r.lastID++
+	c := &Client{
+		ID:        r.lastID,
+		Conn:      conn,
+		CreatedAt: now,
+		ctx:       cctx,
+		cancel:    cancel,
+		topics:    make(map[string]struct{}),
+		patterns:  make(map[string]struct{}),
+
+		outboxSize:   r.OutboxSize,
+		outboxPolicy: r.OutboxPolicy,
+	}
+	r.clients[c.ID] = c
+	return c
+}
+
+func (r *ClientRegistry) Unregister(id uint64) {
+	r.Lock()
+	defer r.Unlock()
+
+	delete(r.clients, id)
+}
+
+func (r *ClientRegistry) Get(id uint64) (*Client, bool) {
+	r.RLock()
+	defer r.RUnlock()
+
+	c, ok := r.clients[id]
+	return c, ok
+}
+
+func (r *ClientRegistry) Len() int {
+	r.RLock()
+	defer r.RUnlock()
+
+	return len(r.clients)
+}
+
+// List return clients in order of ID
+func (r *ClientRegistry) List() []*Client {
+	r.RLock()
+	defer r.RUnlock()
+
+	clients := make([]*Client, 0, len(r.clients))
+	for _, c := range r.clients {
+		clients = append(clients, c)
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].ID < clients[j].ID
+	})
+	return clients
+}
+
+// Find return clients matching fn in order of ID
+func (r *ClientRegistry) Find(fn func(c *Client) bool) []*Client {
+	clients := r.List()
+	found := clients[:0]
+	for _, c := range clients {
+		if fn(c) {
+			found = append(found, c)
+		}
+	}
+	return found
+}
+
+// Info describe every client on its own line
+func (r *ClientRegistry) Info(now time.Time) string {
+	clients := r.List()
+	lines := make([]string, 0, len(clients))
+	for _, c := range clients {
+		lines = append(lines, c.Info(now))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func NewClientRegistry() *ClientRegistry {
+	return &ClientRegistry{
+		clients:    make(map[uint64]*Client),
+		OutboxSize: constant.DefaultSubscriberQueueSize,
+	}
+}
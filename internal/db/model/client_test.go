@@ -0,0 +1,103 @@
+package model
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestClientRegistry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn1 := mocknet.NewMockConn(ctrl)
+	conn2 := mocknet.NewMockConn(ctrl)
+
+	r := NewClientRegistry()
+	c1 := r.Register(nil, conn1, time.Now())
+	c2 := r.Register(nil, conn2, time.Now())
+
+	assert.Equal(t, uint64(1), c1.ID)
+	assert.Equal(t, uint64(2), c2.ID)
+	assert.Equal(t, []*Client{c1, c2}, r.List())
+
+	r.Unregister(c1.ID)
+	_, ok := r.Get(c1.ID)
+	assert.False(t, ok)
+	assert.Equal(t, 1, r.Len())
+
+	// IDs are not reused
+	c3 := r.Register(nil, conn1, time.Now())
+	assert.Equal(t, uint64(3), c3.ID)
+
+	found := r.Find(func(c *Client) bool {
+		return c.ID > 2
+	})
+	assert.Equal(t, []*Client{c3}, found)
+}
+
+func TestClientCloseCancelContext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	parent, cancel := context.WithCancel(context.Background())
+	c := NewClientRegistry().Register(parent, conn, time.Now())
+	assert.Nil(t, c.Context().Err())
+
+	conn.EXPECT().Close().Times(1)
+	c.Close()
+
+	assert.NotNil(t, c.Context().Err())
+
+	// Cancelling parent is seen by every client
+	other := NewClientRegistry().Register(parent, conn, time.Now())
+	cancel()
+	<-other.Done()
+}
+
+func TestClientInfo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	addr := mocknet.NewMockAddr(ctrl)
+
+	created := time.Now()
+	c := NewClientRegistry().Register(nil, conn, created)
+	c.SetName("worker")
+	c.Subscribe("news")
+	c.Subscribe("news")
+	c.PSubscribe("news.*")
+	c.Touch(0, created.Add(5*time.Second))
+
+	conn.EXPECT().RemoteAddr().Times(1).Return(addr)
+	addr.EXPECT().String().Times(1).Return("127.0.0.1:5000")
+
+	info := c.Info(created.Add(7 * time.Second))
+
+	assert.Equal(t, "id=1 addr=127.0.0.1:5000 name=worker age=7 idle=2 sub=1 psub=1", info)
+}
+
+func TestClientTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	c := NewClientRegistry().Register(nil, conn, time.Now())
+
+	assert.False(t, c.Queue(QueuedRequest{Cmd: 1}))
+	assert.True(t, c.Multi())
+	assert.False(t, c.Multi())
+	assert.True(t, c.InTransaction())
+	assert.True(t, c.Queue(QueuedRequest{Cmd: 1}))
+	c.FailTransaction()
+
+	tx := c.EndTransaction()
+	assert.Equal(t, &Transaction{Queued: []QueuedRequest{{Cmd: 1}}, Failed: true}, tx)
+	assert.False(t, c.InTransaction())
+	assert.Nil(t, c.EndTransaction())
+
+	// Key watched again keep its first version
+	assert.True(t, c.Watch("test_key", 1))
+	assert.False(t, c.Watch("test_key", 2))
+	assert.Equal(t, map[string]uint64{"test_key": 1}, c.Unwatch())
+	assert.Nil(t, c.Unwatch())
+}
@@ -0,0 +1,599 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+// Returned by command that changed nothing so the key is not written
+var ErrNoChange = errors.New("Nothing changed")
+
+// Command is a write on value of one key, missing key is given as nil with ok false and
+// key is deleted when keep is false. Only the command is logged to append only file, so
+// Apply depend on nothing but its fields and the value and replaying it give the same value.
+// Result for the reply is left in fields that are not logged.
+type Command interface {
+	Apply(v *Value, ok bool) (nv *Value, keep bool, err error)
+}
+
+type commandJSON struct {
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args"`
+}
+
+func MarshalCommand(cmd Command) ([]byte, error) {
+	name, err := commandName(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	args, err := json.Marshal(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(commandJSON{Name: name, Args: args})
+}
+
+func UnmarshalCommand(data []byte) (Command, error) {
+	cj := commandJSON{}
+	err := json.Unmarshal(data, &cj)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd, err := newCommand(cj.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(cj.Args, cmd)
+	return cmd, err
+}
+
+func commandName(cmd Command) (string, error) {
+	switch cmd.(type) {
+	case *PushCommand:
+		return "push", nil
+	case *PopCommand:
+		return "pop", nil
+	case *HSetCommand:
+		return "hset", nil
+	case *HDelCommand:
+		return "hdel", nil
+	case *HIncrByCommand:
+		return "hincrby", nil
+	case *SAddCommand:
+		return "sadd", nil
+	case *SRemCommand:
+		return "srem", nil
+	case *ZAddCommand:
+		return "zadd", nil
+	case *ZRemCommand:
+		return "zrem", nil
+	case *ZIncrByCommand:
+		return "zincrby", nil
+	case *XAddCommand:
+		return "xadd", nil
+	case *XTrimCommand:
+		return "xtrim", nil
+	case *XGroupCreateCommand:
+		return "xgroupcreate", nil
+	case *XGroupDestroyCommand:
+		return "xgroupdestroy", nil
+	case *XReadGroupCommand:
+		return "xreadgroup", nil
+	case *XAckCommand:
+		return "xack", nil
+	case *XClaimCommand:
+		return "xclaim", nil
+	default:
+		return "", errors.New("Unknown command")
+	}
+}
+
+func newCommand(name string) (Command, error) {
+	switch name {
+	case "push":
+		return new(PushCommand), nil
+	case "pop":
+		return new(PopCommand), nil
+	case "hset":
+		return new(HSetCommand), nil
+	case "hdel":
+		return new(HDelCommand), nil
+	case "hincrby":
+		return new(HIncrByCommand), nil
+	case "sadd":
+		return new(SAddCommand), nil
+	case "srem":
+		return new(SRemCommand), nil
+	case "zadd":
+		return new(ZAddCommand), nil
+	case "zrem":
+		return new(ZRemCommand), nil
+	case "zincrby":
+		return new(ZIncrByCommand), nil
+	case "xadd":
+		return new(XAddCommand), nil
+	case "xtrim":
+		return new(XTrimCommand), nil
+	case "xgroupcreate":
+		return new(XGroupCreateCommand), nil
+	case "xgroupdestroy":
+		return new(XGroupDestroyCommand), nil
+	case "xreadgroup":
+		return new(XReadGroupCommand), nil
+	case "xack":
+		return new(XAckCommand), nil
+	case "xclaim":
+		return new(XClaimCommand), nil
+	default:
+		return nil, errors.New("Unknown command " + name)
+	}
+}
+
+// PushCommand add elements to one end of list in order
+type PushCommand struct {
+	Left  bool     `json:"left"`
+	Elems [][]byte `json:"elems"`
+	// Length of list after push
+	Len int `json:"-"`
+}
+
+func (c *PushCommand) Apply(v *Value, ok bool) (*Value, bool, error) {
+	if !ok {
+		v = NewListValue()
+	}
+	if v.Type != ListValueType {
+		return v, true, ErrWrongType
+	}
+
+	for _, elem := range c.Elems {
+		if c.Left {
+			v.List.PushLeft(elem)
+		} else {
+			v.List.PushRight(elem)
+		}
+	}
+
+	c.Len = v.List.Len()
+	return v, true, nil
+}
+
+// PopCommand remove element from one end of list, list left empty is deleted
+type PopCommand struct {
+	Left bool `json:"left"`
+	// Removed element, nil when list is missing
+	Elem []byte `json:"-"`
+}
+
+func (c *PopCommand) Apply(v *Value, ok bool) (*Value, bool, error) {
+	if !ok {
+		return nil, false, nil
+	}
+	if v.Type != ListValueType {
+		return v, true, ErrWrongType
+	}
+
+	if c.Left {
+		c.Elem, _ = v.List.PopLeft()
+	} else {
+		c.Elem, _ = v.List.PopRight()
+	}
+
+	return v, v.List.Len() > 0, nil
+}
+
+// HSetCommand set fields of hash to raw tlv values
+type HSetCommand struct {
+	Fields map[string][]byte `json:"fields"`
+	// Number of fields added
+	Added int `json:"-"`
+}
+
+func (c *HSetCommand) Apply(v *Value, ok bool) (*Value, bool, error) {
+	if !ok {
+		v = NewHashValue()
+	}
+	if v.Type != HashValueType {
+		return v, true, ErrWrongType
+	}
+
+	c.Added = 0
+	for f, val := range c.Fields {
+		if _, ok := v.Hash[f]; !ok {
+			c.Added++
+		}
+		v.Hash[f] = val
+	}
+	return v, true, nil
+}
+
+// HDelCommand remove fields of hash, hash left empty is deleted
+type HDelCommand struct {
+	Fields []string `json:"fields"`
+	// Number of fields removed
+	Removed int `json:"-"`
+}
+
+func (c *HDelCommand) Apply(v *Value, ok bool) (*Value, bool, error) {
+	if !ok {
+		return nil, false, nil
+	}
+	if v.Type != HashValueType {
+		return v, true, ErrWrongType
+	}
+
+	c.Removed = 0
+	for _, f := range c.Fields {
+		if _, ok := v.Hash[f]; ok {
+			delete(v.Hash, f)
+			c.Removed++
+		}
+	}
+	if c.Removed == 0 {
+		return v, true, ErrNoChange
+	}
+
+	return v, len(v.Hash) > 0, nil
+}
+
+// HIncrByCommand add delta to integer value of hash field, missing field count as zero
+type HIncrByCommand struct {
+	Field string `json:"field"`
+	Delta int64  `json:"delta"`
+	// Value of field after increment
+	Result int64 `json:"-"`
+}
+
+func (c *HIncrByCommand) Apply(v *Value, ok bool) (*Value, bool, error) {
+	if !ok {
+		v = NewHashValue()
+	}
+	if v.Type != HashValueType {
+		return v, true, ErrWrongType
+	}
+
+	var cur int64
+	if raw, ok := v.Hash[c.Field]; ok {
+		n, err := ToInt64(raw)
+		if err != nil {
+			return v, true, err
+		}
+		cur = n
+	}
+
+	sum, err := AddInt64(cur, c.Delta)
+	if err != nil {
+		return v, true, err
+	}
+
+	res := tlv.Int64(sum)
+	raw, err := res.ToTLV()
+	if err != nil {
+		return v, true, err
+	}
+
+	v.Hash[c.Field] = raw
+	c.Result = sum
+	return v, true, nil
+}
+
+// SAddCommand add members to set
+type SAddCommand struct {
+	Members []string `json:"members"`
+	// Number of members added
+	Added int `json:"-"`
+}
+
+func (c *SAddCommand) Apply(v *Value, ok bool) (*Value, bool, error) {
+	if !ok {
+		v = NewSetValue()
+	}
+	if v.Type != SetValueType {
+		return v, true, ErrWrongType
+	}
+
+	c.Added = 0
+	for _, m := range c.Members {
+		if _, ok := v.Set[m]; !ok {
+			v.Set[m] = struct{}{}
+			c.Added++
+		}
+	}
+	return v, true, nil
+}
+
+// SRemCommand remove members of set, set left empty is deleted
+type SRemCommand struct {
+	Members []string `json:"members"`
+	// Number of members removed
+	Removed int `json:"-"`
+}
+
+func (c *SRemCommand) Apply(v *Value, ok bool) (*Value, bool, error) {
+	if !ok {
+		return nil, false, nil
+	}
+	if v.Type != SetValueType {
+		return v, true, ErrWrongType
+	}
+
+	c.Removed = 0
+	for _, m := range c.Members {
+		if _, ok := v.Set[m]; ok {
+			delete(v.Set, m)
+			c.Removed++
+		}
+	}
+	if c.Removed == 0 {
+		return v, true, ErrNoChange
+	}
+
+	return v, len(v.Set) > 0, nil
+}
+
+// ZAddCommand add members to sorted set or update their scores
+type ZAddCommand struct {
+	Scores map[string]float64 `json:"scores"`
+	// Number of members added
+	Added int `json:"-"`
+}
+
+func (c *ZAddCommand) Apply(v *Value, ok bool) (*Value, bool, error) {
+	if !ok {
+		v = NewZSetValue()
+	}
+	if v.Type != ZSetValueType {
+		return v, true, ErrWrongType
+	}
+
+	c.Added = 0
+	for m, score := range c.Scores {
+		if v.ZSet.Add(m, score) {
+			c.Added++
+		}
+	}
+	return v, true, nil
+}
+
+// ZRemCommand remove members of sorted set, sorted set left empty is deleted
+type ZRemCommand struct {
+	Members []string `json:"members"`
+	// Number of members removed
+	Removed int `json:"-"`
+}
+
+func (c *ZRemCommand) Apply(v *Value, ok bool) (*Value, bool, error) {
+	if !ok {
+		return nil, false, nil
+	}
+	if v.Type != ZSetValueType {
+		return v, true, ErrWrongType
+	}
+
+	c.Removed = 0
+	for _, m := range c.Members {
+		if v.ZSet.Remove(m) {
+			c.Removed++
+		}
+	}
+	if c.Removed == 0 {
+		return v, true, ErrNoChange
+	}
+
+	return v, v.ZSet.Len() > 0, nil
+}
+
+// ZIncrByCommand add delta to score of member, missing member count as zero
+type ZIncrByCommand struct {
+	Member string  `json:"member"`
+	Delta  float64 `json:"delta"`
+	// Score of member after increment
+	Score float64 `json:"-"`
+}
+
+func (c *ZIncrByCommand) Apply(v *Value, ok bool) (*Value, bool, error) {
+	if !ok {
+		v = NewZSetValue()
+	}
+	if v.Type != ZSetValueType {
+		return v, true, ErrWrongType
+	}
+
+	cur, _ := v.ZSet.Score(c.Member)
+	sum := cur + c.Delta
+	if math.IsNaN(sum) || math.IsInf(sum, 0) {
+		return v, true, tlv.NewCodeError(tlv.InvalidIncrementError)
+	}
+
+	v.ZSet.Add(c.Member, sum)
+	c.Score = sum
+	return v, true, nil
+}
+
+// XAddCommand add entry to stream and trim it, id is generated from Now when Auto is true
+type XAddCommand struct {
+	ID     StreamID          `json:"id"`
+	Auto   bool              `json:"auto"`
+	Fields map[string][]byte `json:"fields"`
+	Trim   StreamTrim        `json:"trim"`
+	Now    time.Time         `json:"now"`
+	// Id of added entry
+	Added StreamID `json:"-"`
+}
+
+func (c *XAddCommand) Apply(v *Value, ok bool) (*Value, bool, error) {
+	if !ok {
+		v = NewStreamValue()
+	}
+	if v.Type != StreamValueType {
+		return v, true, ErrWrongType
+	}
+
+	id, err := v.Stream.Add(c.ID, c.Auto, c.Fields, c.Now)
+	if err != nil {
+		return v, true, err
+	}
+	v.Stream.Trim(c.Trim, c.Now)
+
+	c.Added = id
+	return v, true, nil
+}
+
+// XTrimCommand trim stream, stream trimmed empty is kept so its ids keep increasing
+type XTrimCommand struct {
+	Trim StreamTrim `json:"trim"`
+	Now  time.Time  `json:"now"`
+	// Number of entries removed
+	Removed int `json:"-"`
+}
+
+func (c *XTrimCommand) Apply(v *Value, ok bool) (*Value, bool, error) {
+	if !ok {
+		return nil, false, nil
+	}
+	if v.Type != StreamValueType {
+		return v, true, ErrWrongType
+	}
+
+	c.Removed = v.Stream.Trim(c.Trim, c.Now)
+	if c.Removed == 0 {
+		return v, true, ErrNoChange
+	}
+	return v, true, nil
+}
+
+// XGroupCreateCommand add group delivering entries after Last, or after last entry of
+// stream when New is true. Stream is created when it does not exist.
+type XGroupCreateCommand struct {
+	Group string   `json:"group"`
+	Last  StreamID `json:"last"`
+	New   bool     `json:"new"`
+}
+
+func (c *XGroupCreateCommand) Apply(v *Value, ok bool) (*Value, bool, error) {
+	if !ok {
+		v = NewStreamValue()
+	}
+	if v.Type != StreamValueType {
+		return v, true, ErrWrongType
+	}
+
+	last := c.Last
+	if c.New {
+		last = v.Stream.LastID()
+	}
+	return v, true, v.Stream.CreateGroup(c.Group, last)
+}
+
+// XGroupDestroyCommand remove group of stream
+type XGroupDestroyCommand struct {
+	Group string `json:"group"`
+}
+
+func (c *XGroupDestroyCommand) Apply(v *Value, ok bool) (*Value, bool, error) {
+	if !ok {
+		return nil, false, ErrNoChange
+	}
+	if v.Type != StreamValueType {
+		return v, true, ErrWrongType
+	}
+
+	if !v.Stream.DestroyGroup(c.Group) {
+		return v, true, ErrNoChange
+	}
+	return v, true, nil
+}
+
+// XReadGroupCommand deliver entries to consumer of group, entries never delivered to
+// group when After is nil and entries pending for consumer after it otherwise
+type XReadGroupCommand struct {
+	Group    string    `json:"group"`
+	Consumer string    `json:"consumer"`
+	After    *StreamID `json:"after,omitempty"`
+	Count    int       `json:"count"`
+	Now      time.Time `json:"now"`
+	// Delivered entries
+	Entries []StreamEntry `json:"-"`
+}
+
+func (c *XReadGroupCommand) Apply(v *Value, ok bool) (*Value, bool, error) {
+	if !ok {
+		return nil, false, ErrNoGroup
+	}
+	if v.Type != StreamValueType {
+		return v, true, ErrWrongType
+	}
+
+	var err error
+	if c.After == nil {
+		c.Entries, err = v.Stream.ReadGroup(c.Group, c.Consumer, c.Count, c.Now)
+	} else {
+		c.Entries, err = v.Stream.ReadPending(c.Group, c.Consumer, *c.After, c.Count, c.Now)
+	}
+	if err != nil {
+		return v, true, err
+	}
+	if len(c.Entries) == 0 {
+		return v, true, ErrNoChange
+	}
+	return v, true, nil
+}
+
+// XAckCommand remove entries from pending entries of group
+type XAckCommand struct {
+	Group string     `json:"group"`
+	IDs   []StreamID `json:"ids"`
+	// Number of entries that were pending
+	Acked int `json:"-"`
+}
+
+func (c *XAckCommand) Apply(v *Value, ok bool) (*Value, bool, error) {
+	if !ok {
+		return nil, false, ErrNoChange
+	}
+	if v.Type != StreamValueType {
+		return v, true, ErrWrongType
+	}
+
+	var err error
+	c.Acked, err = v.Stream.Ack(c.Group, c.IDs)
+	if err != nil {
+		return v, true, err
+	}
+	if c.Acked == 0 {
+		return v, true, ErrNoChange
+	}
+	return v, true, nil
+}
+
+// XClaimCommand hand pending entries of group idle for at least MinIdle to consumer
+type XClaimCommand struct {
+	Group    string        `json:"group"`
+	Consumer string        `json:"consumer"`
+	MinIdle  time.Duration `json:"min_idle"`
+	IDs      []StreamID    `json:"ids"`
+	Count    int           `json:"count"`
+	Now      time.Time     `json:"now"`
+	// Claimed entries
+	Entries []StreamEntry `json:"-"`
+}
+
+func (c *XClaimCommand) Apply(v *Value, ok bool) (*Value, bool, error) {
+	if !ok {
+		return nil, false, ErrNoGroup
+	}
+	if v.Type != StreamValueType {
+		return v, true, ErrWrongType
+	}
+
+	var err error
+	c.Entries, err = v.Stream.Claim(c.Group, c.Consumer, c.MinIdle, c.IDs, c.Count, c.Now)
+	return v, true, err
+}
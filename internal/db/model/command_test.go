@@ -0,0 +1,44 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommandJSON(t *testing.T) {
+	cmd := &XAddCommand{Auto: true, Fields: map[string][]byte{"f": []byte("v")}, Trim: StreamTrim{MaxLen: 1}, Now: time.Now()}
+	v, keep, err := cmd.Apply(nil, false)
+	assert.Nil(t, err)
+	assert.True(t, keep)
+
+	raw, err := MarshalCommand(cmd)
+	assert.Nil(t, err)
+
+	// Replayed command give the same entry
+	res, err := UnmarshalCommand(raw)
+	assert.Nil(t, err)
+	replayed, _, err := res.Apply(nil, false)
+	assert.Nil(t, err)
+	assert.Equal(t, cmd.Added, res.(*XAddCommand).Added)
+	assert.Equal(t, v.Stream.Range(StreamID{}, MaxStreamID, 0), replayed.Stream.Range(StreamID{}, MaxStreamID, 0))
+}
+
+func TestUnmarshalCommandUnknown(t *testing.T) {
+	_, err := UnmarshalCommand([]byte(`{"name":"unknown","args":{}}`))
+
+	assert.NotNil(t, err)
+}
+
+func TestPopCommandDeleteEmptyList(t *testing.T) {
+	v := NewListValue()
+	v.List.PushRight([]byte("a"))
+
+	cmd := &PopCommand{Left: true}
+	_, keep, err := cmd.Apply(v, true)
+
+	assert.Nil(t, err)
+	assert.False(t, keep)
+	assert.Equal(t, []byte("a"), cmd.Elem)
+}
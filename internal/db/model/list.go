@@ -0,0 +1,96 @@
+package model
+
+import (
+	"container/list"
+	"encoding/json"
+)
+
+// List is a double ended list of raw tlv elements
+type List struct {
+	elems *list.List
+}
+
+func (l *List) Len() int {
+	return l.elems.Len()
+}
+
+func (l *List) PushLeft(v []byte) {
+	l.elems.PushFront(v)
+}
+
+func (l *List) PushRight(v []byte) {
+	l.elems.PushBack(v)
+}
+
+func (l *List) PopLeft() ([]byte, bool) {
+	e := l.elems.Front()
+	if e == nil {
+		return nil, false
+	}
+
+	return l.elems.Remove(e).([]byte), true
+}
+
+func (l *List) PopRight() ([]byte, bool) {
+	e := l.elems.Back()
+	if e == nil {
+		return nil, false
+	}
+
+	return l.elems.Remove(e).([]byte), true
+}
+
+// Range return elements from start to stop inclusive, negative index count from the end
+func (l *List) Range(start int, stop int) [][]byte {
+	n := l.elems.Len()
+	if start < 0 {
+		start += n
+	}
+	if stop < 0 {
+		stop += n
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= n {
+		stop = n - 1
+	}
+	if start > stop {
+		return [][]byte{}
+	}
+
+	res := make([][]byte, 0, stop-start+1)
+	i := 0
+	for e := l.elems.Front(); e != nil && i <= stop; e = e.Next() {
+		if i >= start {
+			res = append(res, e.Value.([]byte))
+		}
+		i++
+	}
+
+	return res
+}
+
+func (l *List) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.Range(0, -1))
+}
+
+func (l *List) UnmarshalJSON(data []byte) error {
+	items := [][]byte{}
+	err := json.Unmarshal(data, &items)
+	if err != nil {
+		return err
+	}
+
+	*l = *NewList()
+	for _, v := range items {
+		l.PushRight(v)
+	}
+	return nil
+}
+
+func NewList() *List {
+	return &List{
+		elems: list.New(),
+	}
+}
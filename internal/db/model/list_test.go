@@ -0,0 +1,56 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListPushPop(t *testing.T) {
+	l := NewList()
+	l.PushRight([]byte("b"))
+	l.PushLeft([]byte("a"))
+	l.PushRight([]byte("c"))
+
+	assert.Equal(t, 3, l.Len())
+
+	v, ok := l.PopLeft()
+	assert.True(t, ok)
+	assert.Equal(t, []byte("a"), v)
+
+	v, ok = l.PopRight()
+	assert.True(t, ok)
+	assert.Equal(t, []byte("c"), v)
+
+	l.PopLeft()
+	_, ok = l.PopLeft()
+	assert.False(t, ok)
+}
+
+func TestListRange(t *testing.T) {
+	l := NewList()
+	for _, v := range []string{"a", "b", "c", "d"} {
+		l.PushRight([]byte(v))
+	}
+
+	assert.Equal(t, [][]byte{[]byte("a"), []byte("b")}, l.Range(0, 1))
+	assert.Equal(t, [][]byte{[]byte("c"), []byte("d")}, l.Range(-2, -1))
+	assert.Equal(t, 4, len(l.Range(-100, 100)))
+	assert.Equal(t, 0, len(l.Range(3, 1)))
+}
+
+func TestListJSON(t *testing.T) {
+	l := NewList()
+	l.PushRight([]byte("a"))
+	l.PushRight([]byte("b"))
+
+	raw, err := json.Marshal(l)
+	assert.Nil(t, err)
+
+	res := new(List)
+	err = json.Unmarshal(raw, res)
+
+	assert.Nil(t, err)
+	assert.Equal(t, l.Range(0, -1), res.Range(0, -1))
+}
@@ -0,0 +1,46 @@
+package model
+
+import (
+	"math"
+	"strconv"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+// AddInt64 add delta to n and reject result that overflow
+func AddInt64(n int64, delta int64) (int64, error) {
+	if (delta > 0 && n > math.MaxInt64-delta) || (delta < 0 && n < math.MinInt64-delta) {
+		return 0, tlv.NewCodeError(tlv.NotIntegerError)
+	}
+
+	return n + delta, nil
+}
+
+// ToInt64 read stored value as integer, text values are parsed the way they were set by client
+func ToInt64(raw tlv.TypeLengthValue) (int64, error) {
+	switch raw.GetType() {
+	case tlv.Int64Type:
+		i := new(tlv.Int64)
+		err := i.FromTLV(raw)
+		if err != nil {
+			return 0, tlv.NewCodeError(tlv.NotIntegerError)
+		}
+		return int64(*i), nil
+	case tlv.Float64Type:
+		f := new(tlv.Float64)
+		err := f.FromTLV(raw)
+		if err != nil || float64(*f) != math.Trunc(float64(*f)) ||
+			float64(*f) < math.MinInt64 || float64(*f) >= math.MaxInt64 {
+			return 0, tlv.NewCodeError(tlv.NotIntegerError)
+		}
+		return int64(*f), nil
+	case tlv.StringType, tlv.BinaryType:
+		n, err := strconv.ParseInt(string(raw.GetValue()), 10, 64)
+		if err != nil {
+			return 0, tlv.NewCodeError(tlv.NotIntegerError)
+		}
+		return n, nil
+	default:
+		return 0, tlv.NewCodeError(tlv.NotIntegerError)
+	}
+}
@@ -0,0 +1,215 @@
+package model
+
+import (
+	"errors"
+	"log"
+	"net"
+	"sync"
+)
+
+// OverflowPolicy decide what happen to push queued for client whose outbox is full
+type OverflowPolicy uint8
+
+const (
+	// Drop push queued first to make room, client miss old messages rather than new ones
+	DropOldest OverflowPolicy = iota
+	// Drop push being queued
+	DropNewest
+	// Close connection of client that cannot keep up
+	Disconnect
+)
+
+var (
+	ErrOutboxFull   = errors.New("Outbox full")
+	ErrOutboxClosed = errors.New("Outbox closed")
+)
+
+func ParseOverflowPolicy(s string) (OverflowPolicy, error) {
+	switch s {
+	case "drop-oldest":
+		return DropOldest, nil
+	case "drop-newest":
+		return DropNewest, nil
+	case "disconnect":
+		return Disconnect, nil
+	default:
+		return 0, errors.New("Invalid overflow policy " + s)
+	}
+}
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case DropOldest:
+		return "drop-oldest"
+	case DropNewest:
+		return "drop-newest"
+	case Disconnect:
+		return "disconnect"
+	default:
+		return "unknown"
+	}
+}
+
+type outboxItem struct {
+	conn  net.Conn
+	frame []byte
+}
+
+// Outbox queue pushes to one client and a writer goroutine send them, so publisher never
+// wait for slow client. Queue hold up to size pushes, policy apply once it is full.
+type Outbox struct {
+	size     int
+	policy   OverflowPolicy
+	overflow func()
+
+	mu      sync.Mutex
+	queue   []outboxItem
+	dropped uint64
+	closed  bool
+	// Set from queue filling up until it is drained, so overflow is logged once for it
+	overflowing bool
+	// Signalled whenever push is queued, writer drain queue then
+	wake chan struct{}
+}
+
+// Conn return connection whose writes are queued on outbox and then written to conn, each
+// write must be one whole frame
+func (o *Outbox) Conn(conn net.Conn) net.Conn {
+	return &outboxConn{Conn: conn, outbox: o}
+}
+
+// Len return number of pushes waiting to be written
+func (o *Outbox) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return len(o.queue)
+}
+
+// Dropped return number of pushes dropped for outbox being full
+func (o *Outbox) Dropped() uint64 {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.dropped
+}
+
+func (o *Outbox) push(conn net.Conn, frame []byte) error {
+	o.mu.Lock()
+	if o.closed {
+		o.mu.Unlock()
+		return ErrOutboxClosed
+	}
+
+	if len(o.queue) >= o.size {
+		o.dropped++
+		if !o.overflowing {
+			o.overflowing = true
+			log.Println("outbox: queue full, applying", o.policy, "until it is drained")
+		}
+		switch o.policy {
+		case DropOldest:
+			o.queue = o.queue[1:]
+		case DropNewest:
+			o.mu.Unlock()
+			return ErrOutboxFull
+		case Disconnect:
+			o.closed = true
+			o.queue = nil
+			o.mu.Unlock()
+			o.overflow()
+			return ErrOutboxFull
+		}
+	}
+
+	o.queue = append(o.queue, outboxItem{conn: conn, frame: frame})
+	o.mu.Unlock()
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// take hand every queued push to writer
+func (o *Outbox) take() []outboxItem {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	items := o.queue
+	o.queue = nil
+	o.overflowing = false
+	return items
+}
+
+func (o *Outbox) close() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.closed = true
+	o.queue = nil
+}
+
+// run write queued pushes until done is closed, pushes still queued then are dropped
+func (o *Outbox) run(done <-chan struct{}) {
+	defer o.close()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-o.wake:
+		}
+
+		for items := o.take(); len(items) > 0; items = o.take() {
+			flushed := make(map[net.Conn]struct{})
+			for _, item := range items {
+				_, err := item.conn.Write(item.frame)
+				if err != nil {
+					log.Println("outbox:", err)
+				}
+			}
+			for _, item := range items {
+				if _, ok := flushed[item.conn]; ok {
+					continue
+				}
+				flushed[item.conn] = struct{}{}
+				if f, ok := item.conn.(interface{ Flush() error }); ok {
+					f.Flush()
+				}
+			}
+		}
+	}
+}
+
+func newOutbox(size int, policy OverflowPolicy, overflow func()) *Outbox {
+	if size <= 0 {
+		size = 1
+	}
+
+	return &Outbox{
+		size:     size,
+		policy:   policy,
+		overflow: overflow,
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// outboxConn queue what is written to it on outbox instead of writing it to connection
+type outboxConn struct {
+	net.Conn
+	outbox *Outbox
+}
+
+func (c *outboxConn) Write(b []byte) (int, error) {
+	// Caller may reuse b once written
+	frame := make([]byte, len(b))
+	copy(frame, b)
+
+	err := c.outbox.push(c.Conn, frame)
+	if err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
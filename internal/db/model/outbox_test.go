@@ -0,0 +1,142 @@
+package model
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func queuedFrames(o *Outbox) []string {
+	frames := []string{}
+	for _, item := range o.take() {
+		frames = append(frames, string(item.frame))
+	}
+	return frames
+}
+
+func TestParseOverflowPolicy(t *testing.T) {
+	policy, err := ParseOverflowPolicy("drop-newest")
+	assert.Nil(t, err)
+	assert.Equal(t, DropNewest, policy)
+
+	_, err = ParseOverflowPolicy("drop-all")
+	assert.NotNil(t, err)
+}
+
+func TestOutboxDropOldest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	// Writer is not started so pushes stay queued
+	o := newOutbox(2, DropOldest, nil)
+	c := o.Conn(conn)
+	for _, frame := range []string{"a", "b", "c"} {
+		n, err := c.Write([]byte(frame))
+		assert.Nil(t, err)
+		assert.Equal(t, 1, n)
+	}
+
+	assert.Equal(t, uint64(1), o.Dropped())
+	assert.Equal(t, []string{"b", "c"}, queuedFrames(o))
+}
+
+func TestOutboxDropNewest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	o := newOutbox(2, DropNewest, nil)
+	c := o.Conn(conn)
+	c.Write([]byte("a"))
+	c.Write([]byte("b"))
+	_, err := c.Write([]byte("c"))
+
+	assert.Equal(t, ErrOutboxFull, err)
+	assert.Equal(t, uint64(1), o.Dropped())
+	assert.Equal(t, []string{"a", "b"}, queuedFrames(o))
+}
+
+func TestOutboxOverflowUntilDrained(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	o := newOutbox(1, DropNewest, nil)
+	c := o.Conn(conn)
+	c.Write([]byte("a"))
+	c.Write([]byte("b"))
+	c.Write([]byte("c"))
+
+	assert.True(t, o.overflowing)
+	assert.Equal(t, uint64(2), o.Dropped())
+
+	o.take()
+	assert.False(t, o.overflowing)
+}
+
+func TestOutboxDisconnect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	overflowed := 0
+	o := newOutbox(1, Disconnect, func() {
+		overflowed++
+	})
+	c := o.Conn(conn)
+	c.Write([]byte("a"))
+	_, err := c.Write([]byte("b"))
+
+	assert.Equal(t, ErrOutboxFull, err)
+	assert.Equal(t, 1, overflowed)
+	assert.Equal(t, 0, o.Len())
+
+	_, err = c.Write([]byte("c"))
+	assert.Equal(t, ErrOutboxClosed, err)
+}
+
+func TestClientOutboxWrite(t *testing.T) {
+	sconn, cconn := net.Pipe()
+	defer cconn.Close()
+
+	c := NewClientRegistry().Register(nil, sconn, time.Now())
+	defer c.Close()
+
+	// Subscriber not reading does not hold up writes to outbox
+	w := c.Outbox().Conn(sconn)
+	_, err := w.Write([]byte("a"))
+	assert.Nil(t, err)
+	_, err = w.Write([]byte("b"))
+	assert.Nil(t, err)
+
+	buf := make([]byte, 2)
+	cconn.SetReadDeadline(time.Now().Add(time.Second))
+	n, err := cconn.Read(buf)
+	assert.Nil(t, err)
+	if n < 2 {
+		_, err = cconn.Read(buf[n:])
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, "ab", string(buf))
+}
+
+func TestClientOutboxCloseOnOverflow(t *testing.T) {
+	sconn, cconn := net.Pipe()
+	defer cconn.Close()
+
+	r := NewClientRegistry()
+	r.OutboxSize = 1
+	r.OutboxPolicy = Disconnect
+	c := r.Register(nil, sconn, time.Now())
+
+	// Writer is stuck on first push as nobody read, so the rest fill outbox
+	w := c.Outbox().Conn(sconn)
+	var err error
+	for i := 0; i < 3 && err == nil; i++ {
+		_, err = w.Write([]byte("a"))
+	}
+
+	assert.Equal(t, ErrOutboxFull, err)
+	<-c.Done()
+}
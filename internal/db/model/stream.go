@@ -0,0 +1,237 @@
+package model
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+var (
+	// Returned for id that cannot be parsed
+	ErrInvalidStreamID = tlv.NewCodeError(tlv.InvalidStreamIDError)
+	// Returned when added entry would not be the last one
+	ErrStreamIDTooSmall = tlv.NewCodeError(tlv.StreamIDTooSmallError)
+)
+
+// StreamID is milliseconds time of entry and sequence number among entries of same millisecond
+type StreamID struct {
+	Ms  uint64
+	Seq uint64
+}
+
+// MaxStreamID is greater than any id of entry
+var MaxStreamID = StreamID{Ms: ^uint64(0), Seq: ^uint64(0)}
+
+func (id StreamID) String() string {
+	return strconv.FormatUint(id.Ms, 10) + "-" + strconv.FormatUint(id.Seq, 10)
+}
+
+func (id StreamID) Less(other StreamID) bool {
+	return id.Ms < other.Ms || (id.Ms == other.Ms && id.Seq < other.Seq)
+}
+
+// Next return smallest id greater than id
+func (id StreamID) Next() StreamID {
+	if id.Seq == ^uint64(0) {
+		return StreamID{Ms: id.Ms + 1}
+	}
+	return StreamID{Ms: id.Ms, Seq: id.Seq + 1}
+}
+
+func (id StreamID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(id.String())
+}
+
+func (id *StreamID) UnmarshalJSON(data []byte) error {
+	var s string
+	err := json.Unmarshal(data, &s)
+	if err != nil {
+		return err
+	}
+
+	*id, err = ParseStreamID(s)
+	return err
+}
+
+// ParseStreamID parse id written as ms-seq, sequence default to zero when only ms is given
+func ParseStreamID(s string) (StreamID, error) {
+	ms, seq, hasSeq := strings.Cut(s, "-")
+
+	id := StreamID{}
+	var err error
+	id.Ms, err = strconv.ParseUint(ms, 10, 64)
+	if err != nil {
+		return id, ErrInvalidStreamID
+	}
+	if hasSeq {
+		id.Seq, err = strconv.ParseUint(seq, 10, 64)
+		if err != nil {
+			return id, ErrInvalidStreamID
+		}
+	}
+
+	return id, nil
+}
+
+// StreamEntry is one entry of stream, fields hold raw tlv values
+type StreamEntry struct {
+	ID     StreamID          `json:"id"`
+	Fields map[string][]byte `json:"fields"`
+}
+
+// StreamTrim limit what stream keep, zero field does not limit
+type StreamTrim struct {
+	// Number of entries kept
+	MaxLen int
+	// Age of oldest entry kept, taken from ms part of its id
+	MaxAge time.Duration
+}
+
+// Stream is an append only log of entries ordered by id. Last id is kept even when
+// entries are trimmed so ids never go back. Consumer groups of stream are kept with it.
+type Stream struct {
+	entries []StreamEntry
+	last    StreamID
+	groups  map[string]*StreamGroup
+}
+
+type streamJSON struct {
+	Last    StreamID                    `json:"last"`
+	Entries []StreamEntry               `json:"entries"`
+	Groups  map[string]*streamGroupJSON `json:"groups,omitempty"`
+}
+
+func (s *Stream) Len() int {
+	return len(s.entries)
+}
+
+// LastID return id of last entry ever added
+func (s *Stream) LastID() StreamID {
+	return s.last
+}
+
+// Add append entry and return its id. Id is generated from now when auto is true, else
+// given id must be greater than last one.
+func (s *Stream) Add(id StreamID, auto bool, fields map[string][]byte, now time.Time) (StreamID, error) {
+	if auto {
+		id = StreamID{Ms: uint64(now.UnixMilli())}
+		// Clock going back or many entries in a millisecond keep ids increasing
+		if !s.last.Less(id) {
+			id = s.last.Next()
+		}
+	}
+
+	if !s.last.Less(id) {
+		return id, ErrStreamIDTooSmall
+	}
+
+	s.entries = append(s.entries, StreamEntry{ID: id, Fields: fields})
+	s.last = id
+	return id, nil
+}
+
+// Range return entries with id between start and end inclusive, at most count of them
+// when count is positive
+func (s *Stream) Range(start StreamID, end StreamID, count int) []StreamEntry {
+	i := s.search(start)
+
+	res := []StreamEntry{}
+	for ; i < len(s.entries) && !end.Less(s.entries[i].ID); i++ {
+		if count > 0 && len(res) >= count {
+			break
+		}
+		res = append(res, s.entries[i])
+	}
+
+	return res
+}
+
+// After return entries with id greater than id, at most count of them when count is positive
+func (s *Stream) After(id StreamID, count int) []StreamEntry {
+	if !id.Less(MaxStreamID) {
+		return []StreamEntry{}
+	}
+	return s.Range(id.Next(), MaxStreamID, count)
+}
+
+// Trim remove oldest entries beyond limits of t and return how many were removed
+func (s *Stream) Trim(t StreamTrim, now time.Time) int {
+	n := 0
+	if t.MaxLen > 0 && len(s.entries) > t.MaxLen {
+		n = len(s.entries) - t.MaxLen
+	}
+	if t.MaxAge > 0 {
+		min := now.Add(-t.MaxAge).UnixMilli()
+		if min > 0 {
+			if i := s.search(StreamID{Ms: uint64(min)}); i > n {
+				n = i
+			}
+		}
+	}
+
+	if n > 0 {
+		// Removed entries are cleared so their fields are freed, backing array is let go
+		// once append outgrow it and copy only entries left
+		for i := 0; i < n; i++ {
+			s.entries[i] = StreamEntry{}
+		}
+		s.entries = s.entries[n:]
+	}
+	return n
+}
+
+// search return index of first entry with id not less than id
+func (s *Stream) search(id StreamID) int {
+	return sort.Search(len(s.entries), func(i int) bool {
+		return !s.entries[i].ID.Less(id)
+	})
+}
+
+func (s *Stream) MarshalJSON() ([]byte, error) {
+	sj := streamJSON{
+		Last:    s.last,
+		Entries: s.entries,
+	}
+	if len(s.groups) > 0 {
+		sj.Groups = make(map[string]*streamGroupJSON, len(s.groups))
+		for name, g := range s.groups {
+			sj.Groups[name] = &streamGroupJSON{Last: g.last, Pending: g.Pending("")}
+		}
+	}
+
+	return json.Marshal(sj)
+}
+
+func (s *Stream) UnmarshalJSON(data []byte) error {
+	sj := streamJSON{}
+	err := json.Unmarshal(data, &sj)
+	if err != nil {
+		return err
+	}
+
+	*s = *NewStream()
+	s.last = sj.Last
+	if sj.Entries != nil {
+		s.entries = sj.Entries
+	}
+	for name, gj := range sj.Groups {
+		g := newStreamGroup(gj.Last)
+		for _, p := range gj.Pending {
+			pending := p
+			g.pending[p.ID] = &pending
+		}
+		s.groups[name] = g
+	}
+	return nil
+}
+
+func NewStream() *Stream {
+	return &Stream{
+		entries: []StreamEntry{},
+		groups:  make(map[string]*StreamGroup),
+	}
+}
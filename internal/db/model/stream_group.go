@@ -0,0 +1,226 @@
+package model
+
+import (
+	"sort"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+var (
+	// Returned for group that does not exist
+	ErrNoGroup = tlv.NewCodeError(tlv.NoGroupError)
+	// Returned when created group already exist
+	ErrGroupExists = tlv.NewCodeError(tlv.GroupExistsError)
+)
+
+// StreamPending is entry delivered to consumer of group that was not acknowledged yet
+type StreamPending struct {
+	ID       StreamID `json:"id"`
+	Consumer string   `json:"consumer"`
+	// Time of last delivery, idle time is counted from it
+	Delivered time.Time `json:"delivered"`
+	// Number of times entry was delivered
+	Count int `json:"count"`
+}
+
+// StreamGroup deliver each entry of stream to one of its consumers, entry is then pending
+// for that consumer until acknowledged
+type StreamGroup struct {
+	// Id of last entry delivered to group
+	last    StreamID
+	pending map[StreamID]*StreamPending
+}
+
+type streamGroupJSON struct {
+	Last    StreamID        `json:"last"`
+	Pending []StreamPending `json:"pending"`
+}
+
+// LastID return id of last entry delivered to group
+func (g *StreamGroup) LastID() StreamID {
+	return g.last
+}
+
+// Pending return entries pending for consumer ordered by id, for every consumer when it is empty
+func (g *StreamGroup) Pending(consumer string) []StreamPending {
+	res := []StreamPending{}
+	for _, p := range g.pending {
+		if consumer == "" || p.Consumer == consumer {
+			res = append(res, *p)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].ID.Less(res[j].ID)
+	})
+
+	return res
+}
+
+// deliver make entry pending for consumer
+func (g *StreamGroup) deliver(id StreamID, consumer string, now time.Time) {
+	p, ok := g.pending[id]
+	if !ok {
+		p = &StreamPending{ID: id}
+		g.pending[id] = p
+	}
+	p.Consumer = consumer
+	p.Delivered = now
+	p.Count++
+}
+
+func newStreamGroup(last StreamID) *StreamGroup {
+	return &StreamGroup{
+		last:    last,
+		pending: make(map[StreamID]*StreamPending),
+	}
+}
+
+// CreateGroup add group that deliver entries after last
+func (s *Stream) CreateGroup(name string, last StreamID) error {
+	if _, ok := s.groups[name]; ok {
+		return ErrGroupExists
+	}
+
+	s.groups[name] = newStreamGroup(last)
+	return nil
+}
+
+// DestroyGroup remove group with its pending entries and return false when there is none
+func (s *Stream) DestroyGroup(name string) bool {
+	if _, ok := s.groups[name]; !ok {
+		return false
+	}
+
+	delete(s.groups, name)
+	return true
+}
+
+func (s *Stream) Group(name string) (*StreamGroup, error) {
+	g, ok := s.groups[name]
+	if !ok {
+		return nil, ErrNoGroup
+	}
+	return g, nil
+}
+
+// GroupNames return names of groups in sorted order
+func (s *Stream) GroupNames() []string {
+	names := make([]string, 0, len(s.groups))
+	for name := range s.groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// ReadGroup deliver to consumer entries never delivered to group, at most count of them
+// when count is positive. Delivered entries are pending for consumer until acknowledged.
+func (s *Stream) ReadGroup(group string, consumer string, count int, now time.Time) ([]StreamEntry, error) {
+	g, err := s.Group(group)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := s.After(g.last, count)
+	for _, e := range entries {
+		g.deliver(e.ID, consumer, now)
+		g.last = e.ID
+	}
+
+	return entries, nil
+}
+
+// ReadPending deliver again entries pending for consumer with id greater than after, at
+// most count of them when count is positive. Pending entries trimmed from stream are skipped.
+func (s *Stream) ReadPending(group string, consumer string, after StreamID, count int, now time.Time) ([]StreamEntry, error) {
+	g, err := s.Group(group)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []StreamEntry{}
+	for _, p := range g.Pending(consumer) {
+		if count > 0 && len(entries) >= count {
+			break
+		}
+		if !after.Less(p.ID) {
+			continue
+		}
+
+		e, ok := s.entry(p.ID)
+		if !ok {
+			continue
+		}
+		g.deliver(p.ID, consumer, now)
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+// Ack remove entries from pending entries of group and return how many were pending
+func (s *Stream) Ack(group string, ids []StreamID) (int, error) {
+	g, err := s.Group(group)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, id := range ids {
+		if _, ok := g.pending[id]; ok {
+			delete(g.pending, id)
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// Claim hand to consumer pending entries of group that were idle for at least minIdle and
+// return them. Only given ids are claimed, or any pending entry up to count when there is
+// none. Pending entries trimmed from stream cannot be delivered and are dropped.
+func (s *Stream) Claim(group string, consumer string, minIdle time.Duration, ids []StreamID, count int, now time.Time) ([]StreamEntry, error) {
+	g, err := s.Group(group)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		for _, p := range g.Pending("") {
+			ids = append(ids, p.ID)
+		}
+	}
+
+	entries := []StreamEntry{}
+	for _, id := range ids {
+		if count > 0 && len(entries) >= count {
+			break
+		}
+
+		p, ok := g.pending[id]
+		if !ok || now.Sub(p.Delivered) < minIdle {
+			continue
+		}
+
+		e, ok := s.entry(id)
+		if !ok {
+			delete(g.pending, id)
+			continue
+		}
+		g.deliver(id, consumer, now)
+		entries = append(entries, e)
+	}
+
+	return entries, nil
+}
+
+// entry find entry with id
+func (s *Stream) entry(id StreamID) (StreamEntry, bool) {
+	i := s.search(id)
+	if i < len(s.entries) && s.entries[i].ID == id {
+		return s.entries[i], true
+	}
+	return StreamEntry{}, false
+}
@@ -0,0 +1,97 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func pendingIDs(pending []StreamPending) []string {
+	ids := []string{}
+	for _, p := range pending {
+		ids = append(ids, p.ID.String()+"@"+p.Consumer)
+	}
+	return ids
+}
+
+func newGroupTestStream() *Stream {
+	s := NewStream()
+	for ms := uint64(1); ms <= 4; ms++ {
+		s.Add(StreamID{Ms: ms}, false, map[string][]byte{}, time.Time{})
+	}
+	return s
+}
+
+func TestStreamCreateGroup(t *testing.T) {
+	s := newGroupTestStream()
+
+	assert.Nil(t, s.CreateGroup("workers", StreamID{Ms: 2}))
+	assert.Equal(t, ErrGroupExists, s.CreateGroup("workers", StreamID{}))
+	assert.Equal(t, []string{"workers"}, s.GroupNames())
+
+	_, err := s.ReadGroup("missing", "a", 0, time.Time{})
+	assert.Equal(t, ErrNoGroup, err)
+
+	assert.True(t, s.DestroyGroup("workers"))
+	assert.False(t, s.DestroyGroup("workers"))
+	assert.Equal(t, 0, len(s.GroupNames()))
+}
+
+func TestStreamReadGroup(t *testing.T) {
+	s := newGroupTestStream()
+	s.CreateGroup("workers", StreamID{Ms: 1})
+	now := time.Unix(100, 0)
+
+	// Each entry is delivered to one consumer of group
+	entries, err := s.ReadGroup("workers", "a", 2, now)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"2-0", "3-0"}, streamIDs(entries))
+	entries, _ = s.ReadGroup("workers", "b", 0, now)
+	assert.Equal(t, []string{"4-0"}, streamIDs(entries))
+	entries, _ = s.ReadGroup("workers", "b", 0, now)
+	assert.Equal(t, 0, len(entries))
+
+	g, _ := s.Group("workers")
+	assert.Equal(t, StreamID{Ms: 4}, g.LastID())
+	assert.Equal(t, []string{"2-0@a", "3-0@a", "4-0@b"}, pendingIDs(g.Pending("")))
+	assert.Equal(t, []string{"4-0@b"}, pendingIDs(g.Pending("b")))
+
+	// Pending entries of consumer are delivered again from its history
+	entries, err = s.ReadPending("workers", "a", StreamID{Ms: 2}, 0, now)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"3-0"}, streamIDs(entries))
+	assert.Equal(t, 2, g.Pending("a")[1].Count)
+
+	n, err := s.Ack("workers", []StreamID{{Ms: 2}, {Ms: 4}, {Ms: 9}})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, n)
+	assert.Equal(t, []string{"3-0@a"}, pendingIDs(g.Pending("")))
+}
+
+func TestStreamClaim(t *testing.T) {
+	s := newGroupTestStream()
+	s.CreateGroup("workers", StreamID{})
+	delivered := time.Unix(100, 0)
+	s.ReadGroup("workers", "a", 3, delivered)
+
+	// Entries are not idle long enough yet
+	entries, err := s.Claim("workers", "b", time.Minute, nil, 0, delivered.Add(time.Second))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(entries))
+
+	now := delivered.Add(time.Hour)
+	entries, _ = s.Claim("workers", "b", time.Minute, []StreamID{{Ms: 2}, {Ms: 4}}, 0, now)
+	assert.Equal(t, []string{"2-0"}, streamIDs(entries))
+
+	g, _ := s.Group("workers")
+	assert.Equal(t, []string{"1-0@a", "2-0@b", "3-0@a"}, pendingIDs(g.Pending("")))
+	assert.Equal(t, 2, g.Pending("b")[0].Count)
+	assert.Equal(t, now, g.Pending("b")[0].Delivered)
+
+	// Without ids any idle entry is claimed, trimmed one is dropped and 2-0 was just claimed
+	s.Trim(StreamTrim{MaxLen: 2}, time.Time{})
+	entries, _ = s.Claim("workers", "c", time.Minute, nil, 0, now)
+	assert.Equal(t, []string{"3-0"}, streamIDs(entries))
+	assert.Equal(t, []string{"2-0@b", "3-0@c"}, pendingIDs(g.Pending("")))
+}
@@ -0,0 +1,113 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func streamIDs(entries []StreamEntry) []string {
+	ids := []string{}
+	for _, e := range entries {
+		ids = append(ids, e.ID.String())
+	}
+	return ids
+}
+
+func TestParseStreamID(t *testing.T) {
+	id, err := ParseStreamID("1526919030474-55")
+	assert.Nil(t, err)
+	assert.Equal(t, StreamID{Ms: 1526919030474, Seq: 55}, id)
+	assert.Equal(t, "1526919030474-55", id.String())
+
+	id, err = ParseStreamID("7")
+	assert.Nil(t, err)
+	assert.Equal(t, StreamID{Ms: 7}, id)
+
+	for _, s := range []string{"", "a-1", "1-", "1-b", "-1"} {
+		_, err = ParseStreamID(s)
+		assert.Equal(t, ErrInvalidStreamID, err)
+	}
+}
+
+func TestStreamAdd(t *testing.T) {
+	s := NewStream()
+	now := time.UnixMilli(1000)
+
+	id, err := s.Add(StreamID{}, true, nil, now)
+	assert.Nil(t, err)
+	assert.Equal(t, StreamID{Ms: 1000}, id)
+
+	// Same millisecond and clock going back still give increasing ids
+	id, _ = s.Add(StreamID{}, true, nil, now)
+	assert.Equal(t, StreamID{Ms: 1000, Seq: 1}, id)
+	id, _ = s.Add(StreamID{}, true, nil, time.UnixMilli(10))
+	assert.Equal(t, StreamID{Ms: 1000, Seq: 2}, id)
+
+	_, err = s.Add(StreamID{Ms: 1000, Seq: 2}, false, nil, now)
+	assert.Equal(t, ErrStreamIDTooSmall, err)
+	_, err = s.Add(StreamID{}, false, nil, now)
+	assert.Equal(t, ErrStreamIDTooSmall, err)
+
+	id, err = s.Add(StreamID{Ms: 2000}, false, nil, now)
+	assert.Nil(t, err)
+	assert.Equal(t, StreamID{Ms: 2000}, s.LastID())
+	assert.Equal(t, 4, s.Len())
+}
+
+func TestStreamRange(t *testing.T) {
+	s := NewStream()
+	for _, ms := range []uint64{1, 2, 2, 3, 5} {
+		s.Add(StreamID{}, true, nil, time.UnixMilli(int64(ms)))
+	}
+
+	assert.Equal(t, []string{"1-0", "2-0", "2-1", "3-0", "5-0"}, streamIDs(s.Range(StreamID{}, MaxStreamID, 0)))
+	assert.Equal(t, []string{"2-0", "2-1", "3-0"}, streamIDs(s.Range(StreamID{Ms: 2}, StreamID{Ms: 4}, 0)))
+	assert.Equal(t, []string{"2-1", "3-0"}, streamIDs(s.Range(StreamID{Ms: 2, Seq: 1}, MaxStreamID, 2)))
+	assert.Equal(t, 0, len(s.Range(StreamID{Ms: 4}, StreamID{Ms: 3}, 0)))
+
+	assert.Equal(t, []string{"3-0", "5-0"}, streamIDs(s.After(StreamID{Ms: 2, Seq: 1}, 0)))
+	assert.Equal(t, []string{"1-0"}, streamIDs(s.After(StreamID{}, 1)))
+	assert.Equal(t, 0, len(s.After(StreamID{Ms: 5}, 0)))
+	assert.Equal(t, 0, len(s.After(MaxStreamID, 0)))
+}
+
+func TestStreamTrim(t *testing.T) {
+	s := NewStream()
+	for ms := int64(1000); ms <= 5000; ms += 1000 {
+		s.Add(StreamID{}, true, nil, time.UnixMilli(ms))
+	}
+
+	assert.Equal(t, 0, s.Trim(StreamTrim{MaxLen: 10}, time.Time{}))
+	assert.Equal(t, 1, s.Trim(StreamTrim{MaxLen: 4}, time.Time{}))
+	assert.Equal(t, []string{"2000-0", "3000-0", "4000-0", "5000-0"}, streamIDs(s.Range(StreamID{}, MaxStreamID, 0)))
+
+	// Entries older than max age are removed
+	assert.Equal(t, 2, s.Trim(StreamTrim{MaxAge: 2 * time.Second}, time.UnixMilli(6000)))
+	assert.Equal(t, []string{"4000-0", "5000-0"}, streamIDs(s.Range(StreamID{}, MaxStreamID, 0)))
+
+	// Stricter of both limits win
+	assert.Equal(t, 1, s.Trim(StreamTrim{MaxLen: 1, MaxAge: time.Hour}, time.UnixMilli(6000)))
+	assert.Equal(t, 1, s.Len())
+
+	// Last id is kept when trimmed empty
+	assert.Equal(t, 1, s.Trim(StreamTrim{MaxAge: time.Millisecond}, time.UnixMilli(6000)))
+	assert.Equal(t, 0, s.Len())
+	assert.Equal(t, StreamID{Ms: 5000}, s.LastID())
+}
+
+func TestStreamTrimInPlace(t *testing.T) {
+	s := NewStream()
+	for ms := int64(1); ms <= 4; ms++ {
+		s.Add(StreamID{}, true, map[string][]byte{"f": {1}}, time.UnixMilli(ms))
+	}
+	removed := s.entries[:2]
+	kept := &s.entries[2]
+
+	assert.Equal(t, 2, s.Trim(StreamTrim{MaxLen: 2}, time.Time{}))
+
+	// Entries left are not copied and removed ones are cleared
+	assert.Same(t, kept, &s.entries[0])
+	assert.Equal(t, []StreamEntry{{}, {}}, removed)
+}
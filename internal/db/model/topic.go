@@ -2,6 +2,7 @@ package model
 
 import (
 	"net"
+	"strconv"
 
 	"bitbucket.org/non-pn/mini-redis-go/internal/db"
 	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
@@ -16,14 +17,34 @@ func (topic *Topic[T]) DidInit() bool {
 	return topic.ConnDb != nil
 }
 
-func (topic *Topic[T]) AddConn(conn net.Conn) {
-	k := conn.RemoteAddr()
-	topic.ConnDb.Set(k.String(), conn)
+// AddConn subscribe connection of client with id. Connections are keyed by client rather
+// than address, which clients of unix socket share.
+func (topic *Topic[T]) AddConn(id uint64, conn net.Conn) {
+	topic.ConnDb.Set(strconv.FormatUint(id, 10), conn)
 }
 
-func (topic *Topic[T]) RemoveConn(conn net.Conn) {
-	k := conn.RemoteAddr()
-	topic.ConnDb.Delete(k.String())
+func (topic *Topic[T]) RemoveConn(id uint64) {
+	topic.ConnDb.Delete(strconv.FormatUint(id, 10))
+}
+
+// Len return number of connections subscribed to topic
+func (topic *Topic[T]) Len() int {
+	topic.ConnDb.RLock()
+	defer topic.ConnDb.RUnlock()
+
+	return len(topic.ConnDb.Storage)
+}
+
+// Conns return connections subscribed to topic, safe to range over while they change
+func (topic *Topic[T]) Conns() []net.Conn {
+	topic.ConnDb.RLock()
+	defer topic.ConnDb.RUnlock()
+
+	conns := make([]net.Conn, 0, len(topic.ConnDb.Storage))
+	for _, conn := range topic.ConnDb.Storage {
+		conns = append(conns, conn)
+	}
+	return conns
 }
 
 func NewTopic[T tlv.TLVCompatible](name string) *Topic[T] {
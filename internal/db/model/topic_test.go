@@ -22,7 +22,6 @@ func TestNewStringTopic(t *testing.T) {
 func TestAddConnToTopic(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	conn := mocknet.NewMockConn(ctrl)
-	addr := mocknet.NewMockAddr(ctrl)
 
 	name := "test_topic"
 	topic := &Topic[*tlv.String]{
@@ -32,9 +31,7 @@ func TestAddConnToTopic(t *testing.T) {
 
 	assert.Equal(t, 0, len(topic.ConnDb.Storage))
 
-	conn.EXPECT().RemoteAddr().Times(1).Return(addr)
-	addr.EXPECT().String().Times(1)
-	topic.AddConn(conn)
+	topic.AddConn(1, conn)
 
 	assert.Equal(t, 1, len(topic.ConnDb.Storage))
 }
@@ -42,13 +39,10 @@ func TestAddConnToTopic(t *testing.T) {
 func TestRemoveConnFromTopic(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	conn := mocknet.NewMockConn(ctrl)
-	addr := mocknet.NewMockAddr(ctrl)
-
-	addr.EXPECT().String().Times(1)
 
 	name := "test_topic"
 	db := &db.KVStore[net.Conn]{
-		Storage: map[string]net.Conn{addr.String(): conn},
+		Storage: map[string]net.Conn{"1": conn},
 	}
 	topic := &Topic[*tlv.String]{
 		Name:   name,
@@ -57,13 +51,27 @@ func TestRemoveConnFromTopic(t *testing.T) {
 
 	assert.Equal(t, 1, len(topic.ConnDb.Storage))
 
-	conn.EXPECT().RemoteAddr().Times(1).Return(addr)
-	addr.EXPECT().String().Times(1)
-	topic.RemoveConn(conn)
+	topic.RemoveConn(1)
 
 	assert.Equal(t, 0, len(topic.ConnDb.Storage))
 }
 
+func TestTopicKeepConnsOfSameAddress(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn, other := mocknet.NewMockConn(ctrl), mocknet.NewMockConn(ctrl)
+
+	// Clients of unix socket all have the same address
+	topic := NewTopic[*tlv.String]("test_topic")
+	topic.AddConn(1, conn)
+	topic.AddConn(2, other)
+
+	assert.Equal(t, 2, len(topic.Conns()))
+
+	topic.RemoveConn(1)
+
+	assert.Equal(t, []net.Conn{other}, topic.Conns())
+}
+
 func TestTopicShouldInit(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	conn := mocknet.NewMockConn(ctrl)
@@ -0,0 +1,92 @@
+package model
+
+// QueuedRequest is request queued in transaction, kept as read from connection until EXEC
+type QueuedRequest struct {
+	Version uint8
+	Cmd     uint8
+	Body    []byte
+}
+
+// Transaction is what client queued since MULTI
+type Transaction struct {
+	Queued []QueuedRequest
+	// Set once a request could not be queued, EXEC then discard transaction
+	Failed bool
+}
+
+// Multi start transaction of client, false when one is already started
+func (c *Client) Multi() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.tx != nil {
+		return false
+	}
+	c.tx = &Transaction{}
+	return true
+}
+
+func (c *Client) InTransaction() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.tx != nil
+}
+
+// Queue add request to transaction of client, false when there is none
+func (c *Client) Queue(req QueuedRequest) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.tx == nil {
+		return false
+	}
+	c.tx.Queued = append(c.tx.Queued, req)
+	return true
+}
+
+// FailTransaction mark transaction of client so EXEC discard it
+func (c *Client) FailTransaction() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.tx != nil {
+		c.tx.Failed = true
+	}
+}
+
+// EndTransaction return transaction of client and leave it, nil when there is none
+func (c *Client) EndTransaction() *Transaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tx := c.tx
+	c.tx = nil
+	return tx
+}
+
+// Watch record version of key, key already watched keep version it was first watched with.
+// It return false in that case.
+func (c *Client) Watch(k string, version uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.watched == nil {
+		c.watched = make(map[string]uint64)
+	}
+	if _, ok := c.watched[k]; ok {
+		return false
+	}
+	c.watched[k] = version
+	return true
+}
+
+// Unwatch forget every watched key and return them with their versions
+func (c *Client) Unwatch() map[string]uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	watched := c.watched
+	c.watched = nil
+	return watched
+}
@@ -0,0 +1,162 @@
+package model
+
+import (
+	"encoding/json"
+	"sort"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+type ValueType uint8
+
+const (
+	StringValueType ValueType = iota
+	ListValueType
+	HashValueType
+	SetValueType
+	ZSetValueType
+	StreamValueType
+)
+
+// Returned by any access to key holding value of other type
+var ErrWrongType = tlv.NewCodeError(tlv.WrongTypeError)
+
+// Value is an entry of redis keyspace, only the field matching Type is set
+type Value struct {
+	Type ValueType
+	// Raw tlv of string value
+	Str  []byte
+	List *List
+	// Field to raw tlv of hash value
+	Hash map[string][]byte
+	// Members of unordered set
+	Set    map[string]struct{}
+	ZSet   *ZSet
+	Stream *Stream
+}
+
+type valueJSON struct {
+	Type   ValueType         `json:"type"`
+	Str    []byte            `json:"str,omitempty"`
+	List   *List             `json:"list,omitempty"`
+	Hash   map[string][]byte `json:"hash,omitempty"`
+	Set    []string          `json:"set,omitempty"`
+	ZSet   *ZSet             `json:"zset,omitempty"`
+	Stream *Stream           `json:"stream,omitempty"`
+}
+
+func (v *Value) MarshalJSON() ([]byte, error) {
+	return json.Marshal(valueJSON{
+		Type:   v.Type,
+		Str:    v.Str,
+		List:   v.List,
+		Hash:   v.Hash,
+		Set:    v.Members(),
+		ZSet:   v.ZSet,
+		Stream: v.Stream,
+	})
+}
+
+func (v *Value) UnmarshalJSON(data []byte) error {
+	// Cache written before typed values hold raw string value
+	if len(data) > 0 && data[0] == '"' {
+		var raw []byte
+		err := json.Unmarshal(data, &raw)
+		if err != nil {
+			return err
+		}
+		*v = *NewStringValue(raw)
+		return nil
+	}
+
+	vj := valueJSON{}
+	err := json.Unmarshal(data, &vj)
+	if err != nil {
+		return err
+	}
+
+	*v = Value{
+		Type:   vj.Type,
+		Str:    vj.Str,
+		List:   vj.List,
+		Hash:   vj.Hash,
+		ZSet:   vj.ZSet,
+		Stream: vj.Stream,
+	}
+	if v.Type == ListValueType && v.List == nil {
+		v.List = NewList()
+	}
+	if v.Type == HashValueType && v.Hash == nil {
+		v.Hash = make(map[string][]byte)
+	}
+	if v.Type == SetValueType {
+		v.Set = make(map[string]struct{}, len(vj.Set))
+		for _, m := range vj.Set {
+			v.Set[m] = struct{}{}
+		}
+	}
+	if v.Type == ZSetValueType && v.ZSet == nil {
+		v.ZSet = NewZSet()
+	}
+	if v.Type == StreamValueType && v.Stream == nil {
+		v.Stream = NewStream()
+	}
+	return nil
+}
+
+func NewStringValue(raw []byte) *Value {
+	return &Value{
+		Type: StringValueType,
+		Str:  raw,
+	}
+}
+
+func NewListValue() *Value {
+	return &Value{
+		Type: ListValueType,
+		List: NewList(),
+	}
+}
+
+func NewHashValue() *Value {
+	return &Value{
+		Type: HashValueType,
+		Hash: make(map[string][]byte),
+	}
+}
+
+func NewSetValue() *Value {
+	return &Value{
+		Type: SetValueType,
+		Set:  make(map[string]struct{}),
+	}
+}
+
+func NewZSetValue() *Value {
+	return &Value{
+		Type: ZSetValueType,
+		ZSet: NewZSet(),
+	}
+}
+
+func NewStreamValue() *Value {
+	return &Value{
+		Type:   StreamValueType,
+		Stream: NewStream(),
+	}
+}
+
+// Members return members of set value in sorted order, nil for other types
+func (v *Value) Members() []string {
+	if v.Set == nil {
+		return nil
+	}
+
+	members := make([]string, 0, len(v.Set))
+	for m := range v.Set {
+		members = append(members, m)
+	}
+	sort.Strings(members)
+
+	return members
+}
@@ -0,0 +1,347 @@
+package model
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"sort"
+	"time"
+)
+
+var ErrInvalidBinaryValue = errors.New("Invalid binary value")
+
+// MarshalBinary encode value as [type u8] followed by content of that type.
+// Byte strings are length prefixed with u32, counts are u32, scores are float64 bits and
+// stream ids are u64 ms followed by u64 sequence.
+func (v *Value) MarshalBinary() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte(byte(v.Type))
+
+	switch v.Type {
+	case StringValueType:
+		writeBytes(buf, v.Str)
+		break
+	case ListValueType:
+		elems := v.List.Range(0, -1)
+		writeCount(buf, len(elems))
+		for _, e := range elems {
+			writeBytes(buf, e)
+		}
+		break
+	case HashValueType:
+		writeFields(buf, v.Hash)
+		break
+	case SetValueType:
+		members := v.Members()
+		writeCount(buf, len(members))
+		for _, m := range members {
+			writeBytes(buf, []byte(m))
+		}
+		break
+	case ZSetValueType:
+		members := v.ZSet.Range(0, -1)
+		writeCount(buf, len(members))
+		for _, m := range members {
+			writeBytes(buf, []byte(m.Member))
+			binary.Write(buf, binary.BigEndian, math.Float64bits(m.Score))
+		}
+		break
+	case StreamValueType:
+		writeStreamID(buf, v.Stream.LastID())
+		entries := v.Stream.Range(StreamID{}, MaxStreamID, 0)
+		writeCount(buf, len(entries))
+		for _, e := range entries {
+			writeStreamID(buf, e.ID)
+			writeFields(buf, e.Fields)
+		}
+		writeStreamGroups(buf, v.Stream)
+		break
+	default:
+		return nil, ErrInvalidBinaryValue
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (v *Value) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	typ, err := r.ReadByte()
+	if err != nil {
+		return ErrInvalidBinaryValue
+	}
+
+	switch ValueType(typ) {
+	case StringValueType:
+		str, err := readBytes(r)
+		if err != nil {
+			return err
+		}
+		*v = *NewStringValue(str)
+		break
+	case ListValueType:
+		n, err := readCount(r)
+		if err != nil {
+			return err
+		}
+		*v = *NewListValue()
+		for i := 0; i < n; i++ {
+			e, err := readBytes(r)
+			if err != nil {
+				return err
+			}
+			v.List.PushRight(e)
+		}
+		break
+	case HashValueType:
+		fields, err := readFields(r)
+		if err != nil {
+			return err
+		}
+		*v = *NewHashValue()
+		v.Hash = fields
+		break
+	case SetValueType:
+		n, err := readCount(r)
+		if err != nil {
+			return err
+		}
+		*v = *NewSetValue()
+		for i := 0; i < n; i++ {
+			m, err := readBytes(r)
+			if err != nil {
+				return err
+			}
+			v.Set[string(m)] = struct{}{}
+		}
+		break
+	case ZSetValueType:
+		n, err := readCount(r)
+		if err != nil {
+			return err
+		}
+		*v = *NewZSetValue()
+		for i := 0; i < n; i++ {
+			m, err := readBytes(r)
+			if err != nil {
+				return err
+			}
+			var bits uint64
+			err = binary.Read(r, binary.BigEndian, &bits)
+			if err != nil {
+				return ErrInvalidBinaryValue
+			}
+			v.ZSet.Add(string(m), math.Float64frombits(bits))
+		}
+		break
+	case StreamValueType:
+		last, err := readStreamID(r)
+		if err != nil {
+			return err
+		}
+		n, err := readCount(r)
+		if err != nil {
+			return err
+		}
+		*v = *NewStreamValue()
+		for i := 0; i < n; i++ {
+			id, err := readStreamID(r)
+			if err != nil {
+				return err
+			}
+			fields, err := readFields(r)
+			if err != nil {
+				return err
+			}
+			_, err = v.Stream.Add(id, false, fields, time.Time{})
+			if err != nil {
+				return ErrInvalidBinaryValue
+			}
+		}
+		if last.Less(v.Stream.LastID()) {
+			return ErrInvalidBinaryValue
+		}
+		v.Stream.last = last
+		err = readStreamGroups(r, v.Stream)
+		if err != nil {
+			return err
+		}
+		break
+	default:
+		return ErrInvalidBinaryValue
+	}
+
+	if r.Len() != 0 {
+		return ErrInvalidBinaryValue
+	}
+	return nil
+}
+
+func writeCount(buf *bytes.Buffer, n int) {
+	binary.Write(buf, binary.BigEndian, uint32(n))
+}
+
+func writeBytes(buf *bytes.Buffer, b []byte) {
+	writeCount(buf, len(b))
+	buf.Write(b)
+}
+
+// writeFields write count of fields followed by each field and its value, in field order
+func writeFields(buf *bytes.Buffer, m map[string][]byte) {
+	fields := make([]string, 0, len(m))
+	for f := range m {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+
+	writeCount(buf, len(fields))
+	for _, f := range fields {
+		writeBytes(buf, []byte(f))
+		writeBytes(buf, m[f])
+	}
+}
+
+func writeStreamID(buf *bytes.Buffer, id StreamID) {
+	binary.Write(buf, binary.BigEndian, id.Ms)
+	binary.Write(buf, binary.BigEndian, id.Seq)
+}
+
+// writeStreamGroups write count of groups followed by each group in name order. Group is
+// its name, last delivered id and pending entries, each with id, consumer, delivery time
+// as unix nanoseconds and delivery count.
+func writeStreamGroups(buf *bytes.Buffer, s *Stream) {
+	names := s.GroupNames()
+	writeCount(buf, len(names))
+	for _, name := range names {
+		g := s.groups[name]
+		writeBytes(buf, []byte(name))
+		writeStreamID(buf, g.last)
+
+		pending := g.Pending("")
+		writeCount(buf, len(pending))
+		for _, p := range pending {
+			writeStreamID(buf, p.ID)
+			writeBytes(buf, []byte(p.Consumer))
+			binary.Write(buf, binary.BigEndian, p.Delivered.UnixNano())
+			writeCount(buf, p.Count)
+		}
+	}
+}
+
+func readCount(r *bytes.Reader) (int, error) {
+	var n uint32
+	err := binary.Read(r, binary.BigEndian, &n)
+	if err != nil {
+		return 0, ErrInvalidBinaryValue
+	}
+
+	return int(n), nil
+}
+
+// readBytes read length prefixed bytes, length is checked against what is left so
+// damaged data cannot make it allocate more than its own size
+func readBytes(r *bytes.Reader) ([]byte, error) {
+	n, err := readCount(r)
+	if err != nil {
+		return nil, err
+	}
+	if n > r.Len() {
+		return nil, ErrInvalidBinaryValue
+	}
+
+	b := make([]byte, n)
+	_, err = io.ReadFull(r, b)
+	if err != nil {
+		return nil, ErrInvalidBinaryValue
+	}
+
+	return b, nil
+}
+
+func readFields(r *bytes.Reader) (map[string][]byte, error) {
+	n, err := readCount(r)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string][]byte)
+	for i := 0; i < n; i++ {
+		f, err := readBytes(r)
+		if err != nil {
+			return nil, err
+		}
+		val, err := readBytes(r)
+		if err != nil {
+			return nil, err
+		}
+		m[string(f)] = val
+	}
+
+	return m, nil
+}
+
+func readStreamID(r *bytes.Reader) (StreamID, error) {
+	id := StreamID{}
+	err := binary.Read(r, binary.BigEndian, &id.Ms)
+	if err != nil {
+		return id, ErrInvalidBinaryValue
+	}
+	err = binary.Read(r, binary.BigEndian, &id.Seq)
+	if err != nil {
+		return id, ErrInvalidBinaryValue
+	}
+
+	return id, nil
+}
+
+func readStreamGroups(r *bytes.Reader, s *Stream) error {
+	n, err := readCount(r)
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < n; i++ {
+		name, err := readBytes(r)
+		if err != nil {
+			return err
+		}
+		last, err := readStreamID(r)
+		if err != nil {
+			return err
+		}
+		g := newStreamGroup(last)
+
+		pn, err := readCount(r)
+		if err != nil {
+			return err
+		}
+		for j := 0; j < pn; j++ {
+			p := &StreamPending{}
+			p.ID, err = readStreamID(r)
+			if err != nil {
+				return err
+			}
+			consumer, err := readBytes(r)
+			if err != nil {
+				return err
+			}
+			p.Consumer = string(consumer)
+			var delivered int64
+			err = binary.Read(r, binary.BigEndian, &delivered)
+			if err != nil {
+				return ErrInvalidBinaryValue
+			}
+			p.Delivered = time.Unix(0, delivered)
+			p.Count, err = readCount(r)
+			if err != nil {
+				return err
+			}
+			g.pending[p.ID] = p
+		}
+
+		s.groups[string(name)] = g
+	}
+
+	return nil
+}
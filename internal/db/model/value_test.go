@@ -0,0 +1,139 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValueJSON(t *testing.T) {
+	v := NewListValue()
+	v.List.PushRight([]byte("a"))
+	h := NewHashValue()
+	h.Hash["f"] = []byte("v")
+	set := NewSetValue()
+	set.Set["m"] = struct{}{}
+	zset := NewZSetValue()
+	zset.ZSet.Add("m", 1.5)
+	stream := NewStreamValue()
+	stream.Stream.Add(StreamID{Ms: 1, Seq: 2}, false, map[string][]byte{"f": []byte("v")}, time.Time{})
+	stream.Stream.Add(StreamID{Ms: 3}, false, nil, time.Time{})
+	stream.Stream.Trim(StreamTrim{MaxLen: 1}, time.Time{})
+	stream.Stream.CreateGroup("g", StreamID{})
+	stream.Stream.ReadGroup("g", "c", 0, time.Now())
+	storage := map[string]*Value{
+		"str":    NewStringValue([]byte("s")),
+		"list":   v,
+		"hash":   h,
+		"set":    set,
+		"zset":   zset,
+		"stream": stream,
+	}
+
+	raw, err := json.Marshal(storage)
+	assert.Nil(t, err)
+
+	res := map[string]*Value{}
+	err = json.Unmarshal(raw, &res)
+
+	assert.Nil(t, err)
+	assert.Equal(t, StringValueType, res["str"].Type)
+	assert.Equal(t, []byte("s"), res["str"].Str)
+	assert.Equal(t, ListValueType, res["list"].Type)
+	assert.Equal(t, 1, res["list"].List.Len())
+	assert.Equal(t, HashValueType, res["hash"].Type)
+	assert.Equal(t, []byte("v"), res["hash"].Hash["f"])
+	assert.Equal(t, SetValueType, res["set"].Type)
+	assert.Equal(t, []string{"m"}, res["set"].Members())
+	assert.Equal(t, ZSetValueType, res["zset"].Type)
+	score, ok := res["zset"].ZSet.Score("m")
+	assert.True(t, ok)
+	assert.Equal(t, 1.5, score)
+	assert.Equal(t, StreamValueType, res["stream"].Type)
+	assert.Equal(t, 1, res["stream"].Stream.Len())
+	assert.Equal(t, StreamID{Ms: 3}, res["stream"].Stream.LastID())
+	g, err := res["stream"].Stream.Group("g")
+	assert.Nil(t, err)
+	assert.Equal(t, StreamID{Ms: 3}, g.LastID())
+	assert.Equal(t, 1, len(g.Pending("c")))
+}
+
+func TestValueJSONLegacy(t *testing.T) {
+	raw, _ := json.Marshal(map[string][]byte{"a": []byte("a")})
+
+	res := map[string]*Value{}
+	err := json.Unmarshal(raw, &res)
+
+	assert.Nil(t, err)
+	assert.Equal(t, StringValueType, res["a"].Type)
+	assert.Equal(t, []byte("a"), res["a"].Str)
+}
+
+func TestValueBinary(t *testing.T) {
+	list := NewListValue()
+	list.List.PushRight([]byte("a"))
+	list.List.PushRight([]byte("b"))
+	hash := NewHashValue()
+	hash.Hash["f"] = []byte("v")
+	hash.Hash["g"] = []byte{}
+	set := NewSetValue()
+	set.Set["m"] = struct{}{}
+	set.Set["n"] = struct{}{}
+	zset := NewZSetValue()
+	zset.ZSet.Add("m", 1.5)
+	zset.ZSet.Add("n", -2)
+	stream := NewStreamValue()
+	stream.Stream.Add(StreamID{Ms: 1}, false, map[string][]byte{"f": []byte("v"), "g": []byte("w")}, time.Time{})
+	stream.Stream.Add(StreamID{Ms: 1, Seq: 1}, false, map[string][]byte{}, time.Time{})
+	stream.Stream.Add(StreamID{Ms: 5}, false, map[string][]byte{"f": []byte("x")}, time.Time{})
+	stream.Stream.Trim(StreamTrim{MaxLen: 2}, time.Time{})
+	stream.Stream.CreateGroup("g", StreamID{})
+	stream.Stream.CreateGroup("h", StreamID{Ms: 5})
+	stream.Stream.ReadGroup("g", "c", 1, time.Unix(1, 2))
+
+	for _, v := range []*Value{NewStringValue([]byte("s")), list, hash, set, zset, stream} {
+		raw, err := v.MarshalBinary()
+		assert.Nil(t, err)
+
+		res := new(Value)
+		err = res.UnmarshalBinary(raw)
+		assert.Nil(t, err)
+
+		// Same value must encode to same bytes
+		again, _ := res.MarshalBinary()
+		assert.Equal(t, raw, again)
+		assert.Equal(t, v.Type, res.Type)
+	}
+
+	res := new(Value)
+	assert.Nil(t, res.UnmarshalBinary(mustMarshalBinary(zset)))
+	assert.Equal(t, []ZMember{{"n", -2}, {"m", 1.5}}, res.ZSet.Range(0, -1))
+
+	res = new(Value)
+	assert.Nil(t, res.UnmarshalBinary(mustMarshalBinary(stream)))
+	assert.Equal(t, stream.Stream.Range(StreamID{}, MaxStreamID, 0), res.Stream.Range(StreamID{}, MaxStreamID, 0))
+	assert.Equal(t, StreamID{Ms: 5}, res.Stream.LastID())
+	g, err := res.Stream.Group("g")
+	assert.Nil(t, err)
+	assert.Equal(t, []StreamPending{{ID: StreamID{Ms: 1, Seq: 1}, Consumer: "c", Delivered: time.Unix(1, 2), Count: 1}}, g.Pending(""))
+	assert.Equal(t, []string{"g", "h"}, res.Stream.GroupNames())
+}
+
+func TestValueBinaryInvalid(t *testing.T) {
+	raw := mustMarshalBinary(NewStringValue([]byte("value")))
+
+	for _, data := range [][]byte{nil, {0xee}, raw[:len(raw)-1], append(raw, 0)} {
+		err := new(Value).UnmarshalBinary(data)
+		assert.Equal(t, ErrInvalidBinaryValue, err)
+	}
+}
+
+func mustMarshalBinary(v *Value) []byte {
+	raw, err := v.MarshalBinary()
+	if err != nil {
+		panic(err)
+	}
+	return raw
+}
@@ -0,0 +1,292 @@
+package model
+
+import (
+	"encoding/json"
+	"math/rand"
+)
+
+const (
+	zslMaxLevel = 32
+	// Chance of node to be promoted to next level
+	zslP = 0.25
+)
+
+// ZMember is a member of sorted set with its score
+type ZMember struct {
+	Member string  `json:"member"`
+	Score  float64 `json:"score"`
+}
+
+type zslLevel struct {
+	forward *zslNode
+	// Number of nodes skipped by forward link, used to compute rank
+	span int
+}
+
+type zslNode struct {
+	member   string
+	score    float64
+	backward *zslNode
+	level    []zslLevel
+}
+
+// ZSet is a set of members ordered by score then member, kept in a skiplist
+// so rank and range lookups are logarithmic. Scores are also indexed by member.
+type ZSet struct {
+	dict   map[string]float64
+	header *zslNode
+	tail   *zslNode
+	length int
+	level  int
+}
+
+func (z *ZSet) Len() int {
+	return z.length
+}
+
+func (z *ZSet) Score(member string) (float64, bool) {
+	score, ok := z.dict[member]
+	return score, ok
+}
+
+// Add set score of member and return true when member is new
+func (z *ZSet) Add(member string, score float64) bool {
+	cur, ok := z.dict[member]
+	if ok {
+		if cur != score {
+			z.delete(member, cur)
+			z.insert(member, score)
+			z.dict[member] = score
+		}
+		return false
+	}
+
+	z.insert(member, score)
+	z.dict[member] = score
+	return true
+}
+
+func (z *ZSet) Remove(member string) bool {
+	score, ok := z.dict[member]
+	if !ok {
+		return false
+	}
+
+	z.delete(member, score)
+	delete(z.dict, member)
+	return true
+}
+
+// Rank return zero based position of member ordered by ascending score
+func (z *ZSet) Rank(member string) (int, bool) {
+	score, ok := z.dict[member]
+	if !ok {
+		return 0, false
+	}
+
+	rank := 0
+	x := z.header
+	for i := z.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && !x.level[i].forward.greater(score, member) {
+			rank += x.level[i].span
+			x = x.level[i].forward
+		}
+		if x != z.header && x.member == member {
+			return rank - 1, true
+		}
+	}
+
+	return 0, false
+}
+
+// Range return members from start to stop rank inclusive, negative index count from the end
+func (z *ZSet) Range(start int, stop int) []ZMember {
+	n := z.length
+	if start < 0 {
+		start += n
+	}
+	if stop < 0 {
+		stop += n
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= n {
+		stop = n - 1
+	}
+	if start > stop {
+		return []ZMember{}
+	}
+
+	res := make([]ZMember, 0, stop-start+1)
+	for x := z.nodeByRank(start + 1); x != nil && len(res) < stop-start+1; x = x.level[0].forward {
+		res = append(res, ZMember{Member: x.member, Score: x.score})
+	}
+
+	return res
+}
+
+// RangeByScore return members with score between min and max inclusive
+func (z *ZSet) RangeByScore(min float64, max float64) []ZMember {
+	x := z.header
+	for i := z.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && x.level[i].forward.score < min {
+			x = x.level[i].forward
+		}
+	}
+
+	res := []ZMember{}
+	for x = x.level[0].forward; x != nil && x.score <= max; x = x.level[0].forward {
+		res = append(res, ZMember{Member: x.member, Score: x.score})
+	}
+
+	return res
+}
+
+func (z *ZSet) MarshalJSON() ([]byte, error) {
+	return json.Marshal(z.Range(0, -1))
+}
+
+func (z *ZSet) UnmarshalJSON(data []byte) error {
+	members := []ZMember{}
+	err := json.Unmarshal(data, &members)
+	if err != nil {
+		return err
+	}
+
+	*z = *NewZSet()
+	for _, m := range members {
+		z.Add(m.Member, m.Score)
+	}
+	return nil
+}
+
+func (z *ZSet) insert(member string, score float64) {
+	update := make([]*zslNode, zslMaxLevel)
+	rank := make([]int, zslMaxLevel)
+
+	x := z.header
+	for i := z.level - 1; i >= 0; i-- {
+		if i < z.level-1 {
+			rank[i] = rank[i+1]
+		}
+		for x.level[i].forward != nil && x.level[i].forward.less(score, member) {
+			rank[i] += x.level[i].span
+			x = x.level[i].forward
+		}
+		update[i] = x
+	}
+
+	lvl := zslRandomLevel()
+	if lvl > z.level {
+		for i := z.level; i < lvl; i++ {
+			rank[i] = 0
+			update[i] = z.header
+			update[i].level[i].span = z.length
+		}
+		z.level = lvl
+	}
+
+	x = &zslNode{
+		member: member,
+		score:  score,
+		level:  make([]zslLevel, lvl),
+	}
+	for i := 0; i < lvl; i++ {
+		x.level[i].forward = update[i].level[i].forward
+		update[i].level[i].forward = x
+		x.level[i].span = update[i].level[i].span - (rank[0] - rank[i])
+		update[i].level[i].span = rank[0] - rank[i] + 1
+	}
+	for i := lvl; i < z.level; i++ {
+		update[i].level[i].span++
+	}
+
+	if update[0] != z.header {
+		x.backward = update[0]
+	}
+	if x.level[0].forward != nil {
+		x.level[0].forward.backward = x
+	} else {
+		z.tail = x
+	}
+	z.length++
+}
+
+// delete unlink node of member, caller make sure member is in the list with given score
+func (z *ZSet) delete(member string, score float64) {
+	update := make([]*zslNode, zslMaxLevel)
+
+	x := z.header
+	for i := z.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && x.level[i].forward.less(score, member) {
+			x = x.level[i].forward
+		}
+		update[i] = x
+	}
+
+	x = x.level[0].forward
+	for i := 0; i < z.level; i++ {
+		if update[i].level[i].forward == x {
+			update[i].level[i].span += x.level[i].span - 1
+			update[i].level[i].forward = x.level[i].forward
+		} else {
+			update[i].level[i].span--
+		}
+	}
+
+	if x.level[0].forward != nil {
+		x.level[0].forward.backward = x.backward
+	} else {
+		z.tail = x.backward
+	}
+	for z.level > 1 && z.header.level[z.level-1].forward == nil {
+		z.level--
+	}
+	z.length--
+}
+
+// nodeByRank find node at one based rank
+func (z *ZSet) nodeByRank(rank int) *zslNode {
+	traversed := 0
+	x := z.header
+	for i := z.level - 1; i >= 0; i-- {
+		for x.level[i].forward != nil && traversed+x.level[i].span <= rank {
+			traversed += x.level[i].span
+			x = x.level[i].forward
+		}
+		if traversed == rank {
+			return x
+		}
+	}
+
+	return nil
+}
+
+// less report whether node order before given score and member
+func (n *zslNode) less(score float64, member string) bool {
+	return n.score < score || (n.score == score && n.member < member)
+}
+
+// greater report whether node order after given score and member
+func (n *zslNode) greater(score float64, member string) bool {
+	return n.score > score || (n.score == score && n.member > member)
+}
+
+func zslRandomLevel() int {
+	lvl := 1
+	for lvl < zslMaxLevel && rand.Float64() < zslP {
+		lvl++
+	}
+	return lvl
+}
+
+func NewZSet() *ZSet {
+	return &ZSet{
+		dict: make(map[string]float64),
+		header: &zslNode{
+			level: make([]zslLevel, zslMaxLevel),
+		},
+		level: 1,
+	}
+}
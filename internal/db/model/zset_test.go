@@ -0,0 +1,86 @@
+package model
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestZSetAddRemove(t *testing.T) {
+	z := NewZSet()
+
+	assert.True(t, z.Add("a", 1))
+	assert.True(t, z.Add("b", 2))
+	assert.False(t, z.Add("a", 3))
+	assert.Equal(t, 2, z.Len())
+
+	score, ok := z.Score("a")
+	assert.True(t, ok)
+	assert.Equal(t, float64(3), score)
+
+	assert.True(t, z.Remove("a"))
+	assert.False(t, z.Remove("a"))
+	assert.Equal(t, 1, z.Len())
+
+	_, ok = z.Score("a")
+	assert.False(t, ok)
+}
+
+func TestZSetRange(t *testing.T) {
+	z := NewZSet()
+	z.Add("c", 2)
+	z.Add("a", 1)
+	z.Add("b", 2)
+	z.Add("d", 4)
+
+	assert.Equal(t, []ZMember{{"a", 1}, {"b", 2}, {"c", 2}, {"d", 4}}, z.Range(0, -1))
+	assert.Equal(t, []ZMember{{"c", 2}, {"d", 4}}, z.Range(-2, -1))
+	assert.Equal(t, 0, len(z.Range(3, 1)))
+	assert.Equal(t, []ZMember{{"b", 2}, {"c", 2}}, z.RangeByScore(1.5, 3))
+	assert.Equal(t, 0, len(z.RangeByScore(5, 10)))
+}
+
+func TestZSetRank(t *testing.T) {
+	z := NewZSet()
+	n := 1000
+	// Insert in reverse so every insert land at the head
+	for i := n - 1; i >= 0; i-- {
+		z.Add("m"+strconv.Itoa(i), float64(i))
+	}
+
+	for _, i := range []int{0, 1, 500, 999} {
+		rank, ok := z.Rank("m" + strconv.Itoa(i))
+		assert.True(t, ok)
+		assert.Equal(t, i, rank)
+		assert.Equal(t, "m"+strconv.Itoa(i), z.Range(i, i)[0].Member)
+	}
+
+	for i := 0; i < n; i += 2 {
+		z.Remove("m" + strconv.Itoa(i))
+	}
+
+	rank, ok := z.Rank("m501")
+	assert.True(t, ok)
+	assert.Equal(t, 250, rank)
+	assert.Equal(t, n/2, len(z.Range(0, -1)))
+
+	_, ok = z.Rank("m500")
+	assert.False(t, ok)
+}
+
+func TestZSetJSON(t *testing.T) {
+	z := NewZSet()
+	z.Add("a", 1)
+	z.Add("b", -2.5)
+
+	raw, err := json.Marshal(z)
+	assert.Nil(t, err)
+
+	res := new(ZSet)
+	err = json.Unmarshal(raw, res)
+
+	assert.Nil(t, err)
+	assert.Equal(t, z.Range(0, -1), res.Range(0, -1))
+}
@@ -0,0 +1,535 @@
+package db
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Snapshot file is laid out as
+//
+//	[magic "MRDB"][version u8][created at unix ms int64]
+//	entries of [snapshotEntryOp u8][key len u32][key][expire at unix ms int64, 0 for none][value len u32][value]
+//	[snapshotEOFOp u8][crc32 of every byte before it u32]
+const (
+	SnapshotVersion uint8 = 1
+
+	snapshotEntryOp uint8 = 0x01
+	snapshotEOFOp   uint8 = 0xff
+)
+
+var snapshotMagic = []byte("MRDB")
+
+var (
+	ErrSnapshotActive  = errors.New("Snapshot already in progress")
+	ErrSnapshotNoPath  = errors.New("Snapshot path is not set")
+	ErrInvalidSnapshot = errors.New("Invalid snapshot file")
+)
+
+// ValueCodec encode values of store in snapshot
+type ValueCodec[T any] struct {
+	Marshal   func(v T) ([]byte, error)
+	Unmarshal func(data []byte) (T, error)
+}
+
+// JSONCodec is used when store has no codec set
+func JSONCodec[T any]() ValueCodec[T] {
+	return ValueCodec[T]{
+		Marshal: func(v T) ([]byte, error) {
+			return json.Marshal(v)
+		},
+		Unmarshal: func(data []byte) (T, error) {
+			var v T
+			err := json.Unmarshal(data, &v)
+			return v, err
+		},
+	}
+}
+
+// SavePolicy ask for snapshot once Interval passed since last one and at least Changes writes were made
+type SavePolicy struct {
+	Interval time.Duration
+	Changes  int64
+}
+
+// ParseSavePolicies parse pairs of seconds and changes like "900 1 300 10", empty string give no policy
+func ParseSavePolicies(s string) ([]SavePolicy, error) {
+	fields := strings.Fields(s)
+	if len(fields)%2 != 0 {
+		return nil, fmt.Errorf("Invalid save policy %q", s)
+	}
+
+	policies := make([]SavePolicy, 0, len(fields)/2)
+	for i := 0; i < len(fields); i += 2 {
+		secs, err := strconv.ParseUint(fields[i], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid save policy %q", s)
+		}
+		changes, err := strconv.ParseUint(fields[i+1], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid save policy %q", s)
+		}
+
+		policies = append(policies, SavePolicy{
+			Interval: time.Duration(secs) * time.Second,
+			Changes:  int64(changes),
+		})
+	}
+
+	return policies, nil
+}
+
+type snapshotEntry struct {
+	expireAt int64
+	value    []byte
+}
+
+// snapshotState make running snapshot see keyspace as it was when it started. Key changed
+// before snapshot reach it is encoded right before the change, like copy on write.
+type snapshotState struct {
+	// Keys existing at start that are neither written nor preserved yet
+	pending   map[string]struct{}
+	preserved map[string]*snapshotEntry
+	err       error
+}
+
+func (kv *KVStore[T]) codec() ValueCodec[T] {
+	if kv.Codec.Marshal == nil || kv.Codec.Unmarshal == nil {
+		return JSONCodec[T]()
+	}
+	return kv.Codec
+}
+
+// Save write snapshot of keyspace to SnapshotPath, writes to store go on while it is written
+func (kv *KVStore[T]) Save() error {
+	s, keys, dirty, err := kv.beginSnapshot()
+	if err != nil {
+		return err
+	}
+
+	return kv.writeSnapshot(s, keys, dirty)
+}
+
+// BackgroundSave run Save in background, error is returned only when it cannot start
+func (kv *KVStore[T]) BackgroundSave() error {
+	s, keys, dirty, err := kv.beginSnapshot()
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		err := kv.writeSnapshot(s, keys, dirty)
+		if err != nil {
+			log.Println("snapshot:", err)
+		}
+	}()
+	return nil
+}
+
+// Dirty return number of writes since last successful snapshot
+func (kv *KVStore[T]) Dirty() int64 {
+	kv.RLock()
+	defer kv.RUnlock()
+
+	return kv.dirty
+}
+
+// LastSave return time last successful snapshot was started, or when store was created if there was none
+func (kv *KVStore[T]) LastSave() time.Time {
+	kv.RLock()
+	defer kv.RUnlock()
+
+	return kv.lastSave
+}
+
+// ShouldSave report whether any of policies ask for snapshot now
+func (kv *KVStore[T]) ShouldSave(policies []SavePolicy, now time.Time) bool {
+	kv.RLock()
+	defer kv.RUnlock()
+
+	if kv.snapshot != nil {
+		return false
+	}
+
+	for _, p := range policies {
+		if kv.dirty >= p.Changes && now.Sub(kv.lastSave) >= p.Interval {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadSnapshot replace keyspace with snapshot at path, missing file leave store unchanged.
+// Keys that expired since snapshot was written are not loaded.
+func (kv *KVStore[T]) LoadSnapshot(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	storage, expires, err := readSnapshot(bufio.NewReader(f), kv.codec(), time.Now())
+	if err != nil {
+		return fmt.Errorf("%w %s: %v", ErrInvalidSnapshot, path, err)
+	}
+
+	kv.Lock()
+	defer kv.Unlock()
+
+	kv.Storage = storage
+	kv.Expires = expires
+	kv.lastSave = time.Now()
+	kv.dirty = 0
+	return nil
+}
+
+func (kv *KVStore[T]) beginSnapshot() (*snapshotState, []string, int64, error) {
+	kv.Lock()
+	defer kv.Unlock()
+
+	if kv.SnapshotPath == "" {
+		return nil, nil, 0, ErrSnapshotNoPath
+	}
+	if kv.snapshot != nil {
+		return nil, nil, 0, ErrSnapshotActive
+	}
+
+	s := &snapshotState{
+		pending:   make(map[string]struct{}, len(kv.Storage)),
+		preserved: make(map[string]*snapshotEntry),
+	}
+	keys := make([]string, 0, len(kv.Storage))
+	for k := range kv.Storage {
+		keys = append(keys, k)
+		s.pending[k] = struct{}{}
+	}
+	kv.snapshot = s
+	kv.snapshotStart = time.Now()
+
+	return s, keys, kv.dirty, nil
+}
+
+// writeSnapshot write keys as they were at start to temp file and rename it over SnapshotPath,
+// so crash in the middle never leave partial snapshot behind
+func (kv *KVStore[T]) writeSnapshot(s *snapshotState, keys []string, dirty int64) error {
+	path := kv.SnapshotPath
+	err := kv.writeSnapshotFile(path, s, keys)
+
+	kv.Lock()
+	defer kv.Unlock()
+
+	kv.snapshot = nil
+	if err != nil {
+		return err
+	}
+
+	// Writes made while snapshot was written are not in it
+	kv.dirty -= dirty
+	kv.lastSave = kv.snapshotStart
+	return nil
+}
+
+func (kv *KVStore[T]) writeSnapshotFile(path string, s *snapshotState, keys []string) error {
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// Only left when something failed before rename
+		tmp.Close()
+		os.Remove(tmp.Name())
+	}()
+
+	crc := crc32.NewIEEE()
+	w := bufio.NewWriter(io.MultiWriter(tmp, crc))
+
+	err = writeSnapshotHeader(w, time.Now())
+	if err != nil {
+		return err
+	}
+
+	for _, k := range keys {
+		entry, err := kv.snapshotEntry(s, k)
+		if err != nil {
+			return err
+		}
+		if entry == nil {
+			continue
+		}
+
+		err = writeSnapshotEntry(w, k, entry)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = writeSnapshotTrailer(w, crc)
+	if err != nil {
+		return err
+	}
+
+	err = tmp.Chmod(0644)
+	if err != nil {
+		return err
+	}
+	err = tmp.Sync()
+	if err != nil {
+		return err
+	}
+	err = tmp.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// snapshotEntry return entry of key as it was at snapshot start, nil if it no longer matter.
+// Read lock is enough, state of snapshot is only changed by its writer under read lock
+// and by writes to store under write lock.
+func (kv *KVStore[T]) snapshotEntry(s *snapshotState, k string) (*snapshotEntry, error) {
+	kv.RLock()
+	defer kv.RUnlock()
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	if entry, ok := s.preserved[k]; ok {
+		delete(s.preserved, k)
+		return entry, nil
+	}
+
+	if _, ok := s.pending[k]; !ok {
+		return nil, nil
+	}
+	delete(s.pending, k)
+
+	return kv.encodeEntry(k)
+}
+
+// preserve keep key as it is for running snapshot, must be called with write lock held
+// before key is changed
+func (kv *KVStore[T]) preserve(k string) {
+	s := kv.snapshot
+	if s == nil {
+		return
+	}
+	if _, ok := s.pending[k]; !ok {
+		return
+	}
+	delete(s.pending, k)
+
+	entry, err := kv.encodeEntry(k)
+	if err != nil {
+		s.err = err
+		return
+	}
+	s.preserved[k] = entry
+}
+
+func (kv *KVStore[T]) encodeEntry(k string) (*snapshotEntry, error) {
+	v, ok := kv.Storage[k]
+	if !ok {
+		return nil, nil
+	}
+
+	value, err := kv.codec().Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &snapshotEntry{value: value}
+	if at, ok := kv.Expires[k]; ok {
+		entry.expireAt = at.UnixMilli()
+	}
+	return entry, nil
+}
+
+func writeSnapshotHeader(w io.Writer, created time.Time) error {
+	_, err := w.Write(snapshotMagic)
+	if err != nil {
+		return err
+	}
+
+	err = binary.Write(w, binary.BigEndian, SnapshotVersion)
+	if err != nil {
+		return err
+	}
+
+	return binary.Write(w, binary.BigEndian, created.UnixMilli())
+}
+
+func writeSnapshotEntry(w io.Writer, k string, entry *snapshotEntry) error {
+	err := binary.Write(w, binary.BigEndian, snapshotEntryOp)
+	if err != nil {
+		return err
+	}
+
+	err = writeSnapshotBytes(w, []byte(k))
+	if err != nil {
+		return err
+	}
+
+	err = binary.Write(w, binary.BigEndian, entry.expireAt)
+	if err != nil {
+		return err
+	}
+
+	return writeSnapshotBytes(w, entry.value)
+}
+
+// writeSnapshotTrailer write end marker and checksum of everything written before it.
+// Writer is flushed so crc has seen every byte.
+func writeSnapshotTrailer(w *bufio.Writer, crc hash.Hash32) error {
+	err := binary.Write(w, binary.BigEndian, snapshotEOFOp)
+	if err != nil {
+		return err
+	}
+
+	err = w.Flush()
+	if err != nil {
+		return err
+	}
+
+	err = binary.Write(w, binary.BigEndian, crc.Sum32())
+	if err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+func writeSnapshotBytes(w io.Writer, b []byte) error {
+	err := binary.Write(w, binary.BigEndian, uint32(len(b)))
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(b)
+	return err
+}
+
+func readSnapshot[T any](r io.Reader, codec ValueCodec[T], now time.Time) (map[string]T, map[string]time.Time, error) {
+	crc := crc32.NewIEEE()
+	tr := io.TeeReader(r, crc)
+
+	magic := make([]byte, len(snapshotMagic))
+	_, err := io.ReadFull(tr, magic)
+	if err != nil || !bytes.Equal(magic, snapshotMagic) {
+		return nil, nil, errors.New("missing header")
+	}
+
+	var (
+		version uint8
+		created int64
+	)
+	err = binary.Read(tr, binary.BigEndian, &version)
+	if err != nil {
+		return nil, nil, err
+	}
+	if version == 0 || version > SnapshotVersion {
+		return nil, nil, fmt.Errorf("unsupported version %d", version)
+	}
+	err = binary.Read(tr, binary.BigEndian, &created)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	storage := make(map[string]T)
+	expires := make(map[string]time.Time)
+	for {
+		var op uint8
+		err = binary.Read(tr, binary.BigEndian, &op)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if op == snapshotEOFOp {
+			break
+		}
+		if op != snapshotEntryOp {
+			return nil, nil, fmt.Errorf("unknown entry %d", op)
+		}
+
+		k, err := readSnapshotBytes(tr)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var expireAt int64
+		err = binary.Read(tr, binary.BigEndian, &expireAt)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		raw, err := readSnapshotBytes(tr)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		v, err := codec.Unmarshal(raw)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if expireAt != 0 {
+			at := time.UnixMilli(expireAt)
+			if !now.Before(at) {
+				continue
+			}
+			expires[string(k)] = at
+		}
+		storage[string(k)] = v
+	}
+
+	sum := crc.Sum32()
+	var want uint32
+	err = binary.Read(r, binary.BigEndian, &want)
+	if err != nil {
+		return nil, nil, err
+	}
+	if sum != want {
+		return nil, nil, errors.New("checksum mismatch")
+	}
+
+	return storage, expires, nil
+}
+
+// readSnapshotBytes read length prefixed bytes, buffer grow with data actually read
+// so damaged length cannot make it allocate more than file holds
+func readSnapshotBytes(r io.Reader) ([]byte, error) {
+	var n uint32
+	err := binary.Read(r, binary.BigEndian, &n)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := new(bytes.Buffer)
+	_, err = io.CopyN(buf, r, int64(n))
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,175 @@
+package db
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestSnapshotStore(t *testing.T) *KVStore[string] {
+	kvstore := NewKVStore[string](nil)
+	kvstore.SnapshotPath = filepath.Join(t.TempDir(), "dump.mrdb")
+
+	return kvstore
+}
+
+func TestKVStore_SaveAndLoadSnapshot(t *testing.T) {
+	kvstore := newTestSnapshotStore(t)
+	kvstore.Set("a", "1")
+	kvstore.SetWithTTL("ttl", "2", time.Minute)
+	kvstore.SetWithTTL("expired", "3", time.Millisecond)
+	time.Sleep(2 * time.Millisecond)
+
+	err := kvstore.Save()
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), kvstore.Dirty())
+	assert.False(t, kvstore.LastSave().IsZero())
+
+	loaded := NewKVStore[string](nil)
+	err = loaded.LoadSnapshot(kvstore.SnapshotPath)
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"a": "1", "ttl": "2"}, loaded.Storage)
+	assert.Equal(t, 1, len(loaded.Expires))
+	assert.True(t, loaded.TTL("ttl") > 59*time.Second)
+}
+
+func TestKVStore_SaveSnapshotFileMode(t *testing.T) {
+	kvstore := newTestSnapshotStore(t)
+	kvstore.Set("a", "1")
+
+	err := kvstore.Save()
+	assert.Nil(t, err)
+
+	info, err := os.Stat(kvstore.SnapshotPath)
+	assert.Nil(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+
+	// Temp file is renamed over the snapshot, nothing else is left behind
+	entries, _ := os.ReadDir(filepath.Dir(kvstore.SnapshotPath))
+	assert.Equal(t, 1, len(entries))
+}
+
+func TestKVStore_SnapshotSeeKeyspaceAtStart(t *testing.T) {
+	kvstore := newTestSnapshotStore(t)
+	kvstore.Set("a", "1")
+	kvstore.Set("b", "2")
+	kvstore.Set("c", "3")
+
+	s, keys, dirty, err := kvstore.beginSnapshot()
+	assert.Nil(t, err)
+
+	// Writes made after start must not show up in the snapshot
+	kvstore.Set("a", "changed")
+	kvstore.Delete("b")
+	kvstore.Set("d", "new")
+	kvstore.Expire("c", time.Hour)
+
+	err = kvstore.writeSnapshot(s, keys, dirty)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(4), kvstore.Dirty())
+
+	loaded := NewKVStore[string](nil)
+	err = loaded.LoadSnapshot(kvstore.SnapshotPath)
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"a": "1", "b": "2", "c": "3"}, loaded.Storage)
+	assert.Equal(t, 0, len(loaded.Expires))
+}
+
+func TestKVStore_BackgroundSaveActive(t *testing.T) {
+	kvstore := newTestSnapshotStore(t)
+
+	_, _, _, err := kvstore.beginSnapshot()
+	assert.Nil(t, err)
+
+	assert.Equal(t, ErrSnapshotActive, kvstore.BackgroundSave())
+	assert.Equal(t, ErrSnapshotActive, kvstore.Save())
+}
+
+func TestKVStore_SaveWithoutPath(t *testing.T) {
+	kvstore := NewKVStore[string](nil)
+
+	assert.Equal(t, ErrSnapshotNoPath, kvstore.Save())
+}
+
+func TestKVStore_LoadSnapshotMissingFile(t *testing.T) {
+	kvstore := NewKVStore[string](nil)
+	kvstore.Set("a", "1")
+
+	err := kvstore.LoadSnapshot(filepath.Join(t.TempDir(), "missing.mrdb"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, "1", kvstore.Get("a"))
+}
+
+func TestKVStore_LoadSnapshotChecksumMismatch(t *testing.T) {
+	kvstore := newTestSnapshotStore(t)
+	kvstore.Set("key", "value")
+	kvstore.Save()
+
+	raw, _ := os.ReadFile(kvstore.SnapshotPath)
+	i := bytes.Index(raw, []byte("value"))
+	raw[i] = 'V'
+	os.WriteFile(kvstore.SnapshotPath, raw, 0644)
+
+	loaded := NewKVStore[string](nil)
+	loaded.Set("a", "1")
+	err := loaded.LoadSnapshot(kvstore.SnapshotPath)
+
+	assert.ErrorIs(t, err, ErrInvalidSnapshot)
+	// Store is left as it was
+	assert.Equal(t, "1", loaded.Get("a"))
+}
+
+func TestKVStore_LoadSnapshotTruncated(t *testing.T) {
+	kvstore := newTestSnapshotStore(t)
+	kvstore.Set("key", "value")
+	kvstore.Save()
+
+	raw, _ := os.ReadFile(kvstore.SnapshotPath)
+	os.WriteFile(kvstore.SnapshotPath, raw[:len(raw)-3], 0644)
+
+	err := NewKVStore[string](nil).LoadSnapshot(kvstore.SnapshotPath)
+
+	assert.ErrorIs(t, err, ErrInvalidSnapshot)
+}
+
+func TestKVStore_ShouldSave(t *testing.T) {
+	kvstore := newTestSnapshotStore(t)
+	policies := []SavePolicy{{Interval: time.Minute, Changes: 2}}
+
+	kvstore.Set("a", "1")
+	assert.False(t, kvstore.ShouldSave(policies, time.Now()))
+
+	kvstore.Set("b", "2")
+	assert.False(t, kvstore.ShouldSave(policies, time.Now()))
+	assert.True(t, kvstore.ShouldSave(policies, time.Now().Add(time.Minute)))
+
+	kvstore.Save()
+	kvstore.Set("c", "3")
+	kvstore.Set("d", "4")
+	assert.False(t, kvstore.ShouldSave(policies, time.Now()))
+	assert.True(t, kvstore.ShouldSave(policies, time.Now().Add(time.Minute)))
+}
+
+func TestParseSavePolicies(t *testing.T) {
+	policies, err := ParseSavePolicies("900 1 60 10000")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []SavePolicy{{15 * time.Minute, 1}, {time.Minute, 10000}}, policies)
+
+	policies, err = ParseSavePolicies("")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(policies))
+
+	for _, s := range []string{"900", "900 x", "-1 1"} {
+		_, err = ParseSavePolicies(s)
+		assert.NotNil(t, err)
+	}
+}
@@ -0,0 +1,187 @@
+package db
+
+import "time"
+
+// Store is what requests use of KVStore, Tx implement it for requests run in a transaction
+type Store[T any] interface {
+	Get(k string) T
+	Set(k string, v T)
+	SetWithTTL(k string, v T, ttl time.Duration)
+	Update(k string, fn func(v T, ok bool) (T, error)) (T, error)
+	Compute(k string, fn func(v T, ok bool) (T, bool, error)) (T, error)
+	Exec(k string, cmd Command[T]) (T, error)
+	View(k string, fn func(v T, ok bool) error) error
+	ViewKeys(keys []string, fn func(vs []T) error) error
+	WaitKeys(keys ...string) (<-chan struct{}, func())
+	Delete(keys ...string) int
+	Exists(keys ...string) int
+	Scan(cursor string, match string, count int) (string, []string)
+	Expire(k string, ttl time.Duration) bool
+	TTL(k string) time.Duration
+	Persist(k string) bool
+	Version(k string) uint64
+}
+
+// Tx is KVStore seen from inside Atomically, write lock is already held so its methods
+// do not lock and it must not be used once Atomically return
+type Tx[T any] struct {
+	kv *KVStore[T]
+}
+
+// Atomically run fn with write lock held, writes made through tx are seen by others at once
+func (kv *KVStore[T]) Atomically(fn func(tx *Tx[T]) error) error {
+	kv.Lock()
+	defer kv.Unlock()
+
+	return fn(&Tx[T]{kv: kv})
+}
+
+// Version return version of key, it change on every write to key and is zero only for
+// missing key. Deleted key keep its version while watched. Versions start again when
+// store is loaded.
+func (kv *KVStore[T]) Version(k string) uint64 {
+	kv.Lock()
+	defer kv.Unlock()
+
+	return (&Tx[T]{kv: kv}).Version(k)
+}
+
+// Watch return versions of keys and keep version of watched key once it is deleted, so
+// deleting and adding it back is seen as a change. Unwatch must be called for every Watch.
+func (kv *KVStore[T]) Watch(keys ...string) []uint64 {
+	kv.Lock()
+	defer kv.Unlock()
+
+	if kv.watched == nil {
+		kv.watched = make(map[string]int)
+	}
+
+	now := time.Now()
+	vs := make([]uint64, len(keys))
+	for i, k := range keys {
+		// Expired key is deleted first so its lazy deletion is not taken as a change
+		if kv.isExpired(k, now) {
+			kv.delete(k)
+		}
+		kv.watched[k]++
+		vs[i] = kv.version(k)
+	}
+
+	return vs
+}
+
+func (kv *KVStore[T]) Unwatch(keys ...string) {
+	kv.Lock()
+	defer kv.Unlock()
+
+	for _, k := range keys {
+		if kv.watched[k] == 0 {
+			continue
+		}
+
+		kv.watched[k]--
+		if kv.watched[k] > 0 {
+			continue
+		}
+		delete(kv.watched, k)
+		if _, ok := kv.Storage[k]; !ok {
+			delete(kv.versions, k)
+		}
+	}
+}
+
+func (tx *Tx[T]) Get(k string) T {
+	kv := tx.kv
+	if kv.isExpired(k, time.Now()) {
+		kv.delete(k)
+	}
+
+	return kv.Storage[k]
+}
+
+func (tx *Tx[T]) Set(k string, v T) {
+	tx.kv.set(k, v)
+}
+
+func (tx *Tx[T]) SetWithTTL(k string, v T, ttl time.Duration) {
+	tx.kv.setWithTTL(k, v, ttl)
+}
+
+func (tx *Tx[T]) Update(k string, fn func(v T, ok bool) (T, error)) (T, error) {
+	return tx.Compute(k, func(v T, ok bool) (T, bool, error) {
+		nv, err := fn(v, ok)
+		return nv, true, err
+	})
+}
+
+func (tx *Tx[T]) Compute(k string, fn func(v T, ok bool) (T, bool, error)) (T, error) {
+	return tx.kv.compute(k, fn)
+}
+
+func (tx *Tx[T]) Exec(k string, cmd Command[T]) (T, error) {
+	return tx.kv.exec(k, cmd)
+}
+
+func (tx *Tx[T]) View(k string, fn func(v T, ok bool) error) error {
+	return tx.kv.view(k, fn)
+}
+
+func (tx *Tx[T]) ViewKeys(keys []string, fn func(vs []T) error) error {
+	return tx.kv.viewKeys(keys, fn)
+}
+
+func (tx *Tx[T]) WaitKeys(keys ...string) (<-chan struct{}, func()) {
+	return tx.kv.WaitKeys(keys...)
+}
+
+func (tx *Tx[T]) Delete(keys ...string) int {
+	return tx.kv.deleteKeys(keys)
+}
+
+func (tx *Tx[T]) Exists(keys ...string) int {
+	return tx.kv.countExisting(keys)
+}
+
+func (tx *Tx[T]) Scan(cursor string, match string, count int) (string, []string) {
+	return scanPage(tx.kv.scanCandidates(cursor), match, count)
+}
+
+func (tx *Tx[T]) Expire(k string, ttl time.Duration) bool {
+	return tx.kv.expire(k, ttl)
+}
+
+func (tx *Tx[T]) TTL(k string) time.Duration {
+	return tx.kv.ttl(k)
+}
+
+func (tx *Tx[T]) Persist(k string) bool {
+	return tx.kv.persist(k)
+}
+
+// Version is KVStore.Version, key that expired is deleted first so expiring count as a change
+func (tx *Tx[T]) Version(k string) uint64 {
+	kv := tx.kv
+	if kv.isExpired(k, time.Now()) {
+		kv.delete(k)
+	}
+
+	return kv.version(k)
+}
+
+// version of key that exists but was not written since store was loaded is taken from
+// clock once asked, so version of existing key is never zero
+func (kv *KVStore[T]) version(k string) uint64 {
+	if v, ok := kv.versions[k]; ok {
+		return v
+	}
+	if _, ok := kv.Storage[k]; !ok {
+		return 0
+	}
+
+	kv.clock++
+	if kv.versions == nil {
+		kv.versions = make(map[string]uint64)
+	}
+	kv.versions[k] = kv.clock
+	return kv.clock
+}
@@ -0,0 +1,82 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKVStore_Version(t *testing.T) {
+	testkey := "test_key"
+	kvstore := NewKVStore[string](nil)
+
+	assert.Equal(t, uint64(0), kvstore.Version(testkey))
+
+	kvstore.Set(testkey, "test_val")
+	v1 := kvstore.Version(testkey)
+	kvstore.Set(testkey, "test_val")
+	v2 := kvstore.Version(testkey)
+
+	assert.NotEqual(t, uint64(0), v1)
+	assert.NotEqual(t, v1, v2)
+}
+
+func TestKVStore_VersionOfLoadedKey(t *testing.T) {
+	testkey := "test_key"
+	kvstore := NewKVStore[string](nil)
+	kvstore.Storage[testkey] = "test_val"
+
+	// Key not written since load still has a version of its own
+	v := kvstore.Version(testkey)
+	assert.NotEqual(t, uint64(0), v)
+	assert.Equal(t, v, kvstore.Version(testkey))
+
+	kvstore.Delete(testkey)
+	assert.Equal(t, uint64(0), kvstore.Version(testkey))
+}
+
+func TestKVStore_WatchKeepVersionOfDeletedKey(t *testing.T) {
+	testkey := "test_key"
+	kvstore := NewKVStore[string](nil)
+	kvstore.Set(testkey, "test_val")
+
+	vs := kvstore.Watch("test_missing_key")
+	kvstore.Set("test_missing_key", "test_val")
+	kvstore.Delete("test_missing_key")
+
+	// Deleted key keep its version while watched so adding it back is a change
+	assert.NotEqual(t, vs[0], kvstore.Version("test_missing_key"))
+	kvstore.Unwatch("test_missing_key")
+	assert.Equal(t, uint64(0), kvstore.Version("test_missing_key"))
+
+	vs = kvstore.Watch(testkey)
+	kvstore.Delete(testkey)
+	assert.NotEqual(t, vs[0], kvstore.Version(testkey))
+
+	kvstore.Delete(testkey)
+	kvstore.Unwatch(testkey)
+
+	assert.Equal(t, uint64(0), kvstore.Version(testkey))
+}
+
+func TestKVStore_Atomically(t *testing.T) {
+	kvstore := NewKVStore[int](nil)
+	mockerr := errors.New("Some tx error")
+
+	err := kvstore.Atomically(func(tx *Tx[int]) error {
+		tx.Set("test_key_1", 1)
+		_, err := tx.Update("test_key_1", func(v int, ok bool) (int, error) {
+			return v + 1, nil
+		})
+		assert.Nil(t, err)
+		tx.Set("test_key_2", tx.Get("test_key_1"))
+		return mockerr
+	})
+
+	assert.Equal(t, mockerr, err)
+	// Writes are kept whatever fn return
+	assert.Equal(t, 2, kvstore.Get("test_key_1"))
+	assert.Equal(t, 2, kvstore.Get("test_key_2"))
+	assert.Equal(t, 2, kvstore.Exists("test_key_1", "test_key_2"))
+}
@@ -0,0 +1,58 @@
+package db
+
+import "sync"
+
+// keyWaiters wake goroutines blocked until a key is written
+type keyWaiters struct {
+	sync.Mutex
+	waiting map[string]map[*waiter]struct{}
+}
+
+type waiter struct {
+	once sync.Once
+	ch   chan struct{}
+}
+
+func (w *waiter) wake() {
+	w.once.Do(func() {
+		close(w.ch)
+	})
+}
+
+func (kw *keyWaiters) wait(keys []string) (<-chan struct{}, func()) {
+	w := &waiter{ch: make(chan struct{})}
+
+	kw.Lock()
+	if kw.waiting == nil {
+		kw.waiting = make(map[string]map[*waiter]struct{})
+	}
+	for _, k := range keys {
+		if kw.waiting[k] == nil {
+			kw.waiting[k] = make(map[*waiter]struct{})
+		}
+		kw.waiting[k][w] = struct{}{}
+	}
+	kw.Unlock()
+
+	cancel := func() {
+		kw.Lock()
+		defer kw.Unlock()
+		for _, k := range keys {
+			delete(kw.waiting[k], w)
+			if len(kw.waiting[k]) == 0 {
+				delete(kw.waiting, k)
+			}
+		}
+	}
+
+	return w.ch, cancel
+}
+
+func (kw *keyWaiters) notify(k string) {
+	kw.Lock()
+	defer kw.Unlock()
+
+	for w := range kw.waiting[k] {
+		w.wake()
+	}
+}
@@ -8,6 +8,7 @@ import (
 	tls "crypto/tls"
 	net "net"
 	reflect "reflect"
+	time "time"
 
 	gomock "go.uber.org/mock/gomock"
 )
@@ -36,31 +37,31 @@ func (m *MockDialer) EXPECT() *MockDialerMockRecorder {
 }
 
 // Dial mocks base method.
-func (m *MockDialer) Dial(network, url string) (net.Conn, error) {
+func (m *MockDialer) Dial(network, url string, timeout time.Duration) (net.Conn, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Dial", network, url)
+	ret := m.ctrl.Call(m, "Dial", network, url, timeout)
 	ret0, _ := ret[0].(net.Conn)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Dial indicates an expected call of Dial.
-func (mr *MockDialerMockRecorder) Dial(network, url interface{}) *gomock.Call {
+func (mr *MockDialerMockRecorder) Dial(network, url, timeout interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dial", reflect.TypeOf((*MockDialer)(nil).Dial), network, url)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dial", reflect.TypeOf((*MockDialer)(nil).Dial), network, url, timeout)
 }
 
 // SecureDial mocks base method.
-func (m *MockDialer) SecureDial(network, url string, conf *tls.Config) (net.Conn, error) {
+func (m *MockDialer) SecureDial(network, url string, conf *tls.Config, timeout time.Duration) (net.Conn, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SecureDial", network, url, conf)
+	ret := m.ctrl.Call(m, "SecureDial", network, url, conf, timeout)
 	ret0, _ := ret[0].(net.Conn)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SecureDial indicates an expected call of SecureDial.
-func (mr *MockDialerMockRecorder) SecureDial(network, url, conf interface{}) *gomock.Call {
+func (mr *MockDialerMockRecorder) SecureDial(network, url, conf, timeout interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SecureDial", reflect.TypeOf((*MockDialer)(nil).SecureDial), network, url, conf)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SecureDial", reflect.TypeOf((*MockDialer)(nil).SecureDial), network, url, conf, timeout)
 }
@@ -7,7 +7,9 @@ package mock_payload
 import (
 	net "net"
 	reflect "reflect"
+	time "time"
 
+	model "bitbucket.org/non-pn/mini-redis-go/internal/db/model"
 	payload "bitbucket.org/non-pn/mini-redis-go/internal/payload"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -35,6 +37,90 @@ func (m *MockIRequestContext) EXPECT() *MockIRequestContextMockRecorder {
 	return m.recorder
 }
 
+// AddPatternConn mocks base method.
+func (m *MockIRequestContext) AddPatternConn(k string, id uint64, conn net.Conn) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddPatternConn", k, id, conn)
+}
+
+// AddPatternConn indicates an expected call of AddPatternConn.
+func (mr *MockIRequestContextMockRecorder) AddPatternConn(k, id, conn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPatternConn", reflect.TypeOf((*MockIRequestContext)(nil).AddPatternConn), k, id, conn)
+}
+
+// AddPubsubConn mocks base method.
+func (m *MockIRequestContext) AddPubsubConn(k string, id uint64, conn net.Conn) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "AddPubsubConn", k, id, conn)
+}
+
+// AddPubsubConn indicates an expected call of AddPubsubConn.
+func (mr *MockIRequestContextMockRecorder) AddPubsubConn(k, id, conn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPubsubConn", reflect.TypeOf((*MockIRequestContext)(nil).AddPubsubConn), k, id, conn)
+}
+
+// AtomicRedis mocks base method.
+func (m *MockIRequestContext) AtomicRedis(fn func(payload.IRequestContext) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AtomicRedis", fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AtomicRedis indicates an expected call of AtomicRedis.
+func (mr *MockIRequestContextMockRecorder) AtomicRedis(fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AtomicRedis", reflect.TypeOf((*MockIRequestContext)(nil).AtomicRedis), fn)
+}
+
+// BGSaveRedis mocks base method.
+func (m *MockIRequestContext) BGSaveRedis() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BGSaveRedis")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BGSaveRedis indicates an expected call of BGSaveRedis.
+func (mr *MockIRequestContextMockRecorder) BGSaveRedis() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BGSaveRedis", reflect.TypeOf((*MockIRequestContext)(nil).BGSaveRedis))
+}
+
+// DeleteRedis mocks base method.
+func (m *MockIRequestContext) DeleteRedis(keys ...string) int {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range keys {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "DeleteRedis", varargs...)
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// DeleteRedis indicates an expected call of DeleteRedis.
+func (mr *MockIRequestContextMockRecorder) DeleteRedis(keys ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRedis", reflect.TypeOf((*MockIRequestContext)(nil).DeleteRedis), keys...)
+}
+
+// Done mocks base method.
+func (m *MockIRequestContext) Done() <-chan struct{} {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Done")
+	ret0, _ := ret[0].(<-chan struct{})
+	return ret0
+}
+
+// Done indicates an expected call of Done.
+func (mr *MockIRequestContextMockRecorder) Done() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Done", reflect.TypeOf((*MockIRequestContext)(nil).Done))
+}
+
 // Error mocks base method.
 func (m *MockIRequestContext) Error(code uint16, msg string) error {
 	m.ctrl.T.Helper()
@@ -49,6 +135,94 @@ func (mr *MockIRequestContextMockRecorder) Error(code, msg interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Error", reflect.TypeOf((*MockIRequestContext)(nil).Error), code, msg)
 }
 
+// ExecRedis mocks base method.
+func (m *MockIRequestContext) ExecRedis(k string, cmd model.Command) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExecRedis", k, cmd)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExecRedis indicates an expected call of ExecRedis.
+func (mr *MockIRequestContextMockRecorder) ExecRedis(k, cmd interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecRedis", reflect.TypeOf((*MockIRequestContext)(nil).ExecRedis), k, cmd)
+}
+
+// ExistsRedis mocks base method.
+func (m *MockIRequestContext) ExistsRedis(keys ...string) int {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range keys {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExistsRedis", varargs...)
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// ExistsRedis indicates an expected call of ExistsRedis.
+func (mr *MockIRequestContextMockRecorder) ExistsRedis(keys ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExistsRedis", reflect.TypeOf((*MockIRequestContext)(nil).ExistsRedis), keys...)
+}
+
+// ExpireRedis mocks base method.
+func (m *MockIRequestContext) ExpireRedis(k string, ttl time.Duration) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireRedis", k, ttl)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// ExpireRedis indicates an expected call of ExpireRedis.
+func (mr *MockIRequestContextMockRecorder) ExpireRedis(k, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireRedis", reflect.TypeOf((*MockIRequestContext)(nil).ExpireRedis), k, ttl)
+}
+
+// Flush mocks base method.
+func (m *MockIRequestContext) Flush() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Flush")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Flush indicates an expected call of Flush.
+func (mr *MockIRequestContextMockRecorder) Flush() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flush", reflect.TypeOf((*MockIRequestContext)(nil).Flush))
+}
+
+// GetClient mocks base method.
+func (m *MockIRequestContext) GetClient() *model.Client {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClient")
+	ret0, _ := ret[0].(*model.Client)
+	return ret0
+}
+
+// GetClient indicates an expected call of GetClient.
+func (mr *MockIRequestContextMockRecorder) GetClient() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClient", reflect.TypeOf((*MockIRequestContext)(nil).GetClient))
+}
+
+// GetClients mocks base method.
+func (m *MockIRequestContext) GetClients() *model.ClientRegistry {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetClients")
+	ret0, _ := ret[0].(*model.ClientRegistry)
+	return ret0
+}
+
+// GetClients indicates an expected call of GetClients.
+func (mr *MockIRequestContextMockRecorder) GetClients() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetClients", reflect.TypeOf((*MockIRequestContext)(nil).GetClients))
+}
+
 // GetConn mocks base method.
 func (m *MockIRequestContext) GetConn() net.Conn {
 	m.ctrl.T.Helper()
@@ -92,11 +266,12 @@ func (mr *MockIRequestContextMockRecorder) GetPubsub(k interface{}) *gomock.Call
 }
 
 // GetRedis mocks base method.
-func (m *MockIRequestContext) GetRedis(k string) []byte {
+func (m *MockIRequestContext) GetRedis(k string) ([]byte, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetRedis", k)
 	ret0, _ := ret[0].([]byte)
-	return ret0
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // GetRedis indicates an expected call of GetRedis.
@@ -105,6 +280,58 @@ func (mr *MockIRequestContextMockRecorder) GetRedis(k interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRedis", reflect.TypeOf((*MockIRequestContext)(nil).GetRedis), k)
 }
 
+// MatchPatterns mocks base method.
+func (m *MockIRequestContext) MatchPatterns(topic string) []payload.StringTopic {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MatchPatterns", topic)
+	ret0, _ := ret[0].([]payload.StringTopic)
+	return ret0
+}
+
+// MatchPatterns indicates an expected call of MatchPatterns.
+func (mr *MockIRequestContextMockRecorder) MatchPatterns(topic interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MatchPatterns", reflect.TypeOf((*MockIRequestContext)(nil).MatchPatterns), topic)
+}
+
+// PersistRedis mocks base method.
+func (m *MockIRequestContext) PersistRedis(k string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PersistRedis", k)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// PersistRedis indicates an expected call of PersistRedis.
+func (mr *MockIRequestContextMockRecorder) PersistRedis(k interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PersistRedis", reflect.TypeOf((*MockIRequestContext)(nil).PersistRedis), k)
+}
+
+// RemovePatternConn mocks base method.
+func (m *MockIRequestContext) RemovePatternConn(k string, id uint64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RemovePatternConn", k, id)
+}
+
+// RemovePatternConn indicates an expected call of RemovePatternConn.
+func (mr *MockIRequestContextMockRecorder) RemovePatternConn(k, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePatternConn", reflect.TypeOf((*MockIRequestContext)(nil).RemovePatternConn), k, id)
+}
+
+// RemovePubsubConn mocks base method.
+func (m *MockIRequestContext) RemovePubsubConn(k string, id uint64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RemovePubsubConn", k, id)
+}
+
+// RemovePubsubConn indicates an expected call of RemovePubsubConn.
+func (mr *MockIRequestContextMockRecorder) RemovePubsubConn(k, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePubsubConn", reflect.TypeOf((*MockIRequestContext)(nil).RemovePubsubConn), k, id)
+}
+
 // Response mocks base method.
 func (m *MockIRequestContext) Response(res payload.ResponsePayload) error {
 	m.ctrl.T.Helper()
@@ -119,6 +346,49 @@ func (mr *MockIRequestContextMockRecorder) Response(res interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Response", reflect.TypeOf((*MockIRequestContext)(nil).Response), res)
 }
 
+// RewriteAOFRedis mocks base method.
+func (m *MockIRequestContext) RewriteAOFRedis() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RewriteAOFRedis")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RewriteAOFRedis indicates an expected call of RewriteAOFRedis.
+func (mr *MockIRequestContextMockRecorder) RewriteAOFRedis() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RewriteAOFRedis", reflect.TypeOf((*MockIRequestContext)(nil).RewriteAOFRedis))
+}
+
+// SaveRedis mocks base method.
+func (m *MockIRequestContext) SaveRedis() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveRedis")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveRedis indicates an expected call of SaveRedis.
+func (mr *MockIRequestContextMockRecorder) SaveRedis() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveRedis", reflect.TypeOf((*MockIRequestContext)(nil).SaveRedis))
+}
+
+// ScanRedis mocks base method.
+func (m *MockIRequestContext) ScanRedis(cursor, match string, count int) (string, []string) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScanRedis", cursor, match, count)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].([]string)
+	return ret0, ret1
+}
+
+// ScanRedis indicates an expected call of ScanRedis.
+func (mr *MockIRequestContextMockRecorder) ScanRedis(cursor, match, count interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScanRedis", reflect.TypeOf((*MockIRequestContext)(nil).ScanRedis), cursor, match, count)
+}
+
 // SetPubsub mocks base method.
 func (m *MockIRequestContext) SetPubsub(k string, v payload.StringTopic) {
 	m.ctrl.T.Helper()
@@ -142,3 +412,190 @@ func (mr *MockIRequestContextMockRecorder) SetRedis(k, v interface{}) *gomock.Ca
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRedis", reflect.TypeOf((*MockIRequestContext)(nil).SetRedis), k, v)
 }
+
+// SetRedisWithTTL mocks base method.
+func (m *MockIRequestContext) SetRedisWithTTL(k string, v []byte, ttl time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetRedisWithTTL", k, v, ttl)
+}
+
+// SetRedisWithTTL indicates an expected call of SetRedisWithTTL.
+func (mr *MockIRequestContextMockRecorder) SetRedisWithTTL(k, v, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRedisWithTTL", reflect.TypeOf((*MockIRequestContext)(nil).SetRedisWithTTL), k, v, ttl)
+}
+
+// TTLRedis mocks base method.
+func (m *MockIRequestContext) TTLRedis(k string) time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TTLRedis", k)
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// TTLRedis indicates an expected call of TTLRedis.
+func (mr *MockIRequestContextMockRecorder) TTLRedis(k interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TTLRedis", reflect.TypeOf((*MockIRequestContext)(nil).TTLRedis), k)
+}
+
+// UnwatchRedis mocks base method.
+func (m *MockIRequestContext) UnwatchRedis(keys ...string) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range keys {
+		varargs = append(varargs, a)
+	}
+	m.ctrl.Call(m, "UnwatchRedis", varargs...)
+}
+
+// UnwatchRedis indicates an expected call of UnwatchRedis.
+func (mr *MockIRequestContextMockRecorder) UnwatchRedis(keys ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnwatchRedis", reflect.TypeOf((*MockIRequestContext)(nil).UnwatchRedis), keys...)
+}
+
+// UpdateRedis mocks base method.
+func (m *MockIRequestContext) UpdateRedis(k string, fn func([]byte, bool) ([]byte, error)) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRedis", k, fn)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRedis indicates an expected call of UpdateRedis.
+func (mr *MockIRequestContextMockRecorder) UpdateRedis(k, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRedis", reflect.TypeOf((*MockIRequestContext)(nil).UpdateRedis), k, fn)
+}
+
+// VersionRedis mocks base method.
+func (m *MockIRequestContext) VersionRedis(k string) uint64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VersionRedis", k)
+	ret0, _ := ret[0].(uint64)
+	return ret0
+}
+
+// VersionRedis indicates an expected call of VersionRedis.
+func (mr *MockIRequestContextMockRecorder) VersionRedis(k interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VersionRedis", reflect.TypeOf((*MockIRequestContext)(nil).VersionRedis), k)
+}
+
+// ViewKeysRedis mocks base method.
+func (m *MockIRequestContext) ViewKeysRedis(keys []string, fn func([]*model.Value) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ViewKeysRedis", keys, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ViewKeysRedis indicates an expected call of ViewKeysRedis.
+func (mr *MockIRequestContextMockRecorder) ViewKeysRedis(keys, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ViewKeysRedis", reflect.TypeOf((*MockIRequestContext)(nil).ViewKeysRedis), keys, fn)
+}
+
+// ViewRedis mocks base method.
+func (m *MockIRequestContext) ViewRedis(k string, fn func(*model.Value) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ViewRedis", k, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ViewRedis indicates an expected call of ViewRedis.
+func (mr *MockIRequestContextMockRecorder) ViewRedis(k, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ViewRedis", reflect.TypeOf((*MockIRequestContext)(nil).ViewRedis), k, fn)
+}
+
+// WaitRedis mocks base method.
+func (m *MockIRequestContext) WaitRedis(keys ...string) (<-chan struct{}, func()) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range keys {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "WaitRedis", varargs...)
+	ret0, _ := ret[0].(<-chan struct{})
+	ret1, _ := ret[1].(func())
+	return ret0, ret1
+}
+
+// WaitRedis indicates an expected call of WaitRedis.
+func (mr *MockIRequestContextMockRecorder) WaitRedis(keys ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WaitRedis", reflect.TypeOf((*MockIRequestContext)(nil).WaitRedis), keys...)
+}
+
+// WatchRedis mocks base method.
+func (m *MockIRequestContext) WatchRedis(keys ...string) []uint64 {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{}
+	for _, a := range keys {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "WatchRedis", varargs...)
+	ret0, _ := ret[0].([]uint64)
+	return ret0
+}
+
+// WatchRedis indicates an expected call of WatchRedis.
+func (mr *MockIRequestContextMockRecorder) WatchRedis(keys ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchRedis", reflect.TypeOf((*MockIRequestContext)(nil).WatchRedis), keys...)
+}
+
+// WithRequest mocks base method.
+func (m *MockIRequestContext) WithRequest(pl *payload.RequestPayload, conn net.Conn) payload.IRequestContext {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithRequest", pl, conn)
+	ret0, _ := ret[0].(payload.IRequestContext)
+	return ret0
+}
+
+// WithRequest indicates an expected call of WithRequest.
+func (mr *MockIRequestContextMockRecorder) WithRequest(pl, conn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithRequest", reflect.TypeOf((*MockIRequestContext)(nil).WithRequest), pl, conn)
+}
+
+// MockFlusher is a mock of Flusher interface.
+type MockFlusher struct {
+	ctrl     *gomock.Controller
+	recorder *MockFlusherMockRecorder
+}
+
+// MockFlusherMockRecorder is the mock recorder for MockFlusher.
+type MockFlusherMockRecorder struct {
+	mock *MockFlusher
+}
+
+// NewMockFlusher creates a new mock instance.
+func NewMockFlusher(ctrl *gomock.Controller) *MockFlusher {
+	mock := &MockFlusher{ctrl: ctrl}
+	mock.recorder = &MockFlusherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockFlusher) EXPECT() *MockFlusherMockRecorder {
+	return m.recorder
+}
+
+// Flush mocks base method.
+func (m *MockFlusher) Flush() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Flush")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Flush indicates an expected call of Flush.
+func (mr *MockFlusherMockRecorder) Flush() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flush", reflect.TypeOf((*MockFlusher)(nil).Flush))
+}
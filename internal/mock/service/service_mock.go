@@ -7,9 +7,12 @@ package mock_service
 import (
 	net "net"
 	reflect "reflect"
+	time "time"
 
+	model "bitbucket.org/non-pn/mini-redis-go/internal/db/model"
 	payload "bitbucket.org/non-pn/mini-redis-go/internal/payload"
 	pubsub "bitbucket.org/non-pn/mini-redis-go/internal/service/pubsub"
+	redis "bitbucket.org/non-pn/mini-redis-go/internal/service/redis"
 	tlv "bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
 	gomock "go.uber.org/mock/gomock"
 )
@@ -38,17 +41,17 @@ func (m *MockServiceHandler) EXPECT() *MockServiceHandlerMockRecorder {
 }
 
 // HandleDisconnected mocks base method.
-func (m *MockServiceHandler) HandleDisconnected(conn net.Conn) error {
+func (m *MockServiceHandler) HandleDisconnected(ctx payload.IRequestContext) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "HandleDisconnected", conn)
+	ret := m.ctrl.Call(m, "HandleDisconnected", ctx)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // HandleDisconnected indicates an expected call of HandleDisconnected.
-func (mr *MockServiceHandlerMockRecorder) HandleDisconnected(conn interface{}) *gomock.Call {
+func (mr *MockServiceHandlerMockRecorder) HandleDisconnected(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleDisconnected", reflect.TypeOf((*MockServiceHandler)(nil).HandleDisconnected), conn)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleDisconnected", reflect.TypeOf((*MockServiceHandler)(nil).HandleDisconnected), ctx)
 }
 
 // HandleRequest mocks base method.
@@ -88,53 +91,1987 @@ func (m *MockServiceRequester) EXPECT() *MockServiceRequesterMockRecorder {
 	return m.recorder
 }
 
+// SendBGRewriteAOFRequest mocks base method.
+func (m *MockServiceRequester) SendBGRewriteAOFRequest(conn net.Conn) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendBGRewriteAOFRequest", conn)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendBGRewriteAOFRequest indicates an expected call of SendBGRewriteAOFRequest.
+func (mr *MockServiceRequesterMockRecorder) SendBGRewriteAOFRequest(conn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendBGRewriteAOFRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendBGRewriteAOFRequest), conn)
+}
+
+// SendBGSaveRequest mocks base method.
+func (m *MockServiceRequester) SendBGSaveRequest(conn net.Conn) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendBGSaveRequest", conn)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendBGSaveRequest indicates an expected call of SendBGSaveRequest.
+func (mr *MockServiceRequesterMockRecorder) SendBGSaveRequest(conn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendBGSaveRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendBGSaveRequest), conn)
+}
+
+// SendBLPopRequest mocks base method.
+func (m *MockServiceRequester) SendBLPopRequest(conn net.Conn, keys []string, timeout time.Duration) (string, tlv.TLVCompatible, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendBLPopRequest", conn, keys, timeout)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(tlv.TLVCompatible)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SendBLPopRequest indicates an expected call of SendBLPopRequest.
+func (mr *MockServiceRequesterMockRecorder) SendBLPopRequest(conn, keys, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendBLPopRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendBLPopRequest), conn, keys, timeout)
+}
+
+// SendCASRequest mocks base method.
+func (m *MockServiceRequester) SendCASRequest(conn net.Conn, key string, expected, val tlv.TypeLengthValue, ttl time.Duration) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendCASRequest", conn, key, expected, val, ttl)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendCASRequest indicates an expected call of SendCASRequest.
+func (mr *MockServiceRequesterMockRecorder) SendCASRequest(conn, key, expected, val, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendCASRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendCASRequest), conn, key, expected, val, ttl)
+}
+
+// SendCASVersionRequest mocks base method.
+func (m *MockServiceRequester) SendCASVersionRequest(conn net.Conn, key string, version uint64, val tlv.TypeLengthValue, ttl time.Duration) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendCASVersionRequest", conn, key, version, val, ttl)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendCASVersionRequest indicates an expected call of SendCASVersionRequest.
+func (mr *MockServiceRequesterMockRecorder) SendCASVersionRequest(conn, key, version, val, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendCASVersionRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendCASVersionRequest), conn, key, version, val, ttl)
+}
+
+// SendClientGetNameRequest mocks base method.
+func (m *MockServiceRequester) SendClientGetNameRequest(conn net.Conn) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendClientGetNameRequest", conn)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendClientGetNameRequest indicates an expected call of SendClientGetNameRequest.
+func (mr *MockServiceRequesterMockRecorder) SendClientGetNameRequest(conn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendClientGetNameRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendClientGetNameRequest), conn)
+}
+
+// SendClientKillRequest mocks base method.
+func (m *MockServiceRequester) SendClientKillRequest(conn net.Conn, filter, value string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendClientKillRequest", conn, filter, value)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendClientKillRequest indicates an expected call of SendClientKillRequest.
+func (mr *MockServiceRequesterMockRecorder) SendClientKillRequest(conn, filter, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendClientKillRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendClientKillRequest), conn, filter, value)
+}
+
+// SendClientListRequest mocks base method.
+func (m *MockServiceRequester) SendClientListRequest(conn net.Conn) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendClientListRequest", conn)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendClientListRequest indicates an expected call of SendClientListRequest.
+func (mr *MockServiceRequesterMockRecorder) SendClientListRequest(conn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendClientListRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendClientListRequest), conn)
+}
+
+// SendClientSetNameRequest mocks base method.
+func (m *MockServiceRequester) SendClientSetNameRequest(conn net.Conn, name string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendClientSetNameRequest", conn, name)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendClientSetNameRequest indicates an expected call of SendClientSetNameRequest.
+func (mr *MockServiceRequesterMockRecorder) SendClientSetNameRequest(conn, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendClientSetNameRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendClientSetNameRequest), conn, name)
+}
+
+// SendDecrByRequest mocks base method.
+func (m *MockServiceRequester) SendDecrByRequest(conn net.Conn, key string, delta int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDecrByRequest", conn, key, delta)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendDecrByRequest indicates an expected call of SendDecrByRequest.
+func (mr *MockServiceRequesterMockRecorder) SendDecrByRequest(conn, key, delta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDecrByRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendDecrByRequest), conn, key, delta)
+}
+
+// SendDecrRequest mocks base method.
+func (m *MockServiceRequester) SendDecrRequest(conn net.Conn, key string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDecrRequest", conn, key)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendDecrRequest indicates an expected call of SendDecrRequest.
+func (mr *MockServiceRequesterMockRecorder) SendDecrRequest(conn, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDecrRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendDecrRequest), conn, key)
+}
+
+// SendDelRequest mocks base method.
+func (m *MockServiceRequester) SendDelRequest(conn net.Conn, keys []string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDelRequest", conn, keys)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendDelRequest indicates an expected call of SendDelRequest.
+func (mr *MockServiceRequesterMockRecorder) SendDelRequest(conn, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDelRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendDelRequest), conn, keys)
+}
+
+// SendDiscardRequest mocks base method.
+func (m *MockServiceRequester) SendDiscardRequest(conn net.Conn) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDiscardRequest", conn)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendDiscardRequest indicates an expected call of SendDiscardRequest.
+func (mr *MockServiceRequesterMockRecorder) SendDiscardRequest(conn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDiscardRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendDiscardRequest), conn)
+}
+
+// SendExecRequest mocks base method.
+func (m *MockServiceRequester) SendExecRequest(conn net.Conn) ([]tlv.TypeLengthValue, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendExecRequest", conn)
+	ret0, _ := ret[0].([]tlv.TypeLengthValue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendExecRequest indicates an expected call of SendExecRequest.
+func (mr *MockServiceRequesterMockRecorder) SendExecRequest(conn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendExecRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendExecRequest), conn)
+}
+
+// SendExistsRequest mocks base method.
+func (m *MockServiceRequester) SendExistsRequest(conn net.Conn, keys []string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendExistsRequest", conn, keys)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendExistsRequest indicates an expected call of SendExistsRequest.
+func (mr *MockServiceRequesterMockRecorder) SendExistsRequest(conn, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendExistsRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendExistsRequest), conn, keys)
+}
+
+// SendExpireRequest mocks base method.
+func (m *MockServiceRequester) SendExpireRequest(conn net.Conn, key string, ttl time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendExpireRequest", conn, key, ttl)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendExpireRequest indicates an expected call of SendExpireRequest.
+func (mr *MockServiceRequesterMockRecorder) SendExpireRequest(conn, key, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendExpireRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendExpireRequest), conn, key, ttl)
+}
+
 // SendGetRequest mocks base method.
 func (m *MockServiceRequester) SendGetRequest(conn net.Conn, key string) (tlv.TLVCompatible, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendGetRequest", conn, key)
-	ret0, _ := ret[0].(tlv.TLVCompatible)
+	ret := m.ctrl.Call(m, "SendGetRequest", conn, key)
+	ret0, _ := ret[0].(tlv.TLVCompatible)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendGetRequest indicates an expected call of SendGetRequest.
+func (mr *MockServiceRequesterMockRecorder) SendGetRequest(conn, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendGetRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendGetRequest), conn, key)
+}
+
+// SendGetVersionRequest mocks base method.
+func (m *MockServiceRequester) SendGetVersionRequest(conn net.Conn, key string) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendGetVersionRequest", conn, key)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendGetVersionRequest indicates an expected call of SendGetVersionRequest.
+func (mr *MockServiceRequesterMockRecorder) SendGetVersionRequest(conn, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendGetVersionRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendGetVersionRequest), conn, key)
+}
+
+// SendHDelRequest mocks base method.
+func (m *MockServiceRequester) SendHDelRequest(conn net.Conn, key string, fields []string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHDelRequest", conn, key, fields)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendHDelRequest indicates an expected call of SendHDelRequest.
+func (mr *MockServiceRequesterMockRecorder) SendHDelRequest(conn, key, fields interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHDelRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendHDelRequest), conn, key, fields)
+}
+
+// SendHGetAllRequest mocks base method.
+func (m *MockServiceRequester) SendHGetAllRequest(conn net.Conn, key string) (map[string]tlv.TLVCompatible, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHGetAllRequest", conn, key)
+	ret0, _ := ret[0].(map[string]tlv.TLVCompatible)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendHGetAllRequest indicates an expected call of SendHGetAllRequest.
+func (mr *MockServiceRequesterMockRecorder) SendHGetAllRequest(conn, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHGetAllRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendHGetAllRequest), conn, key)
+}
+
+// SendHGetRequest mocks base method.
+func (m *MockServiceRequester) SendHGetRequest(conn net.Conn, key, field string) (tlv.TLVCompatible, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHGetRequest", conn, key, field)
+	ret0, _ := ret[0].(tlv.TLVCompatible)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendHGetRequest indicates an expected call of SendHGetRequest.
+func (mr *MockServiceRequesterMockRecorder) SendHGetRequest(conn, key, field interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHGetRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendHGetRequest), conn, key, field)
+}
+
+// SendHIncrByRequest mocks base method.
+func (m *MockServiceRequester) SendHIncrByRequest(conn net.Conn, key, field string, delta int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHIncrByRequest", conn, key, field, delta)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendHIncrByRequest indicates an expected call of SendHIncrByRequest.
+func (mr *MockServiceRequesterMockRecorder) SendHIncrByRequest(conn, key, field, delta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHIncrByRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendHIncrByRequest), conn, key, field, delta)
+}
+
+// SendHLenRequest mocks base method.
+func (m *MockServiceRequester) SendHLenRequest(conn net.Conn, key string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHLenRequest", conn, key)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendHLenRequest indicates an expected call of SendHLenRequest.
+func (mr *MockServiceRequesterMockRecorder) SendHLenRequest(conn, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHLenRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendHLenRequest), conn, key)
+}
+
+// SendHSetRequest mocks base method.
+func (m *MockServiceRequester) SendHSetRequest(conn net.Conn, key string, fields tlv.Map) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHSetRequest", conn, key, fields)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendHSetRequest indicates an expected call of SendHSetRequest.
+func (mr *MockServiceRequesterMockRecorder) SendHSetRequest(conn, key, fields interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHSetRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendHSetRequest), conn, key, fields)
+}
+
+// SendIncrByFloatRequest mocks base method.
+func (m *MockServiceRequester) SendIncrByFloatRequest(conn net.Conn, key string, delta float64) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendIncrByFloatRequest", conn, key, delta)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendIncrByFloatRequest indicates an expected call of SendIncrByFloatRequest.
+func (mr *MockServiceRequesterMockRecorder) SendIncrByFloatRequest(conn, key, delta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendIncrByFloatRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendIncrByFloatRequest), conn, key, delta)
+}
+
+// SendIncrByRequest mocks base method.
+func (m *MockServiceRequester) SendIncrByRequest(conn net.Conn, key string, delta int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendIncrByRequest", conn, key, delta)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendIncrByRequest indicates an expected call of SendIncrByRequest.
+func (mr *MockServiceRequesterMockRecorder) SendIncrByRequest(conn, key, delta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendIncrByRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendIncrByRequest), conn, key, delta)
+}
+
+// SendIncrRequest mocks base method.
+func (m *MockServiceRequester) SendIncrRequest(conn net.Conn, key string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendIncrRequest", conn, key)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendIncrRequest indicates an expected call of SendIncrRequest.
+func (mr *MockServiceRequesterMockRecorder) SendIncrRequest(conn, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendIncrRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendIncrRequest), conn, key)
+}
+
+// SendLLenRequest mocks base method.
+func (m *MockServiceRequester) SendLLenRequest(conn net.Conn, key string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendLLenRequest", conn, key)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendLLenRequest indicates an expected call of SendLLenRequest.
+func (mr *MockServiceRequesterMockRecorder) SendLLenRequest(conn, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendLLenRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendLLenRequest), conn, key)
+}
+
+// SendLPopRequest mocks base method.
+func (m *MockServiceRequester) SendLPopRequest(conn net.Conn, key string) (tlv.TLVCompatible, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendLPopRequest", conn, key)
+	ret0, _ := ret[0].(tlv.TLVCompatible)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendLPopRequest indicates an expected call of SendLPopRequest.
+func (mr *MockServiceRequesterMockRecorder) SendLPopRequest(conn, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendLPopRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendLPopRequest), conn, key)
+}
+
+// SendLPushRequest mocks base method.
+func (m *MockServiceRequester) SendLPushRequest(conn net.Conn, key string, vals []tlv.TypeLengthValue) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendLPushRequest", conn, key, vals)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendLPushRequest indicates an expected call of SendLPushRequest.
+func (mr *MockServiceRequesterMockRecorder) SendLPushRequest(conn, key, vals interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendLPushRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendLPushRequest), conn, key, vals)
+}
+
+// SendLRangeRequest mocks base method.
+func (m *MockServiceRequester) SendLRangeRequest(conn net.Conn, key string, start, stop int64) ([]tlv.TLVCompatible, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendLRangeRequest", conn, key, start, stop)
+	ret0, _ := ret[0].([]tlv.TLVCompatible)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendLRangeRequest indicates an expected call of SendLRangeRequest.
+func (mr *MockServiceRequesterMockRecorder) SendLRangeRequest(conn, key, start, stop interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendLRangeRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendLRangeRequest), conn, key, start, stop)
+}
+
+// SendMGetRequest mocks base method.
+func (m *MockServiceRequester) SendMGetRequest(conn net.Conn, keys []string) ([]tlv.TLVCompatible, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMGetRequest", conn, keys)
+	ret0, _ := ret[0].([]tlv.TLVCompatible)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendMGetRequest indicates an expected call of SendMGetRequest.
+func (mr *MockServiceRequesterMockRecorder) SendMGetRequest(conn, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMGetRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendMGetRequest), conn, keys)
+}
+
+// SendMSetNXRequest mocks base method.
+func (m *MockServiceRequester) SendMSetNXRequest(conn net.Conn, pairs tlv.Map) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMSetNXRequest", conn, pairs)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendMSetNXRequest indicates an expected call of SendMSetNXRequest.
+func (mr *MockServiceRequesterMockRecorder) SendMSetNXRequest(conn, pairs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMSetNXRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendMSetNXRequest), conn, pairs)
+}
+
+// SendMSetRequest mocks base method.
+func (m *MockServiceRequester) SendMSetRequest(conn net.Conn, pairs tlv.Map) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMSetRequest", conn, pairs)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendMSetRequest indicates an expected call of SendMSetRequest.
+func (mr *MockServiceRequesterMockRecorder) SendMSetRequest(conn, pairs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMSetRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendMSetRequest), conn, pairs)
+}
+
+// SendMultiRequest mocks base method.
+func (m *MockServiceRequester) SendMultiRequest(conn net.Conn) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMultiRequest", conn)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendMultiRequest indicates an expected call of SendMultiRequest.
+func (mr *MockServiceRequesterMockRecorder) SendMultiRequest(conn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMultiRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendMultiRequest), conn)
+}
+
+// SendPSubRequest mocks base method.
+func (m *MockServiceRequester) SendPSubRequest(conn net.Conn, patterns ...string) (*pubsub.Subscriber, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{conn}
+	for _, a := range patterns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SendPSubRequest", varargs...)
+	ret0, _ := ret[0].(*pubsub.Subscriber)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendPSubRequest indicates an expected call of SendPSubRequest.
+func (mr *MockServiceRequesterMockRecorder) SendPSubRequest(conn interface{}, patterns ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{conn}, patterns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPSubRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendPSubRequest), varargs...)
+}
+
+// SendPersistRequest mocks base method.
+func (m *MockServiceRequester) SendPersistRequest(conn net.Conn, key string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendPersistRequest", conn, key)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendPersistRequest indicates an expected call of SendPersistRequest.
+func (mr *MockServiceRequesterMockRecorder) SendPersistRequest(conn, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPersistRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendPersistRequest), conn, key)
+}
+
+// SendPingRequest mocks base method.
+func (m *MockServiceRequester) SendPingRequest(conn net.Conn, msg *string) (*tlv.String, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendPingRequest", conn, msg)
+	ret0, _ := ret[0].(*tlv.String)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendPingRequest indicates an expected call of SendPingRequest.
+func (mr *MockServiceRequesterMockRecorder) SendPingRequest(conn, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPingRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendPingRequest), conn, msg)
+}
+
+// SendPubRequest mocks base method.
+func (m *MockServiceRequester) SendPubRequest(conn net.Conn, topic string, val tlv.TypeLengthValue) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendPubRequest", conn, topic, val)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendPubRequest indicates an expected call of SendPubRequest.
+func (mr *MockServiceRequesterMockRecorder) SendPubRequest(conn, topic, val interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPubRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendPubRequest), conn, topic, val)
+}
+
+// SendRPopRequest mocks base method.
+func (m *MockServiceRequester) SendRPopRequest(conn net.Conn, key string) (tlv.TLVCompatible, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendRPopRequest", conn, key)
+	ret0, _ := ret[0].(tlv.TLVCompatible)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendRPopRequest indicates an expected call of SendRPopRequest.
+func (mr *MockServiceRequesterMockRecorder) SendRPopRequest(conn, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendRPopRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendRPopRequest), conn, key)
+}
+
+// SendRPushRequest mocks base method.
+func (m *MockServiceRequester) SendRPushRequest(conn net.Conn, key string, vals []tlv.TypeLengthValue) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendRPushRequest", conn, key, vals)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendRPushRequest indicates an expected call of SendRPushRequest.
+func (mr *MockServiceRequesterMockRecorder) SendRPushRequest(conn, key, vals interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendRPushRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendRPushRequest), conn, key, vals)
+}
+
+// SendSAddRequest mocks base method.
+func (m *MockServiceRequester) SendSAddRequest(conn net.Conn, key string, members []string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendSAddRequest", conn, key, members)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendSAddRequest indicates an expected call of SendSAddRequest.
+func (mr *MockServiceRequesterMockRecorder) SendSAddRequest(conn, key, members interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSAddRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendSAddRequest), conn, key, members)
+}
+
+// SendSDiffRequest mocks base method.
+func (m *MockServiceRequester) SendSDiffRequest(conn net.Conn, keys []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendSDiffRequest", conn, keys)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendSDiffRequest indicates an expected call of SendSDiffRequest.
+func (mr *MockServiceRequesterMockRecorder) SendSDiffRequest(conn, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSDiffRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendSDiffRequest), conn, keys)
+}
+
+// SendSInterRequest mocks base method.
+func (m *MockServiceRequester) SendSInterRequest(conn net.Conn, keys []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendSInterRequest", conn, keys)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendSInterRequest indicates an expected call of SendSInterRequest.
+func (mr *MockServiceRequesterMockRecorder) SendSInterRequest(conn, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSInterRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendSInterRequest), conn, keys)
+}
+
+// SendSIsMemberRequest mocks base method.
+func (m *MockServiceRequester) SendSIsMemberRequest(conn net.Conn, key, member string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendSIsMemberRequest", conn, key, member)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendSIsMemberRequest indicates an expected call of SendSIsMemberRequest.
+func (mr *MockServiceRequesterMockRecorder) SendSIsMemberRequest(conn, key, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSIsMemberRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendSIsMemberRequest), conn, key, member)
+}
+
+// SendSMembersRequest mocks base method.
+func (m *MockServiceRequester) SendSMembersRequest(conn net.Conn, key string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendSMembersRequest", conn, key)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendSMembersRequest indicates an expected call of SendSMembersRequest.
+func (mr *MockServiceRequesterMockRecorder) SendSMembersRequest(conn, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSMembersRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendSMembersRequest), conn, key)
+}
+
+// SendSRemRequest mocks base method.
+func (m *MockServiceRequester) SendSRemRequest(conn net.Conn, key string, members []string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendSRemRequest", conn, key, members)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendSRemRequest indicates an expected call of SendSRemRequest.
+func (mr *MockServiceRequesterMockRecorder) SendSRemRequest(conn, key, members interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSRemRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendSRemRequest), conn, key, members)
+}
+
+// SendSUnionRequest mocks base method.
+func (m *MockServiceRequester) SendSUnionRequest(conn net.Conn, keys []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendSUnionRequest", conn, keys)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendSUnionRequest indicates an expected call of SendSUnionRequest.
+func (mr *MockServiceRequesterMockRecorder) SendSUnionRequest(conn, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSUnionRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendSUnionRequest), conn, keys)
+}
+
+// SendSaveRequest mocks base method.
+func (m *MockServiceRequester) SendSaveRequest(conn net.Conn) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendSaveRequest", conn)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendSaveRequest indicates an expected call of SendSaveRequest.
+func (mr *MockServiceRequesterMockRecorder) SendSaveRequest(conn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSaveRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendSaveRequest), conn)
+}
+
+// SendScanRequest mocks base method.
+func (m *MockServiceRequester) SendScanRequest(conn net.Conn, cursor, match string, count int) (string, []string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendScanRequest", conn, cursor, match, count)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].([]string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SendScanRequest indicates an expected call of SendScanRequest.
+func (mr *MockServiceRequesterMockRecorder) SendScanRequest(conn, cursor, match, count interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendScanRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendScanRequest), conn, cursor, match, count)
+}
+
+// SendSetArgsRequest mocks base method.
+func (m *MockServiceRequester) SendSetArgsRequest(conn net.Conn, key string, val tlv.TypeLengthValue, opts redis.SetOptions) (bool, tlv.TLVCompatible, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendSetArgsRequest", conn, key, val, opts)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(tlv.TLVCompatible)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SendSetArgsRequest indicates an expected call of SendSetArgsRequest.
+func (mr *MockServiceRequesterMockRecorder) SendSetArgsRequest(conn, key, val, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSetArgsRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendSetArgsRequest), conn, key, val, opts)
+}
+
+// SendSetExRequest mocks base method.
+func (m *MockServiceRequester) SendSetExRequest(conn net.Conn, key string, val tlv.TypeLengthValue, ttl time.Duration) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendSetExRequest", conn, key, val, ttl)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendSetExRequest indicates an expected call of SendSetExRequest.
+func (mr *MockServiceRequesterMockRecorder) SendSetExRequest(conn, key, val, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSetExRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendSetExRequest), conn, key, val, ttl)
+}
+
+// SendSetRequest mocks base method.
+func (m *MockServiceRequester) SendSetRequest(conn net.Conn, key string, val tlv.TypeLengthValue) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendSetRequest", conn, key, val)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendSetRequest indicates an expected call of SendSetRequest.
+func (mr *MockServiceRequesterMockRecorder) SendSetRequest(conn, key, val interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSetRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendSetRequest), conn, key, val)
+}
+
+// SendSubRequest mocks base method.
+func (m *MockServiceRequester) SendSubRequest(conn net.Conn, topics ...string) (*pubsub.Subscriber, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{conn}
+	for _, a := range topics {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SendSubRequest", varargs...)
+	ret0, _ := ret[0].(*pubsub.Subscriber)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendSubRequest indicates an expected call of SendSubRequest.
+func (mr *MockServiceRequesterMockRecorder) SendSubRequest(conn interface{}, topics ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{conn}, topics...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSubRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendSubRequest), varargs...)
+}
+
+// SendTTLRequest mocks base method.
+func (m *MockServiceRequester) SendTTLRequest(conn net.Conn, key string) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendTTLRequest", conn, key)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendTTLRequest indicates an expected call of SendTTLRequest.
+func (mr *MockServiceRequesterMockRecorder) SendTTLRequest(conn, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendTTLRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendTTLRequest), conn, key)
+}
+
+// SendUnwatchRequest mocks base method.
+func (m *MockServiceRequester) SendUnwatchRequest(conn net.Conn) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendUnwatchRequest", conn)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendUnwatchRequest indicates an expected call of SendUnwatchRequest.
+func (mr *MockServiceRequesterMockRecorder) SendUnwatchRequest(conn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendUnwatchRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendUnwatchRequest), conn)
+}
+
+// SendWatchRequest mocks base method.
+func (m *MockServiceRequester) SendWatchRequest(conn net.Conn, keys []string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendWatchRequest", conn, keys)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendWatchRequest indicates an expected call of SendWatchRequest.
+func (mr *MockServiceRequesterMockRecorder) SendWatchRequest(conn, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendWatchRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendWatchRequest), conn, keys)
+}
+
+// SendXAckRequest mocks base method.
+func (m *MockServiceRequester) SendXAckRequest(conn net.Conn, key, group string, ids []string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendXAckRequest", conn, key, group, ids)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendXAckRequest indicates an expected call of SendXAckRequest.
+func (mr *MockServiceRequesterMockRecorder) SendXAckRequest(conn, key, group, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendXAckRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendXAckRequest), conn, key, group, ids)
+}
+
+// SendXAddRequest mocks base method.
+func (m *MockServiceRequester) SendXAddRequest(conn net.Conn, key, id string, fields tlv.Map, trim model.StreamTrim) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendXAddRequest", conn, key, id, fields, trim)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendXAddRequest indicates an expected call of SendXAddRequest.
+func (mr *MockServiceRequesterMockRecorder) SendXAddRequest(conn, key, id, fields, trim interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendXAddRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendXAddRequest), conn, key, id, fields, trim)
+}
+
+// SendXClaimRequest mocks base method.
+func (m *MockServiceRequester) SendXClaimRequest(conn net.Conn, key, group, consumer string, minIdle time.Duration, count int64, ids []string) ([]redis.StreamEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendXClaimRequest", conn, key, group, consumer, minIdle, count, ids)
+	ret0, _ := ret[0].([]redis.StreamEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendXClaimRequest indicates an expected call of SendXClaimRequest.
+func (mr *MockServiceRequesterMockRecorder) SendXClaimRequest(conn, key, group, consumer, minIdle, count, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendXClaimRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendXClaimRequest), conn, key, group, consumer, minIdle, count, ids)
+}
+
+// SendXGroupCreateRequest mocks base method.
+func (m *MockServiceRequester) SendXGroupCreateRequest(conn net.Conn, key, group, id string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendXGroupCreateRequest", conn, key, group, id)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendXGroupCreateRequest indicates an expected call of SendXGroupCreateRequest.
+func (mr *MockServiceRequesterMockRecorder) SendXGroupCreateRequest(conn, key, group, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendXGroupCreateRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendXGroupCreateRequest), conn, key, group, id)
+}
+
+// SendXGroupDestroyRequest mocks base method.
+func (m *MockServiceRequester) SendXGroupDestroyRequest(conn net.Conn, key, group string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendXGroupDestroyRequest", conn, key, group)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendXGroupDestroyRequest indicates an expected call of SendXGroupDestroyRequest.
+func (mr *MockServiceRequesterMockRecorder) SendXGroupDestroyRequest(conn, key, group interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendXGroupDestroyRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendXGroupDestroyRequest), conn, key, group)
+}
+
+// SendXLenRequest mocks base method.
+func (m *MockServiceRequester) SendXLenRequest(conn net.Conn, key string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendXLenRequest", conn, key)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendXLenRequest indicates an expected call of SendXLenRequest.
+func (mr *MockServiceRequesterMockRecorder) SendXLenRequest(conn, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendXLenRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendXLenRequest), conn, key)
+}
+
+// SendXPendingRequest mocks base method.
+func (m *MockServiceRequester) SendXPendingRequest(conn net.Conn, key, group, consumer string) ([]redis.StreamPending, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendXPendingRequest", conn, key, group, consumer)
+	ret0, _ := ret[0].([]redis.StreamPending)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendXPendingRequest indicates an expected call of SendXPendingRequest.
+func (mr *MockServiceRequesterMockRecorder) SendXPendingRequest(conn, key, group, consumer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendXPendingRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendXPendingRequest), conn, key, group, consumer)
+}
+
+// SendXRangeRequest mocks base method.
+func (m *MockServiceRequester) SendXRangeRequest(conn net.Conn, key, start, end string, count int64) ([]redis.StreamEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendXRangeRequest", conn, key, start, end, count)
+	ret0, _ := ret[0].([]redis.StreamEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendXRangeRequest indicates an expected call of SendXRangeRequest.
+func (mr *MockServiceRequesterMockRecorder) SendXRangeRequest(conn, key, start, end, count interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendXRangeRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendXRangeRequest), conn, key, start, end, count)
+}
+
+// SendXReadGroupRequest mocks base method.
+func (m *MockServiceRequester) SendXReadGroupRequest(conn net.Conn, group, consumer string, keys, ids []string, count int64, block bool, timeout time.Duration) ([]redis.StreamRead, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendXReadGroupRequest", conn, group, consumer, keys, ids, count, block, timeout)
+	ret0, _ := ret[0].([]redis.StreamRead)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendXReadGroupRequest indicates an expected call of SendXReadGroupRequest.
+func (mr *MockServiceRequesterMockRecorder) SendXReadGroupRequest(conn, group, consumer, keys, ids, count, block, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendXReadGroupRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendXReadGroupRequest), conn, group, consumer, keys, ids, count, block, timeout)
+}
+
+// SendXReadRequest mocks base method.
+func (m *MockServiceRequester) SendXReadRequest(conn net.Conn, keys, ids []string, count int64, block bool, timeout time.Duration) ([]redis.StreamRead, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendXReadRequest", conn, keys, ids, count, block, timeout)
+	ret0, _ := ret[0].([]redis.StreamRead)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendXReadRequest indicates an expected call of SendXReadRequest.
+func (mr *MockServiceRequesterMockRecorder) SendXReadRequest(conn, keys, ids, count, block, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendXReadRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendXReadRequest), conn, keys, ids, count, block, timeout)
+}
+
+// SendXTrimRequest mocks base method.
+func (m *MockServiceRequester) SendXTrimRequest(conn net.Conn, key string, trim model.StreamTrim) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendXTrimRequest", conn, key, trim)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendXTrimRequest indicates an expected call of SendXTrimRequest.
+func (mr *MockServiceRequesterMockRecorder) SendXTrimRequest(conn, key, trim interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendXTrimRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendXTrimRequest), conn, key, trim)
+}
+
+// SendZAddRequest mocks base method.
+func (m *MockServiceRequester) SendZAddRequest(conn net.Conn, key string, members map[string]float64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendZAddRequest", conn, key, members)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendZAddRequest indicates an expected call of SendZAddRequest.
+func (mr *MockServiceRequesterMockRecorder) SendZAddRequest(conn, key, members interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendZAddRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendZAddRequest), conn, key, members)
+}
+
+// SendZIncrByRequest mocks base method.
+func (m *MockServiceRequester) SendZIncrByRequest(conn net.Conn, key, member string, delta float64) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendZIncrByRequest", conn, key, member, delta)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendZIncrByRequest indicates an expected call of SendZIncrByRequest.
+func (mr *MockServiceRequesterMockRecorder) SendZIncrByRequest(conn, key, member, delta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendZIncrByRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendZIncrByRequest), conn, key, member, delta)
+}
+
+// SendZRangeByScoreRequest mocks base method.
+func (m *MockServiceRequester) SendZRangeByScoreRequest(conn net.Conn, key string, min, max float64) ([]model.ZMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendZRangeByScoreRequest", conn, key, min, max)
+	ret0, _ := ret[0].([]model.ZMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendZRangeByScoreRequest indicates an expected call of SendZRangeByScoreRequest.
+func (mr *MockServiceRequesterMockRecorder) SendZRangeByScoreRequest(conn, key, min, max interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendZRangeByScoreRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendZRangeByScoreRequest), conn, key, min, max)
+}
+
+// SendZRangeRequest mocks base method.
+func (m *MockServiceRequester) SendZRangeRequest(conn net.Conn, key string, start, stop int64) ([]model.ZMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendZRangeRequest", conn, key, start, stop)
+	ret0, _ := ret[0].([]model.ZMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendZRangeRequest indicates an expected call of SendZRangeRequest.
+func (mr *MockServiceRequesterMockRecorder) SendZRangeRequest(conn, key, start, stop interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendZRangeRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendZRangeRequest), conn, key, start, stop)
+}
+
+// SendZRankRequest mocks base method.
+func (m *MockServiceRequester) SendZRankRequest(conn net.Conn, key, member string) (int64, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendZRankRequest", conn, key, member)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SendZRankRequest indicates an expected call of SendZRankRequest.
+func (mr *MockServiceRequesterMockRecorder) SendZRankRequest(conn, key, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendZRankRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendZRankRequest), conn, key, member)
+}
+
+// SendZRemRequest mocks base method.
+func (m *MockServiceRequester) SendZRemRequest(conn net.Conn, key string, members []string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendZRemRequest", conn, key, members)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendZRemRequest indicates an expected call of SendZRemRequest.
+func (mr *MockServiceRequesterMockRecorder) SendZRemRequest(conn, key, members interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendZRemRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendZRemRequest), conn, key, members)
+}
+
+// SendZScoreRequest mocks base method.
+func (m *MockServiceRequester) SendZScoreRequest(conn net.Conn, key, member string) (float64, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendZScoreRequest", conn, key, member)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SendZScoreRequest indicates an expected call of SendZScoreRequest.
+func (mr *MockServiceRequesterMockRecorder) SendZScoreRequest(conn, key, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendZScoreRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendZScoreRequest), conn, key, member)
+}
+
+// MockIService is a mock of IService interface.
+type MockIService struct {
+	ctrl     *gomock.Controller
+	recorder *MockIServiceMockRecorder
+}
+
+// MockIServiceMockRecorder is the mock recorder for MockIService.
+type MockIServiceMockRecorder struct {
+	mock *MockIService
+}
+
+// NewMockIService creates a new mock instance.
+func NewMockIService(ctrl *gomock.Controller) *MockIService {
+	mock := &MockIService{ctrl: ctrl}
+	mock.recorder = &MockIServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIService) EXPECT() *MockIServiceMockRecorder {
+	return m.recorder
+}
+
+// HandleDisconnected mocks base method.
+func (m *MockIService) HandleDisconnected(ctx payload.IRequestContext) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HandleDisconnected", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HandleDisconnected indicates an expected call of HandleDisconnected.
+func (mr *MockIServiceMockRecorder) HandleDisconnected(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleDisconnected", reflect.TypeOf((*MockIService)(nil).HandleDisconnected), ctx)
+}
+
+// HandleRequest mocks base method.
+func (m *MockIService) HandleRequest(ctx payload.IRequestContext) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HandleRequest", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HandleRequest indicates an expected call of HandleRequest.
+func (mr *MockIServiceMockRecorder) HandleRequest(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleRequest", reflect.TypeOf((*MockIService)(nil).HandleRequest), ctx)
+}
+
+// SendBGRewriteAOFRequest mocks base method.
+func (m *MockIService) SendBGRewriteAOFRequest(conn net.Conn) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendBGRewriteAOFRequest", conn)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendBGRewriteAOFRequest indicates an expected call of SendBGRewriteAOFRequest.
+func (mr *MockIServiceMockRecorder) SendBGRewriteAOFRequest(conn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendBGRewriteAOFRequest", reflect.TypeOf((*MockIService)(nil).SendBGRewriteAOFRequest), conn)
+}
+
+// SendBGSaveRequest mocks base method.
+func (m *MockIService) SendBGSaveRequest(conn net.Conn) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendBGSaveRequest", conn)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendBGSaveRequest indicates an expected call of SendBGSaveRequest.
+func (mr *MockIServiceMockRecorder) SendBGSaveRequest(conn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendBGSaveRequest", reflect.TypeOf((*MockIService)(nil).SendBGSaveRequest), conn)
+}
+
+// SendBLPopRequest mocks base method.
+func (m *MockIService) SendBLPopRequest(conn net.Conn, keys []string, timeout time.Duration) (string, tlv.TLVCompatible, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendBLPopRequest", conn, keys, timeout)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(tlv.TLVCompatible)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SendBLPopRequest indicates an expected call of SendBLPopRequest.
+func (mr *MockIServiceMockRecorder) SendBLPopRequest(conn, keys, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendBLPopRequest", reflect.TypeOf((*MockIService)(nil).SendBLPopRequest), conn, keys, timeout)
+}
+
+// SendCASRequest mocks base method.
+func (m *MockIService) SendCASRequest(conn net.Conn, key string, expected, val tlv.TypeLengthValue, ttl time.Duration) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendCASRequest", conn, key, expected, val, ttl)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendCASRequest indicates an expected call of SendCASRequest.
+func (mr *MockIServiceMockRecorder) SendCASRequest(conn, key, expected, val, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendCASRequest", reflect.TypeOf((*MockIService)(nil).SendCASRequest), conn, key, expected, val, ttl)
+}
+
+// SendCASVersionRequest mocks base method.
+func (m *MockIService) SendCASVersionRequest(conn net.Conn, key string, version uint64, val tlv.TypeLengthValue, ttl time.Duration) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendCASVersionRequest", conn, key, version, val, ttl)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendCASVersionRequest indicates an expected call of SendCASVersionRequest.
+func (mr *MockIServiceMockRecorder) SendCASVersionRequest(conn, key, version, val, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendCASVersionRequest", reflect.TypeOf((*MockIService)(nil).SendCASVersionRequest), conn, key, version, val, ttl)
+}
+
+// SendClientGetNameRequest mocks base method.
+func (m *MockIService) SendClientGetNameRequest(conn net.Conn) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendClientGetNameRequest", conn)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendClientGetNameRequest indicates an expected call of SendClientGetNameRequest.
+func (mr *MockIServiceMockRecorder) SendClientGetNameRequest(conn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendClientGetNameRequest", reflect.TypeOf((*MockIService)(nil).SendClientGetNameRequest), conn)
+}
+
+// SendClientKillRequest mocks base method.
+func (m *MockIService) SendClientKillRequest(conn net.Conn, filter, value string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendClientKillRequest", conn, filter, value)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendClientKillRequest indicates an expected call of SendClientKillRequest.
+func (mr *MockIServiceMockRecorder) SendClientKillRequest(conn, filter, value interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendClientKillRequest", reflect.TypeOf((*MockIService)(nil).SendClientKillRequest), conn, filter, value)
+}
+
+// SendClientListRequest mocks base method.
+func (m *MockIService) SendClientListRequest(conn net.Conn) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendClientListRequest", conn)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendClientListRequest indicates an expected call of SendClientListRequest.
+func (mr *MockIServiceMockRecorder) SendClientListRequest(conn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendClientListRequest", reflect.TypeOf((*MockIService)(nil).SendClientListRequest), conn)
+}
+
+// SendClientSetNameRequest mocks base method.
+func (m *MockIService) SendClientSetNameRequest(conn net.Conn, name string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendClientSetNameRequest", conn, name)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendClientSetNameRequest indicates an expected call of SendClientSetNameRequest.
+func (mr *MockIServiceMockRecorder) SendClientSetNameRequest(conn, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendClientSetNameRequest", reflect.TypeOf((*MockIService)(nil).SendClientSetNameRequest), conn, name)
+}
+
+// SendDecrByRequest mocks base method.
+func (m *MockIService) SendDecrByRequest(conn net.Conn, key string, delta int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDecrByRequest", conn, key, delta)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendDecrByRequest indicates an expected call of SendDecrByRequest.
+func (mr *MockIServiceMockRecorder) SendDecrByRequest(conn, key, delta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDecrByRequest", reflect.TypeOf((*MockIService)(nil).SendDecrByRequest), conn, key, delta)
+}
+
+// SendDecrRequest mocks base method.
+func (m *MockIService) SendDecrRequest(conn net.Conn, key string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDecrRequest", conn, key)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendDecrRequest indicates an expected call of SendDecrRequest.
+func (mr *MockIServiceMockRecorder) SendDecrRequest(conn, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDecrRequest", reflect.TypeOf((*MockIService)(nil).SendDecrRequest), conn, key)
+}
+
+// SendDelRequest mocks base method.
+func (m *MockIService) SendDelRequest(conn net.Conn, keys []string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDelRequest", conn, keys)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendDelRequest indicates an expected call of SendDelRequest.
+func (mr *MockIServiceMockRecorder) SendDelRequest(conn, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDelRequest", reflect.TypeOf((*MockIService)(nil).SendDelRequest), conn, keys)
+}
+
+// SendDiscardRequest mocks base method.
+func (m *MockIService) SendDiscardRequest(conn net.Conn) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendDiscardRequest", conn)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendDiscardRequest indicates an expected call of SendDiscardRequest.
+func (mr *MockIServiceMockRecorder) SendDiscardRequest(conn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendDiscardRequest", reflect.TypeOf((*MockIService)(nil).SendDiscardRequest), conn)
+}
+
+// SendExecRequest mocks base method.
+func (m *MockIService) SendExecRequest(conn net.Conn) ([]tlv.TypeLengthValue, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendExecRequest", conn)
+	ret0, _ := ret[0].([]tlv.TypeLengthValue)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendExecRequest indicates an expected call of SendExecRequest.
+func (mr *MockIServiceMockRecorder) SendExecRequest(conn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendExecRequest", reflect.TypeOf((*MockIService)(nil).SendExecRequest), conn)
+}
+
+// SendExistsRequest mocks base method.
+func (m *MockIService) SendExistsRequest(conn net.Conn, keys []string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendExistsRequest", conn, keys)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendExistsRequest indicates an expected call of SendExistsRequest.
+func (mr *MockIServiceMockRecorder) SendExistsRequest(conn, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendExistsRequest", reflect.TypeOf((*MockIService)(nil).SendExistsRequest), conn, keys)
+}
+
+// SendExpireRequest mocks base method.
+func (m *MockIService) SendExpireRequest(conn net.Conn, key string, ttl time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendExpireRequest", conn, key, ttl)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendExpireRequest indicates an expected call of SendExpireRequest.
+func (mr *MockIServiceMockRecorder) SendExpireRequest(conn, key, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendExpireRequest", reflect.TypeOf((*MockIService)(nil).SendExpireRequest), conn, key, ttl)
+}
+
+// SendGetRequest mocks base method.
+func (m *MockIService) SendGetRequest(conn net.Conn, key string) (tlv.TLVCompatible, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendGetRequest", conn, key)
+	ret0, _ := ret[0].(tlv.TLVCompatible)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendGetRequest indicates an expected call of SendGetRequest.
+func (mr *MockIServiceMockRecorder) SendGetRequest(conn, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendGetRequest", reflect.TypeOf((*MockIService)(nil).SendGetRequest), conn, key)
+}
+
+// SendGetVersionRequest mocks base method.
+func (m *MockIService) SendGetVersionRequest(conn net.Conn, key string) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendGetVersionRequest", conn, key)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendGetVersionRequest indicates an expected call of SendGetVersionRequest.
+func (mr *MockIServiceMockRecorder) SendGetVersionRequest(conn, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendGetVersionRequest", reflect.TypeOf((*MockIService)(nil).SendGetVersionRequest), conn, key)
+}
+
+// SendHDelRequest mocks base method.
+func (m *MockIService) SendHDelRequest(conn net.Conn, key string, fields []string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHDelRequest", conn, key, fields)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendHDelRequest indicates an expected call of SendHDelRequest.
+func (mr *MockIServiceMockRecorder) SendHDelRequest(conn, key, fields interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHDelRequest", reflect.TypeOf((*MockIService)(nil).SendHDelRequest), conn, key, fields)
+}
+
+// SendHGetAllRequest mocks base method.
+func (m *MockIService) SendHGetAllRequest(conn net.Conn, key string) (map[string]tlv.TLVCompatible, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHGetAllRequest", conn, key)
+	ret0, _ := ret[0].(map[string]tlv.TLVCompatible)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendHGetAllRequest indicates an expected call of SendHGetAllRequest.
+func (mr *MockIServiceMockRecorder) SendHGetAllRequest(conn, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHGetAllRequest", reflect.TypeOf((*MockIService)(nil).SendHGetAllRequest), conn, key)
+}
+
+// SendHGetRequest mocks base method.
+func (m *MockIService) SendHGetRequest(conn net.Conn, key, field string) (tlv.TLVCompatible, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHGetRequest", conn, key, field)
+	ret0, _ := ret[0].(tlv.TLVCompatible)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendHGetRequest indicates an expected call of SendHGetRequest.
+func (mr *MockIServiceMockRecorder) SendHGetRequest(conn, key, field interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHGetRequest", reflect.TypeOf((*MockIService)(nil).SendHGetRequest), conn, key, field)
+}
+
+// SendHIncrByRequest mocks base method.
+func (m *MockIService) SendHIncrByRequest(conn net.Conn, key, field string, delta int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHIncrByRequest", conn, key, field, delta)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendHIncrByRequest indicates an expected call of SendHIncrByRequest.
+func (mr *MockIServiceMockRecorder) SendHIncrByRequest(conn, key, field, delta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHIncrByRequest", reflect.TypeOf((*MockIService)(nil).SendHIncrByRequest), conn, key, field, delta)
+}
+
+// SendHLenRequest mocks base method.
+func (m *MockIService) SendHLenRequest(conn net.Conn, key string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHLenRequest", conn, key)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendHLenRequest indicates an expected call of SendHLenRequest.
+func (mr *MockIServiceMockRecorder) SendHLenRequest(conn, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHLenRequest", reflect.TypeOf((*MockIService)(nil).SendHLenRequest), conn, key)
+}
+
+// SendHSetRequest mocks base method.
+func (m *MockIService) SendHSetRequest(conn net.Conn, key string, fields tlv.Map) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendHSetRequest", conn, key, fields)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendHSetRequest indicates an expected call of SendHSetRequest.
+func (mr *MockIServiceMockRecorder) SendHSetRequest(conn, key, fields interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendHSetRequest", reflect.TypeOf((*MockIService)(nil).SendHSetRequest), conn, key, fields)
+}
+
+// SendIncrByFloatRequest mocks base method.
+func (m *MockIService) SendIncrByFloatRequest(conn net.Conn, key string, delta float64) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendIncrByFloatRequest", conn, key, delta)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendIncrByFloatRequest indicates an expected call of SendIncrByFloatRequest.
+func (mr *MockIServiceMockRecorder) SendIncrByFloatRequest(conn, key, delta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendIncrByFloatRequest", reflect.TypeOf((*MockIService)(nil).SendIncrByFloatRequest), conn, key, delta)
+}
+
+// SendIncrByRequest mocks base method.
+func (m *MockIService) SendIncrByRequest(conn net.Conn, key string, delta int64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendIncrByRequest", conn, key, delta)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendIncrByRequest indicates an expected call of SendIncrByRequest.
+func (mr *MockIServiceMockRecorder) SendIncrByRequest(conn, key, delta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendIncrByRequest", reflect.TypeOf((*MockIService)(nil).SendIncrByRequest), conn, key, delta)
+}
+
+// SendIncrRequest mocks base method.
+func (m *MockIService) SendIncrRequest(conn net.Conn, key string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendIncrRequest", conn, key)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendIncrRequest indicates an expected call of SendIncrRequest.
+func (mr *MockIServiceMockRecorder) SendIncrRequest(conn, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendIncrRequest", reflect.TypeOf((*MockIService)(nil).SendIncrRequest), conn, key)
+}
+
+// SendLLenRequest mocks base method.
+func (m *MockIService) SendLLenRequest(conn net.Conn, key string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendLLenRequest", conn, key)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendLLenRequest indicates an expected call of SendLLenRequest.
+func (mr *MockIServiceMockRecorder) SendLLenRequest(conn, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendLLenRequest", reflect.TypeOf((*MockIService)(nil).SendLLenRequest), conn, key)
+}
+
+// SendLPopRequest mocks base method.
+func (m *MockIService) SendLPopRequest(conn net.Conn, key string) (tlv.TLVCompatible, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendLPopRequest", conn, key)
+	ret0, _ := ret[0].(tlv.TLVCompatible)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendLPopRequest indicates an expected call of SendLPopRequest.
+func (mr *MockIServiceMockRecorder) SendLPopRequest(conn, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendLPopRequest", reflect.TypeOf((*MockIService)(nil).SendLPopRequest), conn, key)
+}
+
+// SendLPushRequest mocks base method.
+func (m *MockIService) SendLPushRequest(conn net.Conn, key string, vals []tlv.TypeLengthValue) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendLPushRequest", conn, key, vals)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendLPushRequest indicates an expected call of SendLPushRequest.
+func (mr *MockIServiceMockRecorder) SendLPushRequest(conn, key, vals interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendLPushRequest", reflect.TypeOf((*MockIService)(nil).SendLPushRequest), conn, key, vals)
+}
+
+// SendLRangeRequest mocks base method.
+func (m *MockIService) SendLRangeRequest(conn net.Conn, key string, start, stop int64) ([]tlv.TLVCompatible, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendLRangeRequest", conn, key, start, stop)
+	ret0, _ := ret[0].([]tlv.TLVCompatible)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendLRangeRequest indicates an expected call of SendLRangeRequest.
+func (mr *MockIServiceMockRecorder) SendLRangeRequest(conn, key, start, stop interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendLRangeRequest", reflect.TypeOf((*MockIService)(nil).SendLRangeRequest), conn, key, start, stop)
+}
+
+// SendMGetRequest mocks base method.
+func (m *MockIService) SendMGetRequest(conn net.Conn, keys []string) ([]tlv.TLVCompatible, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMGetRequest", conn, keys)
+	ret0, _ := ret[0].([]tlv.TLVCompatible)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendMGetRequest indicates an expected call of SendMGetRequest.
+func (mr *MockIServiceMockRecorder) SendMGetRequest(conn, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMGetRequest", reflect.TypeOf((*MockIService)(nil).SendMGetRequest), conn, keys)
+}
+
+// SendMSetNXRequest mocks base method.
+func (m *MockIService) SendMSetNXRequest(conn net.Conn, pairs tlv.Map) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMSetNXRequest", conn, pairs)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendMSetNXRequest indicates an expected call of SendMSetNXRequest.
+func (mr *MockIServiceMockRecorder) SendMSetNXRequest(conn, pairs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMSetNXRequest", reflect.TypeOf((*MockIService)(nil).SendMSetNXRequest), conn, pairs)
+}
+
+// SendMSetRequest mocks base method.
+func (m *MockIService) SendMSetRequest(conn net.Conn, pairs tlv.Map) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMSetRequest", conn, pairs)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendMSetRequest indicates an expected call of SendMSetRequest.
+func (mr *MockIServiceMockRecorder) SendMSetRequest(conn, pairs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMSetRequest", reflect.TypeOf((*MockIService)(nil).SendMSetRequest), conn, pairs)
+}
+
+// SendMultiRequest mocks base method.
+func (m *MockIService) SendMultiRequest(conn net.Conn) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendMultiRequest", conn)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendMultiRequest indicates an expected call of SendMultiRequest.
+func (mr *MockIServiceMockRecorder) SendMultiRequest(conn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendMultiRequest", reflect.TypeOf((*MockIService)(nil).SendMultiRequest), conn)
+}
+
+// SendPSubRequest mocks base method.
+func (m *MockIService) SendPSubRequest(conn net.Conn, patterns ...string) (*pubsub.Subscriber, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{conn}
+	for _, a := range patterns {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SendPSubRequest", varargs...)
+	ret0, _ := ret[0].(*pubsub.Subscriber)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendPSubRequest indicates an expected call of SendPSubRequest.
+func (mr *MockIServiceMockRecorder) SendPSubRequest(conn interface{}, patterns ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{conn}, patterns...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPSubRequest", reflect.TypeOf((*MockIService)(nil).SendPSubRequest), varargs...)
+}
+
+// SendPersistRequest mocks base method.
+func (m *MockIService) SendPersistRequest(conn net.Conn, key string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendPersistRequest", conn, key)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendPersistRequest indicates an expected call of SendPersistRequest.
+func (mr *MockIServiceMockRecorder) SendPersistRequest(conn, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPersistRequest", reflect.TypeOf((*MockIService)(nil).SendPersistRequest), conn, key)
+}
+
+// SendPingRequest mocks base method.
+func (m *MockIService) SendPingRequest(conn net.Conn, msg *string) (*tlv.String, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendPingRequest", conn, msg)
+	ret0, _ := ret[0].(*tlv.String)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendPingRequest indicates an expected call of SendPingRequest.
+func (mr *MockIServiceMockRecorder) SendPingRequest(conn, msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPingRequest", reflect.TypeOf((*MockIService)(nil).SendPingRequest), conn, msg)
+}
+
+// SendPubRequest mocks base method.
+func (m *MockIService) SendPubRequest(conn net.Conn, topic string, val tlv.TypeLengthValue) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendPubRequest", conn, topic, val)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendPubRequest indicates an expected call of SendPubRequest.
+func (mr *MockIServiceMockRecorder) SendPubRequest(conn, topic, val interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPubRequest", reflect.TypeOf((*MockIService)(nil).SendPubRequest), conn, topic, val)
+}
+
+// SendRPopRequest mocks base method.
+func (m *MockIService) SendRPopRequest(conn net.Conn, key string) (tlv.TLVCompatible, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendRPopRequest", conn, key)
+	ret0, _ := ret[0].(tlv.TLVCompatible)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendRPopRequest indicates an expected call of SendRPopRequest.
+func (mr *MockIServiceMockRecorder) SendRPopRequest(conn, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendRPopRequest", reflect.TypeOf((*MockIService)(nil).SendRPopRequest), conn, key)
+}
+
+// SendRPushRequest mocks base method.
+func (m *MockIService) SendRPushRequest(conn net.Conn, key string, vals []tlv.TypeLengthValue) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendRPushRequest", conn, key, vals)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SendGetRequest indicates an expected call of SendGetRequest.
-func (mr *MockServiceRequesterMockRecorder) SendGetRequest(conn, key interface{}) *gomock.Call {
+// SendRPushRequest indicates an expected call of SendRPushRequest.
+func (mr *MockIServiceMockRecorder) SendRPushRequest(conn, key, vals interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendGetRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendGetRequest), conn, key)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendRPushRequest", reflect.TypeOf((*MockIService)(nil).SendRPushRequest), conn, key, vals)
 }
 
-// SendPingRequest mocks base method.
-func (m *MockServiceRequester) SendPingRequest(conn net.Conn, msg *string) (*tlv.String, error) {
+// SendSAddRequest mocks base method.
+func (m *MockIService) SendSAddRequest(conn net.Conn, key string, members []string) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendPingRequest", conn, msg)
-	ret0, _ := ret[0].(*tlv.String)
+	ret := m.ctrl.Call(m, "SendSAddRequest", conn, key, members)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SendPingRequest indicates an expected call of SendPingRequest.
-func (mr *MockServiceRequesterMockRecorder) SendPingRequest(conn, msg interface{}) *gomock.Call {
+// SendSAddRequest indicates an expected call of SendSAddRequest.
+func (mr *MockIServiceMockRecorder) SendSAddRequest(conn, key, members interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPingRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendPingRequest), conn, msg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSAddRequest", reflect.TypeOf((*MockIService)(nil).SendSAddRequest), conn, key, members)
 }
 
-// SendPubRequest mocks base method.
-func (m *MockServiceRequester) SendPubRequest(conn net.Conn, topic string, val tlv.TypeLengthValue) (string, error) {
+// SendSDiffRequest mocks base method.
+func (m *MockIService) SendSDiffRequest(conn net.Conn, keys []string) ([]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendPubRequest", conn, topic, val)
+	ret := m.ctrl.Call(m, "SendSDiffRequest", conn, keys)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendSDiffRequest indicates an expected call of SendSDiffRequest.
+func (mr *MockIServiceMockRecorder) SendSDiffRequest(conn, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSDiffRequest", reflect.TypeOf((*MockIService)(nil).SendSDiffRequest), conn, keys)
+}
+
+// SendSInterRequest mocks base method.
+func (m *MockIService) SendSInterRequest(conn net.Conn, keys []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendSInterRequest", conn, keys)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendSInterRequest indicates an expected call of SendSInterRequest.
+func (mr *MockIServiceMockRecorder) SendSInterRequest(conn, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSInterRequest", reflect.TypeOf((*MockIService)(nil).SendSInterRequest), conn, keys)
+}
+
+// SendSIsMemberRequest mocks base method.
+func (m *MockIService) SendSIsMemberRequest(conn net.Conn, key, member string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendSIsMemberRequest", conn, key, member)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendSIsMemberRequest indicates an expected call of SendSIsMemberRequest.
+func (mr *MockIServiceMockRecorder) SendSIsMemberRequest(conn, key, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSIsMemberRequest", reflect.TypeOf((*MockIService)(nil).SendSIsMemberRequest), conn, key, member)
+}
+
+// SendSMembersRequest mocks base method.
+func (m *MockIService) SendSMembersRequest(conn net.Conn, key string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendSMembersRequest", conn, key)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendSMembersRequest indicates an expected call of SendSMembersRequest.
+func (mr *MockIServiceMockRecorder) SendSMembersRequest(conn, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSMembersRequest", reflect.TypeOf((*MockIService)(nil).SendSMembersRequest), conn, key)
+}
+
+// SendSRemRequest mocks base method.
+func (m *MockIService) SendSRemRequest(conn net.Conn, key string, members []string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendSRemRequest", conn, key, members)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendSRemRequest indicates an expected call of SendSRemRequest.
+func (mr *MockIServiceMockRecorder) SendSRemRequest(conn, key, members interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSRemRequest", reflect.TypeOf((*MockIService)(nil).SendSRemRequest), conn, key, members)
+}
+
+// SendSUnionRequest mocks base method.
+func (m *MockIService) SendSUnionRequest(conn net.Conn, keys []string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendSUnionRequest", conn, keys)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendSUnionRequest indicates an expected call of SendSUnionRequest.
+func (mr *MockIServiceMockRecorder) SendSUnionRequest(conn, keys interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSUnionRequest", reflect.TypeOf((*MockIService)(nil).SendSUnionRequest), conn, keys)
+}
+
+// SendSaveRequest mocks base method.
+func (m *MockIService) SendSaveRequest(conn net.Conn) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendSaveRequest", conn)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SendPubRequest indicates an expected call of SendPubRequest.
-func (mr *MockServiceRequesterMockRecorder) SendPubRequest(conn, topic, val interface{}) *gomock.Call {
+// SendSaveRequest indicates an expected call of SendSaveRequest.
+func (mr *MockIServiceMockRecorder) SendSaveRequest(conn interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPubRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendPubRequest), conn, topic, val)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSaveRequest", reflect.TypeOf((*MockIService)(nil).SendSaveRequest), conn)
+}
+
+// SendScanRequest mocks base method.
+func (m *MockIService) SendScanRequest(conn net.Conn, cursor, match string, count int) (string, []string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendScanRequest", conn, cursor, match, count)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].([]string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SendScanRequest indicates an expected call of SendScanRequest.
+func (mr *MockIServiceMockRecorder) SendScanRequest(conn, cursor, match, count interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendScanRequest", reflect.TypeOf((*MockIService)(nil).SendScanRequest), conn, cursor, match, count)
+}
+
+// SendSetArgsRequest mocks base method.
+func (m *MockIService) SendSetArgsRequest(conn net.Conn, key string, val tlv.TypeLengthValue, opts redis.SetOptions) (bool, tlv.TLVCompatible, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendSetArgsRequest", conn, key, val, opts)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(tlv.TLVCompatible)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SendSetArgsRequest indicates an expected call of SendSetArgsRequest.
+func (mr *MockIServiceMockRecorder) SendSetArgsRequest(conn, key, val, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSetArgsRequest", reflect.TypeOf((*MockIService)(nil).SendSetArgsRequest), conn, key, val, opts)
+}
+
+// SendSetExRequest mocks base method.
+func (m *MockIService) SendSetExRequest(conn net.Conn, key string, val tlv.TypeLengthValue, ttl time.Duration) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendSetExRequest", conn, key, val, ttl)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendSetExRequest indicates an expected call of SendSetExRequest.
+func (mr *MockIServiceMockRecorder) SendSetExRequest(conn, key, val, ttl interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSetExRequest", reflect.TypeOf((*MockIService)(nil).SendSetExRequest), conn, key, val, ttl)
 }
 
 // SendSetRequest mocks base method.
-func (m *MockServiceRequester) SendSetRequest(conn net.Conn, key string, val tlv.TypeLengthValue) (string, error) {
+func (m *MockIService) SendSetRequest(conn net.Conn, key string, val tlv.TypeLengthValue) (string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SendSetRequest", conn, key, val)
 	ret0, _ := ret[0].(string)
@@ -143,148 +2080,344 @@ func (m *MockServiceRequester) SendSetRequest(conn net.Conn, key string, val tlv
 }
 
 // SendSetRequest indicates an expected call of SendSetRequest.
-func (mr *MockServiceRequesterMockRecorder) SendSetRequest(conn, key, val interface{}) *gomock.Call {
+func (mr *MockIServiceMockRecorder) SendSetRequest(conn, key, val interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSetRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendSetRequest), conn, key, val)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSetRequest", reflect.TypeOf((*MockIService)(nil).SendSetRequest), conn, key, val)
 }
 
 // SendSubRequest mocks base method.
-func (m *MockServiceRequester) SendSubRequest(conn net.Conn, topic string) (*pubsub.Subscriber, error) {
+func (m *MockIService) SendSubRequest(conn net.Conn, topics ...string) (*pubsub.Subscriber, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendSubRequest", conn, topic)
+	varargs := []interface{}{conn}
+	for _, a := range topics {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SendSubRequest", varargs...)
 	ret0, _ := ret[0].(*pubsub.Subscriber)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SendSubRequest indicates an expected call of SendSubRequest.
-func (mr *MockServiceRequesterMockRecorder) SendSubRequest(conn, topic interface{}) *gomock.Call {
+func (mr *MockIServiceMockRecorder) SendSubRequest(conn interface{}, topics ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSubRequest", reflect.TypeOf((*MockServiceRequester)(nil).SendSubRequest), conn, topic)
+	varargs := append([]interface{}{conn}, topics...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSubRequest", reflect.TypeOf((*MockIService)(nil).SendSubRequest), varargs...)
 }
 
-// MockIService is a mock of IService interface.
-type MockIService struct {
-	ctrl     *gomock.Controller
-	recorder *MockIServiceMockRecorder
+// SendTTLRequest mocks base method.
+func (m *MockIService) SendTTLRequest(conn net.Conn, key string) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendTTLRequest", conn, key)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// MockIServiceMockRecorder is the mock recorder for MockIService.
-type MockIServiceMockRecorder struct {
-	mock *MockIService
+// SendTTLRequest indicates an expected call of SendTTLRequest.
+func (mr *MockIServiceMockRecorder) SendTTLRequest(conn, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendTTLRequest", reflect.TypeOf((*MockIService)(nil).SendTTLRequest), conn, key)
 }
 
-// NewMockIService creates a new mock instance.
-func NewMockIService(ctrl *gomock.Controller) *MockIService {
-	mock := &MockIService{ctrl: ctrl}
-	mock.recorder = &MockIServiceMockRecorder{mock}
-	return mock
+// SendUnwatchRequest mocks base method.
+func (m *MockIService) SendUnwatchRequest(conn net.Conn) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendUnwatchRequest", conn)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockIService) EXPECT() *MockIServiceMockRecorder {
-	return m.recorder
+// SendUnwatchRequest indicates an expected call of SendUnwatchRequest.
+func (mr *MockIServiceMockRecorder) SendUnwatchRequest(conn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendUnwatchRequest", reflect.TypeOf((*MockIService)(nil).SendUnwatchRequest), conn)
 }
 
-// HandleDisconnected mocks base method.
-func (m *MockIService) HandleDisconnected(conn net.Conn) error {
+// SendWatchRequest mocks base method.
+func (m *MockIService) SendWatchRequest(conn net.Conn, keys []string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "HandleDisconnected", conn)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "SendWatchRequest", conn, keys)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// HandleDisconnected indicates an expected call of HandleDisconnected.
-func (mr *MockIServiceMockRecorder) HandleDisconnected(conn interface{}) *gomock.Call {
+// SendWatchRequest indicates an expected call of SendWatchRequest.
+func (mr *MockIServiceMockRecorder) SendWatchRequest(conn, keys interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleDisconnected", reflect.TypeOf((*MockIService)(nil).HandleDisconnected), conn)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendWatchRequest", reflect.TypeOf((*MockIService)(nil).SendWatchRequest), conn, keys)
 }
 
-// HandleRequest mocks base method.
-func (m *MockIService) HandleRequest(ctx payload.IRequestContext) error {
+// SendXAckRequest mocks base method.
+func (m *MockIService) SendXAckRequest(conn net.Conn, key, group string, ids []string) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "HandleRequest", ctx)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "SendXAckRequest", conn, key, group, ids)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// HandleRequest indicates an expected call of HandleRequest.
-func (mr *MockIServiceMockRecorder) HandleRequest(ctx interface{}) *gomock.Call {
+// SendXAckRequest indicates an expected call of SendXAckRequest.
+func (mr *MockIServiceMockRecorder) SendXAckRequest(conn, key, group, ids interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleRequest", reflect.TypeOf((*MockIService)(nil).HandleRequest), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendXAckRequest", reflect.TypeOf((*MockIService)(nil).SendXAckRequest), conn, key, group, ids)
 }
 
-// SendGetRequest mocks base method.
-func (m *MockIService) SendGetRequest(conn net.Conn, key string) (tlv.TLVCompatible, error) {
+// SendXAddRequest mocks base method.
+func (m *MockIService) SendXAddRequest(conn net.Conn, key, id string, fields tlv.Map, trim model.StreamTrim) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendGetRequest", conn, key)
-	ret0, _ := ret[0].(tlv.TLVCompatible)
+	ret := m.ctrl.Call(m, "SendXAddRequest", conn, key, id, fields, trim)
+	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SendGetRequest indicates an expected call of SendGetRequest.
-func (mr *MockIServiceMockRecorder) SendGetRequest(conn, key interface{}) *gomock.Call {
+// SendXAddRequest indicates an expected call of SendXAddRequest.
+func (mr *MockIServiceMockRecorder) SendXAddRequest(conn, key, id, fields, trim interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendGetRequest", reflect.TypeOf((*MockIService)(nil).SendGetRequest), conn, key)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendXAddRequest", reflect.TypeOf((*MockIService)(nil).SendXAddRequest), conn, key, id, fields, trim)
 }
 
-// SendPingRequest mocks base method.
-func (m *MockIService) SendPingRequest(conn net.Conn, msg *string) (*tlv.String, error) {
+// SendXClaimRequest mocks base method.
+func (m *MockIService) SendXClaimRequest(conn net.Conn, key, group, consumer string, minIdle time.Duration, count int64, ids []string) ([]redis.StreamEntry, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendPingRequest", conn, msg)
-	ret0, _ := ret[0].(*tlv.String)
+	ret := m.ctrl.Call(m, "SendXClaimRequest", conn, key, group, consumer, minIdle, count, ids)
+	ret0, _ := ret[0].([]redis.StreamEntry)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SendPingRequest indicates an expected call of SendPingRequest.
-func (mr *MockIServiceMockRecorder) SendPingRequest(conn, msg interface{}) *gomock.Call {
+// SendXClaimRequest indicates an expected call of SendXClaimRequest.
+func (mr *MockIServiceMockRecorder) SendXClaimRequest(conn, key, group, consumer, minIdle, count, ids interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPingRequest", reflect.TypeOf((*MockIService)(nil).SendPingRequest), conn, msg)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendXClaimRequest", reflect.TypeOf((*MockIService)(nil).SendXClaimRequest), conn, key, group, consumer, minIdle, count, ids)
 }
 
-// SendPubRequest mocks base method.
-func (m *MockIService) SendPubRequest(conn net.Conn, topic string, val tlv.TypeLengthValue) (string, error) {
+// SendXGroupCreateRequest mocks base method.
+func (m *MockIService) SendXGroupCreateRequest(conn net.Conn, key, group, id string) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendPubRequest", conn, topic, val)
+	ret := m.ctrl.Call(m, "SendXGroupCreateRequest", conn, key, group, id)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SendPubRequest indicates an expected call of SendPubRequest.
-func (mr *MockIServiceMockRecorder) SendPubRequest(conn, topic, val interface{}) *gomock.Call {
+// SendXGroupCreateRequest indicates an expected call of SendXGroupCreateRequest.
+func (mr *MockIServiceMockRecorder) SendXGroupCreateRequest(conn, key, group, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendPubRequest", reflect.TypeOf((*MockIService)(nil).SendPubRequest), conn, topic, val)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendXGroupCreateRequest", reflect.TypeOf((*MockIService)(nil).SendXGroupCreateRequest), conn, key, group, id)
 }
 
-// SendSetRequest mocks base method.
-func (m *MockIService) SendSetRequest(conn net.Conn, key string, val tlv.TypeLengthValue) (string, error) {
+// SendXGroupDestroyRequest mocks base method.
+func (m *MockIService) SendXGroupDestroyRequest(conn net.Conn, key, group string) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendSetRequest", conn, key, val)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "SendXGroupDestroyRequest", conn, key, group)
+	ret0, _ := ret[0].(bool)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SendSetRequest indicates an expected call of SendSetRequest.
-func (mr *MockIServiceMockRecorder) SendSetRequest(conn, key, val interface{}) *gomock.Call {
+// SendXGroupDestroyRequest indicates an expected call of SendXGroupDestroyRequest.
+func (mr *MockIServiceMockRecorder) SendXGroupDestroyRequest(conn, key, group interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSetRequest", reflect.TypeOf((*MockIService)(nil).SendSetRequest), conn, key, val)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendXGroupDestroyRequest", reflect.TypeOf((*MockIService)(nil).SendXGroupDestroyRequest), conn, key, group)
 }
 
-// SendSubRequest mocks base method.
-func (m *MockIService) SendSubRequest(conn net.Conn, topic string) (*pubsub.Subscriber, error) {
+// SendXLenRequest mocks base method.
+func (m *MockIService) SendXLenRequest(conn net.Conn, key string) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SendSubRequest", conn, topic)
-	ret0, _ := ret[0].(*pubsub.Subscriber)
+	ret := m.ctrl.Call(m, "SendXLenRequest", conn, key)
+	ret0, _ := ret[0].(int64)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// SendSubRequest indicates an expected call of SendSubRequest.
-func (mr *MockIServiceMockRecorder) SendSubRequest(conn, topic interface{}) *gomock.Call {
+// SendXLenRequest indicates an expected call of SendXLenRequest.
+func (mr *MockIServiceMockRecorder) SendXLenRequest(conn, key interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendXLenRequest", reflect.TypeOf((*MockIService)(nil).SendXLenRequest), conn, key)
+}
+
+// SendXPendingRequest mocks base method.
+func (m *MockIService) SendXPendingRequest(conn net.Conn, key, group, consumer string) ([]redis.StreamPending, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendXPendingRequest", conn, key, group, consumer)
+	ret0, _ := ret[0].([]redis.StreamPending)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendXPendingRequest indicates an expected call of SendXPendingRequest.
+func (mr *MockIServiceMockRecorder) SendXPendingRequest(conn, key, group, consumer interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendXPendingRequest", reflect.TypeOf((*MockIService)(nil).SendXPendingRequest), conn, key, group, consumer)
+}
+
+// SendXRangeRequest mocks base method.
+func (m *MockIService) SendXRangeRequest(conn net.Conn, key, start, end string, count int64) ([]redis.StreamEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendXRangeRequest", conn, key, start, end, count)
+	ret0, _ := ret[0].([]redis.StreamEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendXRangeRequest indicates an expected call of SendXRangeRequest.
+func (mr *MockIServiceMockRecorder) SendXRangeRequest(conn, key, start, end, count interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendXRangeRequest", reflect.TypeOf((*MockIService)(nil).SendXRangeRequest), conn, key, start, end, count)
+}
+
+// SendXReadGroupRequest mocks base method.
+func (m *MockIService) SendXReadGroupRequest(conn net.Conn, group, consumer string, keys, ids []string, count int64, block bool, timeout time.Duration) ([]redis.StreamRead, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendXReadGroupRequest", conn, group, consumer, keys, ids, count, block, timeout)
+	ret0, _ := ret[0].([]redis.StreamRead)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendXReadGroupRequest indicates an expected call of SendXReadGroupRequest.
+func (mr *MockIServiceMockRecorder) SendXReadGroupRequest(conn, group, consumer, keys, ids, count, block, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendXReadGroupRequest", reflect.TypeOf((*MockIService)(nil).SendXReadGroupRequest), conn, group, consumer, keys, ids, count, block, timeout)
+}
+
+// SendXReadRequest mocks base method.
+func (m *MockIService) SendXReadRequest(conn net.Conn, keys, ids []string, count int64, block bool, timeout time.Duration) ([]redis.StreamRead, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendXReadRequest", conn, keys, ids, count, block, timeout)
+	ret0, _ := ret[0].([]redis.StreamRead)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendXReadRequest indicates an expected call of SendXReadRequest.
+func (mr *MockIServiceMockRecorder) SendXReadRequest(conn, keys, ids, count, block, timeout interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendXReadRequest", reflect.TypeOf((*MockIService)(nil).SendXReadRequest), conn, keys, ids, count, block, timeout)
+}
+
+// SendXTrimRequest mocks base method.
+func (m *MockIService) SendXTrimRequest(conn net.Conn, key string, trim model.StreamTrim) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendXTrimRequest", conn, key, trim)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendXTrimRequest indicates an expected call of SendXTrimRequest.
+func (mr *MockIServiceMockRecorder) SendXTrimRequest(conn, key, trim interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendXTrimRequest", reflect.TypeOf((*MockIService)(nil).SendXTrimRequest), conn, key, trim)
+}
+
+// SendZAddRequest mocks base method.
+func (m *MockIService) SendZAddRequest(conn net.Conn, key string, members map[string]float64) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendZAddRequest", conn, key, members)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendZAddRequest indicates an expected call of SendZAddRequest.
+func (mr *MockIServiceMockRecorder) SendZAddRequest(conn, key, members interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendZAddRequest", reflect.TypeOf((*MockIService)(nil).SendZAddRequest), conn, key, members)
+}
+
+// SendZIncrByRequest mocks base method.
+func (m *MockIService) SendZIncrByRequest(conn net.Conn, key, member string, delta float64) (float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendZIncrByRequest", conn, key, member, delta)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendZIncrByRequest indicates an expected call of SendZIncrByRequest.
+func (mr *MockIServiceMockRecorder) SendZIncrByRequest(conn, key, member, delta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendZIncrByRequest", reflect.TypeOf((*MockIService)(nil).SendZIncrByRequest), conn, key, member, delta)
+}
+
+// SendZRangeByScoreRequest mocks base method.
+func (m *MockIService) SendZRangeByScoreRequest(conn net.Conn, key string, min, max float64) ([]model.ZMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendZRangeByScoreRequest", conn, key, min, max)
+	ret0, _ := ret[0].([]model.ZMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendZRangeByScoreRequest indicates an expected call of SendZRangeByScoreRequest.
+func (mr *MockIServiceMockRecorder) SendZRangeByScoreRequest(conn, key, min, max interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendZRangeByScoreRequest", reflect.TypeOf((*MockIService)(nil).SendZRangeByScoreRequest), conn, key, min, max)
+}
+
+// SendZRangeRequest mocks base method.
+func (m *MockIService) SendZRangeRequest(conn net.Conn, key string, start, stop int64) ([]model.ZMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendZRangeRequest", conn, key, start, stop)
+	ret0, _ := ret[0].([]model.ZMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendZRangeRequest indicates an expected call of SendZRangeRequest.
+func (mr *MockIServiceMockRecorder) SendZRangeRequest(conn, key, start, stop interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendZRangeRequest", reflect.TypeOf((*MockIService)(nil).SendZRangeRequest), conn, key, start, stop)
+}
+
+// SendZRankRequest mocks base method.
+func (m *MockIService) SendZRankRequest(conn net.Conn, key, member string) (int64, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendZRankRequest", conn, key, member)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SendZRankRequest indicates an expected call of SendZRankRequest.
+func (mr *MockIServiceMockRecorder) SendZRankRequest(conn, key, member interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendZRankRequest", reflect.TypeOf((*MockIService)(nil).SendZRankRequest), conn, key, member)
+}
+
+// SendZRemRequest mocks base method.
+func (m *MockIService) SendZRemRequest(conn net.Conn, key string, members []string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendZRemRequest", conn, key, members)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendZRemRequest indicates an expected call of SendZRemRequest.
+func (mr *MockIServiceMockRecorder) SendZRemRequest(conn, key, members interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendZRemRequest", reflect.TypeOf((*MockIService)(nil).SendZRemRequest), conn, key, members)
+}
+
+// SendZScoreRequest mocks base method.
+func (m *MockIService) SendZScoreRequest(conn net.Conn, key, member string) (float64, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendZScoreRequest", conn, key, member)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// SendZScoreRequest indicates an expected call of SendZScoreRequest.
+func (mr *MockIServiceMockRecorder) SendZScoreRequest(conn, key, member interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendSubRequest", reflect.TypeOf((*MockIService)(nil).SendSubRequest), conn, topic)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendZScoreRequest", reflect.TypeOf((*MockIService)(nil).SendZScoreRequest), conn, key, member)
 }
@@ -1,10 +1,14 @@
 package network
 
 import (
+	"context"
 	"net"
+	"time"
 
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
 	"bitbucket.org/non-pn/mini-redis-go/internal/service"
 	"bitbucket.org/non-pn/mini-redis-go/internal/service/pubsub"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/redis"
 	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
 )
 
@@ -80,30 +84,632 @@ func (c *Client) Set(k string, v tlv.TLVCompatible) (string, error) {
 	return resp, nil
 }
 
-func (c *Client) Sub(topic string) (*pubsub.Subscriber, error) {
-	sub, err := c.Service.SendSubRequest(c.Connection, topic)
+func (c *Client) SetEx(k string, v tlv.TLVCompatible, ttl time.Duration) (string, error) {
+	raw, err := v.ToTLV()
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.Service.SendSetExRequest(c.Connection, k, raw, ttl)
+	if err != nil {
+		return "", err
+	}
+
+	return resp, nil
+}
+
+// SetNX set key only when it is missing and return whether it was set
+func (c *Client) SetNX(k string, v tlv.TLVCompatible) (bool, error) {
+	set, _, err := c.SetArgs(k, v, redis.SetOptions{NX: true})
+	return set, err
+}
+
+// SetXX set key only when it exists and return whether it was set
+func (c *Client) SetXX(k string, v tlv.TLVCompatible) (bool, error) {
+	set, _, err := c.SetArgs(k, v, redis.SetOptions{XX: true})
+	return set, err
+}
+
+// GetSet set key and return value it held before, nil when it was missing
+func (c *Client) GetSet(k string, v tlv.TLVCompatible) (tlv.TLVCompatible, error) {
+	_, prev, err := c.SetArgs(k, v, redis.SetOptions{Get: true})
+	return prev, err
+}
+
+// SetArgs set key when opts allow it, checking and setting happen atomically. It return
+// whether key was set and its previous value when opts.Get is set.
+func (c *Client) SetArgs(k string, v tlv.TLVCompatible, opts redis.SetOptions) (bool, tlv.TLVCompatible, error) {
+	raw, err := v.ToTLV()
+	if err != nil {
+		return false, nil, err
+	}
+
+	return c.Service.SendSetArgsRequest(c.Connection, k, raw, opts)
+}
+
+// CAS set key to v only when it hold expected, nil expected mean key must be missing.
+// Values are compared as encoded so they must be of same type. It return new version of
+// key, 0 when key was not set.
+func (c *Client) CAS(k string, expected tlv.TLVCompatible, v tlv.TLVCompatible, ttl time.Duration) (uint64, error) {
+	var rawexpected tlv.TypeLengthValue
+	if expected != nil {
+		var err error
+		rawexpected, err = expected.ToTLV()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	raw, err := v.ToTLV()
+	if err != nil {
+		return 0, err
+	}
+
+	return c.Service.SendCASRequest(c.Connection, k, rawexpected, raw, ttl)
+}
+
+// CASVersion set key to v only when its version is still version, see GetVersion. It
+// return new version of key, 0 when key was not set.
+func (c *Client) CASVersion(k string, version uint64, v tlv.TLVCompatible, ttl time.Duration) (uint64, error) {
+	raw, err := v.ToTLV()
+	if err != nil {
+		return 0, err
+	}
+
+	return c.Service.SendCASVersionRequest(c.Connection, k, version, raw, ttl)
+}
+
+// GetVersion return version of key, it change on every write and is 0 for missing key
+func (c *Client) GetVersion(k string) (uint64, error) {
+	return c.Service.SendGetVersionRequest(c.Connection, k)
+}
+
+// MGet return values of keys in one request, value of missing key is nil
+func (c *Client) MGet(keys ...string) ([]tlv.TLVCompatible, error) {
+	return c.Service.SendMGetRequest(c.Connection, keys)
+}
+
+// MSet set every key of pairs to its value in one request
+func (c *Client) MSet(pairs map[string]tlv.TLVCompatible) (string, error) {
+	raws := tlv.Map{}
+	for k, v := range pairs {
+		err := raws.Put(k, v)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return c.Service.SendMSetRequest(c.Connection, raws)
+}
+
+// MSetNX set every key of pairs only when none of them exists, it return whether they were set
+func (c *Client) MSetNX(pairs map[string]tlv.TLVCompatible) (bool, error) {
+	raws := tlv.Map{}
+	for k, v := range pairs {
+		err := raws.Put(k, v)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return c.Service.SendMSetNXRequest(c.Connection, raws)
+}
+
+func (c *Client) Expire(k string, ttl time.Duration) (bool, error) {
+	return c.Service.SendExpireRequest(c.Connection, k, ttl)
+}
+
+func (c *Client) TTL(k string) (time.Duration, error) {
+	return c.Service.SendTTLRequest(c.Connection, k)
+}
+
+func (c *Client) Persist(k string) (bool, error) {
+	return c.Service.SendPersistRequest(c.Connection, k)
+}
+
+func (c *Client) Del(keys ...string) (int, error) {
+	return c.Service.SendDelRequest(c.Connection, keys)
+}
+
+func (c *Client) Exists(keys ...string) (int, error) {
+	return c.Service.SendExistsRequest(c.Connection, keys)
+}
+
+// Scan iterate keys matching glob pattern, start with empty cursor and continue with returned cursor until it is empty
+func (c *Client) Scan(cursor string, match string, count int) (string, []string, error) {
+	return c.Service.SendScanRequest(c.Connection, cursor, match, count)
+}
+
+func (c *Client) Incr(k string) (int64, error) {
+	return c.Service.SendIncrRequest(c.Connection, k)
+}
+
+func (c *Client) Decr(k string) (int64, error) {
+	return c.Service.SendDecrRequest(c.Connection, k)
+}
+
+func (c *Client) IncrBy(k string, delta int64) (int64, error) {
+	return c.Service.SendIncrByRequest(c.Connection, k, delta)
+}
+
+func (c *Client) DecrBy(k string, delta int64) (int64, error) {
+	return c.Service.SendDecrByRequest(c.Connection, k, delta)
+}
+
+func (c *Client) IncrByFloat(k string, delta float64) (float64, error) {
+	return c.Service.SendIncrByFloatRequest(c.Connection, k, delta)
+}
+
+func (c *Client) LPush(k string, vals ...tlv.TLVCompatible) (int64, error) {
+	raws, err := toTLVs(vals)
+	if err != nil {
+		return 0, err
+	}
+
+	return c.Service.SendLPushRequest(c.Connection, k, raws)
+}
+
+func (c *Client) RPush(k string, vals ...tlv.TLVCompatible) (int64, error) {
+	raws, err := toTLVs(vals)
+	if err != nil {
+		return 0, err
+	}
+
+	return c.Service.SendRPushRequest(c.Connection, k, raws)
+}
+
+// LPop return nil when list is empty
+func (c *Client) LPop(k string) (tlv.TLVCompatible, error) {
+	return c.Service.SendLPopRequest(c.Connection, k)
+}
+
+func (c *Client) RPop(k string) (tlv.TLVCompatible, error) {
+	return c.Service.SendRPopRequest(c.Connection, k)
+}
+
+// LRange return elements from start to stop inclusive, negative index count from the end
+func (c *Client) LRange(k string, start int64, stop int64) ([]tlv.TLVCompatible, error) {
+	return c.Service.SendLRangeRequest(c.Connection, k, start, stop)
+}
+
+func (c *Client) LLen(k string) (int64, error) {
+	return c.Service.SendLLenRequest(c.Connection, k)
+}
+
+// BLPop block until one of keys has element or timeout expire, zero timeout block forever.
+// Expired timeout return empty key and nil value.
+func (c *Client) BLPop(timeout time.Duration, keys ...string) (string, tlv.TLVCompatible, error) {
+	return c.Service.SendBLPopRequest(c.blockingConn(timeout), keys, timeout)
+}
+
+// HSet set fields of hash and return number of fields that were added
+func (c *Client) HSet(k string, fields map[string]tlv.TLVCompatible) (int64, error) {
+	raws := tlv.Map{}
+	for f, v := range fields {
+		err := raws.Put(f, v)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return c.Service.SendHSetRequest(c.Connection, k, raws)
+}
+
+// HGet return nil when field does not exist
+func (c *Client) HGet(k string, field string) (tlv.TLVCompatible, error) {
+	return c.Service.SendHGetRequest(c.Connection, k, field)
+}
+
+func (c *Client) HDel(k string, fields ...string) (int64, error) {
+	return c.Service.SendHDelRequest(c.Connection, k, fields)
+}
+
+func (c *Client) HGetAll(k string) (map[string]tlv.TLVCompatible, error) {
+	return c.Service.SendHGetAllRequest(c.Connection, k)
+}
+
+func (c *Client) HIncrBy(k string, field string, delta int64) (int64, error) {
+	return c.Service.SendHIncrByRequest(c.Connection, k, field, delta)
+}
+
+func (c *Client) HLen(k string) (int64, error) {
+	return c.Service.SendHLenRequest(c.Connection, k)
+}
+
+func (c *Client) SAdd(k string, members ...string) (int64, error) {
+	return c.Service.SendSAddRequest(c.Connection, k, members)
+}
+
+func (c *Client) SRem(k string, members ...string) (int64, error) {
+	return c.Service.SendSRemRequest(c.Connection, k, members)
+}
+
+func (c *Client) SIsMember(k string, member string) (bool, error) {
+	return c.Service.SendSIsMemberRequest(c.Connection, k, member)
+}
+
+// SMembers return members in sorted order
+func (c *Client) SMembers(k string) ([]string, error) {
+	return c.Service.SendSMembersRequest(c.Connection, k)
+}
+
+func (c *Client) SInter(keys ...string) ([]string, error) {
+	return c.Service.SendSInterRequest(c.Connection, keys)
+}
+
+func (c *Client) SUnion(keys ...string) ([]string, error) {
+	return c.Service.SendSUnionRequest(c.Connection, keys)
+}
+
+// SDiff return members of first key that are not in any of the other keys
+func (c *Client) SDiff(keys ...string) ([]string, error) {
+	return c.Service.SendSDiffRequest(c.Connection, keys)
+}
+
+// ZAdd set scores of members and return number of members that were added
+func (c *Client) ZAdd(k string, members map[string]float64) (int64, error) {
+	return c.Service.SendZAddRequest(c.Connection, k, members)
+}
+
+func (c *Client) ZRem(k string, members ...string) (int64, error) {
+	return c.Service.SendZRemRequest(c.Connection, k, members)
+}
+
+// ZScore return false when member does not exist
+func (c *Client) ZScore(k string, member string) (float64, bool, error) {
+	return c.Service.SendZScoreRequest(c.Connection, k, member)
+}
+
+// ZRange return members ranked from start to stop inclusive, negative index count from the end
+func (c *Client) ZRange(k string, start int64, stop int64) ([]model.ZMember, error) {
+	return c.Service.SendZRangeRequest(c.Connection, k, start, stop)
+}
+
+// ZRangeByScore return members with score between min and max inclusive
+func (c *Client) ZRangeByScore(k string, min float64, max float64) ([]model.ZMember, error) {
+	return c.Service.SendZRangeByScoreRequest(c.Connection, k, min, max)
+}
+
+// ZRank return false when member does not exist
+func (c *Client) ZRank(k string, member string) (int64, bool, error) {
+	return c.Service.SendZRankRequest(c.Connection, k, member)
+}
+
+func (c *Client) ZIncrBy(k string, member string, delta float64) (float64, error) {
+	return c.Service.SendZIncrByRequest(c.Connection, k, member, delta)
+}
+
+// BGRewriteAOF ask server to compact its append only file in background
+func (c *Client) BGRewriteAOF() (string, error) {
+	return c.Service.SendBGRewriteAOFRequest(c.Connection)
+}
+
+// Save ask server to write snapshot, reply come once it is on disk
+func (c *Client) Save() (string, error) {
+	return c.Service.SendSaveRequest(c.Connection)
+}
+
+// BGSave ask server to write snapshot in background
+func (c *Client) BGSave() (string, error) {
+	return c.Service.SendBGSaveRequest(c.Connection)
+}
+
+// ClientList describe every connection of server, a line per connection
+func (c *Client) ClientList() (string, error) {
+	return c.Service.SendClientListRequest(c.Connection)
+}
+
+// ClientKill close connections matching filter "ID" or "ADDR", return number closed
+func (c *Client) ClientKill(filter string, value string) (int64, error) {
+	return c.Service.SendClientKillRequest(c.Connection, filter, value)
+}
+
+func (c *Client) ClientSetName(name string) (string, error) {
+	return c.Service.SendClientSetNameRequest(c.Connection, name)
+}
+
+func (c *Client) ClientGetName() (string, error) {
+	return c.Service.SendClientGetNameRequest(c.Connection)
+}
+
+// XAdd add entry to stream and return its id, id * let server generate it. Stream is then
+// trimmed to limits of trim, zero trim keep every entry.
+func (c *Client) XAdd(k string, id string, fields map[string]tlv.TLVCompatible, trim model.StreamTrim) (string, error) {
+	raws := tlv.Map{}
+	for f, v := range fields {
+		err := raws.Put(f, v)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return c.Service.SendXAddRequest(c.Connection, k, id, raws, trim)
+}
+
+// XRange return entries with id between start and end inclusive, - and + are first and
+// last entry. Zero count return every entry in range.
+func (c *Client) XRange(k string, start string, end string, count int64) ([]redis.StreamEntry, error) {
+	return c.Service.SendXRangeRequest(c.Connection, k, start, end, count)
+}
+
+// XRead return entries after id of each key, id at same position as key. It return nil
+// when there is none.
+func (c *Client) XRead(count int64, keys []string, ids []string) ([]redis.StreamRead, error) {
+	return c.Service.SendXReadRequest(c.Connection, keys, ids, count, false, 0)
+}
+
+// XReadBlock is XRead that wait up to timeout for entries, zero timeout block forever.
+// Id $ wait for entries added after the call.
+func (c *Client) XReadBlock(timeout time.Duration, count int64, keys []string, ids []string) ([]redis.StreamRead, error) {
+	return c.Service.SendXReadRequest(c.blockingConn(timeout), keys, ids, count, true, timeout)
+}
+
+func (c *Client) XLen(k string) (int64, error) {
+	return c.Service.SendXLenRequest(c.Connection, k)
+}
+
+// XTrim remove oldest entries beyond limits of trim and return how many were removed
+func (c *Client) XTrim(k string, trim model.StreamTrim) (int64, error) {
+	return c.Service.SendXTrimRequest(c.Connection, k, trim)
+}
+
+// XGroupCreate create group of stream that deliver entries after id, $ being last entry.
+// Stream is created when it does not exist.
+func (c *Client) XGroupCreate(k string, group string, id string) (string, error) {
+	return c.Service.SendXGroupCreateRequest(c.Connection, k, group, id)
+}
+
+// XGroupDestroy remove group with its pending entries and return false when there is none
+func (c *Client) XGroupDestroy(k string, group string) (bool, error) {
+	return c.Service.SendXGroupDestroyRequest(c.Connection, k, group)
+}
+
+// XReadGroup read entries of each key for consumer of group, id at same position as key.
+// Id > read entries never delivered to group, other id read entries pending for consumer
+// after it. Read entries stay pending until acknowledged. It return nil when there is none.
+func (c *Client) XReadGroup(group string, consumer string, count int64, keys []string, ids []string) ([]redis.StreamRead, error) {
+	return c.Service.SendXReadGroupRequest(c.Connection, group, consumer, keys, ids, count, false, 0)
+}
+
+// XReadGroupBlock is XReadGroup that wait up to timeout for undelivered entries, zero
+// timeout block forever
+func (c *Client) XReadGroupBlock(timeout time.Duration, group string, consumer string, count int64, keys []string, ids []string) ([]redis.StreamRead, error) {
+	return c.Service.SendXReadGroupRequest(c.blockingConn(timeout), group, consumer, keys, ids, count, true, timeout)
+}
+
+// XAck acknowledge entries read from group and return how many of them were pending
+func (c *Client) XAck(k string, group string, ids ...string) (int64, error) {
+	return c.Service.SendXAckRequest(c.Connection, k, group, ids)
+}
+
+// XPending return entries pending for consumer of group, empty consumer return entries of
+// every consumer
+func (c *Client) XPending(k string, group string, consumer string) ([]redis.StreamPending, error) {
+	return c.Service.SendXPendingRequest(c.Connection, k, group, consumer)
+}
+
+// XClaim hand to consumer pending entries idle for at least minIdle and return them.
+// Without ids any idle pending entry is claimed, up to count when it is positive.
+func (c *Client) XClaim(k string, group string, consumer string, minIdle time.Duration, count int64, ids ...string) ([]redis.StreamEntry, error) {
+	return c.Service.SendXClaimRequest(c.Connection, k, group, consumer, minIdle, count, ids)
+}
+
+// Watch make next transaction of client abort when any of keys change before it is executed
+func (c *Client) Watch(keys ...string) (string, error) {
+	return c.Service.SendWatchRequest(c.Connection, keys)
+}
+
+// Unwatch forget every key watched by client
+func (c *Client) Unwatch() (string, error) {
+	return c.Service.SendUnwatchRequest(c.Connection)
+}
+
+// Sub subscribe connection to topics, it is then only used to read their messages and to
+// unsubscribe through returned subscriber
+func (c *Client) Sub(topics ...string) (*pubsub.Subscriber, error) {
+	sub, err := c.Service.SendSubRequest(c.Connection, topics...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Subscriber wait for messages as long as it take
+	if tc, ok := sub.Conn.(*timeoutConn); ok {
+		sub.Conn = tc.withoutReadTimeout()
+	}
+
+	return sub, nil
+}
+
+// PSub subscribe connection to topics matching glob patterns, see Sub
+func (c *Client) PSub(patterns ...string) (*pubsub.Subscriber, error) {
+	sub, err := c.Service.SendPSubRequest(c.Connection, patterns...)
 	if err != nil {
 		return nil, err
 	}
 
+	if tc, ok := sub.Conn.(*timeoutConn); ok {
+		sub.Conn = tc.withoutReadTimeout()
+	}
+
 	return sub, nil
 }
 
-func (c *Client) Pub(topic string, msg string) (string, error) {
+// Pub publish msg on topic and return number of subscribers it was pushed to
+func (c *Client) Pub(topic string, msg string) (int, error) {
 	s := tlv.String(msg)
 	raw, err := s.ToTLV()
 	if err != nil {
-		return "", err
+		return 0, err
 	}
 
 	resp, err := c.Service.SendPubRequest(c.Connection, topic, raw)
 	if err != nil {
-		return "", err
+		return 0, err
 	}
 
 	return resp, nil
 }
 
+// DoContext run fn with its requests bound by ctx. Deadline of ctx is set on connection and
+// cancelling ctx wake up pending read or write. Connection is closed when fn is cut short,
+// as reply to request in flight would otherwise be read as reply to the next one.
+func (c *Client) DoContext(ctx context.Context, fn func(c *Client) error) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	conn := c.Connection
+	deadline, _ := ctx.Deadline()
+	err = conn.SetDeadline(deadline)
+	if err != nil {
+		return err
+	}
+
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		select {
+		case <-ctx.Done():
+			conn.SetDeadline(time.Now())
+		case <-stop:
+		}
+	}()
+
+	err = fn(c)
+	close(stop)
+	<-stopped
+	conn.SetDeadline(time.Time{})
+
+	if err != nil && (ctx.Err() != nil || isTimeout(err)) {
+		conn.Close()
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		// Connection may run out of time just before ctx does
+		if !deadline.IsZero() && !time.Now().Before(deadline) {
+			return context.DeadlineExceeded
+		}
+	}
+	return err
+}
+
+func (c *Client) PingContext(ctx context.Context, msg *string) (string, error) {
+	var resp string
+	err := c.DoContext(ctx, func(c *Client) (err error) {
+		resp, err = c.Ping(msg)
+		return err
+	})
+	return resp, err
+}
+
+func (c *Client) GetContext(ctx context.Context, k string) (tlv.TLVCompatible, error) {
+	var resp tlv.TLVCompatible
+	err := c.DoContext(ctx, func(c *Client) (err error) {
+		resp, err = c.Get(k)
+		return err
+	})
+	return resp, err
+}
+
+func (c *Client) SetContext(ctx context.Context, k string, v tlv.TLVCompatible) (string, error) {
+	var resp string
+	err := c.DoContext(ctx, func(c *Client) (err error) {
+		resp, err = c.Set(k, v)
+		return err
+	})
+	return resp, err
+}
+
+// SubContext bound subscribing by ctx, messages are then waited for as long as it take
+func (c *Client) SubContext(ctx context.Context, topics ...string) (*pubsub.Subscriber, error) {
+	var sub *pubsub.Subscriber
+	err := c.DoContext(ctx, func(c *Client) (err error) {
+		sub, err = c.Sub(topics...)
+		return err
+	})
+	return sub, err
+}
+
+func (c *Client) PubContext(ctx context.Context, topic string, msg string) (int, error) {
+	var resp int
+	err := c.DoContext(ctx, func(c *Client) (err error) {
+		resp, err = c.Pub(topic, msg)
+		return err
+	})
+	return resp, err
+}
+
+// Pipeline queue commands to be sent together, see Pipeline.Exec
+func (c *Client) Pipeline() *Pipeline {
+	return &Pipeline{client: c}
+}
+
+// TxPipeline queue commands to be run by server as one transaction, see Pipeline.Exec. Exec
+// return ErrTxAborted when a key watched by client changed since Watch.
+func (c *Client) TxPipeline() *Pipeline {
+	return &Pipeline{client: c, multi: true}
+}
+
+// Mux start reading replies of connection so it can be shared by goroutines, client itself
+// should no longer send requests over it.
+func (c *Client) Mux() *Mux {
+	conn := c.Connection
+	if tc, ok := conn.(*timeoutConn); ok {
+		conn = tc.withoutReadTimeout()
+	}
+
+	m := &Mux{
+		conn:    conn,
+		client:  c,
+		streams: make(map[uint32]*muxStream),
+		done:    make(chan struct{}),
+	}
+	go m.read()
+	return m
+}
+
+// withConn return copy of client sending its requests over conn
+func (c *Client) withConn(conn net.Conn) *Client {
+	return &Client{
+		Network:    c.Network,
+		Host:       c.Host,
+		Transport:  c.Transport,
+		Connection: conn,
+		Service:    c.Service,
+	}
+}
+
+// blockingConn return connection for request blocking up to timeout on server, read timeout
+// is added on top of it so the reply is not given up while server still wait. Request
+// blocking forever wait for its reply as long as it take.
+func (c *Client) blockingConn(timeout time.Duration) net.Conn {
+	tc, ok := c.Connection.(*timeoutConn)
+	if !ok || tc.readTimeout <= 0 {
+		return c.Connection
+	}
+
+	if timeout <= 0 {
+		return tc.withoutReadTimeout()
+	}
+	return tc.withReadTimeout(timeout + tc.readTimeout)
+}
+
+func toTLVs(vals []tlv.TLVCompatible) ([]tlv.TypeLengthValue, error) {
+	raws := make([]tlv.TypeLengthValue, 0, len(vals))
+	for _, v := range vals {
+		raw, err := v.ToTLV()
+		if err != nil {
+			return nil, err
+		}
+		raws = append(raws, raw)
+	}
+
+	return raws, nil
+}
+
 func NewClient(network string, host string, port string, cert string, key string) *Client {
 	return &Client{
 		Network:    network,
@@ -0,0 +1,141 @@
+package network
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+	"github.com/stretchr/testify/assert"
+)
+
+// newStalledClient return client of server reading requests without ever replying
+func newStalledClient(t *testing.T) *Client {
+	sconn, cconn := net.Pipe()
+	t.Cleanup(func() {
+		sconn.Close()
+	})
+	go func() {
+		for {
+			req := new(payload.RequestPayload)
+			_, err := req.ReadFrom(sconn)
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	return &Client{Connection: cconn, Service: service.NewService()}
+}
+
+func newServedClient(t *testing.T, server *Server) *Client {
+	sconn, cconn := net.Pipe()
+	go server.HandleConnection(sconn)
+	t.Cleanup(func() {
+		cconn.Close()
+	})
+
+	return &Client{Connection: cconn, Service: service.NewService()}
+}
+
+func TestClientContextDeadline(t *testing.T) {
+	client := newStalledClient(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.GetContext(ctx, "test_key")
+
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// Reply to abandoned request could no longer be told apart, so connection is closed
+	_, err = client.Get("test_key")
+	assert.NotNil(t, err)
+}
+
+func TestClientContextCancel(t *testing.T) {
+	client := newStalledClient(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		cancel()
+	}()
+	_, err := client.PingContext(ctx, nil)
+
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestClientContextDoneBeforeRequest(t *testing.T) {
+	client := newServedClient(t, newConnectionTestServer())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	val := tlv.String("test_val")
+	_, err := client.SetContext(ctx, "test_key", &val)
+
+	assert.Equal(t, context.Canceled, err)
+
+	// Nothing was sent, connection is still in sync
+	ctx, cancel = context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	resp, err := client.GetContext(ctx, "test_key")
+	assert.Nil(t, err)
+	assert.Equal(t, new(tlv.String), resp)
+}
+
+func TestClientContextPubSub(t *testing.T) {
+	server := newConnectionTestServer()
+	client := newServedClient(t, server)
+	subclient := newServedClient(t, server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	sub, err := subclient.SubContext(ctx, "test_topic")
+	assert.Nil(t, err)
+
+	msgs := make(chan *tlv.String, 1)
+	go func() {
+		msg, err := sub.NextMessage()
+		assert.Nil(t, err)
+		msgs <- msg
+	}()
+
+	// Deadline of ctx does not outlive subscribing
+	<-ctx.Done()
+	receivers, err := client.PubContext(context.Background(), "test_topic", "test_msg")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, receivers)
+	assert.Equal(t, "test_msg", (<-msgs).String())
+}
+
+func TestMuxClientContextDeadline(t *testing.T) {
+	server := newConnectionTestServer()
+	mux := newServedClient(t, server).Mux()
+	defer mux.Close()
+
+	blocked, err := mux.Client()
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = blocked.DoContext(ctx, func(c *Client) error {
+		_, _, err := c.BLPop(0, "test_list")
+		return err
+	})
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// Late reply to client cut short is dropped, connection of mux stay in sync
+	val := tlv.String("test_val")
+	_, err = newServedClient(t, server).LPush("test_list", &val)
+	assert.Nil(t, err)
+
+	other, err := mux.Client()
+	assert.Nil(t, err)
+	defer other.Close()
+	pong, err := other.PingContext(context.Background(), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, "PONG", pong)
+}
@@ -2,13 +2,17 @@ package network
 
 import (
 	"errors"
+	"net"
 	"testing"
+	"time"
 
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
 	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
 	mocknetwork "bitbucket.org/non-pn/mini-redis-go/internal/mock/network"
 	mockservice "bitbucket.org/non-pn/mini-redis-go/internal/mock/service"
 	"bitbucket.org/non-pn/mini-redis-go/internal/service"
 	"bitbucket.org/non-pn/mini-redis-go/internal/service/pubsub"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/redis"
 	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -259,12 +263,12 @@ func TestClientPub(t *testing.T) {
 	topic := "test_topic"
 	val := "test_val"
 	raw := []byte{2, 0, 0, 0, 8, 116, 101, 115, 116, 95, 118, 97, 108}
-	service.EXPECT().SendPubRequest(conn, topic, raw).Times(1).Return("OK", nil)
+	service.EXPECT().SendPubRequest(conn, topic, raw).Times(1).Return(1, nil)
 
 	resp, err := client.Pub(topic, val)
 
 	assert.Nil(t, err)
-	assert.Equal(t, "OK", resp)
+	assert.Equal(t, 1, resp)
 }
 
 func TestClientPubReturnError(t *testing.T) {
@@ -283,10 +287,429 @@ func TestClientPubReturnError(t *testing.T) {
 	val := "test_val"
 	raw := []byte{2, 0, 0, 0, 8, 116, 101, 115, 116, 95, 118, 97, 108}
 	mockerr := errors.New("Some send pub request error")
-	service.EXPECT().SendPubRequest(conn, topic, raw).Times(1).Return("", mockerr)
+	service.EXPECT().SendPubRequest(conn, topic, raw).Times(1).Return(0, mockerr)
 
 	resp, err := client.Pub(topic, val)
 
 	assert.Equal(t, mockerr, err)
-	assert.Equal(t, "", resp)
+	assert.Equal(t, 0, resp)
+}
+
+func TestClientSetEx(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	service := mockservice.NewMockIService(ctrl)
+
+	client := &Client{
+		Network:    "tcp",
+		Host:       "localhost",
+		Connection: conn,
+		Service:    service,
+	}
+
+	key := "test_key"
+	val := tlv.String("test_val")
+	raw := []byte{2, 0, 0, 0, 8, 116, 101, 115, 116, 95, 118, 97, 108}
+	service.EXPECT().SendSetExRequest(conn, key, raw, time.Minute).Times(1).Return("OK", nil)
+
+	resp, err := client.SetEx(key, &val, time.Minute)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", resp)
+}
+
+func TestClientExpire(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	service := mockservice.NewMockIService(ctrl)
+
+	client := &Client{
+		Network:    "tcp",
+		Host:       "localhost",
+		Connection: conn,
+		Service:    service,
+	}
+
+	key := "test_key"
+	service.EXPECT().SendExpireRequest(conn, key, time.Minute).Times(1).Return(true, nil)
+
+	ok, err := client.Expire(key, time.Minute)
+
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}
+
+func TestClientTTL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	service := mockservice.NewMockIService(ctrl)
+
+	client := &Client{
+		Network:    "tcp",
+		Host:       "localhost",
+		Connection: conn,
+		Service:    service,
+	}
+
+	key := "test_key"
+	service.EXPECT().SendTTLRequest(conn, key).Times(1).Return(time.Second, nil)
+
+	ttl, err := client.TTL(key)
+
+	assert.Nil(t, err)
+	assert.Equal(t, time.Second, ttl)
+}
+
+func TestClientDel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	service := mockservice.NewMockIService(ctrl)
+
+	client := &Client{
+		Network:    "tcp",
+		Host:       "localhost",
+		Connection: conn,
+		Service:    service,
+	}
+
+	service.EXPECT().SendDelRequest(conn, []string{"a", "b"}).Times(1).Return(2, nil)
+
+	n, err := client.Del("a", "b")
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, n)
+}
+
+func TestClientScan(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	service := mockservice.NewMockIService(ctrl)
+
+	client := &Client{
+		Network:    "tcp",
+		Host:       "localhost",
+		Connection: conn,
+		Service:    service,
+	}
+
+	service.EXPECT().SendScanRequest(conn, "", "*", 10).Times(1).Return("", []string{"a"}, nil)
+
+	cursor, keys, err := client.Scan("", "*", 10)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "", cursor)
+	assert.Equal(t, []string{"a"}, keys)
+}
+
+func TestClientIncrBy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	service := mockservice.NewMockIService(ctrl)
+
+	client := &Client{
+		Network:    "tcp",
+		Host:       "localhost",
+		Connection: conn,
+		Service:    service,
+	}
+
+	service.EXPECT().SendIncrByRequest(conn, "counter", int64(5)).Times(1).Return(int64(5), nil)
+
+	n, err := client.IncrBy("counter", 5)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), n)
+}
+
+func TestClientRPush(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	service := mockservice.NewMockIService(ctrl)
+
+	client := &Client{
+		Network:    "tcp",
+		Host:       "localhost",
+		Connection: conn,
+		Service:    service,
+	}
+
+	v := tlv.String("a")
+	raw, _ := v.ToTLV()
+	service.EXPECT().SendRPushRequest(conn, "test_list", []tlv.TypeLengthValue{raw}).Times(1).Return(int64(1), nil)
+
+	n, err := client.RPush("test_list", &v)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+func TestClientHSet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	service := mockservice.NewMockIService(ctrl)
+
+	client := &Client{
+		Network:    "tcp",
+		Host:       "localhost",
+		Connection: conn,
+		Service:    service,
+	}
+
+	v := tlv.String("1")
+	fields := tlv.Map{}
+	fields.Put("a", &v)
+	service.EXPECT().SendHSetRequest(conn, "test_hash", fields).Times(1).Return(int64(1), nil)
+
+	n, err := client.HSet("test_hash", map[string]tlv.TLVCompatible{"a": &v})
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+func TestClientZAdd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	service := mockservice.NewMockIService(ctrl)
+
+	client := &Client{
+		Network:    "tcp",
+		Host:       "localhost",
+		Connection: conn,
+		Service:    service,
+	}
+
+	members := map[string]float64{"a": 1, "b": 2}
+	service.EXPECT().SendZAddRequest(conn, "test_zset", members).Times(1).Return(int64(2), nil)
+
+	n, err := client.ZAdd("test_zset", members)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), n)
+}
+
+func TestClientXAdd(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	service := mockservice.NewMockIService(ctrl)
+
+	client := &Client{
+		Network:    "tcp",
+		Host:       "localhost",
+		Connection: conn,
+		Service:    service,
+	}
+
+	v := tlv.String("1")
+	fields := tlv.Map{}
+	fields.Put("a", &v)
+	trim := model.StreamTrim{MaxLen: 10}
+	service.EXPECT().SendXAddRequest(conn, "test_stream", "*", fields, trim).Times(1).Return("1-0", nil)
+
+	id, err := client.XAdd("test_stream", "*", map[string]tlv.TLVCompatible{"a": &v}, trim)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "1-0", id)
+}
+
+func TestClientXReadGroup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	service := mockservice.NewMockIService(ctrl)
+
+	client := &Client{
+		Network:    "tcp",
+		Host:       "localhost",
+		Connection: conn,
+		Service:    service,
+	}
+
+	reads := []redis.StreamRead{{Key: "test_stream", Entries: []redis.StreamEntry{{ID: "1-0"}}}}
+	service.EXPECT().SendXReadGroupRequest(conn, "test_group", "alice", []string{"test_stream"}, []string{">"}, int64(1), false, time.Duration(0)).Times(1).Return(reads, nil)
+
+	res, err := client.XReadGroup("test_group", "alice", 1, []string{"test_stream"}, []string{">"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, reads, res)
+}
+
+func TestClientSetNX(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	service := mockservice.NewMockIService(ctrl)
+
+	client := &Client{
+		Network:    "tcp",
+		Host:       "localhost",
+		Connection: conn,
+		Service:    service,
+	}
+
+	val := tlv.String("test_val")
+	rawval, _ := val.ToTLV()
+	service.EXPECT().SendSetArgsRequest(conn, "test_key", rawval, redis.SetOptions{NX: true}).Times(1).Return(false, nil, nil)
+
+	set, err := client.SetNX("test_key", &val)
+
+	assert.Nil(t, err)
+	assert.False(t, set)
+}
+
+func TestClientMSet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	service := mockservice.NewMockIService(ctrl)
+
+	client := &Client{
+		Network:    "tcp",
+		Host:       "localhost",
+		Connection: conn,
+		Service:    service,
+	}
+
+	val := tlv.String("test_val")
+	pairs := tlv.Map{}
+	pairs.Put("test_key", &val)
+	service.EXPECT().SendMSetRequest(conn, pairs).Times(1).Return("OK", nil)
+
+	res, err := client.MSet(map[string]tlv.TLVCompatible{"test_key": &val})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", res)
+}
+
+func TestClientCAS(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	service := mockservice.NewMockIService(ctrl)
+
+	client := &Client{
+		Network:    "tcp",
+		Host:       "localhost",
+		Connection: conn,
+		Service:    service,
+	}
+
+	val := tlv.String("test_val")
+	rawval, _ := val.ToTLV()
+	// Nil expected value is sent as no value
+	service.EXPECT().SendCASRequest(conn, "test_key", tlv.TypeLengthValue(nil), rawval, time.Second).Times(1).Return(uint64(1), nil)
+
+	version, err := client.CAS("test_key", nil, &val, time.Second)
+
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(1), version)
+}
+
+func TestClientWatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	service := mockservice.NewMockIService(ctrl)
+
+	client := &Client{
+		Network:    "tcp",
+		Host:       "localhost",
+		Connection: conn,
+		Service:    service,
+	}
+
+	service.EXPECT().SendWatchRequest(conn, []string{"test_key_1", "test_key_2"}).Times(1).Return("OK", nil)
+
+	res, err := client.Watch("test_key_1", "test_key_2")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", res)
+}
+
+func TestClientClientKill(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	service := mockservice.NewMockIService(ctrl)
+
+	client := &Client{
+		Network:    "tcp",
+		Host:       "localhost",
+		Connection: conn,
+		Service:    service,
+	}
+
+	service.EXPECT().SendClientKillRequest(conn, "ID", "3").Times(1).Return(int64(1), nil)
+
+	n, err := client.ClientKill("ID", "3")
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+// newTimeoutClient return client of server over connection of transport with read timeout
+func newTimeoutClient(t *testing.T, server *Server, readTimeout time.Duration) *Client {
+	ctrl := gomock.NewController(t)
+	dialer := mocknetwork.NewMockDialer(ctrl)
+	sconn, cconn := net.Pipe()
+	go server.HandleConnection(sconn)
+	t.Cleanup(func() {
+		cconn.Close()
+	})
+
+	transport := &TcpTransport{
+		Network:     "tcp",
+		Host:        "localhost",
+		Port:        ":6377",
+		Dialer:      dialer,
+		ReadTimeout: readTimeout,
+	}
+	dialer.EXPECT().Dial(transport.Network, transport.Host+transport.Port, time.Duration(0)).Times(1).Return(cconn, nil)
+
+	conn, err := transport.EstablishConnection()
+	assert.Nil(t, err)
+
+	return &Client{Connection: conn, Service: service.NewService()}
+}
+
+func TestClientBLPopLongerThanReadTimeout(t *testing.T) {
+	server := newConnectionTestServer()
+	client := newTimeoutClient(t, server, 20*time.Millisecond)
+
+	go func() {
+		time.Sleep(60 * time.Millisecond)
+		pusher := newServedClient(t, server)
+		val := tlv.String("test_val")
+		pusher.RPush("test_list", &val)
+	}()
+
+	k, val, err := client.BLPop(time.Second, "test_list")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "test_list", k)
+	assert.Equal(t, tlv.String("test_val"), *val.(*tlv.String))
+
+	// Connection is still usable after the wait
+	_, err = client.Ping(nil)
+	assert.Nil(t, err)
+}
+
+func TestClientBLPopForeverLongerThanReadTimeout(t *testing.T) {
+	server := newConnectionTestServer()
+	client := newTimeoutClient(t, server, 20*time.Millisecond)
+
+	go func() {
+		time.Sleep(60 * time.Millisecond)
+		pusher := newServedClient(t, server)
+		val := tlv.String("test_val")
+		pusher.RPush("test_list", &val)
+	}()
+
+	k, _, err := client.BLPop(0, "test_list")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "test_list", k)
+}
+
+func TestClientXReadBlockTimeoutLongerThanReadTimeout(t *testing.T) {
+	server := newConnectionTestServer()
+	client := newTimeoutClient(t, server, 20*time.Millisecond)
+
+	reads, err := client.XReadBlock(60*time.Millisecond, 0, []string{"test_stream"}, []string{"$"})
+
+	assert.Nil(t, err)
+	assert.Nil(t, reads)
 }
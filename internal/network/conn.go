@@ -0,0 +1,178 @@
+package network
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+// bufferedConn batch replies of a connection until Flush. Every payload is written with
+// one Write, so under the lock pushes from other goroutines land between whole frames.
+type bufferedConn struct {
+	net.Conn
+
+	mu sync.Mutex
+	w  *bufio.Writer
+}
+
+func (c *bufferedConn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.w.Write(b)
+}
+
+func (c *bufferedConn) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.w.Flush()
+}
+
+func newBufferedConn(conn net.Conn, size int) *bufferedConn {
+	return &bufferedConn{
+		Conn: conn,
+		w:    bufio.NewWriterSize(conn, size),
+	}
+}
+
+// taggedConn echo request ID in every reply written to it. Each Write is one whole frame,
+// bare tlv written by ping is wrapped in response frame so it can carry the ID too.
+type taggedConn struct {
+	net.Conn
+	id uint32
+}
+
+func (c *taggedConn) Write(b []byte) (int, error) {
+	raw := tlv.TypeLengthValue(b)
+	res := new(payload.ResponsePayload)
+	if raw.GetType() == tlv.RequestPayloadType {
+		err := res.FromTLV(raw)
+		if err != nil {
+			return 0, err
+		}
+	} else {
+		res.Typ = raw.GetType()
+		res.Body = raw
+	}
+	res.ID = c.id
+
+	_, err := res.WriteTo(c.Conn)
+	if err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (c *taggedConn) Flush() error {
+	if f, ok := c.Conn.(payload.Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
+
+// timeoutConn bound each read and write by timeout on top of deadline set on it. Running
+// out of time in the middle of a request leave its reply unread, so connection is closed.
+type timeoutConn struct {
+	net.Conn
+	readTimeout  time.Duration
+	writeTimeout time.Duration
+
+	// Guard deadlines set on connection so cancellation is never overwritten
+	mu            sync.Mutex
+	readDeadline  time.Time
+	writeDeadline time.Time
+}
+
+func (c *timeoutConn) Read(b []byte) (int, error) {
+	if c.readTimeout > 0 {
+		c.mu.Lock()
+		err := c.Conn.SetReadDeadline(earliest(c.readDeadline, time.Now().Add(c.readTimeout)))
+		c.mu.Unlock()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := c.Conn.Read(b)
+	if isTimeout(err) {
+		c.Conn.Close()
+	}
+	return n, err
+}
+
+func (c *timeoutConn) Write(b []byte) (int, error) {
+	if c.writeTimeout > 0 {
+		c.mu.Lock()
+		err := c.Conn.SetWriteDeadline(earliest(c.writeDeadline, time.Now().Add(c.writeTimeout)))
+		c.mu.Unlock()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := c.Conn.Write(b)
+	if isTimeout(err) {
+		c.Conn.Close()
+	}
+	return n, err
+}
+
+func (c *timeoutConn) SetDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.readDeadline = t
+	c.writeDeadline = t
+	return c.Conn.SetDeadline(t)
+}
+
+func (c *timeoutConn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.readDeadline = t
+	return c.Conn.SetReadDeadline(t)
+}
+
+func (c *timeoutConn) SetWriteDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.writeDeadline = t
+	return c.Conn.SetWriteDeadline(t)
+}
+
+// withoutReadTimeout return connection waiting for reads as long as it take, for subscriber
+// waiting for messages and reader of mux waiting for replies
+func (c *timeoutConn) withoutReadTimeout() net.Conn {
+	return c.withReadTimeout(0)
+}
+
+// withReadTimeout return connection bounding each read by timeout instead, zero timeout
+// wait as long as it take
+func (c *timeoutConn) withReadTimeout(timeout time.Duration) net.Conn {
+	return &timeoutConn{
+		Conn:         c.Conn,
+		readTimeout:  timeout,
+		writeTimeout: c.writeTimeout,
+	}
+}
+
+// earliest return deadline coming first, zero deadline is no deadline
+func earliest(a time.Time, b time.Time) time.Time {
+	if a.IsZero() || (!b.IsZero() && b.Before(a)) {
+		return b
+	}
+	return a
+}
+
+func isTimeout(err error) bool {
+	var nerr net.Error
+	return errors.As(err, &nerr) && nerr.Timeout()
+}
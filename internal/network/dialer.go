@@ -3,20 +3,22 @@ package network
 import (
 	"crypto/tls"
 	"net"
+	"time"
 )
 
+// Dialer establish connection to url, zero timeout wait as long as system allow
 type Dialer interface {
-	Dial(network string, url string) (net.Conn, error)
-	SecureDial(network string, url string, conf *tls.Config) (net.Conn, error)
+	Dial(network string, url string, timeout time.Duration) (net.Conn, error)
+	SecureDial(network string, url string, conf *tls.Config, timeout time.Duration) (net.Conn, error)
 }
 
 type TcpDialer struct {
 }
 
-func (d *TcpDialer) Dial(network string, url string) (net.Conn, error) {
-	return net.Dial(network, url)
+func (d *TcpDialer) Dial(network string, url string, timeout time.Duration) (net.Conn, error) {
+	return net.DialTimeout(network, url, timeout)
 }
 
-func (d *TcpDialer) SecureDial(network string, url string, conf *tls.Config) (net.Conn, error) {
-	return tls.Dial(network, url, conf)
+func (d *TcpDialer) SecureDial(network string, url string, conf *tls.Config, timeout time.Duration) (net.Conn, error) {
+	return tls.DialWithDialer(&net.Dialer{Timeout: timeout}, network, url, conf)
 }
@@ -0,0 +1,277 @@
+package network
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/constant"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+// ErrMuxClosed is returned by requests over mux once it is closed
+var ErrMuxClosed = errors.New("Mux closed")
+
+// Mux share connection of client between goroutines. Each goroutine get a client of its own
+// whose requests are sent with an ID, and a reader goroutine hand every reply, and message
+// pushed to it once subscribed, back to the client with same ID.
+type Mux struct {
+	conn   net.Conn
+	client *Client
+
+	// Keep frames of different clients whole
+	wmu sync.Mutex
+
+	mu      sync.Mutex
+	lastID  uint32
+	streams map[uint32]*muxStream
+	err     error
+	done    chan struct{}
+}
+
+// Client return client sharing connection of mux, it is meant for one goroutine at a time.
+// Close it once done with, that only release its ID and leave connection open.
+func (m *Mux) Client() (*Client, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.err != nil {
+		return nil, m.err
+	}
+
+	// ID zero means request without ID
+	m.lastID++
+	if m.lastID == 0 {
+		m.lastID++
+	}
+
+	s := &muxStream{
+		Conn: m.conn,
+		mux:  m,
+		id:   m.lastID,
+	}
+	s.cond = sync.NewCond(&s.mu)
+	m.streams[s.id] = s
+
+	return m.client.withConn(s), nil
+}
+
+// Close close connection and fail requests still waiting for reply
+func (m *Mux) Close() error {
+	err := m.conn.Close()
+	<-m.done
+	return err
+}
+
+// Err return why mux stopped reading, nil while it still does
+func (m *Mux) Err() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.err
+}
+
+// write send requests in one write so frames of other clients never come in between
+func (m *Mux) write(reqs ...*payload.RequestPayload) error {
+	buf := new(bytes.Buffer)
+	for _, req := range reqs {
+		_, err := req.WriteTo(buf)
+		if err != nil {
+			return err
+		}
+	}
+
+	m.wmu.Lock()
+	defer m.wmu.Unlock()
+
+	_, err := m.conn.Write(buf.Bytes())
+	return err
+}
+
+func (m *Mux) remove(id uint32) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.streams, id)
+}
+
+// read route every frame to stream of its ID until connection fails
+func (m *Mux) read() {
+	defer close(m.done)
+
+	r := bufio.NewReaderSize(m.conn, constant.DefaultReadBufferSize)
+	for {
+		res := new(payload.ResponsePayload)
+		_, err := res.ReadFrom(r)
+		if err != nil {
+			m.fail(err)
+			return
+		}
+
+		m.mu.Lock()
+		s, ok := m.streams[res.ID]
+		m.mu.Unlock()
+		if !ok {
+			log.Println("mux: dropped reply to unknown request", res.ID)
+			continue
+		}
+
+		s.deliver(res)
+	}
+}
+
+func (m *Mux) fail(err error) {
+	if errors.Is(err, net.ErrClosed) {
+		err = ErrMuxClosed
+	}
+
+	m.mu.Lock()
+	m.err = err
+	streams := m.streams
+	m.streams = make(map[uint32]*muxStream)
+	m.mu.Unlock()
+
+	for _, s := range streams {
+		s.fail(err)
+	}
+}
+
+// muxStream is connection of one client of mux, it read replies to its own requests only
+type muxStream struct {
+	// Shared connection, only used for its address
+	net.Conn
+
+	mux *Mux
+	id  uint32
+
+	mu   sync.Mutex
+	cond *sync.Cond
+	// Commands still waiting for reply, bare tlv reply of ping is unwrapped
+	pending []uint8
+	buf     bytes.Buffer
+	err     error
+	// Read give up once deadline pass, timer wake it up then
+	deadline time.Time
+	timer    *time.Timer
+}
+
+// Write tag requests with ID of stream and send them, b must hold whole request frames.
+// Pipeline write all of its requests at once.
+func (s *muxStream) Write(b []byte) (int, error) {
+	var reqs []*payload.RequestPayload
+	r := bytes.NewReader(b)
+	for r.Len() > 0 {
+		req := new(payload.RequestPayload)
+		_, err := req.ReadFrom(r)
+		if err != nil {
+			return 0, err
+		}
+		req.ID = s.id
+		reqs = append(reqs, req)
+	}
+
+	s.mu.Lock()
+	if s.err != nil {
+		s.mu.Unlock()
+		return 0, s.err
+	}
+	for _, req := range reqs {
+		s.pending = append(s.pending, req.Cmd)
+	}
+	s.mu.Unlock()
+
+	err := s.mux.write(reqs...)
+	if err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (s *muxStream) Read(b []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for s.buf.Len() == 0 && s.err == nil {
+		if !s.deadline.IsZero() && !time.Now().Before(s.deadline) {
+			return 0, os.ErrDeadlineExceeded
+		}
+		s.cond.Wait()
+	}
+	if s.buf.Len() > 0 {
+		return s.buf.Read(b)
+	}
+	return 0, s.err
+}
+
+// Close release ID of stream, connection of mux is left open
+func (s *muxStream) Close() error {
+	s.mux.remove(s.id)
+	s.fail(net.ErrClosed)
+	return nil
+}
+
+func (s *muxStream) SetDeadline(t time.Time) error {
+	return s.SetReadDeadline(t)
+}
+
+func (s *muxStream) SetReadDeadline(t time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	s.deadline = t
+	if !t.IsZero() {
+		s.timer = time.AfterFunc(time.Until(t), func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+
+			s.cond.Broadcast()
+		})
+	}
+	return nil
+}
+
+// SetWriteDeadline is left to connection shared by mux, write timeout of transport bound it
+func (s *muxStream) SetWriteDeadline(t time.Time) error {
+	return nil
+}
+
+// deliver queue frame for reader of stream as it would be read from a connection of its own
+func (s *muxStream) deliver(res *payload.ResponsePayload) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Pushed message is not a reply to any pending command
+	ping := false
+	if res.Typ != tlv.MsgType && len(s.pending) > 0 {
+		ping = s.pending[0] == payload.PingCmd
+		s.pending = s.pending[1:]
+	}
+
+	res.ID = 0
+	if ping {
+		s.buf.Write(res.Body)
+	} else {
+		res.WriteTo(&s.buf)
+	}
+	s.cond.Broadcast()
+}
+
+func (s *muxStream) fail(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err == nil {
+		s.err = err
+	}
+	s.cond.Broadcast()
+}
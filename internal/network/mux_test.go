@@ -0,0 +1,134 @@
+package network
+
+import (
+	"net"
+	"sync"
+	"testing"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/service"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+	"github.com/stretchr/testify/assert"
+)
+
+func newMuxTestClient(t *testing.T) *Mux {
+	server := newConnectionTestServer()
+	sconn, cconn := net.Pipe()
+	go server.HandleConnection(sconn)
+
+	client := &Client{Connection: cconn, Service: service.NewService()}
+	return client.Mux()
+}
+
+func TestMuxConcurrentRequests(t *testing.T) {
+	mux := newMuxTestClient(t)
+	defer mux.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			c, err := mux.Client()
+			assert.Nil(t, err)
+			defer c.Close()
+
+			for j := 0; j < 10; j++ {
+				_, err := c.Incr("test_key")
+				assert.Nil(t, err)
+			}
+			pong, err := c.Ping(nil)
+			assert.Nil(t, err)
+			assert.Equal(t, "PONG", pong)
+		}()
+	}
+	wg.Wait()
+
+	c, err := mux.Client()
+	assert.Nil(t, err)
+	defer c.Close()
+
+	val, err := c.Get("test_key")
+	assert.Nil(t, err)
+	expected := tlv.Int64(100)
+	assert.Equal(t, &expected, val)
+}
+
+func TestMuxPipeline(t *testing.T) {
+	mux := newMuxTestClient(t)
+	defer mux.Close()
+
+	c, err := mux.Client()
+	assert.Nil(t, err)
+	defer c.Close()
+
+	v := tlv.String("test_val")
+	results, err := c.Pipeline().Do(func(c *Client) (any, error) {
+		return c.Set("test_pipe", &v)
+	}).Do(func(c *Client) (any, error) {
+		return c.Incr("test_pipe_counter")
+	}).Do(func(c *Client) (any, error) {
+		return c.Get("test_pipe")
+	}).Exec()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(results))
+	assert.Equal(t, "OK", results[0].Val)
+	assert.Equal(t, int64(1), results[1].Val)
+	assert.Equal(t, &v, results[2].Val)
+
+	results, err = c.TxPipeline().Do(func(c *Client) (any, error) {
+		return c.Incr("test_pipe_counter")
+	}).Do(func(c *Client) (any, error) {
+		return c.Incr("test_pipe_counter")
+	}).Exec()
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), results[1].Val)
+}
+
+func TestMuxRoutePushToSubscriber(t *testing.T) {
+	mux := newMuxTestClient(t)
+	defer mux.Close()
+
+	subc, err := mux.Client()
+	assert.Nil(t, err)
+	sub, err := subc.Sub("test_topic")
+	assert.Nil(t, err)
+
+	pubc, err := mux.Client()
+	assert.Nil(t, err)
+	defer pubc.Close()
+
+	receivers, err := pubc.Pub("test_topic", "test_msg")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, receivers)
+	n, err := pubc.Incr("test_key")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), n)
+
+	msg, err := sub.NextMessage()
+	assert.Nil(t, err)
+	assert.Equal(t, "test_msg", msg.String())
+
+	// Closing subscriber client leave connection of mux open
+	subc.Close()
+	_, err = sub.NextMessage()
+	assert.NotNil(t, err)
+	_, err = pubc.Incr("test_key")
+	assert.Nil(t, err)
+}
+
+func TestMuxClose(t *testing.T) {
+	mux := newMuxTestClient(t)
+	c, err := mux.Client()
+	assert.Nil(t, err)
+
+	mux.Close()
+
+	_, err = c.Incr("test_key")
+	assert.NotNil(t, err)
+	_, err = mux.Client()
+	assert.NotNil(t, err)
+	assert.NotNil(t, mux.Err())
+}
@@ -0,0 +1,328 @@
+package network
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+// errPipelined is what queued command read while its request is only being encoded
+var errPipelined = errors.New("Reply is read once pipeline is executed")
+
+// ErrTxAborted is returned by Exec of transaction when a watched key changed, no queued
+// command was run then
+var ErrTxAborted = errors.New("Transaction aborted, watched key changed")
+
+// PipelineResult is reply of one pipelined command, Val hold what the queued fn returned
+type PipelineResult struct {
+	Val any
+	Err error
+}
+
+// Pipeline queue commands of client and send them in one write on Exec, replies are then
+// read in order. Commands run against a client of their own so any Client method can be queued.
+type Pipeline struct {
+	client *Client
+	cmds   []func(c *Client) (any, error)
+	// multi wrap commands in MULTI and EXEC so server run them as one transaction
+	multi bool
+}
+
+// Do queue fn, it is run once to encode its request and once more to read its reply
+func (p *Pipeline) Do(fn func(c *Client) (any, error)) *Pipeline {
+	p.cmds = append(p.cmds, fn)
+	return p
+}
+
+func (p *Pipeline) Len() int {
+	return len(p.cmds)
+}
+
+func (p *Pipeline) Ping(msg *string) *Pipeline {
+	return p.Do(func(c *Client) (any, error) {
+		return c.Ping(msg)
+	})
+}
+
+func (p *Pipeline) Get(k string) *Pipeline {
+	return p.Do(func(c *Client) (any, error) {
+		return c.Get(k)
+	})
+}
+
+func (p *Pipeline) Set(k string, v tlv.TLVCompatible) *Pipeline {
+	return p.Do(func(c *Client) (any, error) {
+		return c.Set(k, v)
+	})
+}
+
+func (p *Pipeline) SetEx(k string, v tlv.TLVCompatible, ttl time.Duration) *Pipeline {
+	return p.Do(func(c *Client) (any, error) {
+		return c.SetEx(k, v, ttl)
+	})
+}
+
+func (p *Pipeline) Expire(k string, ttl time.Duration) *Pipeline {
+	return p.Do(func(c *Client) (any, error) {
+		return c.Expire(k, ttl)
+	})
+}
+
+func (p *Pipeline) Del(keys ...string) *Pipeline {
+	return p.Do(func(c *Client) (any, error) {
+		return c.Del(keys...)
+	})
+}
+
+func (p *Pipeline) Exists(keys ...string) *Pipeline {
+	return p.Do(func(c *Client) (any, error) {
+		return c.Exists(keys...)
+	})
+}
+
+func (p *Pipeline) Incr(k string) *Pipeline {
+	return p.Do(func(c *Client) (any, error) {
+		return c.Incr(k)
+	})
+}
+
+func (p *Pipeline) IncrBy(k string, delta int64) *Pipeline {
+	return p.Do(func(c *Client) (any, error) {
+		return c.IncrBy(k, delta)
+	})
+}
+
+func (p *Pipeline) LPush(k string, vals ...tlv.TLVCompatible) *Pipeline {
+	return p.Do(func(c *Client) (any, error) {
+		return c.LPush(k, vals...)
+	})
+}
+
+func (p *Pipeline) RPush(k string, vals ...tlv.TLVCompatible) *Pipeline {
+	return p.Do(func(c *Client) (any, error) {
+		return c.RPush(k, vals...)
+	})
+}
+
+func (p *Pipeline) HSet(k string, fields map[string]tlv.TLVCompatible) *Pipeline {
+	return p.Do(func(c *Client) (any, error) {
+		return c.HSet(k, fields)
+	})
+}
+
+func (p *Pipeline) HGet(k string, field string) *Pipeline {
+	return p.Do(func(c *Client) (any, error) {
+		return c.HGet(k, field)
+	})
+}
+
+func (p *Pipeline) SAdd(k string, members ...string) *Pipeline {
+	return p.Do(func(c *Client) (any, error) {
+		return c.SAdd(k, members...)
+	})
+}
+
+func (p *Pipeline) ZAdd(k string, members map[string]float64) *Pipeline {
+	return p.Do(func(c *Client) (any, error) {
+		return c.ZAdd(k, members)
+	})
+}
+
+func (p *Pipeline) Pub(topic string, msg string) *Pipeline {
+	return p.Do(func(c *Client) (any, error) {
+		return c.Pub(topic, msg)
+	})
+}
+
+// Exec send every queued command in one write and return their results in order. Command
+// failing before its request is written get its error without waiting for a reply. Error
+// is only returned when connection fails, results read before that are kept.
+func (p *Pipeline) Exec() ([]PipelineResult, error) {
+	cmds := p.cmds
+	p.cmds = nil
+
+	if p.multi {
+		return p.execMulti(cmds)
+	}
+
+	results := make([]PipelineResult, len(cmds))
+	sent := make([]bool, len(cmds))
+
+	buf := new(bytes.Buffer)
+	for i, cmd := range cmds {
+		n := buf.Len()
+		_, err := cmd(p.client.withConn(&pipelineConn{Conn: p.client.Connection, w: buf}))
+		sent[i] = buf.Len() > n
+		if !sent[i] {
+			results[i].Err = err
+		}
+	}
+
+	if buf.Len() > 0 {
+		_, err := p.client.Connection.Write(buf.Bytes())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r := &stickyReader{r: p.client.Connection}
+	for i, cmd := range cmds {
+		if !sent[i] {
+			continue
+		}
+
+		val, err := cmd(p.client.withConn(&pipelineConn{Conn: p.client.Connection, r: r, w: io.Discard}))
+		if r.err != nil {
+			return results[:i], r.err
+		}
+		results[i] = PipelineResult{Val: val, Err: err}
+	}
+
+	return results, nil
+}
+
+// execMulti send MULTI, queued commands and EXEC in one write. Commands are run by server
+// once EXEC is read, so their replies come from EXEC array instead of one reply each.
+func (p *Pipeline) execMulti(cmds []func(c *Client) (any, error)) ([]PipelineResult, error) {
+	results := make([]PipelineResult, len(cmds))
+	sent := make([]bool, len(cmds))
+
+	buf := new(bytes.Buffer)
+	wconn := &pipelineConn{Conn: p.client.Connection, w: buf}
+	p.client.Service.SendMultiRequest(wconn)
+	for i, cmd := range cmds {
+		n := buf.Len()
+		_, err := cmd(p.client.withConn(wconn))
+		sent[i] = buf.Len() > n
+		if !sent[i] {
+			results[i].Err = err
+		}
+	}
+	p.client.Service.SendExecRequest(wconn)
+
+	_, err := p.client.Connection.Write(buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	r := &stickyReader{r: p.client.Connection}
+	rconn := &pipelineConn{Conn: p.client.Connection, r: r, w: io.Discard}
+	_, multiErr := p.client.Service.SendMultiRequest(rconn)
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	// Command refused while queued make EXEC discard transaction
+	for i := range cmds {
+		if !sent[i] {
+			continue
+		}
+
+		_, err := readReply(r)
+		if r.err != nil {
+			return nil, r.err
+		}
+		results[i].Err = err
+	}
+
+	replies, err := p.client.Service.SendExecRequest(rconn)
+	if r.err != nil {
+		return nil, r.err
+	}
+	if multiErr != nil {
+		return nil, multiErr
+	}
+	if err != nil {
+		return results, err
+	}
+	if replies == nil {
+		return nil, ErrTxAborted
+	}
+
+	// Each reply is read by its command again as if it was replied alone
+	for i, cmd := range cmds {
+		if !sent[i] {
+			continue
+		}
+		if len(replies) == 0 {
+			return results, errors.New("Missing reply of queued command")
+		}
+
+		reply := replies[0]
+		replies = replies[1:]
+		res := payload.ResponsePayload{Typ: reply.GetType()}
+		if res.Typ != tlv.EmptyType {
+			res.Body = reply
+		}
+		raw, err := res.ToTLV()
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		val, err := cmd(p.client.withConn(&pipelineConn{Conn: p.client.Connection, r: bytes.NewReader(raw), w: io.Discard}))
+		results[i] = PipelineResult{Val: val, Err: err}
+	}
+
+	return results, nil
+}
+
+// readReply read one response, error reply is returned as typed *tlv.Error
+func readReply(r io.Reader) (*payload.ResponsePayload, error) {
+	resp, err := payload.ReadResponse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Typ == tlv.ErrorType {
+		tlvErr := new(tlv.Error)
+		err = tlvErr.FromTLV(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return nil, tlvErr
+	}
+
+	return resp, nil
+}
+
+// pipelineConn write requests to w and read replies from r, read fail while r is nil
+type pipelineConn struct {
+	net.Conn
+	r io.Reader
+	w io.Writer
+}
+
+func (c *pipelineConn) Read(b []byte) (int, error) {
+	if c.r == nil {
+		return 0, errPipelined
+	}
+	return c.r.Read(b)
+}
+
+func (c *pipelineConn) Write(b []byte) (int, error) {
+	return c.w.Write(b)
+}
+
+// stickyReader keep first error of r, replies after it can no longer be matched to commands
+type stickyReader struct {
+	r   io.Reader
+	err error
+}
+
+func (s *stickyReader) Read(b []byte) (int, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+
+	n, err := s.r.Read(b)
+	if err != nil {
+		s.err = err
+	}
+	return n, err
+}
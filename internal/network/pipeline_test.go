@@ -0,0 +1,113 @@
+package network
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+	"github.com/stretchr/testify/assert"
+)
+
+// serveBatch read n requests before replying to any, so client only get replies when it
+// send them all without waiting
+func serveBatch(t *testing.T, conn net.Conn, n int, replies []tlv.TLVCompatible) {
+	conn.SetDeadline(time.Now().Add(time.Second))
+	for i := 0; i < n; i++ {
+		req := new(payload.RequestPayload)
+		_, err := req.ReadFrom(conn)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+	}
+
+	for _, reply := range replies {
+		raw, _ := reply.ToTLV()
+		res := payload.ResponsePayload{Typ: raw.GetType(), Body: raw}
+		res.WriteTo(conn)
+	}
+}
+
+func TestPipelineExec(t *testing.T) {
+	sconn, cconn := net.Pipe()
+	defer cconn.Close()
+	ok, one, receivers := tlv.String("OK"), tlv.String("1"), tlv.Int64(1)
+	go serveBatch(t, sconn, 3, []tlv.TLVCompatible{&ok, &one, &receivers})
+
+	client := &Client{Connection: cconn, Service: service.NewService()}
+	val := tlv.String("test_val")
+	p := client.Pipeline().Set("test_key", &val).Expire("test_key", time.Second).Pub("test_topic", "test_msg")
+	assert.Equal(t, 3, p.Len())
+
+	results, err := p.Exec()
+
+	assert.Nil(t, err)
+	assert.Equal(t, []PipelineResult{
+		{Val: "OK"},
+		{Val: true},
+		{Val: 1},
+	}, results)
+	assert.Equal(t, 0, p.Len())
+}
+
+func TestPipelineExecErrorBeforeWrite(t *testing.T) {
+	sconn, cconn := net.Pipe()
+	defer cconn.Close()
+	receivers := tlv.Int64(1)
+	go serveBatch(t, sconn, 1, []tlv.TLVCompatible{&receivers})
+
+	client := &Client{Connection: cconn, Service: service.NewService()}
+	mockerr := errors.New("Some encode error")
+	results, err := client.Pipeline().
+		Do(func(c *Client) (any, error) {
+			return nil, mockerr
+		}).
+		Pub("test_topic", "test_msg").
+		Exec()
+
+	assert.Nil(t, err)
+	assert.Equal(t, []PipelineResult{
+		{Err: mockerr},
+		{Val: 1},
+	}, results)
+}
+
+func TestPipelineExecConnectionClosed(t *testing.T) {
+	sconn, cconn := net.Pipe()
+	defer cconn.Close()
+	receivers := tlv.Int64(1)
+	go func() {
+		serveBatch(t, sconn, 2, []tlv.TLVCompatible{&receivers})
+		sconn.Close()
+	}()
+
+	client := &Client{Connection: cconn, Service: service.NewService()}
+	results, err := client.Pipeline().Pub("test_topic", "a").Pub("test_topic", "b").Exec()
+
+	assert.NotNil(t, err)
+	assert.Equal(t, []PipelineResult{{Val: 1}}, results)
+}
+
+func TestTxPipelineExec(t *testing.T) {
+	sconn, cconn := net.Pipe()
+	defer cconn.Close()
+	ok, queued := tlv.String("OK"), tlv.String("QUEUED")
+	one, receivers := tlv.Int64(1), tlv.Int64(2)
+	rawone, _ := one.ToTLV()
+	rawreceivers, _ := receivers.ToTLV()
+	replies := tlv.Array{rawone, rawreceivers}
+	go serveBatch(t, sconn, 4, []tlv.TLVCompatible{&ok, &queued, &queued, &replies})
+
+	client := &Client{Connection: cconn, Service: service.NewService()}
+	results, err := client.TxPipeline().Incr("test_key").Pub("test_topic", "test_msg").Exec()
+
+	assert.Nil(t, err)
+	assert.Equal(t, []PipelineResult{
+		{Val: int64(1)},
+		{Val: 2},
+	}, results)
+}
@@ -0,0 +1,341 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/constant"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+// ErrPoolClosed is returned by Acquire once pool is closed
+var ErrPoolClosed = errors.New("Pool closed")
+
+// PoolOptions limit connections of pool, zero leave a limit unset
+type PoolOptions struct {
+	// Idle connections kept open in advance
+	MinIdle int
+	// Connections open at once, idle or in use
+	MaxOpen int
+	// Idle connection unused for longer is closed
+	IdleTimeout time.Duration
+	// Connection open for longer is closed once returned
+	MaxLifetime time.Duration
+}
+
+type PoolStats struct {
+	Open  int
+	Idle  int
+	InUse int
+
+	// Acquire served by idle connection, by new one and ones given up waiting for free slot
+	Hits     uint64
+	Misses   uint64
+	Timeouts uint64
+	// Connections closed for failing PING, being idle or open for too long
+	Stale uint64
+}
+
+type idleClient struct {
+	client *Client
+	since  time.Time
+}
+
+// Pool share connections established by Transport between goroutines. Client acquired is
+// used by one goroutine until released, idle client is checked with PING before reused.
+type Pool struct {
+	Transport Transporter
+	Service   service.IService
+
+	opts PoolOptions
+
+	mu sync.Mutex
+	// Open connections and when they were established
+	conns map[*Client]time.Time
+	// Most recently released last
+	idle []idleClient
+	// Slots taken by connections being established
+	dialing int
+	// Closed and replaced whenever connection is released or closed
+	wake   chan struct{}
+	closed bool
+	stats  PoolStats
+
+	quit chan struct{}
+}
+
+// Acquire return idle client passing PING or establish new one, waiting for free slot while
+// MaxOpen connections are open. Client must be given back with Release.
+func (p *Pool) Acquire(ctx context.Context) (*Client, error) {
+	for {
+		p.mu.Lock()
+		if p.closed {
+			p.mu.Unlock()
+			return nil, ErrPoolClosed
+		}
+
+		if n := len(p.idle); n > 0 {
+			ic := p.idle[n-1]
+			p.idle = p.idle[:n-1]
+			expired := p.expired(ic, time.Now())
+			p.mu.Unlock()
+
+			if expired {
+				p.discard(ic.client, true)
+				continue
+			}
+
+			_, err := ic.client.PingContext(ctx, nil)
+			if err != nil {
+				p.discard(ic.client, true)
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				continue
+			}
+
+			p.mu.Lock()
+			p.stats.Hits++
+			p.mu.Unlock()
+			return ic.client, nil
+		}
+
+		if p.opts.MaxOpen <= 0 || len(p.conns)+p.dialing < p.opts.MaxOpen {
+			p.dialing++
+			p.stats.Misses++
+			p.mu.Unlock()
+
+			return p.dial()
+		}
+
+		wake := p.wake
+		p.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			p.mu.Lock()
+			p.stats.Timeouts++
+			p.mu.Unlock()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Release give client back to pool, it is closed instead once pool is closed or it is open
+// for longer than MaxLifetime
+func (p *Pool) Release(c *Client) {
+	p.mu.Lock()
+	createdAt, ok := p.conns[c]
+	if !ok {
+		p.mu.Unlock()
+		return
+	}
+
+	now := time.Now()
+	closed := p.closed
+	if closed || (p.opts.MaxLifetime > 0 && now.Sub(createdAt) >= p.opts.MaxLifetime) {
+		p.mu.Unlock()
+		p.discard(c, !closed)
+		return
+	}
+
+	p.idle = append(p.idle, idleClient{client: c, since: now})
+	p.notify()
+	p.mu.Unlock()
+}
+
+// Do run fn with client acquired from pool and bound by ctx, see Client.DoContext
+func (p *Pool) Do(ctx context.Context, fn func(c *Client) error) error {
+	c, err := p.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer p.Release(c)
+
+	return c.DoContext(ctx, fn)
+}
+
+func (p *Pool) Get(ctx context.Context, k string) (tlv.TLVCompatible, error) {
+	var resp tlv.TLVCompatible
+	err := p.Do(ctx, func(c *Client) (err error) {
+		resp, err = c.Get(k)
+		return err
+	})
+	return resp, err
+}
+
+func (p *Pool) Set(ctx context.Context, k string, v tlv.TLVCompatible) (string, error) {
+	var resp string
+	err := p.Do(ctx, func(c *Client) (err error) {
+		resp, err = c.Set(k, v)
+		return err
+	})
+	return resp, err
+}
+
+func (p *Pool) Pub(ctx context.Context, topic string, msg string) (int, error) {
+	var resp int
+	err := p.Do(ctx, func(c *Client) (err error) {
+		resp, err = c.Pub(topic, msg)
+		return err
+	})
+	return resp, err
+}
+
+func (p *Pool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := p.stats
+	stats.Open = len(p.conns)
+	stats.Idle = len(p.idle)
+	stats.InUse = stats.Open - stats.Idle
+	return stats
+}
+
+// Close close idle connections at once and ones in use as they are released
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return ErrPoolClosed
+	}
+	p.closed = true
+	idle := p.idle
+	p.idle = nil
+	p.notify()
+	p.mu.Unlock()
+
+	close(p.quit)
+	for _, ic := range idle {
+		p.discard(ic.client, false)
+	}
+	return nil
+}
+
+// dial establish connection in slot taken by caller
+func (p *Pool) dial() (*Client, error) {
+	conn, err := p.Transport.EstablishConnection()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.dialing--
+	if err != nil {
+		p.notify()
+		return nil, err
+	}
+
+	c := &Client{
+		Transport:  p.Transport,
+		Connection: conn,
+		Service:    p.Service,
+	}
+	p.conns[c] = time.Now()
+	return c, nil
+}
+
+// discard close client and free its slot, stale tell whether it count in stats
+func (p *Pool) discard(c *Client, stale bool) {
+	if c.Connection != nil {
+		c.Connection.Close()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.conns, c)
+	if stale {
+		p.stats.Stale++
+	}
+	p.notify()
+}
+
+// notify wake up every Acquire waiting for free slot, mu must be held
+func (p *Pool) notify() {
+	close(p.wake)
+	p.wake = make(chan struct{})
+}
+
+// expired tell whether idle client should be closed rather than reused, mu must be held
+func (p *Pool) expired(ic idleClient, now time.Time) bool {
+	if p.opts.IdleTimeout > 0 && now.Sub(ic.since) >= p.opts.IdleTimeout {
+		return true
+	}
+	return p.opts.MaxLifetime > 0 && now.Sub(p.conns[ic.client]) >= p.opts.MaxLifetime
+}
+
+// maintain close expired idle connections and open new ones until MinIdle are idle
+func (p *Pool) maintain() {
+	ticker := time.NewTicker(constant.DefaultPoolMaintainInterval)
+	defer ticker.Stop()
+
+	for {
+		p.closeExpired(time.Now())
+		p.fillIdle()
+
+		select {
+		case <-p.quit:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Pool) closeExpired(now time.Time) {
+	p.mu.Lock()
+	var expired []*Client
+	idle := p.idle[:0]
+	for _, ic := range p.idle {
+		if p.expired(ic, now) {
+			expired = append(expired, ic.client)
+			continue
+		}
+		idle = append(idle, ic)
+	}
+	p.idle = idle
+	p.mu.Unlock()
+
+	for _, c := range expired {
+		p.discard(c, true)
+	}
+}
+
+func (p *Pool) fillIdle() {
+	for {
+		p.mu.Lock()
+		full := len(p.idle)+p.dialing >= p.opts.MinIdle ||
+			(p.opts.MaxOpen > 0 && len(p.conns)+p.dialing >= p.opts.MaxOpen)
+		if p.closed || full {
+			p.mu.Unlock()
+			return
+		}
+		p.dialing++
+		p.mu.Unlock()
+
+		c, err := p.dial()
+		if err != nil {
+			log.Println("pool:", err)
+			return
+		}
+		p.Release(c)
+	}
+}
+
+func NewPool(transport Transporter, opts PoolOptions) *Pool {
+	p := &Pool{
+		Transport: transport,
+		Service:   &service.Service{},
+		opts:      opts,
+		conns:     make(map[*Client]time.Time),
+		wake:      make(chan struct{}),
+		quit:      make(chan struct{}),
+	}
+	go p.maintain()
+	return p
+}
@@ -0,0 +1,179 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+	"github.com/stretchr/testify/assert"
+)
+
+// addrConn is pipe with address of its own, server tell subscribers apart by address
+type addrConn struct {
+	net.Conn
+	addr string
+}
+
+func (c *addrConn) RemoteAddr() net.Addr {
+	return &net.UnixAddr{Name: c.addr, Net: "pipe"}
+}
+
+// serverTransport connect to server over in memory pipe
+type serverTransport struct {
+	server *Server
+	dials  int32
+	err    error
+}
+
+func (t *serverTransport) GetListener() (net.Listener, error) {
+	return nil, errors.New("Not supported")
+}
+
+func (t *serverTransport) EstablishConnection() (net.Conn, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+
+	n := atomic.AddInt32(&t.dials, 1)
+	sconn, cconn := net.Pipe()
+	go t.server.HandleConnection(&addrConn{Conn: sconn, addr: fmt.Sprint("pipe-", n)})
+	return cconn, nil
+}
+
+func newTestPool(t *testing.T, opts PoolOptions) (*Pool, *serverTransport) {
+	transport := &serverTransport{server: newConnectionTestServer()}
+	pool := NewPool(transport, opts)
+	t.Cleanup(func() {
+		pool.Close()
+	})
+	return pool, transport
+}
+
+func TestPoolSetGetPub(t *testing.T) {
+	pool, transport := newTestPool(t, PoolOptions{})
+	ctx := context.Background()
+
+	val := tlv.String("test_val")
+	resp, err := pool.Set(ctx, "test_key", &val)
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", resp)
+
+	got, err := pool.Get(ctx, "test_key")
+	assert.Nil(t, err)
+	assert.Equal(t, &val, got)
+
+	receivers, err := pool.Pub(ctx, "test_topic", "test_msg")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, receivers)
+
+	// One connection is enough when requests come one after another
+	assert.Equal(t, int32(1), atomic.LoadInt32(&transport.dials))
+	assert.Equal(t, PoolStats{Open: 1, Idle: 1, Hits: 2, Misses: 1}, pool.Stats())
+}
+
+func TestPoolMaxOpen(t *testing.T) {
+	pool, _ := newTestPool(t, PoolOptions{MaxOpen: 1})
+
+	c, err := pool.Acquire(context.Background())
+	assert.Nil(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = pool.Acquire(ctx)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	// Waiting Acquire get client once it is released
+	acquired := make(chan *Client)
+	go func() {
+		c, err := pool.Acquire(context.Background())
+		assert.Nil(t, err)
+		acquired <- c
+	}()
+	time.Sleep(10 * time.Millisecond)
+	pool.Release(c)
+
+	assert.Equal(t, c, <-acquired)
+	assert.Equal(t, PoolStats{Open: 1, InUse: 1, Hits: 1, Misses: 1, Timeouts: 1}, pool.Stats())
+}
+
+func TestPoolHealthCheck(t *testing.T) {
+	pool, transport := newTestPool(t, PoolOptions{})
+
+	c, err := pool.Acquire(context.Background())
+	assert.Nil(t, err)
+	c.Connection.Close()
+	pool.Release(c)
+
+	// Broken connection fail PING and is replaced
+	c2, err := pool.Acquire(context.Background())
+	assert.Nil(t, err)
+	assert.NotEqual(t, c, c2)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&transport.dials))
+	assert.Equal(t, uint64(1), pool.Stats().Stale)
+}
+
+func TestPoolIdleTimeoutAndMaxLifetime(t *testing.T) {
+	pool, _ := newTestPool(t, PoolOptions{IdleTimeout: 10 * time.Millisecond})
+
+	c, err := pool.Acquire(context.Background())
+	assert.Nil(t, err)
+	pool.Release(c)
+	time.Sleep(20 * time.Millisecond)
+
+	c2, err := pool.Acquire(context.Background())
+	assert.Nil(t, err)
+	assert.NotEqual(t, c, c2)
+
+	pool, _ = newTestPool(t, PoolOptions{MaxLifetime: 10 * time.Millisecond})
+
+	c, err = pool.Acquire(context.Background())
+	assert.Nil(t, err)
+	time.Sleep(20 * time.Millisecond)
+	pool.Release(c)
+
+	assert.Equal(t, PoolStats{Misses: 1, Stale: 1}, pool.Stats())
+}
+
+func TestPoolMinIdle(t *testing.T) {
+	pool, _ := newTestPool(t, PoolOptions{MinIdle: 2, MaxOpen: 3})
+
+	deadline := time.Now().Add(time.Second)
+	for pool.Stats().Idle < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	assert.Equal(t, PoolStats{Open: 2, Idle: 2}, pool.Stats())
+}
+
+func TestPoolClose(t *testing.T) {
+	pool, _ := newTestPool(t, PoolOptions{})
+
+	c, err := pool.Acquire(context.Background())
+	assert.Nil(t, err)
+
+	assert.Nil(t, pool.Close())
+	_, err = pool.Acquire(context.Background())
+	assert.Equal(t, ErrPoolClosed, err)
+
+	// Client in use is closed once released
+	pool.Release(c)
+	assert.Equal(t, 0, pool.Stats().Open)
+}
+
+func TestPoolDialError(t *testing.T) {
+	pool, transport := newTestPool(t, PoolOptions{MaxOpen: 1})
+	transport.err = errors.New("Some dial error")
+
+	_, err := pool.Acquire(context.Background())
+	assert.Equal(t, transport.err, err)
+
+	// Slot of failed dial is free again
+	transport.err = nil
+	_, err = pool.Acquire(context.Background())
+	assert.Nil(t, err)
+}
@@ -1,9 +1,14 @@
 package network
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"io"
 	"log"
 	"net"
+	"os"
+	"sync"
 	"time"
 
 	"bitbucket.org/non-pn/mini-redis-go/internal/constant"
@@ -16,26 +21,63 @@ import (
 
 // type RequestHandler func(ctx *RequestContext, arg ...any) error
 
+// ServerOptions configure persistence and subscribers of server created by NewServer
+type ServerOptions struct {
+	// Append only file, empty path leave it disabled
+	AOFPath        string
+	AOFFsyncPolicy db.FsyncPolicy
+	// Snapshot loaded on start and written when any of SavePolicies is met, empty path
+	// leave it disabled
+	SnapshotPath string
+	SavePolicies []db.SavePolicy
+	// Pushes queued for each subscriber and what happen once queue is full, zero size use
+	// default one
+	SubscriberQueueSize      int
+	SubscriberOverflowPolicy model.OverflowPolicy
+}
+
+// ErrServerClosed is returned by Start once server is shut down
+var ErrServerClosed = errors.New("Server closed")
+
 type IServer interface {
 	Start() error
 	Stop() error
+	Shutdown(ctx context.Context) error
 	HandleConnection(conn net.Conn) error
 }
 
 type Server struct {
-	Port        string
-	Transport   Transporter
-	Listener    net.Listener
-	Connections []net.Conn
-	Service     service.IService
-	RedisDb     *db.KVStore[[]byte]
-	PubsubDb    *db.KVStore[*model.Topic[*tlv.String]]
+	Port      string
+	Transport Transporter
+	Listener  net.Listener
+	Clients   *model.ClientRegistry
+	Service   service.IService
+	RedisDb   *db.KVStore[*model.Value]
+	PubsubDb  *db.KVStore[*model.Topic[*tlv.String]]
+	PatternDb *db.KVStore[*model.Topic[*tlv.String]]
+
+	quit chan struct{}
+	opts ServerOptions
+	// Cancelled on shutdown so blocking requests let go
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// Guard shutdown state
+	mu      sync.Mutex
+	closing bool
+	active  int
 }
 
 func (s *Server) Start() error {
+	go s.sweepExpiredKeys()
+	go s.saveByPolicy()
+
 	for {
 		c, err := s.Listener.Accept()
 		if err != nil {
+			if s.isClosing() {
+				return ErrServerClosed
+			}
 			return err
 		}
 
@@ -44,67 +86,367 @@ func (s *Server) Start() error {
 	}
 }
 
+// Stop close server at once without waiting for in-flight requests
 func (s *Server) Stop() error {
-	for _, conn := range s.Connections {
-		err := conn.Close()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := s.Shutdown(ctx)
+	if err == context.Canceled {
+		return nil
+	}
+	return err
+}
+
+// Shutdown stop accepting connections and wait for in-flight requests until ctx is done.
+// Requests arriving meanwhile are replied with shutting down error and blocking ones are
+// let go. Connections are closed after that and keyspace is flushed to disk one last time.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closing {
+		s.mu.Unlock()
+		return ErrServerClosed
+	}
+	s.closing = true
+	s.mu.Unlock()
+
+	if s.Listener != nil {
+		err := s.Listener.Close()
 		if err != nil {
 			return err
 		}
 	}
 
-	if s.Listener != nil {
-		err := s.Listener.Close()
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	drainErr := s.drain(ctx)
+
+	if s.Clients != nil {
+		for _, c := range s.Clients.List() {
+			c.Close()
+		}
+	}
+
+	if s.quit != nil {
+		close(s.quit)
+	}
+
+	err := s.flush()
+	if err != nil {
+		return err
+	}
+
+	return drainErr
+}
+
+func (s *Server) isClosing() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.closing
+}
+
+// drain wait until no request is being handled or ctx is done
+func (s *Server) drain(ctx context.Context) error {
+	ticker := time.NewTicker(constant.ShutdownPollInterval)
+	defer ticker.Stop()
+
+	for {
+		s.mu.Lock()
+		active := s.active
+		s.mu.Unlock()
+		if active == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// flush write final snapshot when keyspace changed since last one and close append only file
+func (s *Server) flush() error {
+	if s.RedisDb == nil {
+		return nil
+	}
+
+	if s.RedisDb.SnapshotPath != "" && s.RedisDb.Dirty() > 0 {
+		err := s.RedisDb.Save()
 		if err != nil {
 			return err
 		}
 	}
 
+	err := s.RedisDb.CloseAOF()
+	if err != nil && err != db.ErrAOFNotEnabled {
+		return err
+	}
+
 	return nil
 }
 
+// HandleConnection register client of conn and handle its requests one after another until
+// it disconnect. Replies are buffered and flushed once no request is left to handle, so
+// pipelined requests are answered in few writes. Client is cleaned up on return.
 func (s *Server) HandleConnection(conn net.Conn) error {
-	s.Connections = append(s.Connections, conn)
+	client := s.Clients.Register(s.ctx, conn, time.Now())
+	bconn := newBufferedConn(conn, constant.DefaultWriteBufferSize)
+	defer s.disconnect(client, bconn)
 
+	reqs := make(chan *payload.RequestPayload, constant.DefaultPendingRequests)
+	readErr := make(chan error, 1)
+	go readRequests(client, reqs, readErr)
+
+	for pl := range reqs {
+		err := s.handleRequest(client, bconn, pl)
+		if err != nil {
+			bconn.Flush()
+			return err
+		}
+
+		if len(reqs) == 0 {
+			err = bconn.Flush()
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	err := <-readErr
+	if err == io.EOF {
+		log.Println("Client disconnected")
+	}
+	return err
+}
+
+// handleRequest handle one request of client, it is rejected once server is shutting down.
+// Replies to request with ID carry it, so do messages pushed to connection it subscribed.
+func (s *Server) handleRequest(client *model.Client, conn net.Conn, pl *payload.RequestPayload) error {
+	now := time.Now()
+	client.Touch(pl.Cmd, now)
+
+	if pl.ID != 0 {
+		conn = &taggedConn{Conn: conn, id: pl.ID}
+	}
+
+	reqctx := &payload.RequestContext{
+		Context:   client.Context(),
+		Conn:      conn,
+		Now:       now,
+		Payload:   pl,
+		RedisDb:   s.RedisDb,
+		PubsubDb:  s.PubsubDb,
+		PatternDb: s.PatternDb,
+		Client:    client,
+		Clients:   s.Clients,
+	}
+
+	s.mu.Lock()
+	if s.closing {
+		s.mu.Unlock()
+		return reqctx.Error(uint16(tlv.ShuttingDownError), tlv.ErrMsg[tlv.ShuttingDownError])
+	}
+	s.active++
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.active--
+		s.mu.Unlock()
+	}()
+
+	return s.Service.HandleRequest(reqctx)
+}
+
+// readRequests read requests of client ahead of the one being handled, so disconnect is
+// noticed and blocked request of client let go even while it is handled
+func readRequests(client *model.Client, reqs chan<- *payload.RequestPayload, readErr chan<- error) {
+	defer close(reqs)
+
+	r := bufio.NewReaderSize(client.Conn, constant.DefaultReadBufferSize)
 	for {
 		pl := new(payload.RequestPayload)
-		_, err := pl.ReadFrom(conn)
-		// Handle remove conn from arr
+		_, err := pl.ReadFrom(r)
 		if err != nil {
-			if err == io.EOF {
-				log.Println("Client disconnected")
+			client.Cancel()
+			readErr <- err
+			return
+		}
+
+		// Request already read is queued even when client is cancelled, so it get a reply
+		select {
+		case reqs <- pl:
+			continue
+		default:
+		}
+
+		select {
+		case reqs <- pl:
+		case <-client.Done():
+			readErr <- context.Canceled
+			return
+		}
+	}
+}
+
+func (s *Server) disconnect(client *model.Client, conn net.Conn) {
+	s.Clients.Unregister(client.ID)
+	client.Close()
+
+	reqctx := &payload.RequestContext{
+		Context:   client.Context(),
+		Conn:      conn,
+		Now:       time.Now(),
+		RedisDb:   s.RedisDb,
+		PubsubDb:  s.PubsubDb,
+		PatternDb: s.PatternDb,
+		Client:    client,
+		Clients:   s.Clients,
+	}
+	err := s.Service.HandleDisconnected(reqctx)
+	if err != nil {
+		log.Println("disconnect:", err)
+	}
+}
+
+func (s *Server) sweepExpiredKeys() {
+	ticker := time.NewTicker(constant.DefaultExpireSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.quit:
+			return
+		case <-ticker.C:
+			s.RedisDb.DeleteExpired()
+		}
+	}
+}
+
+func (s *Server) saveByPolicy() {
+	if len(s.opts.SavePolicies) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(constant.DefaultSavePolicyInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.quit:
+			return
+		case now := <-ticker.C:
+			if !s.RedisDb.ShouldSave(s.opts.SavePolicies, now) {
+				continue
+			}
+
+			err := s.RedisDb.BackgroundSave()
+			if err != nil && err != db.ErrSnapshotActive {
+				log.Println("snapshot:", err)
 			}
-			return err
 		}
+	}
+}
+
+// newRedisDb load keyspace from append only file when it exists as it is the most up to date,
+// otherwise from snapshot. Fresh append only file is rewritten at once so it hold loaded keys.
+func newRedisDb(opts ServerOptions) (*db.KVStore[*model.Value], error) {
+	var redisDb *db.KVStore[*model.Value]
+	aofExists := false
+	if opts.AOFPath != "" {
+		info, err := os.Stat(opts.AOFPath)
+		aofExists = err == nil && info.Size() > 0
+	}
+
+	if aofExists {
+		redisDb = db.NewKVStore[*model.Value](nil)
+	} else {
+		// Keyspace cached as JSON by older versions is still loaded once
+		redisCache := constant.DefaultRedisCachePath
+		redisDb = db.NewKVStore[*model.Value](&redisCache)
+	}
+	redisDb.SnapshotPath = opts.SnapshotPath
+	redisDb.Codec = db.ValueCodec[*model.Value]{
+		Marshal: func(v *model.Value) ([]byte, error) {
+			return v.MarshalBinary()
+		},
+		Unmarshal: func(data []byte) (*model.Value, error) {
+			v := new(model.Value)
+			err := v.UnmarshalBinary(data)
+			return v, err
+		},
+	}
+	redisDb.Commands = db.CommandCodec[*model.Value]{
+		Marshal: func(cmd db.Command[*model.Value]) ([]byte, error) {
+			return model.MarshalCommand(cmd)
+		},
+		Unmarshal: func(data []byte) (db.Command[*model.Value], error) {
+			return model.UnmarshalCommand(data)
+		},
+	}
 
-		reqctx := &payload.RequestContext{
-			Conn:     conn,
-			Now:      time.Now(),
-			Payload:  pl,
-			RedisDb:  s.RedisDb,
-			PubsubDb: s.PubsubDb,
+	if !aofExists && opts.SnapshotPath != "" {
+		err := redisDb.LoadSnapshot(opts.SnapshotPath)
+		if err != nil {
+			return nil, err
 		}
-		err = s.Service.HandleRequest(reqctx)
+	}
+
+	if opts.AOFPath != "" {
+		err := redisDb.OpenAOF(opts.AOFPath, opts.AOFFsyncPolicy)
 		if err != nil {
-			return err
+			return nil, err
+		}
+
+		if !aofExists && len(redisDb.Storage) > 0 {
+			err = redisDb.RewriteAOF()
+			if err != nil {
+				redisDb.CloseAOF()
+				return nil, err
+			}
 		}
 	}
+
+	return redisDb, nil
 }
 
-func NewServer(network string, port string, cert string, key string) (*Server, error) {
+func NewServer(network string, port string, cert string, key string, opts ServerOptions) (*Server, error) {
 	transport := NewTcpTransport(network, "", port, cert, key)
 	l, err := transport.GetListener()
 	if err != nil {
 		return nil, err
 	}
 
-	redisCache := constant.DefaultRedisCachePath
+	redisDb, err := newRedisDb(opts)
+	if err != nil {
+		l.Close()
+		return nil, err
+	}
+
+	clients := model.NewClientRegistry()
+	if opts.SubscriberQueueSize > 0 {
+		clients.OutboxSize = opts.SubscriberQueueSize
+	}
+	clients.OutboxPolicy = opts.SubscriberOverflowPolicy
+
+	ctx, cancel := context.WithCancel(context.Background())
 	return &Server{
-		Port:        port,
-		Transport:   transport,
-		Listener:    l,
-		Connections: make([]net.Conn, 0, 5),
-		Service:     service.NewService(),
-		RedisDb:     db.NewKVStore[[]byte](&redisCache),
-		PubsubDb:    db.NewKVStore[*model.Topic[*tlv.String]](nil),
+		Port:      port,
+		Transport: transport,
+		Listener:  l,
+		Clients:   clients,
+		Service:   service.NewService(),
+		RedisDb:   redisDb,
+		PubsubDb:  db.NewKVStore[*model.Topic[*tlv.String]](nil),
+		PatternDb: db.NewKVStore[*model.Topic[*tlv.String]](nil),
+		quit:      make(chan struct{}),
+		opts:      opts,
+		ctx:       ctx,
+		cancel:    cancel,
 	}, nil
 }
@@ -1,11 +1,14 @@
 package network
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net"
+	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"bitbucket.org/non-pn/mini-redis-go/internal/constant"
 	"bitbucket.org/non-pn/mini-redis-go/internal/db"
@@ -14,6 +17,7 @@ import (
 	mockservice "bitbucket.org/non-pn/mini-redis-go/internal/mock/service"
 	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
 	"bitbucket.org/non-pn/mini-redis-go/internal/service"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/pubsub"
 	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -37,18 +41,120 @@ func expectReadRequestFromConn(t *testing.T, conn *mocknet.MockConn, cmd uint8,
 }
 
 func TestNewServer(t *testing.T) {
-	serv, err := NewServer(constant.Protocol, ":"+constant.DefaultServerPort, "", "")
+	serv, err := NewServer(constant.Protocol, ":"+constant.DefaultServerPort, "", "", ServerOptions{})
 	defer serv.Stop()
 
 	assert.Nil(t, err)
 	assert.NotNil(t, serv)
 	assert.NotNil(t, serv.RedisDb)
 	assert.NotNil(t, serv.PubsubDb)
-	assert.Equal(t, 0, len(serv.Connections))
+	assert.Equal(t, 0, serv.Clients.Len())
+}
+
+func TestNewServerSubscriberOptions(t *testing.T) {
+	opts := ServerOptions{SubscriberQueueSize: 8, SubscriberOverflowPolicy: model.Disconnect}
+	serv, err := NewServer(constant.Protocol, ":"+constant.DefaultServerPort, "", "", opts)
+	defer serv.Stop()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 8, serv.Clients.OutboxSize)
+	assert.Equal(t, model.Disconnect, serv.Clients.OutboxPolicy)
+}
+
+func TestNewServerReplayAOF(t *testing.T) {
+	opts := ServerOptions{AOFPath: filepath.Join(t.TempDir(), "appendonly.aof")}
+
+	serv, err := NewServer(constant.Protocol, ":"+constant.DefaultServerPort, "", "", opts)
+	assert.Nil(t, err)
+	serv.RedisDb.Set("test_key", model.NewStringValue([]byte("test_val")))
+	serv.Stop()
+
+	serv, err = NewServer(constant.Protocol, ":"+constant.DefaultServerPort, "", "", opts)
+	defer serv.Stop()
+
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("test_val"), serv.RedisDb.Get("test_key").Str)
+}
+
+func TestNewServerReplayAOFCommands(t *testing.T) {
+	opts := ServerOptions{AOFPath: filepath.Join(t.TempDir(), "appendonly.aof")}
+
+	serv, err := NewServer(constant.Protocol, ":"+constant.DefaultServerPort, "", "", opts)
+	assert.Nil(t, err)
+	serv.RedisDb.Exec("test_list", &model.PushCommand{Elems: [][]byte{[]byte("a"), []byte("b")}})
+	serv.RedisDb.Exec("test_list", &model.PopCommand{Left: true})
+	serv.RedisDb.Exec("test_hash", &model.HIncrByCommand{Field: "f", Delta: 2})
+	serv.RedisDb.Exec("test_hash", &model.HIncrByCommand{Field: "f", Delta: 3})
+	xadd := &model.XAddCommand{Auto: true, Fields: map[string][]byte{"f": []byte("v")}, Now: time.Now()}
+	serv.RedisDb.Exec("test_stream", xadd)
+	serv.RedisDb.Exec("test_stream", &model.XGroupCreateCommand{Group: "g"})
+	serv.RedisDb.Exec("test_stream", &model.XReadGroupCommand{Group: "g", Consumer: "c", Now: time.Now()})
+	serv.Stop()
+
+	serv, err = NewServer(constant.Protocol, ":"+constant.DefaultServerPort, "", "", opts)
+	defer serv.Stop()
+
+	assert.Nil(t, err)
+	list := serv.RedisDb.Get("test_list").List
+	assert.Equal(t, 1, list.Len())
+	assert.Equal(t, [][]byte{[]byte("b")}, list.Range(0, -1))
+	n, err := model.ToInt64(serv.RedisDb.Get("test_hash").Hash["f"])
+	assert.Nil(t, err)
+	assert.Equal(t, int64(5), n)
+	stream := serv.RedisDb.Get("test_stream").Stream
+	assert.Equal(t, xadd.Added, stream.LastID())
+	g, err := stream.Group("g")
+	assert.Nil(t, err)
+	assert.Len(t, g.Pending("c"), 1)
+}
+
+func TestNewServerLoadSnapshot(t *testing.T) {
+	opts := ServerOptions{SnapshotPath: filepath.Join(t.TempDir(), "dump.mrdb")}
+
+	serv, err := NewServer(constant.Protocol, ":"+constant.DefaultServerPort, "", "", opts)
+	assert.Nil(t, err)
+	zset := model.NewZSetValue()
+	zset.ZSet.Add("member", 1.5)
+	serv.RedisDb.Set("test_zset", zset)
+	err = serv.RedisDb.Save()
+	assert.Nil(t, err)
+	serv.Stop()
+
+	serv, err = NewServer(constant.Protocol, ":"+constant.DefaultServerPort, "", "", opts)
+	defer serv.Stop()
+
+	assert.Nil(t, err)
+	score, ok := serv.RedisDb.Get("test_zset").ZSet.Score("member")
+	assert.True(t, ok)
+	assert.Equal(t, 1.5, score)
+}
+
+func TestNewServerSnapshotIntoNewAOF(t *testing.T) {
+	dir := t.TempDir()
+	opts := ServerOptions{SnapshotPath: filepath.Join(dir, "dump.mrdb")}
+
+	serv, err := NewServer(constant.Protocol, ":"+constant.DefaultServerPort, "", "", opts)
+	assert.Nil(t, err)
+	serv.RedisDb.Set("test_key", model.NewStringValue([]byte("test_val")))
+	serv.RedisDb.Save()
+	serv.Stop()
+
+	// Keys loaded from snapshot must make it into append only file started after it
+	opts.AOFPath = filepath.Join(dir, "appendonly.aof")
+	serv, err = NewServer(constant.Protocol, ":"+constant.DefaultServerPort, "", "", opts)
+	assert.Nil(t, err)
+	serv.Stop()
+
+	opts.SnapshotPath = ""
+	serv, err = NewServer(constant.Protocol, ":"+constant.DefaultServerPort, "", "", opts)
+	defer serv.Stop()
+
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("test_val"), serv.RedisDb.Get("test_key").Str)
 }
 
 func TestNewSecureServerWithInvalidCert(t *testing.T) {
-	_, err := NewServer(constant.Protocol, ":"+constant.DefaultServerPort, "invalid", "invalid")
+	_, err := NewServer(constant.Protocol, ":"+constant.DefaultServerPort, "invalid", "invalid", ServerOptions{})
 
 	assert.NotNil(t, err)
 }
@@ -59,14 +165,14 @@ func TestNewSecureServerWithValidCert(t *testing.T) {
 
 	assert.Nil(t, err)
 
-	serv, err := NewServer(constant.Protocol, ":"+constant.DefaultServerPort, cert, key)
+	serv, err := NewServer(constant.Protocol, ":"+constant.DefaultServerPort, cert, key, ServerOptions{})
 	defer serv.Stop()
 
 	assert.Nil(t, err)
 	assert.NotNil(t, serv)
 	assert.NotNil(t, serv.RedisDb)
 	assert.NotNil(t, serv.PubsubDb)
-	assert.Equal(t, 0, len(serv.Connections))
+	assert.Equal(t, 0, serv.Clients.Len())
 }
 
 func TestStopServer(t *testing.T) {
@@ -74,13 +180,15 @@ func TestStopServer(t *testing.T) {
 	listener := mocknet.NewMockListener(ctrl)
 	conn := mocknet.NewMockConn(ctrl)
 
+	clients := model.NewClientRegistry()
+	clients.Register(nil, conn, time.Now())
 	server := &Server{
-		Port:        ":6337",
-		Listener:    listener,
-		Connections: []net.Conn{conn},
-		Service:     &service.Service{},
-		RedisDb:     &db.KVStore[[]byte]{},
-		PubsubDb:    &db.KVStore[*model.Topic[*tlv.String]]{},
+		Port:     ":6337",
+		Listener: listener,
+		Clients:  clients,
+		Service:  &service.Service{},
+		RedisDb:  &db.KVStore[*model.Value]{},
+		PubsubDb: &db.KVStore[*model.Topic[*tlv.String]]{},
 	}
 
 	conn.EXPECT().Close().Times(1)
@@ -98,18 +206,22 @@ func TestHandleConnectionHandleRequestError(t *testing.T) {
 	service := mockservice.NewMockIService(ctrl)
 
 	server := &Server{
-		Port:        ":6337",
-		Listener:    listener,
-		Connections: []net.Conn{},
-		Service:     service,
-		RedisDb:     &db.KVStore[[]byte]{},
-		PubsubDb:    &db.KVStore[*model.Topic[*tlv.String]]{},
+		Port:     ":6337",
+		Listener: listener,
+		Clients:  model.NewClientRegistry(),
+		Service:  service,
+		RedisDb:  &db.KVStore[*model.Value]{},
+		PubsubDb: &db.KVStore[*model.Topic[*tlv.String]]{},
 	}
 
 	expectReadRequestFromConn(t, conn, payload.GetCmd, []byte{})
+	// Reading ahead stop once connection is closed
+	conn.EXPECT().Read(gomock.Any()).AnyTimes().Return(0, net.ErrClosed)
+	conn.EXPECT().Close().Times(1)
 
 	mockerr := errors.New("Some handle request error")
 	service.EXPECT().HandleRequest(gomock.Any()).AnyTimes().Return(mockerr)
+	service.EXPECT().HandleDisconnected(gomock.Any()).Times(1)
 
 	err := server.HandleConnection(conn)
 
@@ -123,17 +235,430 @@ func TestHandleConnectionEOF(t *testing.T) {
 	service := mockservice.NewMockIService(ctrl)
 
 	server := &Server{
-		Port:        ":6337",
-		Listener:    listener,
-		Connections: []net.Conn{},
-		Service:     service,
-		RedisDb:     &db.KVStore[[]byte]{},
-		PubsubDb:    &db.KVStore[*model.Topic[*tlv.String]]{},
+		Port:     ":6337",
+		Listener: listener,
+		Clients:  model.NewClientRegistry(),
+		Service:  service,
+		RedisDb:  &db.KVStore[*model.Value]{},
+		PubsubDb: &db.KVStore[*model.Topic[*tlv.String]]{},
 	}
 	service.EXPECT().HandleRequest(conn).AnyTimes()
+	service.EXPECT().HandleDisconnected(gomock.Any()).Times(1)
 	conn.EXPECT().Read(gomock.Any()).Return(0, io.EOF)
+	conn.EXPECT().Close().Times(1)
 
 	err := server.HandleConnection(conn)
 
 	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 0, server.Clients.Len())
+}
+
+func newShutdownTestServer(t *testing.T, serv service.IService) *Server {
+	ctrl := gomock.NewController(t)
+	listener := mocknet.NewMockListener(ctrl)
+	listener.EXPECT().Close().Times(1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Server{
+		Port:     ":6337",
+		Listener: listener,
+		Clients:  model.NewClientRegistry(),
+		Service:  serv,
+		RedisDb:  db.NewKVStore[*model.Value](nil),
+		PubsubDb: db.NewKVStore[*model.Topic[*tlv.String]](nil),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+func writePingRequest(t *testing.T, conn net.Conn) {
+	req := payload.RequestPayload{
+		Cmd:  payload.PingCmd,
+		Body: []byte{},
+	}
+	_, err := req.WriteTo(conn)
+	assert.Nil(t, err)
+}
+
+func TestShutdownDrainInFlightRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	serv := mockservice.NewMockIService(ctrl)
+	server := newShutdownTestServer(t, serv)
+	serv.EXPECT().HandleDisconnected(gomock.Any()).AnyTimes()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	serv.EXPECT().HandleRequest(gomock.Any()).Times(1).DoAndReturn(func(ctx payload.IRequestContext) error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	sconn, cconn := net.Pipe()
+	go server.HandleConnection(sconn)
+	writePingRequest(t, cconn)
+	<-started
+
+	shutdown := make(chan error)
+	go func() {
+		shutdown <- server.Shutdown(context.Background())
+	}()
+	for !server.isClosing() {
+		time.Sleep(time.Millisecond)
+	}
+
+	// New request while draining is rejected
+	sconn2, cconn2 := net.Pipe()
+	go server.HandleConnection(sconn2)
+	writePingRequest(t, cconn2)
+	res, err := payload.ReadResponse(cconn2)
+	assert.Nil(t, err)
+	assert.Equal(t, tlv.ErrorType, res.Typ)
+	reserr := new(tlv.Error)
+	reserr.FromTLV(res.Body)
+	assert.Equal(t, tlv.ShuttingDownError, reserr.Code)
+
+	select {
+	case <-shutdown:
+		t.Fatal("Shutdown returned before in-flight request finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	assert.Nil(t, <-shutdown)
+
+	// Connection is closed once drained
+	_, err = payload.ReadResponse(cconn)
+	assert.NotNil(t, err)
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	serv := mockservice.NewMockIService(ctrl)
+	server := newShutdownTestServer(t, serv)
+	serv.EXPECT().HandleDisconnected(gomock.Any()).AnyTimes()
+
+	started := make(chan struct{})
+	serv.EXPECT().HandleRequest(gomock.Any()).Times(1).DoAndReturn(func(ctx payload.IRequestContext) error {
+		close(started)
+		select {}
+	})
+
+	sconn, cconn := net.Pipe()
+	go server.HandleConnection(sconn)
+	writePingRequest(t, cconn)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := server.Shutdown(ctx)
+
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Equal(t, ErrServerClosed, server.Shutdown(context.Background()))
+}
+
+func TestShutdownReleaseBlockingRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	serv := mockservice.NewMockIService(ctrl)
+	server := newShutdownTestServer(t, serv)
+	serv.EXPECT().HandleDisconnected(gomock.Any()).AnyTimes()
+
+	started := make(chan struct{})
+	serv.EXPECT().HandleRequest(gomock.Any()).Times(1).DoAndReturn(func(ctx payload.IRequestContext) error {
+		close(started)
+		<-ctx.Done()
+		return nil
+	})
+
+	sconn, cconn := net.Pipe()
+	go server.HandleConnection(sconn)
+	writePingRequest(t, cconn)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := server.Shutdown(ctx)
+
+	assert.Nil(t, err)
+}
+
+func TestShutdownFinalSave(t *testing.T) {
+	opts := ServerOptions{
+		SnapshotPath: filepath.Join(t.TempDir(), "dump.mrdb"),
+		SavePolicies: []db.SavePolicy{{Interval: time.Hour, Changes: 1}},
+	}
+
+	serv, err := NewServer(constant.Protocol, ":"+constant.DefaultServerPort, "", "", opts)
+	assert.Nil(t, err)
+	started := make(chan error)
+	go func() {
+		started <- serv.Start()
+	}()
+	serv.RedisDb.Set("test_key", model.NewStringValue([]byte("test_val")))
+
+	err = serv.Shutdown(context.Background())
+
+	assert.Nil(t, err)
+	assert.Equal(t, ErrServerClosed, <-started)
+
+	loaded := db.NewKVStore[*model.Value](nil)
+	loaded.Codec = serv.RedisDb.Codec
+	err = loaded.LoadSnapshot(opts.SnapshotPath)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("test_val"), loaded.Get("test_key").Str)
+}
+
+func TestShutdownFinalSaveWithoutPolicies(t *testing.T) {
+	opts := ServerOptions{SnapshotPath: filepath.Join(t.TempDir(), "dump.mrdb")}
+
+	// Nothing changed, so nothing is written
+	serv, err := NewServer(constant.Protocol, ":"+constant.DefaultServerPort, "", "", opts)
+	assert.Nil(t, err)
+	err = serv.Shutdown(context.Background())
+	assert.Nil(t, err)
+	_, err = os.Stat(opts.SnapshotPath)
+	assert.True(t, os.IsNotExist(err))
+
+	serv, err = NewServer(constant.Protocol, ":"+constant.DefaultServerPort, "", "", opts)
+	assert.Nil(t, err)
+	serv.RedisDb.Set("test_key", model.NewStringValue([]byte("test_val")))
+	err = serv.Shutdown(context.Background())
+	assert.Nil(t, err)
+
+	loaded := db.NewKVStore[*model.Value](nil)
+	loaded.Codec = serv.RedisDb.Codec
+	err = loaded.LoadSnapshot(opts.SnapshotPath)
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("test_val"), loaded.Get("test_key").Str)
+}
+
+func newConnectionTestServer() *Server {
+	return &Server{
+		Port:      ":6337",
+		Clients:   model.NewClientRegistry(),
+		Service:   service.NewService(),
+		RedisDb:   db.NewKVStore[*model.Value](nil),
+		PubsubDb:  db.NewKVStore[*model.Topic[*tlv.String]](nil),
+		PatternDb: db.NewKVStore[*model.Topic[*tlv.String]](nil),
+	}
+}
+
+func TestHandleConnectionReleaseBlockedOnDisconnect(t *testing.T) {
+	server := newConnectionTestServer()
+	sconn, cconn := net.Pipe()
+	done := make(chan error)
+	go func() {
+		done <- server.HandleConnection(sconn)
+	}()
+
+	keys, _ := tlv.NewStringArray("test_list")
+	rawkeys, _ := keys.ToTLV()
+	bod := payload.RedisRequestBody{Value: rawkeys}
+	rawbod, _ := bod.ToTLV()
+	req := payload.RequestPayload{Cmd: payload.BLPopCmd, Body: rawbod}
+	_, err := req.WriteTo(cconn)
+	assert.Nil(t, err)
+
+	for {
+		clients := server.Clients.List()
+		if len(clients) == 1 {
+			if cmd, _ := clients[0].LastCommand(); cmd == payload.BLPopCmd {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	cconn.Close()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Blocked request was not released on disconnect")
+	}
+	assert.Equal(t, 0, server.Clients.Len())
+}
+
+func TestHandleConnectionRemoveSubscriberOnDisconnect(t *testing.T) {
+	server := newConnectionTestServer()
+	sconn, cconn := net.Pipe()
+	done := make(chan error)
+	go func() {
+		done <- server.HandleConnection(sconn)
+	}()
+
+	bod := payload.PubsubRequestBody{Topic: "test_topic", Value: []byte{}}
+	rawbod, _ := bod.ToTLV()
+	req := payload.RequestPayload{Cmd: payload.SubCmd, Body: rawbod}
+	_, err := req.WriteTo(cconn)
+	assert.Nil(t, err)
+	_, err = payload.ReadResponse(cconn)
+	assert.Nil(t, err)
+
+	topic := server.PubsubDb.Get("test_topic")
+	assert.Equal(t, 1, len(topic.ConnDb.Storage))
+
+	cconn.Close()
+	<-done
+
+	assert.Equal(t, 0, len(topic.ConnDb.Storage))
+	assert.Nil(t, server.PubsubDb.Get("test_topic"))
+	assert.Equal(t, 0, server.Clients.Len())
+}
+
+func TestHandleConnectionSubscribeMultipleTopics(t *testing.T) {
+	server := newConnectionTestServer()
+	client := newServedClient(t, server)
+	sub, err := newServedClient(t, server).Sub("topic_a", "topic_b")
+	assert.Nil(t, err)
+
+	msgs := make(chan *pubsub.Message)
+	done := make(chan error)
+	go func() {
+		done <- sub.SubscribeMessages(func(msg *pubsub.Message) {
+			msgs <- msg
+		})
+	}()
+
+	published := make(chan error)
+	publish := func(topic string, msg string) {
+		_, err := client.Pub(topic, msg)
+		published <- err
+	}
+
+	go publish("topic_b", "msg_b")
+	msg := <-msgs
+	assert.Nil(t, <-published)
+	assert.Equal(t, "topic_b", msg.Topic)
+	assert.Equal(t, "msg_b", msg.Payload.String())
+
+	assert.Nil(t, sub.Unsubscribe("topic_b"))
+	// Topic is deleted once its last subscriber leave
+	assert.Eventually(t, func() bool {
+		return server.PubsubDb.Get("topic_b") == nil
+	}, time.Second, time.Millisecond)
+
+	// Only topic still subscribed to is delivered
+	_, err = client.Pub("topic_b", "msg_b")
+	assert.Nil(t, err)
+	go publish("topic_a", "msg_a")
+	msg = <-msgs
+	assert.Nil(t, <-published)
+	assert.Equal(t, "topic_a", msg.Topic)
+	assert.Equal(t, "msg_a", msg.Payload.String())
+
+	// Subscriber stop once no topic is left
+	assert.Nil(t, sub.Unsubscribe())
+	assert.Nil(t, <-done)
+}
+
+func TestHandleConnectionPatternSubscription(t *testing.T) {
+	server := newConnectionTestServer()
+	client := newServedClient(t, server)
+	psub, err := newServedClient(t, server).PSub("orders.*")
+	assert.Nil(t, err)
+	sub, err := newServedClient(t, server).Sub("orders.eu.created")
+	assert.Nil(t, err)
+
+	published := make(chan error)
+	go func() {
+		_, err := client.Pub("orders.eu.created", "test_msg")
+		published <- err
+	}()
+
+	msg, err := sub.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, "", msg.Pattern)
+	assert.Equal(t, "orders.eu.created", msg.Topic)
+
+	msg, err = psub.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, "orders.*", msg.Pattern)
+	assert.Equal(t, "orders.eu.created", msg.Topic)
+	assert.Equal(t, "test_msg", msg.Payload.String())
+	assert.Nil(t, <-published)
+
+	// Subscriber stop once no pattern is left
+	assert.Nil(t, psub.PUnsubscribe())
+	_, err = psub.Next()
+	assert.Equal(t, pubsub.ErrUnsubscribed, err)
+	assert.Nil(t, server.PatternDb.Get("orders.*"))
+}
+
+func TestHandleConnectionSlowSubscriber(t *testing.T) {
+	server := newConnectionTestServer()
+	server.Clients.OutboxSize = 2
+	server.Clients.OutboxPolicy = model.DropNewest
+	client := newServedClient(t, server)
+
+	// Subscriber never read its messages
+	_, err := newServedClient(t, server).Sub("test_topic")
+	assert.Nil(t, err)
+
+	// Publisher is not held up, messages beyond what is queued are dropped
+	receivers := 0
+	for i := 0; i < 10; i++ {
+		receivers, err = client.Pub("test_topic", "test_msg")
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, 0, receivers)
+}
+
+func TestHandleConnectionPipelinedRequests(t *testing.T) {
+	server := newConnectionTestServer()
+	sconn, cconn := net.Pipe()
+	go server.HandleConnection(sconn)
+	defer cconn.Close()
+
+	client := &Client{Connection: cconn, Service: service.NewService()}
+	val := tlv.String("1")
+	results, err := client.Pipeline().
+		Set("test_key", &val).
+		Incr("test_key").
+		IncrBy("test_key", 10).
+		Get("test_key").
+		Exec()
+
+	assert.Nil(t, err)
+	assert.Equal(t, 4, len(results))
+	assert.Equal(t, "OK", results[0].Val)
+	assert.Equal(t, int64(2), results[1].Val)
+	assert.Equal(t, int64(12), results[2].Val)
+	expected := tlv.Int64(12)
+	assert.Equal(t, &expected, results[3].Val)
+	for _, r := range results {
+		assert.Nil(t, r.Err)
+	}
+}
+
+func TestHandleConnectionEchoRequestID(t *testing.T) {
+	server := newConnectionTestServer()
+	sconn, cconn := net.Pipe()
+	go server.HandleConnection(sconn)
+	defer cconn.Close()
+
+	ping := tlv.String("PING")
+	rawping, _ := ping.ToTLV()
+	req := payload.RequestPayload{ID: 7, Cmd: payload.PingCmd, Body: rawping}
+	_, err := req.WriteTo(cconn)
+	assert.Nil(t, err)
+
+	// Bare ping reply is wrapped in response frame so it carry the ID
+	res, err := payload.ReadResponse(cconn)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(7), res.ID)
+	assert.Equal(t, tlv.StringType, res.Typ)
+	pong := new(tlv.String)
+	pong.FromTLV(res.Body)
+	assert.Equal(t, "PONG", pong.String())
+
+	bod := payload.RedisRequestBody{Key: "test_key", Value: []byte{}}
+	rawbod, _ := bod.ToTLV()
+	req = payload.RequestPayload{ID: 8, Cmd: payload.IncrCmd, Body: rawbod}
+	_, err = req.WriteTo(cconn)
+	assert.Nil(t, err)
+
+	res, err = payload.ReadResponse(cconn)
+	assert.Nil(t, err)
+	assert.Equal(t, uint32(8), res.ID)
+	assert.Equal(t, tlv.Int64Type, res.Typ)
 }
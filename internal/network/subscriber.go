@@ -0,0 +1,256 @@
+package network
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/constant"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/pubsub"
+)
+
+// ErrSubscriberClosed is reported by last event of subscriber once it is closed
+var ErrSubscriberClosed = errors.New("Subscriber closed")
+
+type SubscriberState uint8
+
+const (
+	SubscriberConnecting SubscriberState = iota
+	SubscriberSubscribed
+	SubscriberDisconnected
+	SubscriberClosed
+)
+
+func (s SubscriberState) String() string {
+	switch s {
+	case SubscriberConnecting:
+		return "connecting"
+	case SubscriberSubscribed:
+		return "subscribed"
+	case SubscriberDisconnected:
+		return "disconnected"
+	case SubscriberClosed:
+		return "closed"
+	default:
+		return "unknown"
+	}
+}
+
+// SubscriberEvent report change of connection state, Attempt count reconnects since subscriber
+// was last subscribed and Err tell why it is disconnected
+type SubscriberEvent struct {
+	State   SubscriberState
+	Attempt int
+	Err     error
+}
+
+// SubscriberMessage is message of topic, Pattern is set when topic matched one of patterns
+type SubscriberMessage struct {
+	Pattern string
+	Topic   string
+	Payload string
+}
+
+// Backoff grow delay between reconnects by Factor from Min up to Max, Jitter is the fraction
+// of delay taken off at random so subscribers of a restarted server do not reconnect at once
+type Backoff struct {
+	Min    time.Duration
+	Max    time.Duration
+	Factor float64
+	Jitter float64
+}
+
+// Delay return how long to wait before reconnect attempt, first attempt is 0
+func (b Backoff) Delay(attempt int) time.Duration {
+	d := float64(b.Min) * math.Pow(b.Factor, float64(attempt))
+	if d > float64(b.Max) {
+		d = float64(b.Max)
+	}
+	d -= d * b.Jitter * rand.Float64()
+	return time.Duration(d)
+}
+
+func DefaultBackoff() Backoff {
+	return Backoff{
+		Min:    constant.DefaultReconnectMinDelay,
+		Max:    constant.DefaultReconnectMaxDelay,
+		Factor: constant.DefaultReconnectFactor,
+		Jitter: constant.DefaultReconnectJitter,
+	}
+}
+
+// ResilientSubscriber stay subscribed to topics and patterns across lost connections. It
+// reconnect with Backoff and subscribe again, messages and state events are delivered over
+// channels which are closed once subscriber is. Events nobody read are dropped rather than
+// block it.
+type ResilientSubscriber struct {
+	Transport Transporter
+	Service   service.IService
+	Topics    []string
+	Patterns  []string
+	Backoff   Backoff
+
+	messages chan SubscriberMessage
+	events   chan SubscriberEvent
+
+	closeOnce sync.Once
+	quit      chan struct{}
+	done      chan struct{}
+}
+
+func (s *ResilientSubscriber) Messages() <-chan SubscriberMessage {
+	return s.messages
+}
+
+func (s *ResilientSubscriber) Events() <-chan SubscriberEvent {
+	return s.events
+}
+
+// Close stop subscriber and wait for its connection to be closed
+func (s *ResilientSubscriber) Close() error {
+	s.closeOnce.Do(func() {
+		close(s.quit)
+	})
+	<-s.done
+	return nil
+}
+
+func (s *ResilientSubscriber) run() {
+	defer close(s.done)
+	defer close(s.messages)
+	defer close(s.events)
+
+	attempt := 0
+	for {
+		s.emit(SubscriberEvent{State: SubscriberConnecting, Attempt: attempt})
+
+		subscribed, err := s.serve()
+		if s.isClosed() {
+			s.emit(SubscriberEvent{State: SubscriberClosed, Err: ErrSubscriberClosed})
+			return
+		}
+		if subscribed {
+			attempt = 0
+		}
+		s.emit(SubscriberEvent{State: SubscriberDisconnected, Attempt: attempt, Err: err})
+
+		select {
+		case <-s.quit:
+			s.emit(SubscriberEvent{State: SubscriberClosed, Err: ErrSubscriberClosed})
+			return
+		case <-time.After(s.Backoff.Delay(attempt)):
+		}
+		attempt++
+	}
+}
+
+// serve connect and subscribe to every topic and pattern, then relay messages until
+// connection fails
+func (s *ResilientSubscriber) serve() (bool, error) {
+	conn, err := s.Transport.EstablishConnection()
+	if err != nil {
+		return false, err
+	}
+
+	// Closing connection end subscribing or reading in progress
+	stop := make(chan struct{})
+	defer close(stop)
+	go func() {
+		select {
+		case <-s.quit:
+		case <-stop:
+		}
+		conn.Close()
+	}()
+
+	client := &Client{
+		Transport:  s.Transport,
+		Connection: conn,
+		Service:    s.Service,
+	}
+	sub, err := s.subscribe(client)
+	if err != nil {
+		return false, err
+	}
+
+	s.emit(SubscriberEvent{State: SubscriberSubscribed})
+
+	for {
+		msg, err := sub.Next()
+		if err != nil {
+			return true, err
+		}
+
+		select {
+		case s.messages <- SubscriberMessage{Pattern: msg.Pattern, Topic: msg.Topic, Payload: msg.Payload.String()}:
+		case <-s.quit:
+			return true, ErrSubscriberClosed
+		}
+	}
+}
+
+// subscribe send SUB for topics and PSUB for patterns on connection of client, reply to
+// PSUB sent after SUB is read by Next of subscriber
+func (s *ResilientSubscriber) subscribe(client *Client) (*pubsub.Subscriber, error) {
+	if len(s.Topics) == 0 {
+		return client.PSub(s.Patterns...)
+	}
+
+	sub, err := client.Sub(s.Topics...)
+	if err != nil || len(s.Patterns) == 0 {
+		return sub, err
+	}
+	return sub, sub.PSubscribe(s.Patterns...)
+}
+
+func (s *ResilientSubscriber) emit(ev SubscriberEvent) {
+	select {
+	case s.events <- ev:
+	default:
+	}
+}
+
+func (s *ResilientSubscriber) isClosed() bool {
+	select {
+	case <-s.quit:
+		return true
+	default:
+		return false
+	}
+}
+
+// NewResilientSubscriber subscribe to topics over connections established by transport
+// until it is closed
+func NewResilientSubscriber(transport Transporter, backoff Backoff, topics ...string) *ResilientSubscriber {
+	s := &ResilientSubscriber{
+		Transport: transport,
+		Service:   &service.Service{},
+		Topics:    topics,
+		Backoff:   backoff,
+		messages:  make(chan SubscriberMessage),
+		events:    make(chan SubscriberEvent, constant.DefaultSubscriberEvents),
+		quit:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// NewResilientPSubscriber subscribe to patterns like NewResilientSubscriber do to topics
+func NewResilientPSubscriber(transport Transporter, backoff Backoff, patterns ...string) *ResilientSubscriber {
+	s := &ResilientSubscriber{
+		Transport: transport,
+		Service:   &service.Service{},
+		Patterns:  patterns,
+		Backoff:   backoff,
+		messages:  make(chan SubscriberMessage),
+		events:    make(chan SubscriberEvent, constant.DefaultSubscriberEvents),
+		quit:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
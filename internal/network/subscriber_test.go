@@ -0,0 +1,189 @@
+package network
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+var testBackoff = Backoff{
+	Min:    time.Millisecond,
+	Max:    10 * time.Millisecond,
+	Factor: 2,
+	Jitter: 0.2,
+}
+
+// failingTransport fail first dials before connecting to server
+type failingTransport struct {
+	*serverTransport
+	failures int
+}
+
+func (t *failingTransport) EstablishConnection() (net.Conn, error) {
+	if t.failures > 0 {
+		t.failures--
+		return nil, errors.New("Some dial error")
+	}
+	return t.serverTransport.EstablishConnection()
+}
+
+func waitState(t *testing.T, sub *ResilientSubscriber, state SubscriberState) SubscriberEvent {
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case ev := <-sub.Events():
+			if ev.State == state {
+				return ev
+			}
+		case <-timeout:
+			t.Fatal("Subscriber did not become", state)
+		}
+	}
+}
+
+// publish in background, returned channel is closed once publisher got the reply
+func publish(t *testing.T, server *Server, topic string, msg string) <-chan struct{} {
+	client := newServedClient(t, server)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		receivers, err := client.PubContext(context.Background(), topic, msg)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, receivers)
+	}()
+	return done
+}
+
+func TestBackoffDelay(t *testing.T) {
+	b := Backoff{Min: 100 * time.Millisecond, Max: time.Second, Factor: 2, Jitter: 0.5}
+
+	for attempt, max := range []time.Duration{100, 200, 400, 800, 1000, 1000} {
+		max *= time.Millisecond
+		d := b.Delay(attempt)
+		assert.LessOrEqual(t, d, max)
+		assert.GreaterOrEqual(t, d, max/2)
+	}
+}
+
+func TestResilientSubscriberMessages(t *testing.T) {
+	transport := &serverTransport{server: newConnectionTestServer()}
+	sub := NewResilientSubscriber(transport, testBackoff, "topic_a", "topic_b")
+	defer sub.Close()
+	waitState(t, sub, SubscriberSubscribed)
+
+	done := publish(t, transport.server, "topic_b", "msg_b")
+	assert.Equal(t, SubscriberMessage{Topic: "topic_b", Payload: "msg_b"}, <-sub.Messages())
+	<-done
+
+	done = publish(t, transport.server, "topic_a", "msg_a")
+	assert.Equal(t, SubscriberMessage{Topic: "topic_a", Payload: "msg_a"}, <-sub.Messages())
+	<-done
+}
+
+func TestResilientSubscriberReconnect(t *testing.T) {
+	transport := &serverTransport{server: newConnectionTestServer()}
+	sub := NewResilientSubscriber(transport, testBackoff, "test_topic")
+	defer sub.Close()
+	waitState(t, sub, SubscriberSubscribed)
+
+	// Server drop connection
+	for _, c := range transport.server.Clients.List() {
+		c.Close()
+	}
+	ev := waitState(t, sub, SubscriberDisconnected)
+	assert.NotNil(t, ev.Err)
+	waitState(t, sub, SubscriberSubscribed)
+
+	done := publish(t, transport.server, "test_topic", "test_msg")
+	assert.Equal(t, SubscriberMessage{Topic: "test_topic", Payload: "test_msg"}, <-sub.Messages())
+	<-done
+}
+
+func TestResilientSubscriberReconnectPatterns(t *testing.T) {
+	transport := &serverTransport{server: newConnectionTestServer()}
+	sub := NewResilientPSubscriber(transport, testBackoff, "orders.*")
+	defer sub.Close()
+	waitState(t, sub, SubscriberSubscribed)
+
+	for _, c := range transport.server.Clients.List() {
+		c.Close()
+	}
+	waitState(t, sub, SubscriberDisconnected)
+	waitState(t, sub, SubscriberSubscribed)
+
+	// Patterns are subscribed to again
+	done := publish(t, transport.server, "orders.eu", "test_msg")
+	assert.Equal(t, SubscriberMessage{Pattern: "orders.*", Topic: "orders.eu", Payload: "test_msg"}, <-sub.Messages())
+	<-done
+}
+
+func TestResilientSubscriberTopicsAndPatterns(t *testing.T) {
+	transport := &serverTransport{server: newConnectionTestServer()}
+	sub := &ResilientSubscriber{
+		Transport: transport,
+		Service:   &service.Service{},
+		Topics:    []string{"test_topic"},
+		Patterns:  []string{"orders.*"},
+		Backoff:   testBackoff,
+		messages:  make(chan SubscriberMessage),
+		events:    make(chan SubscriberEvent, 8),
+		quit:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	go sub.run()
+	defer sub.Close()
+	waitState(t, sub, SubscriberSubscribed)
+
+	assert.Eventually(t, func() bool {
+		return transport.server.PatternDb.Get("orders.*") != nil
+	}, time.Second, time.Millisecond)
+
+	// Message is read past reply to PSUB sent after SUB
+	done := publish(t, transport.server, "test_topic", "msg_a")
+	assert.Equal(t, SubscriberMessage{Topic: "test_topic", Payload: "msg_a"}, <-sub.Messages())
+	<-done
+	done = publish(t, transport.server, "orders.eu", "msg_b")
+	assert.Equal(t, SubscriberMessage{Pattern: "orders.*", Topic: "orders.eu", Payload: "msg_b"}, <-sub.Messages())
+	<-done
+}
+
+func TestResilientSubscriberRetryDial(t *testing.T) {
+	transport := &failingTransport{
+		serverTransport: &serverTransport{server: newConnectionTestServer()},
+		failures:        2,
+	}
+	sub := NewResilientSubscriber(transport, testBackoff, "test_topic")
+	defer sub.Close()
+
+	var attempts []int
+	for ev := range sub.Events() {
+		if ev.State == SubscriberConnecting {
+			attempts = append(attempts, ev.Attempt)
+		}
+		if ev.State == SubscriberSubscribed {
+			break
+		}
+	}
+	assert.Equal(t, []int{0, 1, 2}, attempts)
+}
+
+func TestResilientSubscriberClose(t *testing.T) {
+	transport := &serverTransport{server: newConnectionTestServer()}
+	sub := NewResilientSubscriber(transport, testBackoff, "test_topic")
+	waitState(t, sub, SubscriberSubscribed)
+
+	assert.Nil(t, sub.Close())
+
+	ev := waitState(t, sub, SubscriberClosed)
+	assert.Equal(t, ErrSubscriberClosed, ev.Err)
+	_, ok := <-sub.Messages()
+	assert.False(t, ok)
+	_, ok = <-sub.Events()
+	assert.False(t, ok)
+}
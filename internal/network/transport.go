@@ -4,6 +4,7 @@ import (
 	"crypto/tls"
 	"log"
 	"net"
+	"time"
 
 	"bitbucket.org/non-pn/mini-redis-go/internal/network/ssl"
 )
@@ -20,6 +21,12 @@ type TcpTransport struct {
 	CertPath string
 	KeyPath  string
 	Dialer   Dialer
+
+	// Zero leave connection without timeout. Read and write timeouts bound each of them on
+	// connection established, running out closes it as its reply can no longer be matched.
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
 }
 
 func (t *TcpTransport) GetListener() (net.Listener, error) {
@@ -59,22 +66,34 @@ func (t *TcpTransport) EstablishConnection() (net.Conn, error) {
 		err error
 	)
 	if cert == "" || key == "" {
-		conn, err = t.Dialer.Dial(network, url)
+		conn, err = t.Dialer.Dial(network, url, t.DialTimeout)
 		if err != nil {
 			return nil, err
 		}
 
-		return conn, nil
+		return t.withTimeout(conn), nil
 	}
 
 	log.Println("Use secure tcp")
 	tlsconf, err := ssl.GetClientTlsConfig(cert, key)
-	conn, err = t.Dialer.SecureDial(network, url, tlsconf)
+	conn, err = t.Dialer.SecureDial(network, url, tlsconf, t.DialTimeout)
 	if err != nil {
 		return nil, err
 	}
 
-	return conn, nil
+	return t.withTimeout(conn), nil
+}
+
+func (t *TcpTransport) withTimeout(conn net.Conn) net.Conn {
+	if t.ReadTimeout <= 0 && t.WriteTimeout <= 0 {
+		return conn
+	}
+
+	return &timeoutConn{
+		Conn:         conn,
+		readTimeout:  t.ReadTimeout,
+		writeTimeout: t.WriteTimeout,
+	}
 }
 
 func NewTcpTransport(network string, host string, port string, cert string, key string) Transporter {
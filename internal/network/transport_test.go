@@ -1,8 +1,10 @@
 package network
 
 import (
+	"net"
 	"path/filepath"
 	"testing"
+	"time"
 
 	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
 	mocknetwork "bitbucket.org/non-pn/mini-redis-go/internal/mock/network"
@@ -27,7 +29,7 @@ func TestEstablishConnection(t *testing.T) {
 		Dialer:   dialer,
 	}
 
-	dialer.EXPECT().Dial(transport.Network, transport.Host+transport.Port).Times(1).Return(conn, nil)
+	dialer.EXPECT().Dial(transport.Network, transport.Host+transport.Port, time.Duration(0)).Times(1).Return(conn, nil)
 
 	c, err := transport.EstablishConnection()
 
@@ -56,10 +58,41 @@ func TestEstablishSecureConnection(t *testing.T) {
 
 	assert.Nil(t, err)
 
-	dialer.EXPECT().SecureDial(transport.Network, transport.Host+transport.Port, conf).Times(1).Return(conn, nil)
+	dialer.EXPECT().SecureDial(transport.Network, transport.Host+transport.Port, conf, time.Duration(0)).Times(1).Return(conn, nil)
 
 	c, err := transport.EstablishConnection()
 
 	assert.Nil(t, err)
 	assert.NotNil(t, conn, c)
 }
+
+func TestEstablishConnectionWithTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	dialer := mocknetwork.NewMockDialer(ctrl)
+	sconn, cconn := net.Pipe()
+	defer sconn.Close()
+
+	transport := &TcpTransport{
+		Network:      "tcp",
+		Host:         "localhost",
+		Port:         ":6377",
+		Dialer:       dialer,
+		DialTimeout:  time.Second,
+		ReadTimeout:  20 * time.Millisecond,
+		WriteTimeout: 20 * time.Millisecond,
+	}
+
+	dialer.EXPECT().Dial(transport.Network, transport.Host+transport.Port, time.Second).Times(1).Return(cconn, nil)
+
+	c, err := transport.EstablishConnection()
+	assert.Nil(t, err)
+
+	// Nobody read on the other end
+	_, err = c.Write([]byte{1})
+	assert.True(t, isTimeout(err))
+
+	// Connection is closed once it ran out of time
+	_, err = c.Read(make([]byte, 1))
+	assert.NotNil(t, err)
+	assert.False(t, isTimeout(err))
+}
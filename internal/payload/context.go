@@ -2,34 +2,81 @@ package payload
 
 import (
 	"context"
+	"errors"
 	"net"
 	"time"
 
 	"bitbucket.org/non-pn/mini-redis-go/internal/db"
 	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/glob"
 	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
 )
 
 type StringTopic *model.Topic[*tlv.String]
 
+// ErrWouldBlock is returned by Flush of request run in transaction, store is locked so
+// blocking request give up at once as if it timed out
+var ErrWouldBlock = errors.New("Request cannot block in transaction")
+
 type IRequestContext interface {
 	Response(res ResponsePayload) error
 	Error(code uint16, msg string) error
 	GetConn() net.Conn
 	GetPayload() *RequestPayload
-	GetRedis(k string) []byte
+	GetRedis(k string) ([]byte, error)
 	SetRedis(k string, v []byte)
+	SetRedisWithTTL(k string, v []byte, ttl time.Duration)
+	ExpireRedis(k string, ttl time.Duration) bool
+	TTLRedis(k string) time.Duration
+	PersistRedis(k string) bool
+	DeleteRedis(keys ...string) int
+	ExistsRedis(keys ...string) int
+	ScanRedis(cursor string, match string, count int) (string, []string)
+	UpdateRedis(k string, fn func(v []byte, ok bool) ([]byte, error)) ([]byte, error)
+	ExecRedis(k string, cmd model.Command) error
+	ViewRedis(k string, fn func(v *model.Value) error) error
+	ViewKeysRedis(keys []string, fn func(vs []*model.Value) error) error
+	WaitRedis(keys ...string) (<-chan struct{}, func())
+	VersionRedis(k string) uint64
+	WatchRedis(keys ...string) []uint64
+	UnwatchRedis(keys ...string)
+	AtomicRedis(fn func(ctx IRequestContext) error) error
+	WithRequest(pl *RequestPayload, conn net.Conn) IRequestContext
+	Done() <-chan struct{}
+	RewriteAOFRedis() error
+	SaveRedis() error
+	BGSaveRedis() error
 	GetPubsub(k string) StringTopic
 	SetPubsub(k string, v StringTopic)
+	AddPubsubConn(k string, id uint64, conn net.Conn)
+	RemovePubsubConn(k string, id uint64)
+	AddPatternConn(k string, id uint64, conn net.Conn)
+	RemovePatternConn(k string, id uint64)
+	MatchPatterns(topic string) []StringTopic
+	GetClient() *model.Client
+	GetClients() *model.ClientRegistry
+	Flush() error
+}
+
+// Flusher is implemented by connection that buffer what is written to it
+type Flusher interface {
+	Flush() error
 }
 
 type RequestContext struct {
 	context.Context
-	Conn     net.Conn
-	RedisDb  *db.KVStore[[]byte]
+	Conn    net.Conn
+	RedisDb *db.KVStore[*model.Value]
+	// Set while request is run by AtomicRedis, store is then reached through it
+	RedisTx  *db.Tx[*model.Value]
 	PubsubDb *db.KVStore[*model.Topic[*tlv.String]]
-	Now      time.Time
-	Payload  *RequestPayload
+	// Topics of pattern subscriptions keyed by pattern
+	PatternDb *db.KVStore[*model.Topic[*tlv.String]]
+	Now       time.Time
+	Payload   *RequestPayload
+	// Client sending request and registry of every connected client
+	Client  *model.Client
+	Clients *model.ClientRegistry
 }
 
 func (ctx *RequestContext) Response(res ResponsePayload) error {
@@ -43,7 +90,17 @@ func (ctx *RequestContext) Response(res ResponsePayload) error {
 
 func (ctx *RequestContext) Error(code uint16, msg string) error {
 	tlvErr := tlv.NewError(code, msg)
-	_, err := tlvErr.WriteTo(ctx.Conn)
+	raw, err := tlvErr.ToTLV()
+	if err != nil {
+		return err
+	}
+
+	// Error is sent in response frame so client read it like any other reply
+	res := ResponsePayload{
+		Typ:  tlv.ErrorType,
+		Body: raw,
+	}
+	_, err = res.WriteTo(ctx.Conn)
 	if err != nil {
 		return err
 	}
@@ -59,11 +116,148 @@ func (ctx *RequestContext) GetPayload() *RequestPayload {
 	return ctx.Payload
 }
 
-func (ctx *RequestContext) GetRedis(k string) []byte {
-	return ctx.RedisDb.Get(k)
+func (ctx *RequestContext) GetRedis(k string) ([]byte, error) {
+	v := ctx.redis().Get(k)
+	if v == nil {
+		return nil, nil
+	}
+	if v.Type != model.StringValueType {
+		return nil, model.ErrWrongType
+	}
+	return v.Str, nil
 }
 func (ctx *RequestContext) SetRedis(k string, v []byte) {
-	ctx.RedisDb.Set(k, v)
+	ctx.redis().Set(k, model.NewStringValue(v))
+}
+func (ctx *RequestContext) SetRedisWithTTL(k string, v []byte, ttl time.Duration) {
+	ctx.redis().SetWithTTL(k, model.NewStringValue(v), ttl)
+}
+func (ctx *RequestContext) ExpireRedis(k string, ttl time.Duration) bool {
+	return ctx.redis().Expire(k, ttl)
+}
+func (ctx *RequestContext) TTLRedis(k string) time.Duration {
+	return ctx.redis().TTL(k)
+}
+func (ctx *RequestContext) PersistRedis(k string) bool {
+	return ctx.redis().Persist(k)
+}
+func (ctx *RequestContext) DeleteRedis(keys ...string) int {
+	return ctx.redis().Delete(keys...)
+}
+func (ctx *RequestContext) ExistsRedis(keys ...string) int {
+	return ctx.redis().Exists(keys...)
+}
+func (ctx *RequestContext) ScanRedis(cursor string, match string, count int) (string, []string) {
+	return ctx.redis().Scan(cursor, match, count)
+}
+func (ctx *RequestContext) UpdateRedis(k string, fn func(v []byte, ok bool) ([]byte, error)) ([]byte, error) {
+	v, err := ctx.redis().Update(k, func(v *model.Value, ok bool) (*model.Value, error) {
+		if ok && v.Type != model.StringValueType {
+			return nil, model.ErrWrongType
+		}
+
+		var raw []byte
+		if ok {
+			raw = v.Str
+		}
+		nraw, err := fn(raw, ok)
+		if err != nil {
+			return nil, err
+		}
+		return model.NewStringValue(nraw), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.Str, nil
+}
+
+// ExecRedis run cmd on value of key, only cmd is logged to append only file
+func (ctx *RequestContext) ExecRedis(k string, cmd model.Command) error {
+	_, err := ctx.redis().Exec(k, cmd)
+	return err
+}
+func (ctx *RequestContext) ViewRedis(k string, fn func(v *model.Value) error) error {
+	return ctx.redis().View(k, func(v *model.Value, ok bool) error {
+		return fn(v)
+	})
+}
+
+// ViewKeysRedis pass values of keys in order to fn, nil for missing key
+func (ctx *RequestContext) ViewKeysRedis(keys []string, fn func(vs []*model.Value) error) error {
+	return ctx.redis().ViewKeys(keys, fn)
+}
+
+// Done is closed when blocking request should give up, nil when request has no context
+func (ctx *RequestContext) Done() <-chan struct{} {
+	if ctx.Context == nil {
+		return nil
+	}
+	return ctx.Context.Done()
+}
+
+func (ctx *RequestContext) WaitRedis(keys ...string) (<-chan struct{}, func()) {
+	return ctx.redis().WaitKeys(keys...)
+}
+
+func (ctx *RequestContext) VersionRedis(k string) uint64 {
+	return ctx.redis().Version(k)
+}
+
+// WatchRedis return versions of keys, UnwatchRedis must be called with them once they are
+// no longer watched
+func (ctx *RequestContext) WatchRedis(keys ...string) []uint64 {
+	return ctx.RedisDb.Watch(keys...)
+}
+
+func (ctx *RequestContext) UnwatchRedis(keys ...string) {
+	ctx.RedisDb.Unwatch(keys...)
+}
+
+// AtomicRedis run fn with redis store locked, requests handled with ctx given to fn and
+// contexts derived from it are applied at once. Persistence commands must not be run by fn.
+func (ctx *RequestContext) AtomicRedis(fn func(ctx IRequestContext) error) error {
+	// Request run by EXEC hold store lock already
+	if ctx.RedisTx != nil {
+		return fn(ctx)
+	}
+
+	return ctx.RedisDb.Atomically(func(tx *db.Tx[*model.Value]) error {
+		txctx := *ctx
+		txctx.RedisTx = tx
+		return fn(&txctx)
+	})
+}
+
+// WithRequest return copy of ctx handling pl and replying to conn
+func (ctx *RequestContext) WithRequest(pl *RequestPayload, conn net.Conn) IRequestContext {
+	reqctx := *ctx
+	reqctx.Payload = pl
+	reqctx.Conn = conn
+	return &reqctx
+}
+
+// redis return store requests are run against, transaction of AtomicRedis when there is one
+func (ctx *RequestContext) redis() db.Store[*model.Value] {
+	if ctx.RedisTx != nil {
+		return ctx.RedisTx
+	}
+	return ctx.RedisDb
+}
+
+// RewriteAOFRedis start rewrite of append only file in background
+func (ctx *RequestContext) RewriteAOFRedis() error {
+	return ctx.RedisDb.BackgroundRewriteAOF()
+}
+
+// SaveRedis write snapshot of keyspace and return once it is on disk
+func (ctx *RequestContext) SaveRedis() error {
+	return ctx.RedisDb.Save()
+}
+
+// BGSaveRedis start snapshot of keyspace in background
+func (ctx *RequestContext) BGSaveRedis() error {
+	return ctx.RedisDb.BackgroundSave()
 }
 func (ctx *RequestContext) GetPubsub(k string) StringTopic {
 	return ctx.PubsubDb.Get(k)
@@ -71,3 +265,76 @@ func (ctx *RequestContext) GetPubsub(k string) StringTopic {
 func (ctx *RequestContext) SetPubsub(k string, v StringTopic) {
 	ctx.PubsubDb.Set(k, v)
 }
+
+// AddPubsubConn subscribe connection of client with id to topic, topic is created when there is none
+func (ctx *RequestContext) AddPubsubConn(k string, id uint64, conn net.Conn) {
+	addTopicConn(ctx.PubsubDb, k, id, conn)
+}
+
+// RemovePubsubConn unsubscribe connection of client with id from topic, topic is deleted once
+// no connection is left in it
+func (ctx *RequestContext) RemovePubsubConn(k string, id uint64) {
+	removeTopicConn(ctx.PubsubDb, k, id)
+}
+
+// AddPatternConn is AddPubsubConn for topic of pattern subscription
+func (ctx *RequestContext) AddPatternConn(k string, id uint64, conn net.Conn) {
+	addTopicConn(ctx.PatternDb, k, id, conn)
+}
+
+// RemovePatternConn is RemovePubsubConn for topic of pattern subscription
+func (ctx *RequestContext) RemovePatternConn(k string, id uint64) {
+	removeTopicConn(ctx.PatternDb, k, id)
+}
+
+// Topic is changed under lock of store, so it is not deleted while connection is added to it
+func addTopicConn(topics *db.KVStore[*model.Topic[*tlv.String]], k string, id uint64, conn net.Conn) {
+	topics.Update(k, func(topic *model.Topic[*tlv.String], ok bool) (*model.Topic[*tlv.String], error) {
+		if !ok || topic == nil || !topic.DidInit() {
+			topic = model.NewTopic[*tlv.String](k)
+		}
+		topic.AddConn(id, conn)
+		return topic, nil
+	})
+}
+
+func removeTopicConn(topics *db.KVStore[*model.Topic[*tlv.String]], k string, id uint64) {
+	topics.Compute(k, func(topic *model.Topic[*tlv.String], ok bool) (*model.Topic[*tlv.String], bool, error) {
+		if !ok || topic == nil || !topic.DidInit() {
+			return topic, false, nil
+		}
+		topic.RemoveConn(id)
+		return topic, topic.Len() > 0, nil
+	})
+}
+
+// MatchPatterns return topics of every pattern matching topic
+func (ctx *RequestContext) MatchPatterns(topic string) []StringTopic {
+	ctx.PatternDb.RLock()
+	defer ctx.PatternDb.RUnlock()
+
+	matched := []StringTopic{}
+	for pattern, t := range ctx.PatternDb.Storage {
+		if glob.Match(pattern, topic) {
+			matched = append(matched, t)
+		}
+	}
+	return matched
+}
+func (ctx *RequestContext) GetClient() *model.Client {
+	return ctx.Client
+}
+func (ctx *RequestContext) GetClients() *model.ClientRegistry {
+	return ctx.Clients
+}
+
+// Flush send replies buffered so far, needed before request block waiting
+func (ctx *RequestContext) Flush() error {
+	if ctx.RedisTx != nil {
+		return ErrWouldBlock
+	}
+	if f, ok := ctx.Conn.(Flusher); ok {
+		return f.Flush()
+	}
+	return nil
+}
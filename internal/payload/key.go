@@ -0,0 +1,60 @@
+package payload
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	tlvpac "bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+// readKey read key or topic encoded as tlv string, its length is checked before allocation
+func readKey(r io.Reader) (string, int64, error) {
+	var (
+		typ  uint8
+		klen uint32
+		n    int64
+
+		err error
+	)
+
+	err = binary.Read(r, binary.BigEndian, &typ)
+	if err != nil {
+		return "", 0, err
+	}
+
+	n += 1
+
+	if typ != tlvpac.StringType {
+		return "", n, errors.New("Invalid key")
+	}
+
+	err = binary.Read(r, binary.BigEndian, &klen)
+	if err != nil {
+		return "", n, err
+	}
+
+	n += 4
+
+	if klen > MaxKeyLength {
+		return "", n, errors.New("Error: Key length exceed limit")
+	}
+
+	buf := make([]byte, klen)
+	o, err := io.ReadFull(r, buf)
+	n += int64(o)
+	if err != nil {
+		return "", n, err
+	}
+
+	return string(buf), n, nil
+}
+
+func writeKey(w io.Writer, key string) (int64, error) {
+	if len(key) > int(MaxKeyLength) {
+		return 0, errors.New("Error: Key length exceed limit")
+	}
+
+	k := tlvpac.String(key)
+	return k.WriteTo(w)
+}
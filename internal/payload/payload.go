@@ -3,8 +3,10 @@ package payload
 import (
 	"bytes"
 	"encoding/binary"
+	"errors"
 	"io"
 
+	"bitbucket.org/non-pn/mini-redis-go/internal/constant"
 	tlvpac "bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
 )
 
@@ -19,8 +21,6 @@ const (
 	SetResp
 	SubResp
 	PubResp
-
-	ErrResp = 99
 )
 
 const (
@@ -29,49 +29,143 @@ const (
 	SetCmd
 	SubCmd
 	PubCmd
+	ExpireCmd
+	TTLCmd
+	PersistCmd
+	DelCmd
+	ExistsCmd
+	ScanCmd
+	IncrCmd
+	DecrCmd
+	IncrByCmd
+	DecrByCmd
+	IncrByFloatCmd
+	LPushCmd
+	RPushCmd
+	LPopCmd
+	RPopCmd
+	LRangeCmd
+	LLenCmd
+	BLPopCmd
+	HSetCmd
+	HGetCmd
+	HDelCmd
+	HGetAllCmd
+	HIncrByCmd
+	HLenCmd
+	SAddCmd
+	SRemCmd
+	SIsMemberCmd
+	SMembersCmd
+	SInterCmd
+	SUnionCmd
+	SDiffCmd
+	ZAddCmd
+	ZRemCmd
+	ZScoreCmd
+	ZRangeCmd
+	ZRangeByScoreCmd
+	ZRankCmd
+	ZIncrByCmd
+	BGRewriteAOFCmd
+	SaveCmd
+	BGSaveCmd
+	ClientListCmd
+	ClientKillCmd
+	ClientSetNameCmd
+	ClientGetNameCmd
+	UnsubCmd
+	PSubCmd
+	PUnsubCmd
+	XAddCmd
+	XRangeCmd
+	XReadCmd
+	XLenCmd
+	XTrimCmd
+	XGroupCreateCmd
+	XGroupDestroyCmd
+	XReadGroupCmd
+	XAckCmd
+	XPendingCmd
+	XClaimCmd
+	MultiCmd
+	ExecCmd
+	DiscardCmd
+	WatchCmd
+	UnwatchCmd
+	SetArgsCmd
+	CASCmd
+	CASVersionCmd
+	GetVersionCmd
+	MGetCmd
+	MSetCmd
+	MSetNXCmd
+)
+
+const (
+	// Implied by frame without version byte, key and topic are padded to fixed width
+	FixedWidthProtocolVersion uint8 = iota + 1
+	// Key and topic are length prefixed strings
+	TLVProtocolVersion
+
+	ProtocolVersion = TLVProtocolVersion
+
+	// Set on version byte so it cannot be mistaken for command of frame without version
+	VersionFlag uint8 = 0x80
+	// Set on version byte of request and type byte of response followed by request ID
+	IDFlag uint8 = 0x40
 )
 
+// Longest key or topic accepted in request body
+var MaxKeyLength = constant.DefaultMaxKeyLength
+
 type RawRequestPayload []byte
 type RequestPayload struct {
+	// Zero value is written as ProtocolVersion
+	Version uint8
+	// Echoed back in response so replies can be matched to requests, zero when not set
+	ID   uint32
 	Cmd  uint8
 	Body tlvpac.TypeLengthValue
 }
 
 func (req *RequestPayload) ReadFrom(r io.Reader) (int64, error) {
-	var (
-		typ uint8
-		len uint32
-		buf []byte
-		n   int64
-
-		err error
-	)
-
-	err = binary.Read(r, binary.BigEndian, &typ)
+	// Length is checked against MaxPayloadSize before body is allocated
+	tlv, err := tlvpac.ReadTLV(r)
 	if err != nil {
 		return 0, err
 	}
 
-	n += 1
+	n := int64(len(tlv))
+	buf := tlv.GetValue()
+	len := tlv.GetLength()
 
-	err = binary.Read(r, binary.BigEndian, &len)
-	if err != nil {
-		return n, err
+	if len == 0 {
+		return n, errors.New("Invalid request payload")
 	}
 
-	n += 4
-
-	buf = make([]byte, len)
-	o, err := r.Read(buf)
-	if err != nil {
-		return n, err
+	version := FixedWidthProtocolVersion
+	var id uint32
+	if buf[0]&VersionFlag != 0 {
+		if len < 2 {
+			return n, errors.New("Invalid request payload")
+		}
+		version = buf[0] &^ (VersionFlag | IDFlag)
+		if buf[0]&IDFlag != 0 {
+			if len < 6 {
+				return n, errors.New("Invalid request payload")
+			}
+			id = binary.BigEndian.Uint32(buf[1:5])
+			buf = buf[4:]
+		}
+		buf = buf[1:]
 	}
 
-	n += int64(o)
-
 	*req = RequestPayload{
-		Cmd:  buf[0],
-		Body: buf[1:],
+		Version: version,
+		ID:      id,
+		Cmd:     buf[0],
+		Body:    buf[1:],
 	}
 	return n, nil
 }
@@ -85,7 +179,30 @@ func (req *RequestPayload) WriteTo(w io.Writer) (int64, error) {
 		err error
 	)
 
+	version := req.Version
+	if version == 0 {
+		version = ProtocolVersion
+	}
+
 	buf := new(bytes.Buffer)
+	if version != FixedWidthProtocolVersion {
+		flags := VersionFlag
+		if req.ID != 0 {
+			flags |= IDFlag
+		}
+		err = binary.Write(buf, binary.BigEndian, version|flags)
+		if err != nil {
+			return 0, err
+		}
+	} else if req.ID != 0 {
+		return 0, errors.New("Request ID needs versioned request payload")
+	}
+	if req.ID != 0 {
+		err = binary.Write(buf, binary.BigEndian, req.ID)
+		if err != nil {
+			return 0, err
+		}
+	}
 	err = binary.Write(buf, binary.BigEndian, req.Cmd)
 	if err != nil {
 		return 0, err
@@ -97,28 +214,10 @@ func (req *RequestPayload) WriteTo(w io.Writer) (int64, error) {
 
 	val = buf.Bytes()
 
-	err = binary.Write(w, binary.BigEndian, typ)
-	if err != nil {
-		return 0, err
-	}
-
-	n += 1
-
-	binary.Write(w, binary.BigEndian, uint32(len(val)))
-	if err != nil {
-		return n, err
-	}
-
-	n += 4
-
-	o, err := w.Write(val)
-	if err != nil {
-		return n, err
-	}
-
+	o, err := w.Write(frame(typ, val))
 	n += int64(o)
 
-	return n, nil
+	return n, err
 }
 
 func (req *RequestPayload) FromTLV(tlv tlvpac.TypeLengthValue) error {
@@ -143,44 +242,41 @@ func (req *RequestPayload) ToTLV() (tlvpac.TypeLengthValue, error) {
 
 type RawResponsePayload []byte
 type ResponsePayload struct {
+	// ID of request replied to, zero when request had none
+	ID   uint32
 	Typ  uint8
 	Body tlvpac.TypeLengthValue
 }
 
 func (res *ResponsePayload) ReadFrom(r io.Reader) (int64, error) {
-	var (
-		typ uint8
-		len uint32
-		buf []byte
-		n   int64
-
-		err error
-	)
-
-	err = binary.Read(r, binary.BigEndian, &typ)
+	// Length is checked against MaxPayloadSize before body is allocated
+	tlv, err := tlvpac.ReadTLV(r)
 	if err != nil {
 		return 0, err
 	}
 
-	n += 1
+	n := int64(len(tlv))
+	buf := tlv.GetValue()
+	len := tlv.GetLength()
 
-	err = binary.Read(r, binary.BigEndian, &len)
-	if err != nil {
-		return n, err
+	if len == 0 {
+		return n, errors.New("Invalid response payload")
 	}
 
-	n += 4
-
-	buf = make([]byte, len)
-	o, err := r.Read(buf)
-	if err != nil {
-		return n, err
+	var id uint32
+	typ := buf[0]
+	if typ&IDFlag != 0 {
+		if len < 5 {
+			return n, errors.New("Invalid response payload")
+		}
+		id = binary.BigEndian.Uint32(buf[1:5])
+		typ &^= IDFlag
+		buf = buf[4:]
 	}
 
-	n += int64(o)
-
 	*res = ResponsePayload{
-		Typ:  buf[0],
+		ID:   id,
+		Typ:  typ,
 		Body: buf[1:],
 	}
 	return n, nil
@@ -196,7 +292,12 @@ func (res *ResponsePayload) WriteTo(w io.Writer) (int64, error) {
 	)
 
 	buf := new(bytes.Buffer)
-	binary.Write(buf, binary.BigEndian, res.Typ)
+	if res.ID != 0 {
+		binary.Write(buf, binary.BigEndian, res.Typ|IDFlag)
+		binary.Write(buf, binary.BigEndian, res.ID)
+	} else {
+		binary.Write(buf, binary.BigEndian, res.Typ)
+	}
 	if err != nil {
 		return 0, err
 	}
@@ -207,28 +308,10 @@ func (res *ResponsePayload) WriteTo(w io.Writer) (int64, error) {
 
 	val = buf.Bytes()
 
-	err = binary.Write(w, binary.BigEndian, typ)
-	if err != nil {
-		return 0, err
-	}
-
-	n += 1
-
-	err = binary.Write(w, binary.BigEndian, uint32(len(val)))
-	if err != nil {
-		return n, err
-	}
-
-	n += 4
-
-	o, err := w.Write(val)
-	if err != nil {
-		return n, err
-	}
-
+	o, err := w.Write(frame(typ, val))
 	n += int64(o)
 
-	return n, nil
+	return n, err
 }
 
 func (res *ResponsePayload) FromTLV(tlv tlvpac.TypeLengthValue) error {
@@ -260,3 +343,13 @@ func ReadResponse(r io.Reader) (*ResponsePayload, error) {
 
 	return res, nil
 }
+
+// frame lay out type, length and value in one buffer so payload is written with one Write,
+// frames written to same connection from different goroutines then never interleave
+func frame(typ uint8, val []byte) []byte {
+	buf := make([]byte, 5+len(val))
+	buf[0] = typ
+	binary.BigEndian.PutUint32(buf[1:5], uint32(len(val)))
+	copy(buf[5:], val)
+	return buf
+}
@@ -0,0 +1,234 @@
+package payload
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestPayloadReadFromVersioned(t *testing.T) {
+	tests := []byte{3, 0, 0, 0, 3, VersionFlag | TLVProtocolVersion, GetCmd, 0}
+	testreader := bytes.NewReader(tests)
+
+	pl := new(RequestPayload)
+	n, err := pl.ReadFrom(testreader)
+
+	assert.Equal(t, len(tests), int(n))
+	assert.Nil(t, err)
+	assert.Equal(t, TLVProtocolVersion, pl.Version)
+	assert.Equal(t, GetCmd, pl.Cmd)
+	assert.Equal(t, []byte{0}, []byte(pl.Body))
+}
+
+func TestRequestPayloadReadFromLegacy(t *testing.T) {
+	tests := []byte{3, 0, 0, 0, 2, GetCmd, 0}
+	testreader := bytes.NewReader(tests)
+
+	pl := new(RequestPayload)
+	n, err := pl.ReadFrom(testreader)
+
+	assert.Equal(t, len(tests), int(n))
+	assert.Nil(t, err)
+	assert.Equal(t, FixedWidthProtocolVersion, pl.Version)
+	assert.Equal(t, GetCmd, pl.Cmd)
+}
+
+func TestPayloadReadFromExceedLimit(t *testing.T) {
+	// Length over limit is rejected from header alone
+	tests := []byte{3, 0xff, 0xff, 0xff, 0xff, GetCmd}
+
+	_, err := new(RequestPayload).ReadFrom(bytes.NewReader(tests))
+	assert.NotNil(t, err)
+
+	_, err = new(ResponsePayload).ReadFrom(bytes.NewReader(tests))
+	assert.NotNil(t, err)
+}
+
+func TestRequestPayloadWriteTo(t *testing.T) {
+	testwriter := new(bytes.Buffer)
+
+	pl := RequestPayload{Cmd: GetCmd, Body: []byte{0}}
+	_, err := pl.WriteTo(testwriter)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{3, 0, 0, 0, 3, VersionFlag | ProtocolVersion, GetCmd, 0}, testwriter.Bytes())
+
+	testwriter.Reset()
+	pl.Version = FixedWidthProtocolVersion
+	_, err = pl.WriteTo(testwriter)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{3, 0, 0, 0, 2, GetCmd, 0}, testwriter.Bytes())
+}
+
+func TestRequestPayloadWithID(t *testing.T) {
+	testwriter := new(bytes.Buffer)
+
+	pl := RequestPayload{ID: 258, Cmd: GetCmd, Body: []byte{0}}
+	_, err := pl.WriteTo(testwriter)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{3, 0, 0, 0, 7, VersionFlag | IDFlag | ProtocolVersion, 0, 0, 1, 2, GetCmd, 0}, testwriter.Bytes())
+
+	res := new(RequestPayload)
+	_, err = res.ReadFrom(testwriter)
+
+	assert.Nil(t, err)
+	assert.Equal(t, ProtocolVersion, res.Version)
+	assert.Equal(t, uint32(258), res.ID)
+	assert.Equal(t, GetCmd, res.Cmd)
+	assert.Equal(t, []byte{0}, []byte(res.Body))
+
+	pl.Version = FixedWidthProtocolVersion
+	_, err = pl.WriteTo(testwriter)
+
+	assert.NotNil(t, err)
+}
+
+func TestResponsePayloadWithID(t *testing.T) {
+	testwriter := new(bytes.Buffer)
+
+	pl := ResponsePayload{ID: 258, Typ: 2, Body: []byte{1}}
+	_, err := pl.WriteTo(testwriter)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{3, 0, 0, 0, 6, 2 | IDFlag, 0, 0, 1, 2, 1}, testwriter.Bytes())
+
+	res := new(ResponsePayload)
+	_, err = res.ReadFrom(testwriter)
+
+	assert.Nil(t, err)
+	assert.Equal(t, pl, *res)
+
+	// Response without ID is laid out as before
+	testwriter.Reset()
+	pl.ID = 0
+	pl.WriteTo(testwriter)
+
+	assert.Equal(t, []byte{3, 0, 0, 0, 2, 2, 1}, testwriter.Bytes())
+}
+
+func TestRedisRequestBodyLongKey(t *testing.T) {
+	key := strings.Repeat("k", 100)
+	bod := RedisRequestBody{Key: key, Value: []byte{2, 0, 0, 0, 1, 118}, TTL: time.Second}
+	raw, err := bod.ToTLV()
+	assert.Nil(t, err)
+
+	res := new(RedisRequestBody)
+	n, err := res.ReadFrom(bytes.NewReader(raw))
+
+	assert.Nil(t, err)
+	assert.Equal(t, len(raw), int(n))
+	assert.Equal(t, key, res.Key)
+	assert.Equal(t, bod.Value, res.Value)
+	assert.Equal(t, time.Second, res.TTL)
+}
+
+func TestRedisRequestBodyKeyExceedLimit(t *testing.T) {
+	bod := RedisRequestBody{Key: strings.Repeat("k", int(MaxKeyLength)+1)}
+	_, err := bod.ToTLV()
+
+	assert.NotNil(t, err)
+
+	// Key length is checked on read before it is allocated
+	tests := []byte{6, 0, 0, 0, 13, 2, 0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0, 0, 0, 0, 0}
+	res := new(RedisRequestBody)
+	_, err = res.ReadFrom(bytes.NewReader(tests))
+
+	assert.NotNil(t, err)
+}
+
+func TestRedisRequestBodyInvalidLength(t *testing.T) {
+	// Length not covering key and ttl
+	tests := []byte{6, 0, 0, 0, 6, 2, 0, 0, 0, 1, 107, 0, 0, 0, 0, 0, 0, 0, 0}
+	res := new(RedisRequestBody)
+	_, err := res.ReadFrom(bytes.NewReader(tests))
+
+	assert.NotNil(t, err)
+
+	// Length over limit is rejected before key and value are read
+	tests[1], tests[2], tests[3], tests[4] = 0xff, 0xff, 0xff, 0xff
+	_, err = res.ReadFrom(bytes.NewReader(tests))
+
+	assert.NotNil(t, err)
+}
+
+func TestRedisRequestBodyFixedWidth(t *testing.T) {
+	bod := RedisRequestBody{Version: FixedWidthProtocolVersion, Key: "key", Value: []byte{2, 0, 0, 0, 1, 118}}
+	raw, err := bod.ToTLV()
+	assert.Nil(t, err)
+	assert.Equal(t, 5+int(KeyDataLength)+len(bod.Value), len(raw))
+
+	res := &RedisRequestBody{Version: FixedWidthProtocolVersion}
+	err = res.FromTLV(raw)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "key", res.Key)
+	assert.Equal(t, bod.Value, res.Value)
+
+	bod.Key = strings.Repeat("k", int(KeyDataLength)+1)
+	_, err = bod.ToTLV()
+
+	assert.NotNil(t, err)
+}
+
+func TestRedisRequestBodyFixedWidthInvalidLength(t *testing.T) {
+	// Length of ttl request not covering key and ttl
+	tests := append([]byte{13, 0, 0, 0, 16}, make([]byte, int(KeyDataLength)+int(TTLDataLength))...)
+	res := &RedisRequestBody{Version: FixedWidthProtocolVersion}
+	_, err := res.ReadFrom(bytes.NewReader(tests))
+
+	assert.NotNil(t, err)
+
+	// Length over limit is rejected before value is allocated
+	tests = append([]byte{6, 0xff, 0xff, 0xff, 0xff}, make([]byte, int(KeyDataLength))...)
+	_, err = res.ReadFrom(bytes.NewReader(tests))
+
+	assert.NotNil(t, err)
+}
+
+func TestPubsubRequestBodyLongTopic(t *testing.T) {
+	topic := "orders.eu.created.priority"
+	bod := PubsubRequestBody{Topic: topic, Value: []byte{2, 0, 0, 0, 1, 118}}
+	raw, err := bod.ToTLV()
+	assert.Nil(t, err)
+
+	res := new(PubsubRequestBody)
+	err = res.FromTLV(raw)
+
+	assert.Nil(t, err)
+	assert.Equal(t, topic, res.Topic)
+	assert.Equal(t, bod.Value, res.Value)
+}
+
+func TestPubsubRequestBodyFixedWidth(t *testing.T) {
+	bod := PubsubRequestBody{Version: FixedWidthProtocolVersion, Topic: "topic", Value: []byte{}}
+	raw, err := bod.ToTLV()
+	assert.Nil(t, err)
+	assert.Equal(t, 5+int(TopicDataLength), len(raw))
+
+	res := &PubsubRequestBody{Version: FixedWidthProtocolVersion}
+	err = res.FromTLV(raw)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "topic", res.Topic)
+}
+
+func TestPubsubRequestBodyInvalidLength(t *testing.T) {
+	// Length not covering topic
+	tests := append([]byte{9, 0, 0, 0, 1}, make([]byte, int(TopicDataLength))...)
+	res := &PubsubRequestBody{Version: FixedWidthProtocolVersion}
+	_, err := res.ReadFrom(bytes.NewReader(tests))
+
+	assert.NotNil(t, err)
+
+	// Length over limit is rejected before topic and value are read
+	tests = []byte{9, 0xff, 0xff, 0xff, 0xff, 2, 0, 0, 0, 1, 116}
+	res = new(PubsubRequestBody)
+	_, err = res.ReadFrom(bytes.NewReader(tests))
+
+	assert.NotNil(t, err)
+}
@@ -14,11 +14,133 @@ const (
 )
 
 type PubsubRequestBody struct {
-	Topic string
-	Value tlvpac.TypeLengthValue
+	// Framing of body, taken from request and not written as part of body.
+	// Zero value is ProtocolVersion.
+	Version uint8
+	Topic   string
+	Value   tlvpac.TypeLengthValue
 }
 
 func (b *PubsubRequestBody) ReadFrom(r io.Reader) (int64, error) {
+	if b.Version == FixedWidthProtocolVersion {
+		return b.readFixedWidth(r)
+	}
+
+	var (
+		typ uint8
+		len uint32
+		n   int64
+
+		err error
+	)
+
+	err = binary.Read(r, binary.BigEndian, &typ)
+	if err != nil {
+		return 0, err
+	}
+
+	n += 1
+
+	if typ != tlvpac.PubsubRequestPayloadType {
+		return n, errors.New("Error: Invalid pubsub request")
+	}
+
+	err = binary.Read(r, binary.BigEndian, &len)
+	if err != nil {
+		return n, err
+	}
+
+	n += 4
+
+	if len > tlvpac.MaxPayloadSize {
+		return n, errors.New("Error: Invalid pubsub request")
+	}
+
+	topic, tlen, err := readKey(r)
+	n += tlen
+	if err != nil {
+		return n, err
+	}
+
+	if int64(len) < tlen {
+		return n, errors.New("Error: Invalid pubsub request")
+	}
+
+	vlen := len - uint32(tlen)
+	buf := make([]byte, vlen)
+
+	if vlen > 0 {
+		o, err := io.ReadFull(r, buf)
+		if err != nil {
+			return n, err
+		}
+
+		n += int64(o)
+	}
+
+	*b = PubsubRequestBody{
+		Version: b.Version,
+		Topic:   topic,
+		Value:   tlvpac.TypeLengthValue(buf),
+	}
+
+	return n, nil
+}
+
+func (b *PubsubRequestBody) WriteTo(w io.Writer) (int64, error) {
+	if b.Version == FixedWidthProtocolVersion {
+		return b.writeFixedWidth(w)
+	}
+
+	var (
+		n int64
+
+		err error
+	)
+
+	topic := new(bytes.Buffer)
+	_, err = writeKey(topic, b.Topic)
+	if err != nil {
+		return 0, err
+	}
+
+	typ := tlvpac.PubsubRequestPayloadType
+	val := b.Value
+	blen := uint32(topic.Len()) + uint32(len(val))
+
+	err = binary.Write(w, binary.BigEndian, typ)
+	if err != nil {
+		return 0, err
+	}
+
+	n += 1
+
+	err = binary.Write(w, binary.BigEndian, blen)
+	if err != nil {
+		return n, err
+	}
+
+	n += 4
+
+	o, err := w.Write(topic.Bytes())
+	if err != nil {
+		return n, err
+	}
+
+	n += int64(o)
+
+	o, err = w.Write(val)
+	if err != nil {
+		return n, err
+	}
+
+	n += int64(o)
+
+	return n, nil
+}
+
+// readFixedWidth read body of FixedWidthProtocolVersion where topic is left padded to TopicDataLength
+func (b *PubsubRequestBody) readFixedWidth(r io.Reader) (int64, error) {
 	var (
 		typ   uint8
 		len   uint32
@@ -46,6 +168,11 @@ func (b *PubsubRequestBody) ReadFrom(r io.Reader) (int64, error) {
 
 	n += 4
 
+	// Length must cover topic so value length cannot underflow
+	if len < uint32(TopicDataLength) || len > tlvpac.MaxPayloadSize {
+		return n, errors.New("Error: Invalid pubsub request")
+	}
+
 	err = binary.Read(r, binary.BigEndian, &topic)
 	if err != nil {
 		return n, err
@@ -57,7 +184,7 @@ func (b *PubsubRequestBody) ReadFrom(r io.Reader) (int64, error) {
 	buf := make([]byte, vlen)
 
 	if vlen > 0 {
-		o, err := r.Read(buf)
+		o, err := io.ReadFull(r, buf)
 		if err != nil {
 			return n, err
 		}
@@ -66,14 +193,15 @@ func (b *PubsubRequestBody) ReadFrom(r io.Reader) (int64, error) {
 	}
 
 	*b = PubsubRequestBody{
-		Topic: string(topic),
-		Value: tlvpac.TypeLengthValue(buf),
+		Version: b.Version,
+		Topic:   string(bytes.TrimLeft(topic, "\x00")),
+		Value:   tlvpac.TypeLengthValue(buf),
 	}
 
 	return n, nil
 }
 
-func (b *PubsubRequestBody) WriteTo(w io.Writer) (int64, error) {
+func (b *PubsubRequestBody) writeFixedWidth(w io.Writer) (int64, error) {
 	var (
 		n int64
 
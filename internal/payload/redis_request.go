@@ -5,24 +5,35 @@ import (
 	"encoding/binary"
 	"errors"
 	"io"
+	"time"
 
 	tlvpac "bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
 )
 
 const (
 	KeyDataLength uint8 = 16
+	TTLDataLength uint8 = 8
 )
 
 type RedisRequestBody struct {
-	Key   string
-	Value tlvpac.TypeLengthValue
+	// Framing of body, taken from request and not written as part of body.
+	// Zero value is ProtocolVersion.
+	Version uint8
+	Key     string
+	Value   tlvpac.TypeLengthValue
+	// Time to live of key, zero mean no ttl
+	TTL time.Duration
 }
 
 func (b *RedisRequestBody) ReadFrom(r io.Reader) (int64, error) {
+	if b.Version == FixedWidthProtocolVersion {
+		return b.readFixedWidth(r)
+	}
+
 	var (
 		typ uint8
 		len uint32
-		key = make([]byte, KeyDataLength)
+		ttl int64
 		n   int64
 
 		err error
@@ -46,6 +57,147 @@ func (b *RedisRequestBody) ReadFrom(r io.Reader) (int64, error) {
 
 	n += 4
 
+	if len > tlvpac.MaxPayloadSize {
+		return n, errors.New("Invalid redis request")
+	}
+
+	key, klen, err := readKey(r)
+	n += klen
+	if err != nil {
+		return n, err
+	}
+
+	err = binary.Read(r, binary.BigEndian, &ttl)
+	if err != nil {
+		return n, err
+	}
+
+	n += int64(TTLDataLength)
+
+	if int64(len) < klen+int64(TTLDataLength) {
+		return n, errors.New("Invalid redis request")
+	}
+
+	vlen := len - uint32(klen) - uint32(TTLDataLength)
+	buf := make([]byte, vlen)
+
+	if vlen > 0 {
+		o, err := io.ReadFull(r, buf)
+		if err != nil {
+			return n, err
+		}
+
+		n += int64(o)
+	}
+
+	*b = RedisRequestBody{
+		Version: b.Version,
+		Key:     key,
+		Value:   tlvpac.TypeLengthValue(buf),
+		TTL:     time.Duration(ttl) * time.Millisecond,
+	}
+
+	return n, nil
+}
+
+func (b *RedisRequestBody) WriteTo(w io.Writer) (int64, error) {
+	if b.Version == FixedWidthProtocolVersion {
+		return b.writeFixedWidth(w)
+	}
+
+	var (
+		n int64
+
+		err error
+	)
+
+	key := new(bytes.Buffer)
+	_, err = writeKey(key, b.Key)
+	if err != nil {
+		return 0, err
+	}
+
+	typ := tlvpac.RedisRequestPayloadType
+	val := b.Value
+	blen := uint32(key.Len()) + uint32(TTLDataLength) + uint32(len(val))
+
+	err = binary.Write(w, binary.BigEndian, typ)
+	if err != nil {
+		return 0, err
+	}
+
+	n += 1
+
+	err = binary.Write(w, binary.BigEndian, blen)
+	if err != nil {
+		return n, err
+	}
+
+	n += 4
+
+	o, err := w.Write(key.Bytes())
+	if err != nil {
+		return n, err
+	}
+
+	n += int64(o)
+
+	err = binary.Write(w, binary.BigEndian, b.TTL.Milliseconds())
+	if err != nil {
+		return n, err
+	}
+
+	n += int64(TTLDataLength)
+
+	o, err = w.Write(val)
+	if err != nil {
+		return n, err
+	}
+
+	n += int64(o)
+
+	return n, nil
+}
+
+// readFixedWidth read body of FixedWidthProtocolVersion where key is left padded to KeyDataLength
+func (b *RedisRequestBody) readFixedWidth(r io.Reader) (int64, error) {
+	var (
+		typ uint8
+		len uint32
+		key = make([]byte, KeyDataLength)
+		ttl int64
+		n   int64
+
+		err error
+	)
+
+	err = binary.Read(r, binary.BigEndian, &typ)
+	if err != nil {
+		return 0, err
+	}
+
+	n += 1
+
+	if typ != tlvpac.RedisRequestPayloadType && typ != tlvpac.RedisTTLRequestPayloadType {
+		return n, errors.New("Invalid redis request")
+	}
+
+	err = binary.Read(r, binary.BigEndian, &len)
+	if err != nil {
+		return n, err
+	}
+
+	n += 4
+
+	// Length must cover key and ttl so value length cannot underflow
+	hlen := uint32(KeyDataLength)
+	if typ == tlvpac.RedisTTLRequestPayloadType {
+		hlen += uint32(TTLDataLength)
+	}
+	if len < hlen || len > tlvpac.MaxPayloadSize {
+		return n, errors.New("Invalid redis request")
+	}
+
 	err = binary.Read(r, binary.BigEndian, &key)
 	if err != nil {
 		return n, err
@@ -53,11 +205,21 @@ func (b *RedisRequestBody) ReadFrom(r io.Reader) (int64, error) {
 
 	n += int64(KeyDataLength)
 
-	vlen := len - uint32(KeyDataLength)
+	if typ == tlvpac.RedisTTLRequestPayloadType {
+		err = binary.Read(r, binary.BigEndian, &ttl)
+		if err != nil {
+			return n, err
+		}
+
+		n += int64(TTLDataLength)
+	}
+
+	vlen := len - hlen
+
 	buf := make([]byte, vlen)
 
 	if vlen > 0 {
-		o, err := r.Read(buf)
+		o, err := io.ReadFull(r, buf)
 		if err != nil {
 			return n, err
 		}
@@ -65,15 +227,18 @@ func (b *RedisRequestBody) ReadFrom(r io.Reader) (int64, error) {
 		n += int64(o)
 	}
 
+	// Key is left padded to fixed width, strip padding so it match keys sent in value
 	*b = RedisRequestBody{
-		Key:   string(key),
-		Value: tlvpac.TypeLengthValue(buf),
+		Version: b.Version,
+		Key:     string(bytes.TrimLeft(key, "\x00")),
+		Value:   tlvpac.TypeLengthValue(buf),
+		TTL:     time.Duration(ttl) * time.Millisecond,
 	}
 
 	return n, nil
 }
 
-func (b *RedisRequestBody) WriteTo(w io.Writer) (int64, error) {
+func (b *RedisRequestBody) writeFixedWidth(w io.Writer) (int64, error) {
 	var (
 		n int64
 
@@ -85,9 +250,13 @@ func (b *RedisRequestBody) WriteTo(w io.Writer) (int64, error) {
 	}
 
 	typ := tlvpac.RedisRequestPayloadType
-	key := append(make([]byte, int(TopicDataLength)-len(b.Key)), []byte(b.Key)...)
+	key := append(make([]byte, int(KeyDataLength)-len(b.Key)), []byte(b.Key)...)
 	val := b.Value
-	blen := uint32(len(val)) + uint32(TopicDataLength)
+	blen := uint32(len(val)) + uint32(KeyDataLength)
+	if b.TTL != 0 {
+		typ = tlvpac.RedisTTLRequestPayloadType
+		blen += uint32(TTLDataLength)
+	}
 
 	err = binary.Write(w, binary.BigEndian, typ)
 	if err != nil {
@@ -110,6 +279,15 @@ func (b *RedisRequestBody) WriteTo(w io.Writer) (int64, error) {
 
 	n += int64(KeyDataLength)
 
+	if b.TTL != 0 {
+		err = binary.Write(w, binary.BigEndian, b.TTL.Milliseconds())
+		if err != nil {
+			return n, err
+		}
+
+		n += int64(TTLDataLength)
+	}
+
 	o, err := w.Write(val)
 	if err != nil {
 		return n, err
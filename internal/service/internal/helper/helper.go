@@ -9,7 +9,11 @@ import (
 
 func ResponseWithString(s string, ctx payload.IRequestContext) error {
 	ss := tlv.String(s)
-	raw, err := ss.ToTLV()
+	return ResponseWithTLV(&ss, ctx)
+}
+
+func ResponseWithTLV(v tlv.TLVCompatible, ctx payload.IRequestContext) error {
+	raw, err := v.ToTLV()
 	if err != nil {
 		err = ctx.Error(uint16(tlv.DataTransformError), tlv.ErrMsg[tlv.DataTransformError])
 		log.Println(err)
@@ -30,3 +34,28 @@ func ResponseWithString(s string, ctx payload.IRequestContext) error {
 
 	return nil
 }
+
+// ResponseWithRaw reply already encoded tlv, nil is replied as empty response
+func ResponseWithRaw(raw tlv.TypeLengthValue, ctx payload.IRequestContext) error {
+	resp := payload.ResponsePayload{
+		Typ:  raw.GetType(),
+		Body: raw,
+	}
+	_, err := resp.WriteTo(ctx.GetConn())
+	if err != nil {
+		log.Println(err)
+		return err
+	}
+
+	return nil
+}
+
+// ResponseWithError reply typed tlv error as is, any other error is replied as data transform error
+func ResponseWithError(err error, ctx payload.IRequestContext) error {
+	tlvErr, ok := err.(*tlv.Error)
+	if !ok {
+		return ctx.Error(uint16(tlv.DataTransformError), tlv.ErrMsg[tlv.DataTransformError])
+	}
+
+	return ctx.Error(uint16(tlvErr.Code), tlvErr.Msg)
+}
@@ -18,13 +18,8 @@ func ExpectWriteRequestToConn(t *testing.T, conn *mocknet.MockConn, cmd uint8, b
 	raw, err := req.ToTLV()
 	assert.Nil(t, err)
 
-	typ := raw[0:1]
-	blen := raw[1:5]
-	val := raw[5:]
-
-	conn.EXPECT().Write(typ).SetArg(0, typ).Return(len(typ), nil)
-	conn.EXPECT().Write(blen).SetArg(0, blen).Return(len(blen), nil)
-	conn.EXPECT().Write(val).SetArg(0, val).Return(len(val), nil)
+	// Payload is written as a whole frame
+	conn.EXPECT().Write(raw).Return(len(raw), nil)
 }
 
 func ExpectReadResponseFromConn(t *testing.T, conn *mocknet.MockConn, restyp uint8, body tlv.TypeLengthValue) {
@@ -67,13 +62,8 @@ func ExpectWriteResponseToConn(t *testing.T, conn *mocknet.MockConn, restyp uint
 	raw, err := resp.ToTLV()
 	assert.Nil(t, err)
 
-	typ := raw[0:1]
-	blen := raw[1:5]
-	val := raw[5:]
-
-	conn.EXPECT().Write(typ).SetArg(0, typ).Return(len(typ), nil)
-	conn.EXPECT().Write(blen).SetArg(0, blen).Return(len(blen), nil)
-	conn.EXPECT().Write(val).SetArg(0, val).Return(len(val), nil)
+	// Payload is written as a whole frame
+	conn.EXPECT().Write(raw).Return(len(raw), nil)
 }
 
 func ExpectWriteStringResponseToConn(t *testing.T, conn *mocknet.MockConn, str string) {
@@ -82,11 +72,5 @@ func ExpectWriteStringResponseToConn(t *testing.T, conn *mocknet.MockConn, str s
 
 	assert.Nil(t, err)
 
-	typ := raw[0:1]
-	blen := raw[1:5]
-	val := raw[5:]
-
-	conn.EXPECT().Write(typ).SetArg(0, typ).Return(len(typ), nil)
-	conn.EXPECT().Write(blen).SetArg(0, blen).Return(len(blen), nil)
-	conn.EXPECT().Write(val).SetArg(0, val).Return(len(val), nil)
+	conn.EXPECT().Write(raw).Return(len(raw), nil)
 }
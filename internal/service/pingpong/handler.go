@@ -24,7 +24,11 @@ func HandleRequest(ctx payload.IRequestContext) error {
 		pong = msg
 	}
 
-	_, err = pong.WriteTo(ctx.GetConn())
+	// Written at once so it is not split by message pushed to same connection
+	raw, err := pong.ToTLV()
+	if err == nil {
+		_, err = ctx.GetConn().Write(raw)
+	}
 	if err != nil {
 		err = ctx.Error(uint16(tlv.DataTransformError), tlv.ErrMsg[tlv.DataTransformError])
 		log.Println(err)
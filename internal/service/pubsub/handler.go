@@ -2,13 +2,12 @@ package pubsub
 
 import (
 	"bytes"
-	"log"
+	"net"
 
 	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
 	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
 	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/helper"
 	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
-	"bitbucket.org/non-pn/mini-redis-go/pkg/async"
 )
 
 func HandleRequest(ctx payload.IRequestContext) error {
@@ -21,7 +20,7 @@ func HandleRequest(ctx payload.IRequestContext) error {
 	cmd = pl.Cmd
 	body = pl.Body
 
-	pubsubBody := new(payload.PubsubRequestBody)
+	pubsubBody := &payload.PubsubRequestBody{Version: pl.Version}
 	_, err = pubsubBody.ReadFrom(bytes.NewReader(body))
 	if err != nil {
 		return err
@@ -31,6 +30,15 @@ func HandleRequest(ctx payload.IRequestContext) error {
 	case payload.SubCmd:
 		err = handleSubRequest(ctx, pubsubBody)
 		break
+	case payload.UnsubCmd:
+		err = handleUnsubRequest(ctx, pubsubBody)
+		break
+	case payload.PSubCmd:
+		err = handlePSubRequest(ctx, pubsubBody)
+		break
+	case payload.PUnsubCmd:
+		err = handlePUnsubRequest(ctx, pubsubBody)
+		break
 	case payload.PubCmd:
 		err = handlePubRequest(ctx, pubsubBody)
 		break
@@ -41,16 +49,47 @@ func HandleRequest(ctx payload.IRequestContext) error {
 	return nil
 }
 
+// requestTopics return topic of body followed by ones in its value, which is an array of
+// topic names when request is for more than one
+func requestTopics(body *payload.PubsubRequestBody) ([]string, error) {
+	topics := []string{}
+	if body.Topic != "" {
+		topics = append(topics, body.Topic)
+	}
+	if len(body.Value) == 0 {
+		return topics, nil
+	}
+
+	rest := new(tlv.Array)
+	err := rest.FromTLV(body.Value)
+	if err != nil {
+		return nil, err
+	}
+	names, err := rest.Strings()
+	if err != nil {
+		return nil, err
+	}
+
+	return append(topics, names...), nil
+}
+
 func handleSubRequest(ctx payload.IRequestContext, body *payload.PubsubRequestBody) error {
 	var (
 		err error
 	)
-	topic := ctx.GetPubsub(body.Topic)
-	if topic == nil || !(*topic).DidInit() {
-		topic = model.NewTopic[*tlv.String](body.Topic)
-		ctx.SetPubsub(body.Topic, topic)
+	topics, err := requestTopics(body)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	client := ctx.GetClient()
+	conn := subscriberConn(client, ctx)
+	for _, name := range topics {
+		ctx.AddPubsubConn(name, subscriberID(client), conn)
+		if client != nil {
+			client.Subscribe(name)
+		}
 	}
-	(*topic).AddConn(ctx.GetConn())
 
 	err = helper.ResponseWithString("OK", ctx)
 	if err != nil {
@@ -59,60 +98,188 @@ func handleSubRequest(ctx payload.IRequestContext, body *payload.PubsubRequestBo
 
 	return nil
 }
+
+// handleUnsubRequest unsubscribe from topics of request or from every topic when there is
+// none, reply with number of topics and patterns connection is still subscribed to
+func handleUnsubRequest(ctx payload.IRequestContext, body *payload.PubsubRequestBody) error {
+	topics, err := requestTopics(body)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	client := ctx.GetClient()
+	if len(topics) == 0 && client != nil {
+		topics = client.Topics()
+	}
+
+	for _, name := range topics {
+		ctx.RemovePubsubConn(name, subscriberID(client))
+		if client != nil {
+			client.Unsubscribe(name)
+		}
+	}
+
+	return responseWithSubscriptions(client, ctx)
+}
+
+// handlePSubRequest subscribe to topics matching glob patterns, patterns are taken from body
+// same as topics of SUB
+func handlePSubRequest(ctx payload.IRequestContext, body *payload.PubsubRequestBody) error {
+	patterns, err := requestTopics(body)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	client := ctx.GetClient()
+	conn := subscriberConn(client, ctx)
+	for _, pattern := range patterns {
+		ctx.AddPatternConn(pattern, subscriberID(client), conn)
+		if client != nil {
+			client.PSubscribe(pattern)
+		}
+	}
+
+	return helper.ResponseWithString("OK", ctx)
+}
+
+// handlePUnsubRequest unsubscribe from patterns of request or from every pattern when there
+// is none, reply like UNSUB
+func handlePUnsubRequest(ctx payload.IRequestContext, body *payload.PubsubRequestBody) error {
+	patterns, err := requestTopics(body)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	client := ctx.GetClient()
+	if len(patterns) == 0 && client != nil {
+		patterns = client.Patterns()
+	}
+
+	for _, pattern := range patterns {
+		ctx.RemovePatternConn(pattern, subscriberID(client))
+		if client != nil {
+			client.PUnsubscribe(pattern)
+		}
+	}
+
+	return responseWithSubscriptions(client, ctx)
+}
+
+// subscriberConn return connection messages to client are pushed on. They are queued on
+// outbox of client so publisher does not wait for it, connection without client is written
+// to right away.
+func subscriberConn(client *model.Client, ctx payload.IRequestContext) net.Conn {
+	if client == nil {
+		return ctx.GetConn()
+	}
+	return client.Outbox().Conn(ctx.GetConn())
+}
+
+// subscriberID key connection of client in topics, connection without client get zero
+func subscriberID(client *model.Client) uint64 {
+	if client == nil {
+		return 0
+	}
+	return client.ID
+}
+
+// responseWithSubscriptions reply with number of topics and patterns client is left with
+func responseWithSubscriptions(client *model.Client, ctx payload.IRequestContext) error {
+	left := tlv.Int64(0)
+	if client != nil {
+		left = tlv.Int64(client.Subscriptions())
+	}
+	return helper.ResponseWithTLV(&left, ctx)
+}
+
+// HandleDisconnected remove connection of client from every topic and pattern it subscribed to
+func HandleDisconnected(ctx payload.IRequestContext) error {
+	client := ctx.GetClient()
+	if client == nil {
+		return nil
+	}
+
+	for _, name := range client.Topics() {
+		ctx.RemovePubsubConn(name, subscriberID(client))
+		client.Unsubscribe(name)
+	}
+	for _, pattern := range client.Patterns() {
+		ctx.RemovePatternConn(pattern, subscriberID(client))
+		client.PUnsubscribe(pattern)
+	}
+
+	return nil
+}
+
 func handlePubRequest(ctx payload.IRequestContext, body *payload.PubsubRequestBody) error {
 	var (
 		err error
 	)
 
-	topic := ctx.GetPubsub(body.Topic)
-	if topic == nil || !(*topic).DidInit() {
-		topic = model.NewTopic[*tlv.String](body.Topic)
-		ctx.SetPubsub(body.Topic, topic)
-	}
-
 	msg := body.Value
 	smsg := new(tlv.String)
 	err = smsg.FromTLV(msg)
 	if err != nil {
-		return err
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongTypeError), ctx)
 	}
 
-	rc, ec := async.Async(func() (any, error) {
-		err := broadCastMessage[*tlv.String](topic, smsg)
+	// Topic without subscriber is not created, message may still match patterns
+	receivers := 0
+	topic := ctx.GetPubsub(body.Topic)
+	if topic != nil && (*topic).DidInit() {
+		// Messages are only queued for subscribers, so this does not wait for slow ones
+		receivers, err = broadCastMessage[*tlv.String](topic, smsg, body.Topic)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		return nil, nil
-	})
-	_, err = async.Await(rc, ec)
-	if err != nil {
-		return err
 	}
-
-	err = helper.ResponseWithString("OK", ctx)
-	if err != nil {
-		return err
+	// Pattern subscribers are told which pattern matched too
+	for _, ptopic := range ctx.MatchPatterns(body.Topic) {
+		n, err := broadCastMessage[*tlv.String](ptopic, smsg, (*ptopic).Name, body.Topic)
+		if err != nil {
+			return err
+		}
+		receivers += n
 	}
 
-	return nil
+	count := tlv.Int64(receivers)
+	return helper.ResponseWithTLV(&count, ctx)
 }
 
-func broadCastMessage[T tlv.TLVCompatible](topic *model.Topic[T], msg T) error {
-	conns := topic.ConnDb.Storage
-	for _, conn := range conns {
-		c := conn
+// broadCastMessage push message to every connection subscribed to topic and return to how
+// many it was pushed. Body of push is an array of names followed by message, names are
+// topic of message preceded by pattern it matched when topic is of pattern subscription.
+func broadCastMessage[T tlv.TLVCompatible](topic *model.Topic[T], msg T, names ...string) (int, error) {
+	push, err := tlv.NewStringArray(names...)
+	if err != nil {
+		return 0, err
+	}
+	err = push.Append(msg)
+	if err != nil {
+		return 0, err
+	}
+	raw, err := push.ToTLV()
+	if err != nil {
+		return 0, err
+	}
+	resp := payload.ResponsePayload{
+		Typ:  tlv.MsgType,
+		Body: raw,
+	}
 
-		log.Println("Relaying message to:", c.RemoteAddr().String())
+	receivers := 0
+	for _, conn := range topic.Conns() {
+		c := conn
 
-		raw, err := msg.ToTLV()
+		// Subscriber whose queue is full or gone is not counted, outbox log when it overflow
+		_, err := resp.WriteTo(c)
 		if err != nil {
-			return err
+			continue
 		}
-		resp := payload.ResponsePayload{
-			Typ:  tlv.MsgType,
-			Body: raw,
+		if f, ok := c.(payload.Flusher); ok {
+			f.Flush()
 		}
-		resp.WriteTo(c)
+		receivers++
 	}
-	return nil
+	return receivers, nil
 }
@@ -3,6 +3,7 @@ package pubsub
 import (
 	"net"
 	"testing"
+	"time"
 
 	"bitbucket.org/non-pn/mini-redis-go/internal/db"
 	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
@@ -33,19 +34,16 @@ func getPubsubRequestPayload(t *testing.T, cmd uint8, topic string, val tlv.Type
 func TestHandleSubRequest(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	conn := mocknet.NewMockConn(ctrl)
-	addr := mocknet.NewMockAddr(ctrl)
 	ctx := mockpayload.NewMockIRequestContext(ctrl)
 
 	topicname := "test_topic"
-	pltopic := "\x00\x00\x00\x00\x00\x00test_topic"
 	pl := getPubsubRequestPayload(t, payload.SubCmd, topicname, []byte{})
+	client := model.NewClientRegistry().Register(nil, conn, time.Now())
 
 	ctx.EXPECT().GetPayload().Times(1).Return(&pl)
-	conn.EXPECT().RemoteAddr().Times(1).Return(addr)
-	ctx.EXPECT().GetPubsub(pltopic).Times(1).Return(nil)
-	addr.EXPECT().String().Times(1).Return("localhost")
-	ctx.EXPECT().SetPubsub(pltopic, gomock.Any()).Times(1)
+	ctx.EXPECT().AddPubsubConn(topicname, client.ID, gomock.Any()).Times(1)
 	ctx.EXPECT().GetConn().AnyTimes().Return(conn)
+	ctx.EXPECT().GetClient().Times(1).Return(client)
 
 	rawok := []byte{2, 0, 0, 0, 2, 79, 75}
 	test.ExpectWriteResponseToConn(t, conn, tlv.StringType, rawok)
@@ -53,37 +51,264 @@ func TestHandleSubRequest(t *testing.T) {
 	err := HandleRequest(ctx)
 
 	assert.Nil(t, err)
+	assert.Equal(t, []string{topicname}, client.Topics())
+}
+
+func TestHandleSubRequestMultipleTopics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	rest, _ := tlv.NewStringArray("topic_b")
+	rawrest, _ := rest.ToTLV()
+	pl := getPubsubRequestPayload(t, payload.SubCmd, "topic_a", rawrest)
+	client := model.NewClientRegistry().Register(nil, conn, time.Now())
+
+	ctx.EXPECT().GetPayload().Times(1).Return(&pl)
+	ctx.EXPECT().GetConn().AnyTimes().Return(conn)
+	ctx.EXPECT().AddPubsubConn("topic_a", client.ID, gomock.Any()).Times(1)
+	ctx.EXPECT().AddPubsubConn("topic_b", client.ID, gomock.Any()).Times(1)
+	ctx.EXPECT().GetClient().AnyTimes().Return(client)
+
+	rawok := []byte{2, 0, 0, 0, 2, 79, 75}
+	test.ExpectWriteResponseToConn(t, conn, tlv.StringType, rawok)
+
+	err := HandleRequest(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"topic_a", "topic_b"}, client.Topics())
+}
+
+func TestHandleUnsubRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	topicname := "test_topic"
+	pl := getPubsubRequestPayload(t, payload.UnsubCmd, topicname, []byte{})
+	client := model.NewClientRegistry().Register(nil, conn, time.Now())
+	client.Subscribe(topicname)
+	client.Subscribe("other_topic")
+
+	ctx.EXPECT().GetPayload().Times(1).Return(&pl)
+	ctx.EXPECT().GetClient().Times(1).Return(client)
+	ctx.EXPECT().RemovePubsubConn(topicname, client.ID).Times(1)
+	ctx.EXPECT().GetConn().AnyTimes().Return(conn)
+
+	left := tlv.Int64(1)
+	rawleft, _ := left.ToTLV()
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawleft)
+
+	err := HandleRequest(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"other_topic"}, client.Topics())
+}
+
+func TestHandleUnsubRequestEveryTopic(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	pl := getPubsubRequestPayload(t, payload.UnsubCmd, "", []byte{})
+	client := model.NewClientRegistry().Register(nil, conn, time.Now())
+	client.Subscribe("topic_a")
+	client.Subscribe("topic_b")
+
+	ctx.EXPECT().GetPayload().Times(1).Return(&pl)
+	ctx.EXPECT().GetClient().Times(1).Return(client)
+	ctx.EXPECT().RemovePubsubConn("topic_a", client.ID).Times(1)
+	ctx.EXPECT().RemovePubsubConn("topic_b", client.ID).Times(1)
+	ctx.EXPECT().GetConn().AnyTimes().Return(conn)
+
+	left := tlv.Int64(0)
+	rawleft, _ := left.ToTLV()
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawleft)
+
+	err := HandleRequest(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(client.Topics()))
+}
+
+func TestHandlePSubRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	pattern := "orders.*"
+	pl := getPubsubRequestPayload(t, payload.PSubCmd, pattern, []byte{})
+	client := model.NewClientRegistry().Register(nil, conn, time.Now())
+
+	ctx.EXPECT().GetPayload().Times(1).Return(&pl)
+	ctx.EXPECT().AddPatternConn(pattern, client.ID, gomock.Any()).Times(1)
+	ctx.EXPECT().GetConn().AnyTimes().Return(conn)
+	ctx.EXPECT().GetClient().Times(1).Return(client)
+
+	rawok := []byte{2, 0, 0, 0, 2, 79, 75}
+	test.ExpectWriteResponseToConn(t, conn, tlv.StringType, rawok)
+
+	err := HandleRequest(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{pattern}, client.Patterns())
+	assert.Equal(t, 0, len(client.Topics()))
+}
+
+func TestHandlePUnsubRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	pattern := "orders.*"
+	pl := getPubsubRequestPayload(t, payload.PUnsubCmd, "", []byte{})
+	client := model.NewClientRegistry().Register(nil, conn, time.Now())
+	client.PSubscribe(pattern)
+	client.Subscribe("test_topic")
+
+	ctx.EXPECT().GetPayload().Times(1).Return(&pl)
+	ctx.EXPECT().GetClient().Times(1).Return(client)
+	ctx.EXPECT().RemovePatternConn(pattern, client.ID).Times(1)
+	ctx.EXPECT().GetConn().AnyTimes().Return(conn)
+
+	// Topic subscribed to is still counted
+	left := tlv.Int64(1)
+	rawleft, _ := left.ToTLV()
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawleft)
+
+	err := HandleRequest(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(client.Patterns()))
+	assert.Equal(t, []string{"test_topic"}, client.Topics())
+}
+
+func TestHandleDisconnected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	topicname := "test_topic"
+	pattern := "orders.*"
+	client := model.NewClientRegistry().Register(nil, conn, time.Now())
+	client.Subscribe(topicname)
+	client.PSubscribe(pattern)
+
+	ctx.EXPECT().GetClient().Times(1).Return(client)
+	ctx.EXPECT().RemovePubsubConn(topicname, client.ID).Times(1)
+	ctx.EXPECT().RemovePatternConn(pattern, client.ID).Times(1)
+
+	err := HandleDisconnected(ctx)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(client.Topics()))
+	assert.Equal(t, 0, len(client.Patterns()))
 }
 
 func TestHandlePubRequest(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	conn := mocknet.NewMockConn(ctrl)
 	subconn := mocknet.NewMockConn(ctrl)
-	subaddr := mocknet.NewMockAddr(ctrl)
 	ctx := mockpayload.NewMockIRequestContext(ctrl)
 
 	topicname := "test_topic"
-	pltopic := "\x00\x00\x00\x00\x00\x00test_topic"
 	msg := []byte{2, 0, 0, 0, 8, 116, 101, 115, 116, 95, 109, 115, 103}
 	pl := getPubsubRequestPayload(t, payload.PubCmd, topicname, msg)
 
 	topic := &model.Topic[*tlv.String]{
-		Name: pltopic,
+		Name: topicname,
 		ConnDb: &db.KVStore[net.Conn]{
 			Storage: map[string]net.Conn{"localhost": subconn},
 		},
 	}
 
 	ctx.EXPECT().GetPayload().Times(1).Return(&pl)
-	ctx.EXPECT().GetPubsub(pltopic).Times(1).Return(topic)
+	ctx.EXPECT().GetPubsub(topicname).Times(1).Return(topic)
 	ctx.EXPECT().GetConn().AnyTimes().Return(conn)
+	ctx.EXPECT().MatchPatterns(topicname).Times(1).Return([]payload.StringTopic{})
 
-	subconn.EXPECT().RemoteAddr().Times(1).Return(subaddr)
-	subaddr.EXPECT().String().Times(1).Return("localhost")
-	test.ExpectWriteResponseToConn(t, subconn, tlv.MsgType, msg)
+	push, _ := tlv.NewStringArray(topicname, "test_msg")
+	rawpush, _ := push.ToTLV()
+	test.ExpectWriteResponseToConn(t, subconn, tlv.MsgType, rawpush)
 
-	rawok := []byte{2, 0, 0, 0, 2, 79, 75}
-	test.ExpectWriteResponseToConn(t, conn, tlv.StringType, rawok)
+	receivers := tlv.Int64(1)
+	rawreceivers, _ := receivers.ToTLV()
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawreceivers)
+
+	err := HandleRequest(ctx)
+
+	assert.Nil(t, err)
+}
+
+func TestHandlePubRequestToPattern(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	subconn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	topicname := "orders.eu.created"
+	pattern := "orders.*"
+	msg := tlv.String("test_msg")
+	rawmsg, _ := msg.ToTLV()
+	pl := getPubsubRequestPayload(t, payload.PubCmd, topicname, rawmsg)
+
+	topic := model.NewTopic[*tlv.String](topicname)
+	ptopic := model.NewTopic[*tlv.String](pattern)
+	ptopic.ConnDb.Set("localhost", subconn)
+
+	ctx.EXPECT().GetPayload().Times(1).Return(&pl)
+	ctx.EXPECT().GetPubsub(topicname).Times(1).Return(topic)
+	ctx.EXPECT().GetConn().AnyTimes().Return(conn)
+	ctx.EXPECT().MatchPatterns(topicname).Times(1).Return([]payload.StringTopic{ptopic})
+
+	push, _ := tlv.NewStringArray(pattern, topicname, "test_msg")
+	rawpush, _ := push.ToTLV()
+	test.ExpectWriteResponseToConn(t, subconn, tlv.MsgType, rawpush)
+
+	receivers := tlv.Int64(1)
+	rawreceivers, _ := receivers.ToTLV()
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawreceivers)
+
+	err := HandleRequest(ctx)
+
+	assert.Nil(t, err)
+}
+
+func TestHandlePubRequestWithoutTopic(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	topicname := "test_topic"
+	msg := tlv.String("test_msg")
+	rawmsg, _ := msg.ToTLV()
+	pl := getPubsubRequestPayload(t, payload.PubCmd, topicname, rawmsg)
+
+	ctx.EXPECT().GetPayload().Times(1).Return(&pl)
+	ctx.EXPECT().GetPubsub(topicname).Times(1).Return(nil)
+	ctx.EXPECT().SetPubsub(gomock.Any(), gomock.Any()).Times(0)
+	ctx.EXPECT().GetConn().AnyTimes().Return(conn)
+	ctx.EXPECT().MatchPatterns(topicname).Times(1).Return([]payload.StringTopic{})
+
+	receivers := tlv.Int64(0)
+	rawreceivers, _ := receivers.ToTLV()
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawreceivers)
+
+	err := HandleRequest(ctx)
+
+	assert.Nil(t, err)
+}
+
+func TestHandlePubRequestNotString(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	msg := tlv.Int64(1)
+	rawmsg, _ := msg.ToTLV()
+	pl := getPubsubRequestPayload(t, payload.PubCmd, "test_topic", rawmsg)
+
+	ctx.EXPECT().GetPayload().Times(1).Return(&pl)
+	ctx.EXPECT().Error(uint16(tlv.WrongTypeError), tlv.ErrMsg[tlv.WrongTypeError]).Times(1)
 
 	err := HandleRequest(ctx)
 
@@ -1,25 +1,63 @@
 package pubsub
 
 import (
+	"errors"
 	"net"
 
 	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
 	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
 )
 
-func SendSubRequest(conn net.Conn, topic string) (*Subscriber, error) {
+// newPubsubRequest put first topic in body and the rest in an array as its value
+func newPubsubRequest(cmd uint8, topics []string) (*payload.RequestPayload, error) {
 	body := payload.PubsubRequestBody{
-		Topic: topic,
 		Value: []byte{},
 	}
+	if len(topics) > 0 {
+		body.Topic = topics[0]
+	}
+	if len(topics) > 1 {
+		rest, err := tlv.NewStringArray(topics[1:]...)
+		if err != nil {
+			return nil, err
+		}
+		body.Value, err = rest.ToTLV()
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	rawbod, err := body.ToTLV()
 	if err != nil {
 		return nil, err
 	}
 
-	req := payload.RequestPayload{
-		Cmd:  payload.SubCmd,
+	return &payload.RequestPayload{
+		Cmd:  cmd,
 		Body: rawbod,
+	}, nil
+}
+
+func SendSubRequest(conn net.Conn, topics ...string) (*Subscriber, error) {
+	if len(topics) == 0 {
+		return nil, errors.New("Error: Sub require at least one topic")
+	}
+
+	return sendSubscribeRequest(conn, payload.SubCmd, topics)
+}
+
+func SendPSubRequest(conn net.Conn, patterns ...string) (*Subscriber, error) {
+	if len(patterns) == 0 {
+		return nil, errors.New("Error: PSub require at least one pattern")
+	}
+
+	return sendSubscribeRequest(conn, payload.PSubCmd, patterns)
+}
+
+func sendSubscribeRequest(conn net.Conn, cmd uint8, topics []string) (*Subscriber, error) {
+	req, err := newPubsubRequest(cmd, topics)
+	if err != nil {
+		return nil, err
 	}
 
 	_, err = req.WriteTo(conn)
@@ -37,14 +75,15 @@ func SendSubRequest(conn net.Conn, topic string) (*Subscriber, error) {
 	return sub, nil
 }
 
-func SendPubRequest(conn net.Conn, topic string, val tlv.TypeLengthValue) (string, error) {
+// SendPubRequest publish val on topic and return number of subscribers it was pushed to
+func SendPubRequest(conn net.Conn, topic string, val tlv.TypeLengthValue) (int, error) {
 	body := payload.PubsubRequestBody{
 		Topic: topic,
 		Value: val,
 	}
 	rawbod, err := body.ToTLV()
 	if err != nil {
-		return "", err
+		return 0, err
 	}
 
 	req := payload.RequestPayload{
@@ -54,19 +93,19 @@ func SendPubRequest(conn net.Conn, topic string, val tlv.TypeLengthValue) (strin
 
 	_, err = req.WriteTo(conn)
 	if err != nil {
-		return "", err
+		return 0, err
 	}
 
 	resp, err := payload.ReadResponse(conn)
 	if err != nil {
-		return "", err
+		return 0, err
 	}
 
-	msg := new(tlv.String)
-	err = msg.FromTLV(resp.Body)
+	receivers := new(tlv.Int64)
+	err = receivers.FromTLV(resp.Body)
 	if err != nil {
-		return "", err
+		return 0, err
 	}
 
-	return string(*msg), nil
+	return int(*receivers), nil
 }
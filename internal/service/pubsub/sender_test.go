@@ -33,6 +33,49 @@ func TestSendSubRequest(t *testing.T) {
 	assert.NotNil(t, sub)
 }
 
+func TestSendSubRequestMultipleTopics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	rest, _ := tlv.NewStringArray("topic_b", "topic_c")
+	rawrest, _ := rest.ToTLV()
+	reqbod := payload.PubsubRequestBody{
+		Topic: "topic_a",
+		Value: rawrest,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.SubCmd, rawreqbod)
+
+	resbod := tlv.String("OK")
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.StringType, rawresbod)
+
+	sub, err := SendSubRequest(conn, "topic_a", "topic_b", "topic_c")
+
+	assert.Nil(t, err)
+	assert.NotNil(t, sub)
+}
+
+func TestSendPSubRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	reqbod := payload.PubsubRequestBody{
+		Topic: "orders.*",
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.PSubCmd, rawreqbod)
+
+	resbod := tlv.String("OK")
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.StringType, rawresbod)
+
+	sub, err := SendPSubRequest(conn, "orders.*")
+
+	assert.Nil(t, err)
+	assert.NotNil(t, sub)
+}
+
 func TestSendPubRequest(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	conn := mocknet.NewMockConn(ctrl)
@@ -48,12 +91,12 @@ func TestSendPubRequest(t *testing.T) {
 	rawreqbod, _ := reqbod.ToTLV()
 	test.ExpectWriteRequestToConn(t, conn, payload.PubCmd, rawreqbod)
 
-	resbod := tlv.String("OK")
+	resbod := tlv.Int64(2)
 	rawresbod, _ := resbod.ToTLV()
-	test.ExpectReadResponseFromConn(t, conn, tlv.StringType, rawresbod)
+	test.ExpectReadResponseFromConn(t, conn, tlv.Int64Type, rawresbod)
 
 	resp, err := SendPubRequest(conn, topic, rawmsg)
 
 	assert.Nil(t, err)
-	assert.Equal(t, "OK", resp)
+	assert.Equal(t, 2, resp)
 }
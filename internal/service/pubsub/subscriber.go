@@ -10,43 +10,114 @@ import (
 	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
 )
 
+var (
+	// ErrUnsubscribed is returned once subscriber is no longer subscribed to any topic
+	ErrUnsubscribed = errors.New("Unsubscribed from every topic")
+	// ErrSubscriberBusy is returned by Next while messages are read by SubscribeMessages
+	ErrSubscriberBusy = errors.New("Subscriber is already reading messages")
+)
+
+// Message is pushed to subscriber for one of topics it subscribed to, Pattern is set when
+// topic matched pattern subscribed to rather than topic itself
+type Message struct {
+	Pattern string
+	Topic   string
+	Payload *tlv.String
+}
+
 type Subscriber struct {
 	Conn         net.Conn
 	IsSubscribed bool
 }
 
-func (sub *Subscriber) NextMessage() (*tlv.String, error) {
-	if !sub.IsSubscribed {
-		pl := new(payload.ResponsePayload)
-		_, err := pl.ReadFrom(sub.Conn)
-		if err != nil {
-			if err == io.EOF {
-				log.Println("Client disconnected")
-			}
-			return nil, err
-		}
+// Next return next message pushed to subscriber. Replies to PSubscribe and Unsubscribe are
+// read on the way, ErrUnsubscribed is returned when no topic is left.
+func (sub *Subscriber) Next() (*Message, error) {
+	if sub.IsSubscribed {
+		return nil, ErrSubscriberBusy
+	}
 
-		if pl.Typ != tlv.MsgType {
-			return nil, errors.New("Error not message type")
-		}
+	return sub.next()
+}
 
-		s := new(tlv.String)
-		err = s.FromTLV(pl.Body)
-		if err != nil {
-			return nil, err
-		}
+func (sub *Subscriber) NextMessage() (*tlv.String, error) {
+	if sub.IsSubscribed {
+		return nil, nil
+	}
 
-		return s, nil
+	msg, err := sub.Next()
+	if err != nil {
+		return nil, err
 	}
 
-	return nil, nil
+	return msg.Payload, nil
 }
 
 func (sub *Subscriber) Subscribe(handler func(string)) error {
+	return sub.SubscribeMessages(func(msg *Message) {
+		handler(msg.Payload.String())
+	})
+}
+
+// SubscribeMessages call handler with every message until connection fails, nil is returned
+// once subscriber is unsubscribed from every topic
+func (sub *Subscriber) SubscribeMessages(handler func(*Message)) error {
 	sub.IsSubscribed = true
 	defer func() {
 		sub.IsSubscribed = false
 	}()
+	for {
+		msg, err := sub.next()
+		if err == ErrUnsubscribed {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		handler(msg)
+	}
+}
+
+// PSubscribe add patterns to subscriber, reply is read by Next like one of Unsubscribe
+func (sub *Subscriber) PSubscribe(patterns ...string) error {
+	if len(patterns) == 0 {
+		return errors.New("Error: PSub require at least one pattern")
+	}
+
+	req, err := newPubsubRequest(payload.PSubCmd, patterns)
+	if err != nil {
+		return err
+	}
+
+	_, err = req.WriteTo(sub.Conn)
+	return err
+}
+
+// Unsubscribe stop messages of topics, or of every topic when none is given. Reply is read
+// by Next as messages sent before it may still come first.
+func (sub *Subscriber) Unsubscribe(topics ...string) error {
+	req, err := newPubsubRequest(payload.UnsubCmd, topics)
+	if err != nil {
+		return err
+	}
+
+	_, err = req.WriteTo(sub.Conn)
+	return err
+}
+
+// PUnsubscribe stop messages of patterns, or of every pattern when none is given, see Unsubscribe
+func (sub *Subscriber) PUnsubscribe(patterns ...string) error {
+	req, err := newPubsubRequest(payload.PUnsubCmd, patterns)
+	if err != nil {
+		return err
+	}
+
+	_, err = req.WriteTo(sub.Conn)
+	return err
+}
+
+func (sub *Subscriber) next() (*Message, error) {
 	for {
 		pl := new(payload.ResponsePayload)
 		_, err := pl.ReadFrom(sub.Conn)
@@ -54,21 +125,59 @@ func (sub *Subscriber) Subscribe(handler func(string)) error {
 			if err == io.EOF {
 				log.Println("Client disconnected")
 			}
-			return err
+			return nil, err
 		}
 
-		if pl.Typ != tlv.MsgType {
-			return errors.New("Error not message type")
+		switch pl.Typ {
+		case tlv.MsgType:
+			return readMessage(pl.Body)
+		case tlv.StringType:
+			// Reply to PSubscribe
+		case tlv.Int64Type:
+			// Reply to Unsubscribe tell how many topics and patterns are left
+			left := new(tlv.Int64)
+			err = left.FromTLV(pl.Body)
+			if err != nil {
+				return nil, err
+			}
+			if *left == 0 {
+				return nil, ErrUnsubscribed
+			}
+		default:
+			return nil, errors.New("Error not message type")
 		}
+	}
+}
 
-		s := new(tlv.String)
-		err = s.FromTLV(pl.Body)
-		if err != nil {
-			return err
-		}
+// readMessage decode body of push, an array of topic and message with pattern first when
+// message is for pattern subscription
+func readMessage(body tlv.TypeLengthValue) (*Message, error) {
+	arr := new(tlv.Array)
+	err := arr.FromTLV(body)
+	if err != nil {
+		return nil, err
+	}
+	if len(*arr) != 2 && len(*arr) != 3 {
+		return nil, errors.New("Invalid message")
+	}
+
+	prefix := (*arr)[:len(*arr)-1]
+	names, err := prefix.Strings()
+	if err != nil {
+		return nil, err
+	}
+
+	s := new(tlv.String)
+	err = s.FromTLV((*arr)[len(*arr)-1])
+	if err != nil {
+		return nil, err
+	}
 
-		handler(s.String())
+	msg := &Message{Topic: names[len(names)-1], Payload: s}
+	if len(names) == 2 {
+		msg.Pattern = names[0]
 	}
+	return msg, nil
 }
 
 func NewSubscriber(conn net.Conn) *Subscriber {
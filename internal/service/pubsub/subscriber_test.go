@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
 	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/test"
 	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
 	"github.com/stretchr/testify/assert"
@@ -45,19 +46,123 @@ func TestNextMesssageIsSubscribe(t *testing.T) {
 func TestNextMesssageIsNotSubscribe(t *testing.T) {
 	sub, conn := getTestSubscriber(t)
 
-	typ := []byte{0x3}
-	blen := []byte{0x0, 0x0, 0x0, 0xe}
-	val := []byte{0xc, 0x2, 0x0, 0x0, 0x0, 0x8, 0x74, 0x65, 0x73, 0x74, 0x5f, 0x6d, 0x73, 0x67}
-
-	conn.EXPECT().Read(gomock.Any()).SetArg(0, typ).Return(len(typ), nil)
-	conn.EXPECT().Read(gomock.Any()).SetArg(0, blen).Return(len(blen), nil)
-	conn.EXPECT().Read(gomock.Any()).SetArg(0, val).Return(len(val), nil)
+	push, _ := tlv.NewStringArray("test_topic", "test_msg")
+	raw, _ := push.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.MsgType, raw)
 	msg, err := sub.NextMessage()
 
 	assert.Nil(t, err)
 	assert.Equal(t, "test_msg", msg.String())
 }
 
+func TestNextWithTopic(t *testing.T) {
+	sub, conn := getTestSubscriber(t)
+
+	push, _ := tlv.NewStringArray("test_topic", "test_msg")
+	raw, _ := push.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.MsgType, raw)
+	msg, err := sub.Next()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "test_topic", msg.Topic)
+	assert.Equal(t, "test_msg", msg.Payload.String())
+}
+
+func TestNextWithPattern(t *testing.T) {
+	sub, conn := getTestSubscriber(t)
+
+	push, _ := tlv.NewStringArray("orders.*", "orders.eu.created", "test_msg")
+	raw, _ := push.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.MsgType, raw)
+	msg, err := sub.Next()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "orders.*", msg.Pattern)
+	assert.Equal(t, "orders.eu.created", msg.Topic)
+	assert.Equal(t, "test_msg", msg.Payload.String())
+}
+
+func TestNextSkipUnsubscribeReply(t *testing.T) {
+	sub, conn := getTestSubscriber(t)
+
+	left := tlv.Int64(1)
+	rawleft, _ := left.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.Int64Type, rawleft)
+	push, _ := tlv.NewStringArray("test_topic", "test_msg")
+	raw, _ := push.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.MsgType, raw)
+	msg, err := sub.Next()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "test_msg", msg.Payload.String())
+}
+
+func TestNextSkipPSubscribeReply(t *testing.T) {
+	sub, conn := getTestSubscriber(t)
+
+	ok := tlv.String("OK")
+	rawok, _ := ok.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.StringType, rawok)
+	push, _ := tlv.NewStringArray("orders.*", "orders.eu.created", "test_msg")
+	raw, _ := push.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.MsgType, raw)
+	msg, err := sub.Next()
+
+	assert.Nil(t, err)
+	assert.Equal(t, "orders.*", msg.Pattern)
+}
+
+func TestNextIsSubscribe(t *testing.T) {
+	sub, _ := getTestSubscriber(t)
+	sub.IsSubscribed = true
+	msg, err := sub.Next()
+
+	assert.Nil(t, msg)
+	assert.Equal(t, ErrSubscriberBusy, err)
+}
+
+func TestNextUnsubscribedFromEveryTopic(t *testing.T) {
+	sub, conn := getTestSubscriber(t)
+
+	left := tlv.Int64(0)
+	rawleft, _ := left.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.Int64Type, rawleft)
+	msg, err := sub.Next()
+
+	assert.Equal(t, ErrUnsubscribed, err)
+	assert.Nil(t, msg)
+}
+
+func TestUnsubscribe(t *testing.T) {
+	sub, conn := getTestSubscriber(t)
+
+	topics, _ := tlv.NewStringArray("topic_b")
+	rawtopics, _ := topics.ToTLV()
+	reqbod := payload.PubsubRequestBody{
+		Topic: "topic_a",
+		Value: rawtopics,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.UnsubCmd, rawreqbod)
+
+	err := sub.Unsubscribe("topic_a", "topic_b")
+
+	assert.Nil(t, err)
+}
+
+func TestPSubscribe(t *testing.T) {
+	sub, conn := getTestSubscriber(t)
+
+	reqbod := payload.PubsubRequestBody{Topic: "orders.*", Value: []byte{}}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.PSubCmd, rawreqbod)
+
+	err := sub.PSubscribe("orders.*")
+
+	assert.Nil(t, err)
+	assert.NotNil(t, sub.PSubscribe())
+}
+
 func TestNextMesssageEOF(t *testing.T) {
 	sub, conn := getTestSubscriber(t)
 
@@ -85,16 +190,28 @@ func TestNextMesssageIsNotMsgType(t *testing.T) {
 	assert.Nil(t, msg)
 }
 
+func TestPUnsubscribe(t *testing.T) {
+	sub, conn := getTestSubscriber(t)
+
+	reqbod := payload.PubsubRequestBody{}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.PUnsubCmd, rawreqbod)
+
+	err := sub.PUnsubscribe()
+
+	assert.Nil(t, err)
+}
+
 func TestSubscribeToTopic(t *testing.T) {
 	sub, conn := getTestSubscriber(t)
 
-	msg := tlv.String("test_msg")
-	raw, _ := msg.ToTLV()
+	push, _ := tlv.NewStringArray("test_topic", "test_msg")
+	raw, _ := push.ToTLV()
 	test.ExpectReadResponseFromConn(t, conn, tlv.MsgType, raw)
 
 	conn.EXPECT().Read(gomock.Any()).Return(0, io.EOF)
 
 	sub.Subscribe(func(s string) {
-		assert.Equal(t, s, msg.String())
+		assert.Equal(t, "test_msg", s)
 	})
 }
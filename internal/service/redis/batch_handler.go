@@ -0,0 +1,74 @@
+package redis
+
+import (
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/helper"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+// Values of keys are read at once and replied in order of keys, missing key and key not
+// holding a string get Nil
+func handleMGetRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	keys, err := readArgs(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if len(keys) == 0 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	reply := make(tlv.Array, len(keys))
+	err = ctx.ViewKeysRedis(keys, func(vs []*model.Value) error {
+		for i, v := range vs {
+			reply[i] = tlv.NilValue
+			if v != nil && v.Type == model.StringValueType {
+				reply[i] = v.Str
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	return helper.ResponseWithTLV(&reply, ctx)
+}
+
+// Pairs to set are sent as map of key to value. MSETNX set none of them when any key exists
+// and reply 1 only when they were all set.
+func handleMSetRequest(ctx payload.IRequestContext, cmd uint8, body *payload.RedisRequestBody) error {
+	pairs := new(tlv.Map)
+	err := pairs.FromTLV(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.SyntaxError), ctx)
+	}
+
+	if len(*pairs) == 0 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	keys := pairs.Keys()
+	set := false
+	err = ctx.AtomicRedis(func(txctx payload.IRequestContext) error {
+		if cmd == payload.MSetNXCmd && txctx.ExistsRedis(keys...) > 0 {
+			return nil
+		}
+
+		for _, k := range keys {
+			txctx.SetRedis(k, (*pairs)[k])
+		}
+		set = true
+		return nil
+	})
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if cmd == payload.MSetNXCmd {
+		return helper.ResponseWithString(boolToReply(set), ctx)
+	}
+
+	return helper.ResponseWithString("OK", ctx)
+}
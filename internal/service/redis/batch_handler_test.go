@@ -0,0 +1,149 @@
+package redis
+
+import (
+	"testing"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
+	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
+	mockpayload "bitbucket.org/non-pn/mini-redis-go/internal/mock/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/test"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandleMGetRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	keys := []string{"test_key_1", "test_key_2", "test_key_3"}
+	args, _ := tlv.NewStringArray(keys...)
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("", rawargs)
+	s, b := tlv.String("test_val"), tlv.Binary("test_bin")
+	raws, _ := s.ToTLV()
+	rawb, _ := b.ToTLV()
+	reply := tlv.Array{raws, tlv.NilValue, rawb}
+	rawreply, _ := reply.ToTLV()
+
+	ctx.EXPECT().ViewKeysRedis(keys, gomock.Any()).Times(1).DoAndReturn(func(keys []string, fn func(vs []*model.Value) error) error {
+		return fn([]*model.Value{model.NewStringValue(raws), nil, model.NewStringValue(rawb)})
+	})
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.ArrayType, rawreply)
+
+	err := handleMGetRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleMGetRequestWrongType(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	args, _ := tlv.NewStringArray("test_list")
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("", rawargs)
+	reply := tlv.Array{tlv.NilValue}
+	rawreply, _ := reply.ToTLV()
+
+	// Key of other type is nil instead of an error
+	ctx.EXPECT().ViewKeysRedis([]string{"test_list"}, gomock.Any()).Times(1).DoAndReturn(func(keys []string, fn func(vs []*model.Value) error) error {
+		return fn([]*model.Value{model.NewListValue()})
+	})
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.ArrayType, rawreply)
+
+	err := handleMGetRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleMGetRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	rawkeys, _ := (&tlv.Array{}).ToTLV()
+	bod := getRedisRequestBody("", rawkeys)
+	expectErrorReply(ctx, tlv.WrongArgumentsError)
+
+	err := handleMGetRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleMSetRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	v1, v2 := tlv.String("test_val_1"), tlv.Int64(2)
+	pairs := tlv.Map{}
+	pairs.Put("test_key_1", &v1)
+	pairs.Put("test_key_2", &v2)
+	rawpairs, _ := pairs.ToTLV()
+	bod := getRedisRequestBody("", rawpairs)
+	ok := tlv.String("OK")
+	rawok, _ := ok.ToTLV()
+
+	expectAtomicRedis(ctx)
+	ctx.EXPECT().SetRedis("test_key_1", pairs["test_key_1"]).Times(1)
+	ctx.EXPECT().SetRedis("test_key_2", pairs["test_key_2"]).Times(1)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.StringType, rawok)
+
+	err := handleMSetRequest(ctx, payload.MSetCmd, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleMSetNXRequestKeyExists(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	v1, v2 := tlv.String("test_val_1"), tlv.String("test_val_2")
+	pairs := tlv.Map{}
+	pairs.Put("test_key_1", &v1)
+	pairs.Put("test_key_2", &v2)
+	rawpairs, _ := pairs.ToTLV()
+	bod := getRedisRequestBody("", rawpairs)
+	zero := tlv.String("0")
+	rawzero, _ := zero.ToTLV()
+
+	// One existing key is enough for none to be set
+	expectAtomicRedis(ctx)
+	ctx.EXPECT().ExistsRedis("test_key_1", "test_key_2").Times(1).Return(1)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.StringType, rawzero)
+
+	err := handleMSetRequest(ctx, payload.MSetNXCmd, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleMSetRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	// Pairs not sent as map
+	args, _ := tlv.NewStringArray("k", "v")
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("", rawargs)
+	expectErrorReply(ctx, tlv.SyntaxError)
+
+	err := handleMSetRequest(ctx, payload.MSetCmd, &bod)
+
+	assert.Nil(t, err)
+
+	rawpairs, _ := (&tlv.Map{}).ToTLV()
+	bod = getRedisRequestBody("", rawpairs)
+	expectErrorReply(ctx, tlv.WrongArgumentsError)
+
+	err = handleMSetRequest(ctx, payload.MSetNXCmd, &bod)
+
+	assert.Nil(t, err)
+}
@@ -0,0 +1,82 @@
+package redis
+
+import (
+	"net"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+// SendMGetRequest return values of keys in order of keys, nil for missing key
+func SendMGetRequest(conn net.Conn, keys []string) ([]tlv.TLVCompatible, error) {
+	args, err := tlv.NewStringArray(keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	rawargs, err := args.ToTLV()
+	if err != nil {
+		return nil, err
+	}
+
+	body := payload.RedisRequestBody{
+		Value: rawargs,
+	}
+
+	resp, err := sendRequest(conn, payload.MGetCmd, body)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := new(tlv.Array)
+	err = reply.FromTLV(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	vals, err := reply.Values()
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range vals {
+		if _, ok := v.(*tlv.Nil); ok {
+			vals[i] = nil
+		}
+	}
+
+	return vals, nil
+}
+
+// SendMSetRequest set every key of pairs to its value at once
+func SendMSetRequest(conn net.Conn, pairs tlv.Map) (string, error) {
+	rawpairs, err := pairs.ToTLV()
+	if err != nil {
+		return "", err
+	}
+
+	body := payload.RedisRequestBody{
+		Value: rawpairs,
+	}
+
+	return sendStringRequest(conn, payload.MSetCmd, body)
+}
+
+// SendMSetNXRequest set every key of pairs only when none of them exists, it return whether
+// keys were set
+func SendMSetNXRequest(conn net.Conn, pairs tlv.Map) (bool, error) {
+	rawpairs, err := pairs.ToTLV()
+	if err != nil {
+		return false, err
+	}
+
+	body := payload.RedisRequestBody{
+		Value: rawpairs,
+	}
+
+	resp, err := sendStringRequest(conn, payload.MSetNXCmd, body)
+	if err != nil {
+		return false, err
+	}
+
+	return resp == "1", nil
+}
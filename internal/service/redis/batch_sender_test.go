@@ -0,0 +1,61 @@
+package redis
+
+import (
+	"testing"
+
+	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/test"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSendMGetRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	args, _ := tlv.NewStringArray("test_key_1", "test_key_2")
+	rawargs, _ := args.ToTLV()
+	reqbod := payload.RedisRequestBody{
+		Value: rawargs,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.MGetCmd, rawreqbod)
+
+	val := tlv.String("test_val")
+	resbod := tlv.Array{}
+	resbod.Append(&tlv.Nil{})
+	resbod.Append(&val)
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.ArrayType, rawresbod)
+
+	vals, err := SendMGetRequest(conn, []string{"test_key_1", "test_key_2"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []tlv.TLVCompatible{nil, &val}, vals)
+}
+
+func TestSendMSetNXRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	val := tlv.String("test_val")
+	pairs := tlv.Map{}
+	pairs.Put("test_key", &val)
+	rawpairs, _ := pairs.ToTLV()
+	reqbod := payload.RedisRequestBody{
+		Value: rawpairs,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.MSetNXCmd, rawreqbod)
+
+	resbod := tlv.String("1")
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.StringType, rawresbod)
+
+	ok, err := SendMSetNXRequest(conn, pairs)
+
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}
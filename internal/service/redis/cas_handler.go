@@ -0,0 +1,177 @@
+package redis
+
+import (
+	"bytes"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/helper"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+const (
+	// Set condition making SET only write missing key
+	setNX = "NX"
+	// Set condition making SET only write existing key
+	setXX = "XX"
+)
+
+// Set arguments are value, condition NX, XX or empty, and 1 when previous value is wanted.
+// Reply is array of 1 when key was set and 0 otherwise, followed by previous value when it
+// was asked and key held one.
+func handleSetArgsRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	args, err := readArray(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if len(*args) != 3 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	cond, get := new(tlv.String), new(tlv.String)
+	err = cond.FromTLV((*args)[1])
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.SyntaxError), ctx)
+	}
+	err = get.FromTLV((*args)[2])
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.SyntaxError), ctx)
+	}
+
+	if *cond != "" && *cond != setNX && *cond != setXX {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.SyntaxError), ctx)
+	}
+
+	var (
+		set  bool
+		prev []byte
+	)
+	err = ctx.AtomicRedis(func(txctx payload.IRequestContext) error {
+		if *get == "1" {
+			var err error
+			prev, err = txctx.GetRedis(body.Key)
+			if err != nil {
+				return err
+			}
+		}
+
+		exists := txctx.ExistsRedis(body.Key) > 0
+		if (*cond == setNX && exists) || (*cond == setXX && !exists) {
+			return nil
+		}
+
+		setRedis(txctx, body.Key, (*args)[0], body.TTL)
+		set = true
+		return nil
+	})
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	n := tlv.Int64(0)
+	if set {
+		n = 1
+	}
+	reply := tlv.Array{}
+	err = reply.Append(&n)
+	if err != nil {
+		return err
+	}
+	if len(prev) > 0 {
+		reply = append(reply, tlv.TypeLengthValue(prev))
+	}
+
+	return helper.ResponseWithTLV(&reply, ctx)
+}
+
+// CAS arguments are new value and value key must hold, key must be missing when there is
+// no second argument. Values are compared as encoded so they must be of same tlv type.
+// Reply is version of key once written, 0 when it was not.
+func handleCASRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	args, err := readArray(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if len(*args) != 1 && len(*args) != 2 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	var version uint64
+	err = ctx.AtomicRedis(func(txctx payload.IRequestContext) error {
+		cur, err := txctx.GetRedis(body.Key)
+		if err != nil {
+			return err
+		}
+
+		if len(*args) == 1 && cur != nil {
+			return nil
+		}
+		if len(*args) == 2 && !bytes.Equal(cur, (*args)[1]) {
+			return nil
+		}
+
+		setRedis(txctx, body.Key, (*args)[0], body.TTL)
+		version = txctx.VersionRedis(body.Key)
+		return nil
+	})
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	res := tlv.Int64(version)
+	return helper.ResponseWithTLV(&res, ctx)
+}
+
+// CAS by version arguments are new value and version key must have, version 0 is missing
+// key. Key of any type is overwritten like SET does. Reply is version of key once written,
+// 0 when it was not.
+func handleCASVersionRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	args, err := readArray(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if len(*args) != 2 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	expected := new(tlv.Int64)
+	err = expected.FromTLV((*args)[1])
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.NotIntegerError), ctx)
+	}
+
+	var version uint64
+	err = ctx.AtomicRedis(func(txctx payload.IRequestContext) error {
+		if txctx.VersionRedis(body.Key) != uint64(*expected) {
+			return nil
+		}
+
+		setRedis(txctx, body.Key, (*args)[0], body.TTL)
+		version = txctx.VersionRedis(body.Key)
+		return nil
+	})
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	res := tlv.Int64(version)
+	return helper.ResponseWithTLV(&res, ctx)
+}
+
+func handleGetVersionRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	res := tlv.Int64(ctx.VersionRedis(body.Key))
+
+	return helper.ResponseWithTLV(&res, ctx)
+}
+
+// setRedis write string value like SET, zero ttl remove ttl of key
+func setRedis(ctx payload.IRequestContext, k string, v []byte, ttl time.Duration) {
+	if ttl > 0 {
+		ctx.SetRedisWithTTL(k, v, ttl)
+	} else {
+		ctx.SetRedis(k, v)
+	}
+}
@@ -0,0 +1,254 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
+	mockpayload "bitbucket.org/non-pn/mini-redis-go/internal/mock/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/test"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func getSetArgs(val tlv.TypeLengthValue, cond string, get string) tlv.TypeLengthValue {
+	args, _ := tlv.NewStringArray(cond, get)
+	*args = append(tlv.Array{val}, *args...)
+	raw, _ := args.ToTLV()
+	return raw
+}
+
+func expectAtomicRedis(ctx *mockpayload.MockIRequestContext) {
+	ctx.EXPECT().AtomicRedis(gomock.Any()).Times(1).DoAndReturn(func(fn func(ctx payload.IRequestContext) error) error {
+		return fn(ctx)
+	})
+}
+
+func TestHandleSetArgsRequestNX(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	val := tlv.String("test_val")
+	rawval, _ := val.ToTLV()
+	bod := getRedisRequestBody("test_key", getSetArgs(rawval, setNX, "0"))
+	bod.TTL = time.Second
+	set := tlv.Int64(1)
+	reply := tlv.Array{}
+	reply.Append(&set)
+	rawreply, _ := reply.ToTLV()
+
+	expectAtomicRedis(ctx)
+	ctx.EXPECT().ExistsRedis("test_key").Times(1).Return(0)
+	ctx.EXPECT().SetRedisWithTTL("test_key", rawval, time.Second).Times(1)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.ArrayType, rawreply)
+
+	err := handleSetArgsRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleSetArgsRequestXXWithGet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	val := tlv.String("test_val")
+	rawval, _ := val.ToTLV()
+	bod := getRedisRequestBody("test_key", getSetArgs(rawval, setXX, "1"))
+	set := tlv.Int64(0)
+	reply := tlv.Array{}
+	reply.Append(&set)
+	rawreply, _ := reply.ToTLV()
+
+	// Missing key is not set and has no previous value
+	expectAtomicRedis(ctx)
+	ctx.EXPECT().GetRedis("test_key").Times(1).Return(nil, nil)
+	ctx.EXPECT().ExistsRedis("test_key").Times(1).Return(0)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.ArrayType, rawreply)
+
+	err := handleSetArgsRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleSetArgsRequestGet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	val, prev := tlv.String("test_val"), tlv.String("test_prev")
+	rawval, _ := val.ToTLV()
+	rawprev, _ := prev.ToTLV()
+	bod := getRedisRequestBody("test_key", getSetArgs(rawval, "", "1"))
+	set := tlv.Int64(1)
+	reply := tlv.Array{}
+	reply.Append(&set)
+	reply.Append(&prev)
+	rawreply, _ := reply.ToTLV()
+
+	expectAtomicRedis(ctx)
+	ctx.EXPECT().GetRedis("test_key").Times(1).Return([]byte(rawprev), nil)
+	ctx.EXPECT().ExistsRedis("test_key").Times(1).Return(1)
+	ctx.EXPECT().SetRedis("test_key", rawval).Times(1)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.ArrayType, rawreply)
+
+	err := handleSetArgsRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleSetArgsRequestSyntaxError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	val := tlv.String("test_val")
+	rawval, _ := val.ToTLV()
+	bod := getRedisRequestBody("test_key", getSetArgs(rawval, setNX+setXX, "0"))
+
+	ctx.EXPECT().Error(uint16(tlv.SyntaxError), tlv.ErrMsg[tlv.SyntaxError]).Times(1)
+
+	err := handleSetArgsRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleSetArgsRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	args, _ := tlv.NewStringArray("test_val", setNX)
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("test_key", rawargs)
+	expectErrorReply(ctx, tlv.WrongArgumentsError)
+
+	err := handleSetArgsRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleCASRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	val, expected := tlv.String("test_val"), tlv.String("test_prev")
+	rawval, _ := val.ToTLV()
+	rawexpected, _ := expected.ToTLV()
+	args := tlv.Array{rawval, rawexpected}
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("test_key", rawargs)
+	version := tlv.Int64(7)
+	rawversion, _ := version.ToTLV()
+
+	expectAtomicRedis(ctx)
+	ctx.EXPECT().GetRedis("test_key").Times(1).Return([]byte(rawexpected), nil)
+	ctx.EXPECT().SetRedis("test_key", rawval).Times(1)
+	ctx.EXPECT().VersionRedis("test_key").Times(1).Return(uint64(7))
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawversion)
+
+	err := handleCASRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleCASRequestMismatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	val, cur := tlv.String("test_val"), tlv.String("test_cur")
+	rawval, _ := val.ToTLV()
+	rawcur, _ := cur.ToTLV()
+	// Only new value is given so key must be missing
+	args := tlv.Array{rawval}
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("test_key", rawargs)
+	version := tlv.Int64(0)
+	rawversion, _ := version.ToTLV()
+
+	expectAtomicRedis(ctx)
+	ctx.EXPECT().GetRedis("test_key").Times(1).Return([]byte(rawcur), nil)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawversion)
+
+	err := handleCASRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleCASRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	val := tlv.String("new")
+	rawval, _ := val.ToTLV()
+	bod := getRedisRequestBody("test_key", rawval)
+	expectErrorReply(ctx, tlv.SyntaxError)
+
+	err := handleCASRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+
+	rawargs, _ := (&tlv.Array{}).ToTLV()
+	bod = getRedisRequestBody("test_key", rawargs)
+	expectErrorReply(ctx, tlv.WrongArgumentsError)
+
+	err = handleCASRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleCASVersionRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	val, expected := tlv.String("test_val"), tlv.Int64(3)
+	rawval, _ := val.ToTLV()
+	rawexpected, _ := expected.ToTLV()
+	args := tlv.Array{rawval, rawexpected}
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("test_key", rawargs)
+	version := tlv.Int64(0)
+	rawversion, _ := version.ToTLV()
+
+	// Key written since version 3 is not overwritten
+	expectAtomicRedis(ctx)
+	ctx.EXPECT().VersionRedis("test_key").Times(1).Return(uint64(4))
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawversion)
+
+	err := handleCASVersionRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleCASVersionRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	args, _ := tlv.NewStringArray("new", "1")
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("test_key", rawargs)
+	expectErrorReply(ctx, tlv.NotIntegerError)
+
+	err := handleCASVersionRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+
+	args, _ = tlv.NewStringArray("new")
+	rawargs, _ = args.ToTLV()
+	bod = getRedisRequestBody("test_key", rawargs)
+	expectErrorReply(ctx, tlv.WrongArgumentsError)
+
+	err = handleCASVersionRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
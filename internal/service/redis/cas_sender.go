@@ -0,0 +1,143 @@
+package redis
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+// SetOptions are conditions of SET, zero value set key whatever it holds
+type SetOptions struct {
+	// NX set key only when it is missing, XX only when it exists
+	NX bool
+	XX bool
+	// Get return previous value of key, whether key was set or not
+	Get bool
+	// Time to live of key once set, zero mean no ttl
+	TTL time.Duration
+}
+
+// SendSetArgsRequest set key to val when opts allow it and return whether it was set, and
+// previous value of key when opts.Get is set
+func SendSetArgsRequest(conn net.Conn, key string, val tlv.TypeLengthValue, opts SetOptions) (bool, tlv.TLVCompatible, error) {
+	cond := ""
+	if opts.NX {
+		cond = setNX
+	}
+	if opts.XX {
+		cond += setXX
+	}
+	get := "0"
+	if opts.Get {
+		get = "1"
+	}
+
+	args, err := tlv.NewStringArray(cond, get)
+	if err != nil {
+		return false, nil, err
+	}
+	*args = append(tlv.Array{val}, *args...)
+
+	rawargs, err := args.ToTLV()
+	if err != nil {
+		return false, nil, err
+	}
+
+	body := payload.RedisRequestBody{
+		Key:   key,
+		Value: rawargs,
+		TTL:   opts.TTL,
+	}
+
+	resp, err := sendRequest(conn, payload.SetArgsCmd, body)
+	if err != nil {
+		return false, nil, err
+	}
+
+	// Reply is array of set flag and previous value when key held one
+	reply := new(tlv.Array)
+	err = reply.FromTLV(resp.Body)
+	if err != nil {
+		return false, nil, err
+	}
+
+	if len(*reply) == 0 {
+		return false, nil, errors.New("Invalid set reply")
+	}
+
+	set := new(tlv.Int64)
+	err = set.FromTLV((*reply)[0])
+	if err != nil {
+		return false, nil, err
+	}
+
+	var prev tlv.TLVCompatible
+	if len(*reply) > 1 {
+		rawprev := (*reply)[1]
+		prev, err = decodeValue(rawprev.GetType(), rawprev)
+		if err != nil {
+			return false, nil, err
+		}
+	}
+
+	return *set == 1, prev, nil
+}
+
+// SendCASRequest set key to val only when it hold expected, or when it is missing if
+// expected is nil. It return version of key once set, 0 when it was not set.
+func SendCASRequest(conn net.Conn, key string, expected tlv.TypeLengthValue, val tlv.TypeLengthValue, ttl time.Duration) (uint64, error) {
+	args := tlv.Array{val}
+	if expected != nil {
+		args = append(args, expected)
+	}
+
+	return sendCASRequest(conn, payload.CASCmd, key, args, ttl)
+}
+
+// SendCASVersionRequest set key to val only when its version is version, version 0 is
+// missing key. It return version of key once set, 0 when it was not set.
+func SendCASVersionRequest(conn net.Conn, key string, version uint64, val tlv.TypeLengthValue, ttl time.Duration) (uint64, error) {
+	v := tlv.Int64(version)
+	rawv, err := v.ToTLV()
+	if err != nil {
+		return 0, err
+	}
+
+	return sendCASRequest(conn, payload.CASVersionCmd, key, tlv.Array{val, rawv}, ttl)
+}
+
+// SendGetVersionRequest return version of key, it change on every write and is 0 for
+// missing key
+func SendGetVersionRequest(conn net.Conn, key string) (uint64, error) {
+	n, err := sendInt64Request(conn, payload.GetVersionCmd, key, []byte{})
+	return uint64(n), err
+}
+
+func sendCASRequest(conn net.Conn, cmd uint8, key string, args tlv.Array, ttl time.Duration) (uint64, error) {
+	rawargs, err := args.ToTLV()
+	if err != nil {
+		return 0, err
+	}
+
+	body := payload.RedisRequestBody{
+		Key:   key,
+		Value: rawargs,
+		TTL:   ttl,
+	}
+
+	resp, err := sendRequest(conn, cmd, body)
+	if err != nil {
+		return 0, err
+	}
+
+	version := new(tlv.Int64)
+	err = version.FromTLV(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(*version), nil
+}
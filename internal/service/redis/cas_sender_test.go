@@ -0,0 +1,93 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/test"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSendSetArgsRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	val, prev := tlv.String("test_val"), tlv.String("test_prev")
+	rawval, _ := val.ToTLV()
+	reqbod := payload.RedisRequestBody{
+		Key:   "test_key",
+		Value: getSetArgs(rawval, setXX, "1"),
+		TTL:   time.Second,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.SetArgsCmd, rawreqbod)
+
+	set := tlv.Int64(1)
+	resbod := tlv.Array{}
+	resbod.Append(&set)
+	resbod.Append(&prev)
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.ArrayType, rawresbod)
+
+	ok, res, err := SendSetArgsRequest(conn, "test_key", rawval, SetOptions{XX: true, Get: true, TTL: time.Second})
+
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, &prev, res)
+}
+
+func TestSendCASRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	val, expected := tlv.String("test_val"), tlv.String("test_prev")
+	rawval, _ := val.ToTLV()
+	rawexpected, _ := expected.ToTLV()
+	args := tlv.Array{rawval, rawexpected}
+	rawargs, _ := args.ToTLV()
+	reqbod := payload.RedisRequestBody{
+		Key:   "test_key",
+		Value: rawargs,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.CASCmd, rawreqbod)
+
+	resbod := tlv.Int64(7)
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.Int64Type, rawresbod)
+
+	version, err := SendCASRequest(conn, "test_key", rawexpected, rawval, 0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(7), version)
+}
+
+func TestSendCASVersionRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	val, expected := tlv.String("test_val"), tlv.Int64(3)
+	rawval, _ := val.ToTLV()
+	rawexpected, _ := expected.ToTLV()
+	args := tlv.Array{rawval, rawexpected}
+	rawargs, _ := args.ToTLV()
+	reqbod := payload.RedisRequestBody{
+		Key:   "test_key",
+		Value: rawargs,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.CASVersionCmd, rawreqbod)
+
+	resbod := tlv.Int64(0)
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.Int64Type, rawresbod)
+
+	version, err := SendCASVersionRequest(conn, "test_key", 3, rawval, 0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, uint64(0), version)
+}
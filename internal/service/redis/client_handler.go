@@ -0,0 +1,110 @@
+package redis
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/helper"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+// Filters accepted by CLIENT KILL
+const (
+	ClientKillByID   = "ID"
+	ClientKillByAddr = "ADDR"
+)
+
+// Clients are replied as one string, a line of key=value pairs per client
+func handleClientListRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	return helper.ResponseWithString(ctx.GetClients().Info(time.Now()), ctx)
+}
+
+// Filter and its value are sent as string array, reply is number of clients killed.
+// Client killing itself get the reply before its connection is closed.
+func handleClientKillRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	args, err := readArgs(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if len(args) != 2 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	var match func(c *model.Client) bool
+	switch strings.ToUpper(args[0]) {
+	case ClientKillByID:
+		id, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			return helper.ResponseWithError(tlv.NewCodeError(tlv.NotIntegerError), ctx)
+		}
+		match = func(c *model.Client) bool {
+			return c.ID == id
+		}
+		break
+	case ClientKillByAddr:
+		match = func(c *model.Client) bool {
+			return c.Conn.RemoteAddr().String() == args[1]
+		}
+		break
+	default:
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.SyntaxError), ctx)
+	}
+
+	self := ctx.GetClient()
+	killSelf := false
+	clients := ctx.GetClients().Find(match)
+	for _, c := range clients {
+		if c == self {
+			killSelf = true
+			continue
+		}
+		c.Close()
+	}
+
+	n := tlv.Int64(len(clients))
+	err = helper.ResponseWithTLV(&n, ctx)
+	if killSelf {
+		self.Close()
+	}
+	return err
+}
+
+// Name is sent as string, empty name clear it
+func handleClientSetNameRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	name := new(tlv.String)
+	err := name.FromTLV(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.SyntaxError), ctx)
+	}
+
+	if !isValidClientName(name.String()) {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.InvalidClientNameError), ctx)
+	}
+
+	ctx.GetClient().SetName(name.String())
+	return helper.ResponseWithString("OK", ctx)
+}
+
+// Client without name is replied as empty response
+func handleClientGetNameRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	name := ctx.GetClient().Name()
+	if name == "" {
+		return helper.ResponseWithRaw(nil, ctx)
+	}
+
+	return helper.ResponseWithString(name, ctx)
+}
+
+// Name must be printable ascii without space so CLIENT LIST stay parsable
+func isValidClientName(name string) bool {
+	for i := 0; i < len(name); i++ {
+		if name[i] < '!' || name[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,147 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
+	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
+	mockpayload "bitbucket.org/non-pn/mini-redis-go/internal/mock/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/test"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHandleClientKillRequestByID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	other := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	clients := model.NewClientRegistry()
+	self := clients.Register(nil, conn, time.Now())
+	killed := clients.Register(nil, other, time.Now())
+
+	args, _ := tlv.NewStringArray("id", "2")
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("", rawargs)
+
+	n := tlv.Int64(1)
+	rawn, _ := n.ToTLV()
+	ctx.EXPECT().GetClient().Times(1).Return(self)
+	ctx.EXPECT().GetClients().Times(1).Return(clients)
+	other.EXPECT().Close().Times(1)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawn)
+
+	err := handleClientKillRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, killed.Context().Err())
+	assert.Nil(t, self.Context().Err())
+}
+
+func TestHandleClientKillRequestSelf(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	addr := mocknet.NewMockAddr(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	clients := model.NewClientRegistry()
+	self := clients.Register(nil, conn, time.Now())
+
+	args, _ := tlv.NewStringArray("addr", "127.0.0.1:5000")
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("", rawargs)
+
+	n := tlv.Int64(1)
+	rawn, _ := n.ToTLV()
+	ctx.EXPECT().GetClient().Times(1).Return(self)
+	ctx.EXPECT().GetClients().Times(1).Return(clients)
+	conn.EXPECT().RemoteAddr().Times(1).Return(addr)
+	addr.EXPECT().String().Times(1).Return("127.0.0.1:5000")
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawn)
+	conn.EXPECT().Close().Times(1)
+
+	err := handleClientKillRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, self.Context().Err())
+}
+
+func TestHandleClientKillRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	args, _ := tlv.NewStringArray("NAME", "other")
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("", rawargs)
+	expectErrorReply(ctx, tlv.SyntaxError)
+
+	err := handleClientKillRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+
+	args, _ = tlv.NewStringArray("ID")
+	rawargs, _ = args.ToTLV()
+	bod = getRedisRequestBody("", rawargs)
+	expectErrorReply(ctx, tlv.WrongArgumentsError)
+
+	err = handleClientKillRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleClientSetNameRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	client := model.NewClientRegistry().Register(nil, conn, time.Now())
+
+	name := tlv.String("worker-1")
+	rawname, _ := name.ToTLV()
+	bod := getRedisRequestBody("", rawname)
+
+	ctx.EXPECT().GetClient().Times(1).Return(client)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	ok := tlv.String("OK")
+	rawok, _ := ok.ToTLV()
+	test.ExpectWriteResponseToConn(t, conn, tlv.StringType, rawok)
+
+	err := handleClientSetNameRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "worker-1", client.Name())
+}
+
+func TestHandleClientSetNameRequestInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	name := tlv.String("worker 1")
+	rawname, _ := name.ToTLV()
+	bod := getRedisRequestBody("", rawname)
+
+	ctx.EXPECT().Error(uint16(tlv.InvalidClientNameError), tlv.ErrMsg[tlv.InvalidClientNameError]).Times(1)
+
+	err := handleClientSetNameRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleClientSetNameRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	names, _ := tlv.NewStringArray("a")
+	rawnames, _ := names.ToTLV()
+	bod := getRedisRequestBody("", rawnames)
+	expectErrorReply(ctx, tlv.SyntaxError)
+
+	err := handleClientSetNameRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
@@ -0,0 +1,84 @@
+package redis
+
+import (
+	"net"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+func SendClientListRequest(conn net.Conn) (string, error) {
+	body := payload.RedisRequestBody{
+		Value: []byte{},
+	}
+
+	return sendStringRequest(conn, payload.ClientListCmd, body)
+}
+
+// SendClientKillRequest kill clients matching filter ClientKillByID or ClientKillByAddr
+func SendClientKillRequest(conn net.Conn, filter string, value string) (int64, error) {
+	args, err := tlv.NewStringArray(filter, value)
+	if err != nil {
+		return 0, err
+	}
+
+	rawargs, err := args.ToTLV()
+	if err != nil {
+		return 0, err
+	}
+
+	body := payload.RedisRequestBody{
+		Value: rawargs,
+	}
+
+	resp, err := sendRequest(conn, payload.ClientKillCmd, body)
+	if err != nil {
+		return 0, err
+	}
+
+	n := new(tlv.Int64)
+	err = n.FromTLV(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(*n), nil
+}
+
+func SendClientSetNameRequest(conn net.Conn, name string) (string, error) {
+	s := tlv.String(name)
+	raw, err := s.ToTLV()
+	if err != nil {
+		return "", err
+	}
+
+	body := payload.RedisRequestBody{
+		Value: raw,
+	}
+
+	return sendStringRequest(conn, payload.ClientSetNameCmd, body)
+}
+
+// SendClientGetNameRequest return empty string when connection has no name
+func SendClientGetNameRequest(conn net.Conn) (string, error) {
+	body := payload.RedisRequestBody{
+		Value: []byte{},
+	}
+
+	resp, err := sendRequest(conn, payload.ClientGetNameCmd, body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.Typ == tlv.EmptyType {
+		return "", nil
+	}
+
+	name := new(tlv.String)
+	err = name.FromTLV(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return name.String(), nil
+}
@@ -0,0 +1,51 @@
+package redis
+
+import (
+	"testing"
+
+	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/test"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSendClientKillRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	args, _ := tlv.NewStringArray(ClientKillByID, "3")
+	rawargs, _ := args.ToTLV()
+	reqbod := payload.RedisRequestBody{
+		Value: rawargs,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.ClientKillCmd, rawreqbod)
+
+	resbod := tlv.Int64(1)
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.Int64Type, rawresbod)
+
+	n, err := SendClientKillRequest(conn, ClientKillByID, "3")
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+func TestSendClientGetNameRequestNoName(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	reqbod := payload.RedisRequestBody{
+		Value: []byte{},
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.ClientGetNameCmd, rawreqbod)
+	test.ExpectReadResponseFromConn(t, conn, tlv.EmptyType, nil)
+
+	name, err := SendClientGetNameRequest(conn)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "", name)
+}
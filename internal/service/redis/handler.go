@@ -3,8 +3,12 @@ package redis
 import (
 	"bytes"
 	"log"
+	"math"
+	"strconv"
 
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
 	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/helper"
 	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
 )
 
@@ -18,7 +22,7 @@ func HandleRequest(ctx payload.IRequestContext) error {
 	cmd = pl.Cmd
 	body = pl.Body
 
-	redisBody := new(payload.RedisRequestBody)
+	redisBody := &payload.RedisRequestBody{Version: pl.Version}
 	_, err = redisBody.ReadFrom(bytes.NewReader(body))
 	if err != nil {
 		return err
@@ -31,11 +35,177 @@ func HandleRequest(ctx payload.IRequestContext) error {
 	case payload.SetCmd:
 		err = handleSetRequest(ctx, redisBody)
 		break
+	case payload.ExpireCmd:
+		err = handleExpireRequest(ctx, redisBody)
+		break
+	case payload.TTLCmd:
+		err = handleTTLRequest(ctx, redisBody)
+		break
+	case payload.PersistCmd:
+		err = handlePersistRequest(ctx, redisBody)
+		break
+	case payload.DelCmd:
+		err = handleDelRequest(ctx, redisBody)
+		break
+	case payload.ExistsCmd:
+		err = handleExistsRequest(ctx, redisBody)
+		break
+	case payload.ScanCmd:
+		err = handleScanRequest(ctx, redisBody)
+		break
+	case payload.IncrCmd, payload.DecrCmd, payload.IncrByCmd, payload.DecrByCmd:
+		err = handleIncrRequest(ctx, cmd, redisBody)
+		break
+	case payload.IncrByFloatCmd:
+		err = handleIncrByFloatRequest(ctx, redisBody)
+		break
+	case payload.LPushCmd, payload.RPushCmd:
+		err = handlePushRequest(ctx, cmd, redisBody)
+		break
+	case payload.LPopCmd, payload.RPopCmd:
+		err = handlePopRequest(ctx, cmd, redisBody)
+		break
+	case payload.LRangeCmd:
+		err = handleLRangeRequest(ctx, redisBody)
+		break
+	case payload.LLenCmd:
+		err = handleLLenRequest(ctx, redisBody)
+		break
+	case payload.BLPopCmd:
+		err = handleBLPopRequest(ctx, redisBody)
+		break
+	case payload.HSetCmd:
+		err = handleHSetRequest(ctx, redisBody)
+		break
+	case payload.HGetCmd:
+		err = handleHGetRequest(ctx, redisBody)
+		break
+	case payload.HDelCmd:
+		err = handleHDelRequest(ctx, redisBody)
+		break
+	case payload.HGetAllCmd:
+		err = handleHGetAllRequest(ctx, redisBody)
+		break
+	case payload.HIncrByCmd:
+		err = handleHIncrByRequest(ctx, redisBody)
+		break
+	case payload.HLenCmd:
+		err = handleHLenRequest(ctx, redisBody)
+		break
+	case payload.SAddCmd:
+		err = handleSAddRequest(ctx, redisBody)
+		break
+	case payload.SRemCmd:
+		err = handleSRemRequest(ctx, redisBody)
+		break
+	case payload.SIsMemberCmd:
+		err = handleSIsMemberRequest(ctx, redisBody)
+		break
+	case payload.SMembersCmd:
+		err = handleSMembersRequest(ctx, redisBody)
+		break
+	case payload.SInterCmd, payload.SUnionCmd, payload.SDiffCmd:
+		err = handleSetOpRequest(ctx, cmd, redisBody)
+		break
+	case payload.ZAddCmd:
+		err = handleZAddRequest(ctx, redisBody)
+		break
+	case payload.ZRemCmd:
+		err = handleZRemRequest(ctx, redisBody)
+		break
+	case payload.ZScoreCmd:
+		err = handleZScoreRequest(ctx, redisBody)
+		break
+	case payload.ZRangeCmd:
+		err = handleZRangeRequest(ctx, redisBody)
+		break
+	case payload.ZRangeByScoreCmd:
+		err = handleZRangeByScoreRequest(ctx, redisBody)
+		break
+	case payload.ZRankCmd:
+		err = handleZRankRequest(ctx, redisBody)
+		break
+	case payload.ZIncrByCmd:
+		err = handleZIncrByRequest(ctx, redisBody)
+		break
+	case payload.BGRewriteAOFCmd:
+		err = handleBGRewriteAOFRequest(ctx, redisBody)
+		break
+	case payload.SaveCmd:
+		err = handleSaveRequest(ctx, redisBody)
+		break
+	case payload.BGSaveCmd:
+		err = handleBGSaveRequest(ctx, redisBody)
+		break
+	case payload.ClientListCmd:
+		err = handleClientListRequest(ctx, redisBody)
+		break
+	case payload.ClientKillCmd:
+		err = handleClientKillRequest(ctx, redisBody)
+		break
+	case payload.ClientSetNameCmd:
+		err = handleClientSetNameRequest(ctx, redisBody)
+		break
+	case payload.ClientGetNameCmd:
+		err = handleClientGetNameRequest(ctx, redisBody)
+		break
+	case payload.XAddCmd:
+		err = handleXAddRequest(ctx, redisBody)
+		break
+	case payload.XRangeCmd:
+		err = handleXRangeRequest(ctx, redisBody)
+		break
+	case payload.XReadCmd:
+		err = handleXReadRequest(ctx, redisBody)
+		break
+	case payload.XLenCmd:
+		err = handleXLenRequest(ctx, redisBody)
+		break
+	case payload.XTrimCmd:
+		err = handleXTrimRequest(ctx, redisBody)
+		break
+	case payload.XGroupCreateCmd:
+		err = handleXGroupCreateRequest(ctx, redisBody)
+		break
+	case payload.XGroupDestroyCmd:
+		err = handleXGroupDestroyRequest(ctx, redisBody)
+		break
+	case payload.XReadGroupCmd:
+		err = handleXReadGroupRequest(ctx, redisBody)
+		break
+	case payload.XAckCmd:
+		err = handleXAckRequest(ctx, redisBody)
+		break
+	case payload.XPendingCmd:
+		err = handleXPendingRequest(ctx, redisBody)
+		break
+	case payload.XClaimCmd:
+		err = handleXClaimRequest(ctx, redisBody)
+		break
+	case payload.SetArgsCmd:
+		err = handleSetArgsRequest(ctx, redisBody)
+		break
+	case payload.CASCmd:
+		err = handleCASRequest(ctx, redisBody)
+		break
+	case payload.CASVersionCmd:
+		err = handleCASVersionRequest(ctx, redisBody)
+		break
+	case payload.GetVersionCmd:
+		err = handleGetVersionRequest(ctx, redisBody)
+		break
+	case payload.MGetCmd:
+		err = handleMGetRequest(ctx, redisBody)
+		break
+	case payload.MSetCmd, payload.MSetNXCmd:
+		err = handleMSetRequest(ctx, cmd, redisBody)
+		break
 	default:
 		break
 	}
 
-	return nil
+	// Invalid arguments are replied as error, what is left is failing to write reply
+	return err
 }
 
 func handleGetRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
@@ -44,7 +214,10 @@ func handleGetRequest(ctx payload.IRequestContext, body *payload.RedisRequestBod
 		typ uint8
 		err error
 	)
-	data := ctx.GetRedis(body.Key)
+	data, err := ctx.GetRedis(body.Key)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
 	raw = tlv.TypeLengthValue(data)
 	typ = raw.GetType()
 
@@ -64,7 +237,11 @@ func handleSetRequest(ctx payload.IRequestContext, body *payload.RedisRequestBod
 		resp payload.ResponsePayload
 		err  error
 	)
-	ctx.SetRedis(body.Key, body.Value)
+	if body.TTL > 0 {
+		ctx.SetRedisWithTTL(body.Key, body.Value, body.TTL)
+	} else {
+		ctx.SetRedis(body.Key, body.Value)
+	}
 
 	s := tlv.String("OK")
 	raw, err := s.ToTLV()
@@ -85,3 +262,253 @@ func handleSetRequest(ctx payload.IRequestContext, body *payload.RedisRequestBod
 	}
 	return nil
 }
+
+func handleExpireRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	ok := ctx.ExpireRedis(body.Key, body.TTL)
+
+	return helper.ResponseWithString(boolToReply(ok), ctx)
+}
+
+func handleTTLRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	ttl := ctx.TTLRedis(body.Key)
+
+	// Ttl is replied in milliseconds, negative value keep its meaning from db
+	reply := strconv.FormatInt(int64(ttl), 10)
+	if ttl >= 0 {
+		reply = strconv.FormatInt(ttl.Milliseconds(), 10)
+	}
+
+	return helper.ResponseWithString(reply, ctx)
+}
+
+func handlePersistRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	ok := ctx.PersistRedis(body.Key)
+
+	return helper.ResponseWithString(boolToReply(ok), ctx)
+}
+
+func handleDelRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	keys, err := readArgs(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	n := ctx.DeleteRedis(keys...)
+
+	return helper.ResponseWithString(strconv.Itoa(n), ctx)
+}
+
+func handleExistsRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	keys, err := readArgs(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	n := ctx.ExistsRedis(keys...)
+
+	return helper.ResponseWithString(strconv.Itoa(n), ctx)
+}
+
+// Scan arguments are cursor, match pattern and count in that order
+func handleScanRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	args, err := readArgs(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if len(args) != 3 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	count, err := strconv.Atoi(args[2])
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.NotIntegerError), ctx)
+	}
+
+	next, keys := ctx.ScanRedis(args[0], args[1], count)
+
+	rawkeys, err := tlv.NewStringArray(keys...)
+	if err != nil {
+		return err
+	}
+
+	reply, err := tlv.NewStringArray(next)
+	if err != nil {
+		return err
+	}
+
+	err = reply.Append(rawkeys)
+	if err != nil {
+		return err
+	}
+
+	return helper.ResponseWithTLV(reply, ctx)
+}
+
+// Rewrite keep going after the reply, only failing to start it is replied as error
+func handleBGRewriteAOFRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	err := ctx.RewriteAOFRedis()
+	if err != nil {
+		return helper.ResponseWithError(&tlv.Error{Code: tlv.PersistenceError, Msg: err.Error()}, ctx)
+	}
+
+	return helper.ResponseWithString("Background append only file rewriting started", ctx)
+}
+
+// Reply is sent once snapshot is on disk
+func handleSaveRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	err := ctx.SaveRedis()
+	if err != nil {
+		return helper.ResponseWithError(&tlv.Error{Code: tlv.PersistenceError, Msg: err.Error()}, ctx)
+	}
+
+	return helper.ResponseWithString("OK", ctx)
+}
+
+// Snapshot keep going after the reply, only failing to start it is replied as error
+func handleBGSaveRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	err := ctx.BGSaveRedis()
+	if err != nil {
+		return helper.ResponseWithError(&tlv.Error{Code: tlv.PersistenceError, Msg: err.Error()}, ctx)
+	}
+
+	return helper.ResponseWithString("Background saving started", ctx)
+}
+
+// Incr family share one handler, INCR and DECR step by one and BY variants carry tlv.Int64 step
+func handleIncrRequest(ctx payload.IRequestContext, cmd uint8, body *payload.RedisRequestBody) error {
+	delta := int64(1)
+	if cmd == payload.IncrByCmd || cmd == payload.DecrByCmd {
+		d := new(tlv.Int64)
+		err := d.FromTLV(body.Value)
+		if err != nil {
+			return helper.ResponseWithError(tlv.NewCodeError(tlv.NotIntegerError), ctx)
+		}
+		delta = int64(*d)
+	}
+
+	if cmd == payload.DecrCmd || cmd == payload.DecrByCmd {
+		if delta == math.MinInt64 {
+			return helper.ResponseWithError(tlv.NewCodeError(tlv.NotIntegerError), ctx)
+		}
+		delta = -delta
+	}
+
+	var res tlv.Int64
+	_, err := ctx.UpdateRedis(body.Key, func(v []byte, ok bool) ([]byte, error) {
+		var cur int64
+		if ok {
+			n, err := model.ToInt64(v)
+			if err != nil {
+				return nil, err
+			}
+			cur = n
+		}
+
+		sum, err := model.AddInt64(cur, delta)
+		if err != nil {
+			return nil, err
+		}
+
+		res = tlv.Int64(sum)
+		return res.ToTLV()
+	})
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	return helper.ResponseWithTLV(&res, ctx)
+}
+
+func handleIncrByFloatRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	d := new(tlv.Float64)
+	err := d.FromTLV(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.NotFloatError), ctx)
+	}
+
+	var res tlv.Float64
+	_, err = ctx.UpdateRedis(body.Key, func(v []byte, ok bool) ([]byte, error) {
+		var cur float64
+		if ok {
+			f, err := toFloat64(v)
+			if err != nil {
+				return nil, err
+			}
+			cur = f
+		}
+
+		sum := cur + float64(*d)
+		if math.IsNaN(sum) || math.IsInf(sum, 0) {
+			return nil, tlv.NewCodeError(tlv.InvalidIncrementError)
+		}
+
+		res = tlv.Float64(sum)
+		return res.ToTLV()
+	})
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	return helper.ResponseWithTLV(&res, ctx)
+}
+
+func toFloat64(raw tlv.TypeLengthValue) (float64, error) {
+	switch raw.GetType() {
+	case tlv.Int64Type:
+		i := new(tlv.Int64)
+		err := i.FromTLV(raw)
+		if err != nil {
+			return 0, tlv.NewCodeError(tlv.NotFloatError)
+		}
+		return float64(*i), nil
+	case tlv.Float64Type:
+		f := new(tlv.Float64)
+		err := f.FromTLV(raw)
+		if err != nil {
+			return 0, tlv.NewCodeError(tlv.NotFloatError)
+		}
+		return float64(*f), nil
+	case tlv.StringType, tlv.BinaryType:
+		f, err := strconv.ParseFloat(string(raw.GetValue()), 64)
+		if err != nil || math.IsNaN(f) {
+			return 0, tlv.NewCodeError(tlv.NotFloatError)
+		}
+		return f, nil
+	default:
+		return 0, tlv.NewCodeError(tlv.NotFloatError)
+	}
+}
+
+// readArgs read string arguments, malformed arguments are syntax error to reply to client
+func readArgs(raw tlv.TypeLengthValue) ([]string, error) {
+	args, err := readArray(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	strs, err := args.Strings()
+	if err != nil {
+		return nil, tlv.NewCodeError(tlv.SyntaxError)
+	}
+
+	return strs, nil
+}
+
+// readArray read arguments of any type, malformed arguments are syntax error to reply to client
+func readArray(raw tlv.TypeLengthValue) (*tlv.Array, error) {
+	args := new(tlv.Array)
+	err := args.FromTLV(raw)
+	if err != nil {
+		return nil, tlv.NewCodeError(tlv.SyntaxError)
+	}
+
+	return args, nil
+}
+
+func boolToReply(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
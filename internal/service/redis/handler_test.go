@@ -2,8 +2,12 @@ package redis
 
 import (
 	"errors"
+	"math"
 	"testing"
+	"time"
 
+	"bitbucket.org/non-pn/mini-redis-go/internal/db"
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
 	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
 	mockpayload "bitbucket.org/non-pn/mini-redis-go/internal/mock/payload"
 	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
@@ -109,7 +113,7 @@ func TestHandleGetRequestStringType(t *testing.T) {
 	rawval, _ := val.ToTLV()
 	bod := getRedisRequestBody(key, []byte{})
 
-	ctx.EXPECT().GetRedis(bod.Key).Times(1).Return(rawval)
+	ctx.EXPECT().GetRedis(bod.Key).Times(1).Return(rawval, nil)
 	ctx.EXPECT().GetConn().Times(1).Return(conn)
 	test.ExpectWriteResponseToConn(t, conn, tlv.StringType, rawval)
 
@@ -128,7 +132,7 @@ func TestHandleGetRequestBinaryType(t *testing.T) {
 	rawval, _ := val.ToTLV()
 	bod := getRedisRequestBody(key, []byte{})
 
-	ctx.EXPECT().GetRedis(bod.Key).Times(1).Return(rawval)
+	ctx.EXPECT().GetRedis(bod.Key).Times(1).Return(rawval, nil)
 	ctx.EXPECT().GetConn().Times(1).Return(conn)
 	test.ExpectWriteResponseToConn(t, conn, tlv.BinaryType, rawval)
 
@@ -137,6 +141,20 @@ func TestHandleGetRequestBinaryType(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestHandleGetRequestWrongType(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("test_list", []byte{})
+
+	ctx.EXPECT().GetRedis(bod.Key).Times(1).Return(nil, model.ErrWrongType)
+	ctx.EXPECT().Error(uint16(tlv.WrongTypeError), tlv.ErrMsg[tlv.WrongTypeError]).Times(1)
+
+	err := handleGetRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
 func TestHandleGetRequestReturnError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	conn := mocknet.NewMockConn(ctrl)
@@ -147,7 +165,7 @@ func TestHandleGetRequestReturnError(t *testing.T) {
 	rawval, _ := val.ToTLV()
 	bod := getRedisRequestBody(key, []byte{})
 
-	ctx.EXPECT().GetRedis(bod.Key).Times(1).Return(rawval)
+	ctx.EXPECT().GetRedis(bod.Key).Times(1).Return(rawval, nil)
 	ctx.EXPECT().GetConn().Times(1).Return(conn)
 	conn.EXPECT().Write(gomock.Any()).AnyTimes().Return(0, errors.New("Some write error"))
 
@@ -214,3 +232,364 @@ func TestHandleSetRequestWriteError(t *testing.T) {
 
 	assert.NotNil(t, err)
 }
+
+func TestHandleSetRequestWithTTL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	key := "test_key"
+	val := tlv.String("test_val")
+	rawval, _ := val.ToTLV()
+	bod := getRedisRequestBody(key, rawval)
+	bod.TTL = time.Minute
+	rawok := []byte{2, 0, 0, 0, 2, 79, 75}
+
+	ctx.EXPECT().SetRedisWithTTL(bod.Key, rawval, time.Minute).Times(1)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.StringType, rawok)
+
+	err := handleSetRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleExpireRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("test_key", []byte{})
+	bod.TTL = time.Minute
+	rawone := []byte{2, 0, 0, 0, 1, 49}
+
+	ctx.EXPECT().ExpireRedis(bod.Key, time.Minute).Times(1).Return(true)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.StringType, rawone)
+
+	err := handleExpireRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleTTLRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("test_key", []byte{})
+	s := tlv.String("1500")
+	rawttl, _ := s.ToTLV()
+
+	ctx.EXPECT().TTLRedis(bod.Key).Times(1).Return(1500 * time.Millisecond)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.StringType, rawttl)
+
+	err := handleTTLRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleTTLRequestKeyNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("test_key", []byte{})
+	s := tlv.String("-2")
+	rawttl, _ := s.ToTLV()
+
+	ctx.EXPECT().TTLRedis(bod.Key).Times(1).Return(db.KeyNotFoundTTL)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.StringType, rawttl)
+
+	err := handleTTLRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandlePersistRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("test_key", []byte{})
+	rawzero := []byte{2, 0, 0, 0, 1, 48}
+
+	ctx.EXPECT().PersistRedis(bod.Key).Times(1).Return(false)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.StringType, rawzero)
+
+	err := handlePersistRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleDelRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	keys, _ := tlv.NewStringArray("a", "b")
+	rawkeys, _ := keys.ToTLV()
+	bod := getRedisRequestBody("", rawkeys)
+	s := tlv.String("2")
+	rawn, _ := s.ToTLV()
+
+	ctx.EXPECT().DeleteRedis("a", "b").Times(1).Return(2)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.StringType, rawn)
+
+	err := handleDelRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleDelRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	s := tlv.String("a")
+	raw, _ := s.ToTLV()
+	bod := getRedisRequestBody("", raw)
+	expectErrorReply(ctx, tlv.SyntaxError)
+
+	err := handleDelRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleExistsRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	keys, _ := tlv.NewStringArray("a")
+	rawkeys, _ := keys.ToTLV()
+	bod := getRedisRequestBody("", rawkeys)
+	s := tlv.String("1")
+	rawn, _ := s.ToTLV()
+
+	ctx.EXPECT().ExistsRedis("a").Times(1).Return(1)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.StringType, rawn)
+
+	err := handleExistsRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleScanRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	args, _ := tlv.NewStringArray("", "user:*", "10")
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("", rawargs)
+
+	keys, _ := tlv.NewStringArray("user:1", "user:2")
+	reply, _ := tlv.NewStringArray("user:2")
+	reply.Append(keys)
+	rawreply, _ := reply.ToTLV()
+
+	ctx.EXPECT().ScanRedis("", "user:*", 10).Times(1).Return("user:2", []string{"user:1", "user:2"})
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.ArrayType, rawreply)
+
+	err := handleScanRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleScanRequestInvalidCount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	args, _ := tlv.NewStringArray("", "", "ten")
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("", rawargs)
+	expectErrorReply(ctx, tlv.NotIntegerError)
+
+	err := handleScanRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleScanRequestWrongArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	args, _ := tlv.NewStringArray("", "")
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("", rawargs)
+	expectErrorReply(ctx, tlv.WrongArgumentsError)
+
+	err := handleScanRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleIncrRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("counter", []byte{})
+	cur := tlv.String("41")
+	rawcur, _ := cur.ToTLV()
+	res := tlv.Int64(42)
+	rawres, _ := res.ToTLV()
+
+	ctx.EXPECT().UpdateRedis("counter", gomock.Any()).Times(1).DoAndReturn(
+		func(k string, fn func(v []byte, ok bool) ([]byte, error)) ([]byte, error) {
+			return fn(rawcur, true)
+		})
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawres)
+
+	err := handleIncrRequest(ctx, payload.IncrCmd, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleDecrByRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	delta := tlv.Int64(5)
+	rawdelta, _ := delta.ToTLV()
+	bod := getRedisRequestBody("counter", rawdelta)
+	res := tlv.Int64(-5)
+	rawres, _ := res.ToTLV()
+
+	ctx.EXPECT().UpdateRedis("counter", gomock.Any()).Times(1).DoAndReturn(
+		func(k string, fn func(v []byte, ok bool) ([]byte, error)) ([]byte, error) {
+			return fn(nil, false)
+		})
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawres)
+
+	err := handleIncrRequest(ctx, payload.DecrByCmd, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleIncrRequestNotInteger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("counter", []byte{})
+	cur := tlv.String("abc")
+	rawcur, _ := cur.ToTLV()
+
+	ctx.EXPECT().UpdateRedis("counter", gomock.Any()).Times(1).DoAndReturn(
+		func(k string, fn func(v []byte, ok bool) ([]byte, error)) ([]byte, error) {
+			return fn(rawcur, true)
+		})
+	ctx.EXPECT().Error(uint16(tlv.NotIntegerError), tlv.ErrMsg[tlv.NotIntegerError]).Times(1)
+
+	err := handleIncrRequest(ctx, payload.IncrCmd, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleIncrRequestOverflow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("counter", []byte{})
+	cur := tlv.Int64(math.MaxInt64)
+	rawcur, _ := cur.ToTLV()
+
+	ctx.EXPECT().UpdateRedis("counter", gomock.Any()).Times(1).DoAndReturn(
+		func(k string, fn func(v []byte, ok bool) ([]byte, error)) ([]byte, error) {
+			return fn(rawcur, true)
+		})
+	ctx.EXPECT().Error(uint16(tlv.NotIntegerError), tlv.ErrMsg[tlv.NotIntegerError]).Times(1)
+
+	err := handleIncrRequest(ctx, payload.IncrCmd, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleIncrByFloatRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	delta := tlv.Float64(0.5)
+	rawdelta, _ := delta.ToTLV()
+	bod := getRedisRequestBody("counter", rawdelta)
+	cur := tlv.Int64(10)
+	rawcur, _ := cur.ToTLV()
+	res := tlv.Float64(10.5)
+	rawres, _ := res.ToTLV()
+
+	ctx.EXPECT().UpdateRedis("counter", gomock.Any()).Times(1).DoAndReturn(
+		func(k string, fn func(v []byte, ok bool) ([]byte, error)) ([]byte, error) {
+			return fn(rawcur, true)
+		})
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.Float64Type, rawres)
+
+	err := handleIncrByFloatRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleIncrByFloatRequestNotFloat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	delta := tlv.Float64(0.5)
+	rawdelta, _ := delta.ToTLV()
+	bod := getRedisRequestBody("counter", rawdelta)
+	cur := tlv.String("abc")
+	rawcur, _ := cur.ToTLV()
+
+	ctx.EXPECT().UpdateRedis("counter", gomock.Any()).Times(1).DoAndReturn(
+		func(k string, fn func(v []byte, ok bool) ([]byte, error)) ([]byte, error) {
+			return fn(rawcur, true)
+		})
+	ctx.EXPECT().Error(uint16(tlv.NotFloatError), tlv.ErrMsg[tlv.NotFloatError]).Times(1)
+
+	err := handleIncrByFloatRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleBGRewriteAOFRequestNotEnabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("", []byte{})
+
+	ctx.EXPECT().RewriteAOFRedis().Times(1).Return(db.ErrAOFNotEnabled)
+	ctx.EXPECT().Error(uint16(tlv.PersistenceError), db.ErrAOFNotEnabled.Error()).Times(1)
+
+	err := handleBGRewriteAOFRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleBGSaveRequestActive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("", []byte{})
+
+	ctx.EXPECT().BGSaveRedis().Times(1).Return(db.ErrSnapshotActive)
+	ctx.EXPECT().Error(uint16(tlv.PersistenceError), db.ErrSnapshotActive.Error()).Times(1)
+
+	err := handleBGSaveRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+// expectErrorReply expect request to be replied with error, connection is kept open as
+// handler return no error
+func expectErrorReply(ctx *mockpayload.MockIRequestContext, code tlv.ErrCode) {
+	ctx.EXPECT().Error(uint16(code), tlv.ErrMsg[code]).Times(1)
+}
@@ -0,0 +1,150 @@
+package redis
+
+import (
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/helper"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+// Fields are sent as map of field to value, reply is number of fields added
+func handleHSetRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	fields := new(tlv.Map)
+	err := fields.FromTLV(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.SyntaxError), ctx)
+	}
+
+	if len(*fields) == 0 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	hset := &model.HSetCommand{Fields: make(map[string][]byte, len(*fields))}
+	for f, val := range *fields {
+		hset.Fields[f] = val
+	}
+	err = ctx.ExecRedis(body.Key, hset)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	n := tlv.Int64(hset.Added)
+	return helper.ResponseWithTLV(&n, ctx)
+}
+
+// Field is sent as string, missing field is replied as empty response
+func handleHGetRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	field := new(tlv.String)
+	err := field.FromTLV(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.SyntaxError), ctx)
+	}
+
+	var val []byte
+	err = ctx.ViewRedis(body.Key, func(v *model.Value) error {
+		if v == nil {
+			return nil
+		}
+		if v.Type != model.HashValueType {
+			return model.ErrWrongType
+		}
+
+		val = v.Hash[field.String()]
+		return nil
+	})
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	return helper.ResponseWithRaw(val, ctx)
+}
+
+// Fields are sent as string array, hash left empty is deleted
+func handleHDelRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	fields, err := readArgs(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	hdel := &model.HDelCommand{Fields: fields}
+	err = ctx.ExecRedis(body.Key, hdel)
+	if err != nil && err != model.ErrNoChange {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	n := tlv.Int64(hdel.Removed)
+	return helper.ResponseWithTLV(&n, ctx)
+}
+
+func handleHGetAllRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	reply := tlv.Map{}
+	err := ctx.ViewRedis(body.Key, func(v *model.Value) error {
+		if v == nil {
+			return nil
+		}
+		if v.Type != model.HashValueType {
+			return model.ErrWrongType
+		}
+
+		for f, val := range v.Hash {
+			reply[f] = val
+		}
+		return nil
+	})
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	return helper.ResponseWithTLV(&reply, ctx)
+}
+
+// Arguments are field as tlv.String and increment as tlv.Int64
+func handleHIncrByRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	args, err := readArray(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if len(*args) != 2 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	field, delta := new(tlv.String), new(tlv.Int64)
+	err = field.FromTLV((*args)[0])
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.SyntaxError), ctx)
+	}
+	err = delta.FromTLV((*args)[1])
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.NotIntegerError), ctx)
+	}
+
+	incr := &model.HIncrByCommand{Field: field.String(), Delta: int64(*delta)}
+	err = ctx.ExecRedis(body.Key, incr)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	res := tlv.Int64(incr.Result)
+	return helper.ResponseWithTLV(&res, ctx)
+}
+
+func handleHLenRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	var n tlv.Int64
+	err := ctx.ViewRedis(body.Key, func(v *model.Value) error {
+		if v == nil {
+			return nil
+		}
+		if v.Type != model.HashValueType {
+			return model.ErrWrongType
+		}
+
+		n = tlv.Int64(len(v.Hash))
+		return nil
+	})
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	return helper.ResponseWithTLV(&n, ctx)
+}
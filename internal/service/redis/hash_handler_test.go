@@ -0,0 +1,265 @@
+package redis
+
+import (
+	"testing"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
+	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
+	mockpayload "bitbucket.org/non-pn/mini-redis-go/internal/mock/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/test"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func expectViewRedis(ctx *mockpayload.MockIRequestContext, key string, v *model.Value) {
+	ctx.EXPECT().ViewRedis(key, gomock.Any()).Times(1).DoAndReturn(
+		func(k string, fn func(v *model.Value) error) error {
+			return fn(v)
+		})
+}
+
+func TestHandleHSetRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	a, b := tlv.String("1"), tlv.String("2")
+	fields := tlv.Map{}
+	fields.Put("a", &a)
+	fields.Put("b", &b)
+	rawfields, _ := fields.ToTLV()
+	bod := getRedisRequestBody("test_hash", rawfields)
+
+	hash := model.NewHashValue()
+	hash.Hash["a"] = []byte{}
+	n := tlv.Int64(1)
+	rawn, _ := n.ToTLV()
+
+	expectExecRedis(ctx, "test_hash", hash)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawn)
+
+	err := handleHSetRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []byte(fields["a"]), hash.Hash["a"])
+	assert.Equal(t, 2, len(hash.Hash))
+}
+
+func TestHandleHSetRequestWrongType(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	a := tlv.String("1")
+	fields := tlv.Map{}
+	fields.Put("a", &a)
+	rawfields, _ := fields.ToTLV()
+	bod := getRedisRequestBody("test_list", rawfields)
+
+	expectExecRedis(ctx, "test_list", model.NewListValue())
+	ctx.EXPECT().Error(uint16(tlv.WrongTypeError), tlv.ErrMsg[tlv.WrongTypeError]).Times(1)
+
+	err := handleHSetRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleHSetRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	// Fields not sent as map
+	args, _ := tlv.NewStringArray("a", "1")
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("test_hash", rawargs)
+	expectErrorReply(ctx, tlv.SyntaxError)
+
+	err := handleHSetRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+
+	rawfields, _ := (&tlv.Map{}).ToTLV()
+	bod = getRedisRequestBody("test_hash", rawfields)
+	expectErrorReply(ctx, tlv.WrongArgumentsError)
+
+	err = handleHSetRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleHGetRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	field := tlv.String("a")
+	rawfield, _ := field.ToTLV()
+	bod := getRedisRequestBody("test_hash", rawfield)
+
+	val := tlv.String("1")
+	rawval, _ := val.ToTLV()
+	hash := model.NewHashValue()
+	hash.Hash["a"] = rawval
+
+	expectViewRedis(ctx, "test_hash", hash)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.StringType, rawval)
+
+	err := handleHGetRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleHGetRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	n := tlv.Int64(1)
+	rawn, _ := n.ToTLV()
+	bod := getRedisRequestBody("test_hash", rawn)
+	expectErrorReply(ctx, tlv.SyntaxError)
+
+	err := handleHGetRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleHDelRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	fields, _ := tlv.NewStringArray("a", "missing")
+	rawfields, _ := fields.ToTLV()
+	bod := getRedisRequestBody("test_hash", rawfields)
+
+	hash := model.NewHashValue()
+	hash.Hash["a"] = []byte{}
+	n := tlv.Int64(1)
+	rawn, _ := n.ToTLV()
+
+	// Hash left empty is deleted by not keeping it
+	ctx.EXPECT().ExecRedis("test_hash", gomock.Any()).Times(1).DoAndReturn(
+		func(k string, cmd model.Command) error {
+			_, keep, err := cmd.Apply(hash, true)
+			assert.False(t, keep)
+			return err
+		})
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawn)
+
+	err := handleHDelRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleHDelRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	field := tlv.String("a")
+	rawfield, _ := field.ToTLV()
+	bod := getRedisRequestBody("test_hash", rawfield)
+	expectErrorReply(ctx, tlv.SyntaxError)
+
+	err := handleHDelRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleHGetAllRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("test_hash", []byte{})
+
+	a := tlv.String("1")
+	reply := tlv.Map{}
+	reply.Put("a", &a)
+	rawreply, _ := reply.ToTLV()
+	hash := model.NewHashValue()
+	hash.Hash["a"] = reply["a"]
+
+	expectViewRedis(ctx, "test_hash", hash)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.MapType, rawreply)
+
+	err := handleHGetAllRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleHIncrByRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	field, delta := tlv.String("visits"), tlv.Int64(3)
+	args := tlv.Array{}
+	args.Append(&field)
+	args.Append(&delta)
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("test_hash", rawargs)
+
+	cur := tlv.String("2")
+	rawcur, _ := cur.ToTLV()
+	hash := model.NewHashValue()
+	hash.Hash["visits"] = rawcur
+	res := tlv.Int64(5)
+	rawres, _ := res.ToTLV()
+
+	expectExecRedis(ctx, "test_hash", hash)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawres)
+
+	err := handleHIncrByRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []byte(rawres), hash.Hash["visits"])
+}
+
+func TestHandleHIncrByRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	field, delta := tlv.String("n"), tlv.String("one")
+	args := tlv.Array{}
+	args.Append(&field)
+	args.Append(&delta)
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("test_hash", rawargs)
+	expectErrorReply(ctx, tlv.NotIntegerError)
+
+	err := handleHIncrByRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+
+	args = args[:1]
+	rawargs, _ = args.ToTLV()
+	bod = getRedisRequestBody("test_hash", rawargs)
+	expectErrorReply(ctx, tlv.WrongArgumentsError)
+
+	err = handleHIncrByRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleHLenRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("test_hash", []byte{})
+	n := tlv.Int64(0)
+	rawn, _ := n.ToTLV()
+
+	expectViewRedis(ctx, "test_hash", nil)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawn)
+
+	err := handleHLenRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
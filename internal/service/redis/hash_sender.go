@@ -0,0 +1,104 @@
+package redis
+
+import (
+	"net"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+func SendHSetRequest(conn net.Conn, key string, fields tlv.Map) (int64, error) {
+	rawfields, err := fields.ToTLV()
+	if err != nil {
+		return 0, err
+	}
+
+	return sendInt64Request(conn, payload.HSetCmd, key, rawfields)
+}
+
+func SendHGetRequest(conn net.Conn, key string, field string) (tlv.TLVCompatible, error) {
+	f := tlv.String(field)
+	rawfield, err := f.ToTLV()
+	if err != nil {
+		return nil, err
+	}
+
+	body := payload.RedisRequestBody{
+		Key:   key,
+		Value: rawfield,
+	}
+
+	resp, err := sendRequest(conn, payload.HGetCmd, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeValue(resp.Typ, resp.Body)
+}
+
+func SendHDelRequest(conn net.Conn, key string, fields []string) (int64, error) {
+	args, err := tlv.NewStringArray(fields...)
+	if err != nil {
+		return 0, err
+	}
+
+	rawargs, err := args.ToTLV()
+	if err != nil {
+		return 0, err
+	}
+
+	return sendInt64Request(conn, payload.HDelCmd, key, rawargs)
+}
+
+func SendHGetAllRequest(conn net.Conn, key string) (map[string]tlv.TLVCompatible, error) {
+	body := payload.RedisRequestBody{
+		Key:   key,
+		Value: []byte{},
+	}
+
+	resp, err := sendRequest(conn, payload.HGetAllCmd, body)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := new(tlv.Map)
+	err = reply.FromTLV(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]tlv.TLVCompatible, len(*reply))
+	for f, raw := range *reply {
+		val, err := decodeValue(raw.GetType(), raw)
+		if err != nil {
+			return nil, err
+		}
+		fields[f] = val
+	}
+
+	return fields, nil
+}
+
+func SendHIncrByRequest(conn net.Conn, key string, field string, delta int64) (int64, error) {
+	args := tlv.Array{}
+	f, d := tlv.String(field), tlv.Int64(delta)
+	err := args.Append(&f)
+	if err != nil {
+		return 0, err
+	}
+	err = args.Append(&d)
+	if err != nil {
+		return 0, err
+	}
+
+	rawargs, err := args.ToTLV()
+	if err != nil {
+		return 0, err
+	}
+
+	return sendInt64Request(conn, payload.HIncrByCmd, key, rawargs)
+}
+
+func SendHLenRequest(conn net.Conn, key string) (int64, error) {
+	return sendInt64Request(conn, payload.HLenCmd, key, []byte{})
+}
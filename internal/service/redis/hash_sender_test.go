@@ -0,0 +1,88 @@
+package redis
+
+import (
+	"testing"
+
+	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/test"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSendHSetRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	a := tlv.String("1")
+	fields := tlv.Map{}
+	fields.Put("a", &a)
+	rawfields, _ := fields.ToTLV()
+	reqbod := payload.RedisRequestBody{
+		Key:   "test_hash",
+		Value: rawfields,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.HSetCmd, rawreqbod)
+
+	resbod := tlv.Int64(1)
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.Int64Type, rawresbod)
+
+	n, err := SendHSetRequest(conn, "test_hash", fields)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+func TestSendHGetAllRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	reqbod := payload.RedisRequestBody{
+		Key:   "test_hash",
+		Value: []byte{},
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.HGetAllCmd, rawreqbod)
+
+	a, b := tlv.String("1"), tlv.Int64(2)
+	reply := tlv.Map{}
+	reply.Put("a", &a)
+	reply.Put("b", &b)
+	rawreply, _ := reply.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.MapType, rawreply)
+
+	fields, err := SendHGetAllRequest(conn, "test_hash")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "1", fields["a"].String())
+	assert.Equal(t, "2", fields["b"].String())
+}
+
+func TestSendHIncrByRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	field, delta := tlv.String("visits"), tlv.Int64(3)
+	args := tlv.Array{}
+	args.Append(&field)
+	args.Append(&delta)
+	rawargs, _ := args.ToTLV()
+	reqbod := payload.RedisRequestBody{
+		Key:   "test_hash",
+		Value: rawargs,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.HIncrByCmd, rawreqbod)
+
+	resbod := tlv.Int64(3)
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.Int64Type, rawresbod)
+
+	n, err := SendHIncrByRequest(conn, "test_hash", "visits", 3)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), n)
+}
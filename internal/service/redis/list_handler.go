@@ -0,0 +1,191 @@
+package redis
+
+import (
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/helper"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+// Push elements are sent as array, each element is kept as given tlv
+func handlePushRequest(ctx payload.IRequestContext, cmd uint8, body *payload.RedisRequestBody) error {
+	elems, err := readArray(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if len(*elems) == 0 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	push := &model.PushCommand{Left: cmd == payload.LPushCmd, Elems: make([][]byte, 0, len(*elems))}
+	for _, elem := range *elems {
+		push.Elems = append(push.Elems, elem)
+	}
+	err = ctx.ExecRedis(body.Key, push)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	n := tlv.Int64(push.Len)
+	return helper.ResponseWithTLV(&n, ctx)
+}
+
+func handlePopRequest(ctx payload.IRequestContext, cmd uint8, body *payload.RedisRequestBody) error {
+	elem, err := popList(ctx, body.Key, cmd == payload.LPopCmd)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	return helper.ResponseWithRaw(elem, ctx)
+}
+
+// Range arguments are start and stop as tlv.Int64
+func handleLRangeRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	args, err := readArray(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if len(*args) != 2 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	start, stop := new(tlv.Int64), new(tlv.Int64)
+	err = start.FromTLV((*args)[0])
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.NotIntegerError), ctx)
+	}
+	err = stop.FromTLV((*args)[1])
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.NotIntegerError), ctx)
+	}
+
+	reply := tlv.Array{}
+	err = ctx.ViewRedis(body.Key, func(v *model.Value) error {
+		if v == nil {
+			return nil
+		}
+		if v.Type != model.ListValueType {
+			return model.ErrWrongType
+		}
+
+		for _, elem := range v.List.Range(int(*start), int(*stop)) {
+			reply = append(reply, elem)
+		}
+		return nil
+	})
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	return helper.ResponseWithTLV(&reply, ctx)
+}
+
+func handleLLenRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	var n tlv.Int64
+	err := ctx.ViewRedis(body.Key, func(v *model.Value) error {
+		if v == nil {
+			return nil
+		}
+		if v.Type != model.ListValueType {
+			return model.ErrWrongType
+		}
+
+		n = tlv.Int64(v.List.Len())
+		return nil
+	})
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	return helper.ResponseWithTLV(&n, ctx)
+}
+
+// Blocking pop take keys as string array and timeout as ttl, zero timeout block until element arrive.
+// Reply is array of key and element, or empty reply when timeout expire. Waiting stop with
+// shutting down error when server shut down or client is gone.
+func handleBLPopRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	keys, err := readArgs(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if len(keys) == 0 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	var timeout <-chan time.Time
+	if body.TTL > 0 {
+		timer := time.NewTimer(body.TTL)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	for {
+		// Wait is registered before trying so push in between wake us up
+		wake, cancel := ctx.WaitRedis(keys...)
+
+		k, elem, err := popFirstList(ctx, keys)
+		if err != nil {
+			cancel()
+			return helper.ResponseWithError(err, ctx)
+		}
+
+		if elem != nil {
+			cancel()
+			key := tlv.String(k)
+			reply := tlv.Array{}
+			err = reply.Append(&key)
+			if err != nil {
+				return err
+			}
+			reply = append(reply, elem)
+
+			return helper.ResponseWithTLV(&reply, ctx)
+		}
+
+		// Replies of requests pipelined before this one must not wait for it
+		err = ctx.Flush()
+		if err != nil {
+			cancel()
+			return err
+		}
+
+		select {
+		case <-wake:
+			cancel()
+		case <-timeout:
+			cancel()
+			return helper.ResponseWithRaw(nil, ctx)
+		case <-ctx.Done():
+			cancel()
+			return helper.ResponseWithError(tlv.NewCodeError(tlv.ShuttingDownError), ctx)
+		}
+	}
+}
+
+// popFirstList pop from head of the first non empty list among keys
+func popFirstList(ctx payload.IRequestContext, keys []string) (string, tlv.TypeLengthValue, error) {
+	for _, k := range keys {
+		elem, err := popList(ctx, k, true)
+		if err != nil {
+			return "", nil, err
+		}
+		if elem != nil {
+			return k, elem, nil
+		}
+	}
+
+	return "", nil, nil
+}
+
+// popList remove element from one end of list, list left empty is deleted
+func popList(ctx payload.IRequestContext, k string, left bool) (tlv.TypeLengthValue, error) {
+	pop := &model.PopCommand{Left: left}
+	err := ctx.ExecRedis(k, pop)
+
+	return pop.Elem, err
+}
@@ -0,0 +1,303 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
+	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
+	mockpayload "bitbucket.org/non-pn/mini-redis-go/internal/mock/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/test"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func expectExecRedis(ctx *mockpayload.MockIRequestContext, key string, v *model.Value) {
+	ctx.EXPECT().ExecRedis(key, gomock.Any()).Times(1).DoAndReturn(
+		func(k string, cmd model.Command) error {
+			_, _, err := cmd.Apply(v, v != nil)
+			return err
+		})
+}
+
+func TestHandlePushRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	elems, _ := tlv.NewStringArray("a", "b")
+	rawelems, _ := elems.ToTLV()
+	bod := getRedisRequestBody("test_list", rawelems)
+	list := model.NewListValue()
+	n := tlv.Int64(2)
+	rawn, _ := n.ToTLV()
+
+	expectExecRedis(ctx, "test_list", list)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawn)
+
+	err := handlePushRequest(ctx, payload.LPushCmd, &bod)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []byte((*elems)[1]), list.List.Range(0, 0)[0])
+}
+
+func TestHandlePushRequestWrongType(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	elems, _ := tlv.NewStringArray("a")
+	rawelems, _ := elems.ToTLV()
+	bod := getRedisRequestBody("test_str", rawelems)
+
+	expectExecRedis(ctx, "test_str", model.NewStringValue([]byte{}))
+	ctx.EXPECT().Error(uint16(tlv.WrongTypeError), tlv.ErrMsg[tlv.WrongTypeError]).Times(1)
+
+	err := handlePushRequest(ctx, payload.RPushCmd, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandlePushRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	// Elements not sent as array
+	s := tlv.String("a")
+	raw, _ := s.ToTLV()
+	bod := getRedisRequestBody("test_list", raw)
+	expectErrorReply(ctx, tlv.SyntaxError)
+
+	err := handlePushRequest(ctx, payload.LPushCmd, &bod)
+
+	assert.Nil(t, err)
+
+	rawempty, _ := (&tlv.Array{}).ToTLV()
+	bod = getRedisRequestBody("test_list", rawempty)
+	expectErrorReply(ctx, tlv.WrongArgumentsError)
+
+	err = handlePushRequest(ctx, payload.RPushCmd, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandlePopRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("test_list", []byte{})
+	a, b := tlv.String("a"), tlv.String("b")
+	rawa, _ := a.ToTLV()
+	rawb, _ := b.ToTLV()
+	list := model.NewListValue()
+	list.List.PushRight(rawa)
+	list.List.PushRight(rawb)
+
+	expectExecRedis(ctx, "test_list", list)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.StringType, rawb)
+
+	err := handlePopRequest(ctx, payload.RPopCmd, &bod)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, list.List.Len())
+}
+
+func TestHandlePopRequestEmpty(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("test_list", []byte{})
+
+	expectExecRedis(ctx, "test_list", nil)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.EmptyType, nil)
+
+	err := handlePopRequest(ctx, payload.LPopCmd, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleLRangeRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	start, stop := tlv.Int64(0), tlv.Int64(-1)
+	args := tlv.Array{}
+	args.Append(&start)
+	args.Append(&stop)
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("test_list", rawargs)
+
+	elems, _ := tlv.NewStringArray("a", "b")
+	list := model.NewListValue()
+	for _, elem := range *elems {
+		list.List.PushRight(elem)
+	}
+	rawelems, _ := elems.ToTLV()
+
+	ctx.EXPECT().ViewRedis("test_list", gomock.Any()).Times(1).DoAndReturn(
+		func(k string, fn func(v *model.Value) error) error {
+			return fn(list)
+		})
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.ArrayType, rawelems)
+
+	err := handleLRangeRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleLRangeRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	args, _ := tlv.NewStringArray("0", "-1")
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("test_list", rawargs)
+	expectErrorReply(ctx, tlv.NotIntegerError)
+
+	err := handleLRangeRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+
+	args, _ = tlv.NewStringArray("0")
+	rawargs, _ = args.ToTLV()
+	bod = getRedisRequestBody("test_list", rawargs)
+	expectErrorReply(ctx, tlv.WrongArgumentsError)
+
+	err = handleLRangeRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleLLenRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("test_list", []byte{})
+	list := model.NewListValue()
+	list.List.PushRight([]byte{})
+	n := tlv.Int64(1)
+	rawn, _ := n.ToTLV()
+
+	ctx.EXPECT().ViewRedis("test_list", gomock.Any()).Times(1).DoAndReturn(
+		func(k string, fn func(v *model.Value) error) error {
+			return fn(list)
+		})
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawn)
+
+	err := handleLLenRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleBLPopRequestTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	keys, _ := tlv.NewStringArray("test_list")
+	rawkeys, _ := keys.ToTLV()
+	bod := getRedisRequestBody("", rawkeys)
+	bod.TTL = 10 * time.Millisecond
+
+	ctx.EXPECT().WaitRedis("test_list").Times(1).Return(make(chan struct{}), func() {})
+	expectExecRedis(ctx, "test_list", nil)
+	ctx.EXPECT().Done().AnyTimes().Return(nil)
+	ctx.EXPECT().Flush().Times(1).Return(nil)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.EmptyType, nil)
+
+	err := handleBLPopRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleBLPopRequestShuttingDown(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	keys, _ := tlv.NewStringArray("test_list")
+	rawkeys, _ := keys.ToTLV()
+	bod := getRedisRequestBody("", rawkeys)
+
+	done := make(chan struct{})
+	close(done)
+	ctx.EXPECT().WaitRedis("test_list").Times(1).Return(make(chan struct{}), func() {})
+	expectExecRedis(ctx, "test_list", nil)
+	ctx.EXPECT().Done().Times(1).Return(done)
+	ctx.EXPECT().Flush().Times(1).Return(nil)
+	ctx.EXPECT().Error(uint16(tlv.ShuttingDownError), tlv.ErrMsg[tlv.ShuttingDownError]).Times(1)
+
+	err := handleBLPopRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleBLPopRequestWakeUp(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	keys, _ := tlv.NewStringArray("test_list")
+	rawkeys, _ := keys.ToTLV()
+	bod := getRedisRequestBody("", rawkeys)
+
+	elem := tlv.String("job")
+	rawelem, _ := elem.ToTLV()
+	list := model.NewListValue()
+	list.List.PushRight(rawelem)
+
+	key := tlv.String("test_list")
+	reply := tlv.Array{}
+	reply.Append(&key)
+	reply = append(reply, rawelem)
+	rawreply, _ := reply.ToTLV()
+
+	// First try find nothing, second try after wake up pop the pushed element
+	woken := make(chan struct{})
+	close(woken)
+	gomock.InOrder(
+		ctx.EXPECT().WaitRedis("test_list").Times(1).Return(woken, func() {}),
+		ctx.EXPECT().ExecRedis("test_list", gomock.Any()).Times(1).DoAndReturn(
+			func(k string, cmd model.Command) error {
+				_, _, err := cmd.Apply(nil, false)
+				return err
+			}),
+		ctx.EXPECT().WaitRedis("test_list").Times(1).Return(make(chan struct{}), func() {}),
+		ctx.EXPECT().ExecRedis("test_list", gomock.Any()).Times(1).DoAndReturn(
+			func(k string, cmd model.Command) error {
+				_, _, err := cmd.Apply(list, true)
+				return err
+			}),
+	)
+	ctx.EXPECT().Done().AnyTimes().Return(nil)
+	ctx.EXPECT().Flush().Times(1).Return(nil)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.ArrayType, rawreply)
+
+	err := handleBLPopRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleBLPopRequestNoKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	rawkeys, _ := (&tlv.Array{}).ToTLV()
+	bod := getRedisRequestBody("", rawkeys)
+	expectErrorReply(ctx, tlv.WrongArgumentsError)
+
+	err := handleBLPopRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
@@ -0,0 +1,149 @@
+package redis
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+func SendLPushRequest(conn net.Conn, key string, vals []tlv.TypeLengthValue) (int64, error) {
+	return sendPushRequest(conn, payload.LPushCmd, key, vals)
+}
+
+func SendRPushRequest(conn net.Conn, key string, vals []tlv.TypeLengthValue) (int64, error) {
+	return sendPushRequest(conn, payload.RPushCmd, key, vals)
+}
+
+func SendLPopRequest(conn net.Conn, key string) (tlv.TLVCompatible, error) {
+	return sendPopRequest(conn, payload.LPopCmd, key)
+}
+
+func SendRPopRequest(conn net.Conn, key string) (tlv.TLVCompatible, error) {
+	return sendPopRequest(conn, payload.RPopCmd, key)
+}
+
+func SendLRangeRequest(conn net.Conn, key string, start int64, stop int64) ([]tlv.TLVCompatible, error) {
+	args := tlv.Array{}
+	startarg, stoparg := tlv.Int64(start), tlv.Int64(stop)
+	err := args.Append(&startarg)
+	if err != nil {
+		return nil, err
+	}
+	err = args.Append(&stoparg)
+	if err != nil {
+		return nil, err
+	}
+
+	rawargs, err := args.ToTLV()
+	if err != nil {
+		return nil, err
+	}
+
+	body := payload.RedisRequestBody{
+		Key:   key,
+		Value: rawargs,
+	}
+
+	resp, err := sendRequest(conn, payload.LRangeCmd, body)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := new(tlv.Array)
+	err = reply.FromTLV(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	vals := make([]tlv.TLVCompatible, 0, len(*reply))
+	for _, elem := range *reply {
+		val, err := decodeValue(elem.GetType(), elem)
+		if err != nil {
+			return nil, err
+		}
+		vals = append(vals, val)
+	}
+
+	return vals, nil
+}
+
+func SendLLenRequest(conn net.Conn, key string) (int64, error) {
+	return sendInt64Request(conn, payload.LLenCmd, key, []byte{})
+}
+
+// SendBLPopRequest return empty key and nil value when timeout expire, zero timeout block forever
+func SendBLPopRequest(conn net.Conn, keys []string, timeout time.Duration) (string, tlv.TLVCompatible, error) {
+	args, err := tlv.NewStringArray(keys...)
+	if err != nil {
+		return "", nil, err
+	}
+
+	rawargs, err := args.ToTLV()
+	if err != nil {
+		return "", nil, err
+	}
+
+	body := payload.RedisRequestBody{
+		Value: rawargs,
+		TTL:   timeout,
+	}
+
+	resp, err := sendRequest(conn, payload.BLPopCmd, body)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if resp.Typ == tlv.EmptyType {
+		return "", nil, nil
+	}
+
+	reply := new(tlv.Array)
+	err = reply.FromTLV(resp.Body)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(*reply) != 2 {
+		return "", nil, errors.New("Invalid blpop reply")
+	}
+
+	key := new(tlv.String)
+	err = key.FromTLV((*reply)[0])
+	if err != nil {
+		return "", nil, err
+	}
+
+	val, err := decodeValue((*reply)[1].GetType(), (*reply)[1])
+	if err != nil {
+		return "", nil, err
+	}
+
+	return key.String(), val, nil
+}
+
+func sendPushRequest(conn net.Conn, cmd uint8, key string, vals []tlv.TypeLengthValue) (int64, error) {
+	elems := tlv.Array(vals)
+	rawelems, err := elems.ToTLV()
+	if err != nil {
+		return 0, err
+	}
+
+	return sendInt64Request(conn, cmd, key, rawelems)
+}
+
+func sendPopRequest(conn net.Conn, cmd uint8, key string) (tlv.TLVCompatible, error) {
+	body := payload.RedisRequestBody{
+		Key:   key,
+		Value: []byte{},
+	}
+
+	resp, err := sendRequest(conn, cmd, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeValue(resp.Typ, resp.Body)
+}
@@ -0,0 +1,105 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/test"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSendRPushRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	elems, _ := tlv.NewStringArray("a", "b")
+	rawelems, _ := elems.ToTLV()
+	reqbod := payload.RedisRequestBody{
+		Key:   "test_list",
+		Value: rawelems,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.RPushCmd, rawreqbod)
+
+	resbod := tlv.Int64(2)
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.Int64Type, rawresbod)
+
+	n, err := SendRPushRequest(conn, "test_list", *elems)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), n)
+}
+
+func TestSendLPopRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	reqbod := payload.RedisRequestBody{
+		Key:   "test_list",
+		Value: []byte{},
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.LPopCmd, rawreqbod)
+
+	resbod := tlv.String("a")
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.StringType, rawresbod)
+
+	val, err := SendLPopRequest(conn, "test_list")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "a", val.String())
+}
+
+func TestSendLRangeRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	start, stop := tlv.Int64(0), tlv.Int64(-1)
+	args := tlv.Array{}
+	args.Append(&start)
+	args.Append(&stop)
+	rawargs, _ := args.ToTLV()
+	reqbod := payload.RedisRequestBody{
+		Key:   "test_list",
+		Value: rawargs,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.LRangeCmd, rawreqbod)
+
+	elems, _ := tlv.NewStringArray("a", "b")
+	rawelems, _ := elems.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.ArrayType, rawelems)
+
+	vals, err := SendLRangeRequest(conn, "test_list", 0, -1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(vals))
+	assert.Equal(t, "b", vals[1].String())
+}
+
+func TestSendBLPopRequestTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	keys, _ := tlv.NewStringArray("test_list")
+	rawkeys, _ := keys.ToTLV()
+	reqbod := payload.RedisRequestBody{
+		Value: rawkeys,
+		TTL:   time.Second,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.BLPopCmd, rawreqbod)
+	test.ExpectReadResponseFromConn(t, conn, tlv.EmptyType, nil)
+
+	k, val, err := SendBLPopRequest(conn, []string{"test_list"}, time.Second)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "", k)
+	assert.Nil(t, val)
+}
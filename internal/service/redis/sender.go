@@ -1,7 +1,10 @@
 package redis
 
 import (
+	"errors"
 	"net"
+	"strconv"
+	"time"
 
 	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
 	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
@@ -12,70 +15,307 @@ func SendGetRequest(conn net.Conn, key string) (tlv.TLVCompatible, error) {
 		Key:   key,
 		Value: []byte{},
 	}
-	rawbod, err := body.ToTLV()
+
+	resp, err := sendRequest(conn, payload.GetCmd, body)
 	if err != nil {
 		return nil, err
 	}
 
-	req := payload.RequestPayload{
-		Cmd:  payload.GetCmd,
-		Body: rawbod,
+	return decodeValue(resp.Typ, resp.Body)
+}
+
+func SendSetRequest(conn net.Conn, key string, val tlv.TypeLengthValue) (string, error) {
+	return SendSetExRequest(conn, key, val, 0)
+}
+
+func SendSetExRequest(conn net.Conn, key string, val tlv.TypeLengthValue, ttl time.Duration) (string, error) {
+	body := payload.RedisRequestBody{
+		Key:   key,
+		Value: val,
+		TTL:   ttl,
 	}
 
-	_, err = req.WriteTo(conn)
+	return sendStringRequest(conn, payload.SetCmd, body)
+}
+
+func SendExpireRequest(conn net.Conn, key string, ttl time.Duration) (bool, error) {
+	body := payload.RedisRequestBody{
+		Key:   key,
+		Value: []byte{},
+		TTL:   ttl,
+	}
+
+	resp, err := sendStringRequest(conn, payload.ExpireCmd, body)
 	if err != nil {
-		return nil, err
+		return false, err
 	}
 
-	resp, err := payload.ReadResponse(conn)
+	return resp == "1", nil
+}
+
+func SendTTLRequest(conn net.Conn, key string) (time.Duration, error) {
+	body := payload.RedisRequestBody{
+		Key:   key,
+		Value: []byte{},
+	}
+
+	resp, err := sendStringRequest(conn, payload.TTLCmd, body)
 	if err != nil {
-		return nil, err
+		return 0, err
 	}
 
-	var val tlv.TLVCompatible
-	switch resp.Typ {
-	case tlv.StringType:
-		val = new(tlv.String)
-		err = val.FromTLV(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		break
-	case tlv.BinaryType:
-		val = new(tlv.Binary)
-		err = val.FromTLV(resp.Body)
-		if err != nil {
-			return nil, err
-		}
-		break
-	default:
-		break
+	ms, err := strconv.ParseInt(resp, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+
+	// Negative reply is db.KeyNotFoundTTL or db.NoExpireTTL
+	if ms < 0 {
+		return time.Duration(ms), nil
 	}
 
-	return val, nil
+	return time.Duration(ms) * time.Millisecond, nil
 }
 
-func SendSetRequest(conn net.Conn, key string, val tlv.TypeLengthValue) (string, error) {
+func SendPersistRequest(conn net.Conn, key string) (bool, error) {
 	body := payload.RedisRequestBody{
 		Key:   key,
-		Value: val,
+		Value: []byte{},
+	}
+
+	resp, err := sendStringRequest(conn, payload.PersistCmd, body)
+	if err != nil {
+		return false, err
+	}
+
+	return resp == "1", nil
+}
+
+func SendDelRequest(conn net.Conn, keys []string) (int, error) {
+	return sendCountRequest(conn, payload.DelCmd, keys...)
+}
+
+func SendExistsRequest(conn net.Conn, keys []string) (int, error) {
+	return sendCountRequest(conn, payload.ExistsCmd, keys...)
+}
+
+func SendScanRequest(conn net.Conn, cursor string, match string, count int) (string, []string, error) {
+	args, err := tlv.NewStringArray(cursor, match, strconv.Itoa(count))
+	if err != nil {
+		return "", nil, err
+	}
+
+	rawargs, err := args.ToTLV()
+	if err != nil {
+		return "", nil, err
+	}
+
+	body := payload.RedisRequestBody{
+		Value: rawargs,
+	}
+
+	resp, err := sendRequest(conn, payload.ScanCmd, body)
+	if err != nil {
+		return "", nil, err
+	}
+
+	// Reply is an array of next cursor and array of keys
+	reply := new(tlv.Array)
+	err = reply.FromTLV(resp.Body)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if len(*reply) != 2 {
+		return "", nil, errors.New("Invalid scan reply")
+	}
+
+	next := new(tlv.String)
+	err = next.FromTLV((*reply)[0])
+	if err != nil {
+		return "", nil, err
+	}
+
+	rawkeys := new(tlv.Array)
+	err = rawkeys.FromTLV((*reply)[1])
+	if err != nil {
+		return "", nil, err
+	}
+
+	keys, err := rawkeys.Strings()
+	if err != nil {
+		return "", nil, err
+	}
+
+	return next.String(), keys, nil
+}
+
+func SendIncrRequest(conn net.Conn, key string) (int64, error) {
+	return sendInt64Request(conn, payload.IncrCmd, key, []byte{})
+}
+
+func SendDecrRequest(conn net.Conn, key string) (int64, error) {
+	return sendInt64Request(conn, payload.DecrCmd, key, []byte{})
+}
+
+func SendIncrByRequest(conn net.Conn, key string, delta int64) (int64, error) {
+	d := tlv.Int64(delta)
+	rawdelta, err := d.ToTLV()
+	if err != nil {
+		return 0, err
 	}
+
+	return sendInt64Request(conn, payload.IncrByCmd, key, rawdelta)
+}
+
+func SendDecrByRequest(conn net.Conn, key string, delta int64) (int64, error) {
+	d := tlv.Int64(delta)
+	rawdelta, err := d.ToTLV()
+	if err != nil {
+		return 0, err
+	}
+
+	return sendInt64Request(conn, payload.DecrByCmd, key, rawdelta)
+}
+
+func SendIncrByFloatRequest(conn net.Conn, key string, delta float64) (float64, error) {
+	d := tlv.Float64(delta)
+	rawdelta, err := d.ToTLV()
+	if err != nil {
+		return 0, err
+	}
+
+	body := payload.RedisRequestBody{
+		Key:   key,
+		Value: rawdelta,
+	}
+
+	resp, err := sendRequest(conn, payload.IncrByFloatCmd, body)
+	if err != nil {
+		return 0, err
+	}
+
+	res := new(tlv.Float64)
+	err = res.FromTLV(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(*res), nil
+}
+
+func SendBGRewriteAOFRequest(conn net.Conn) (string, error) {
+	body := payload.RedisRequestBody{
+		Value: []byte{},
+	}
+
+	return sendStringRequest(conn, payload.BGRewriteAOFCmd, body)
+}
+
+func SendSaveRequest(conn net.Conn) (string, error) {
+	body := payload.RedisRequestBody{
+		Value: []byte{},
+	}
+
+	return sendStringRequest(conn, payload.SaveCmd, body)
+}
+
+func SendBGSaveRequest(conn net.Conn) (string, error) {
+	body := payload.RedisRequestBody{
+		Value: []byte{},
+	}
+
+	return sendStringRequest(conn, payload.BGSaveCmd, body)
+}
+
+func sendInt64Request(conn net.Conn, cmd uint8, key string, delta tlv.TypeLengthValue) (int64, error) {
+	body := payload.RedisRequestBody{
+		Key:   key,
+		Value: delta,
+	}
+
+	resp, err := sendRequest(conn, cmd, body)
+	if err != nil {
+		return 0, err
+	}
+
+	res := new(tlv.Int64)
+	err = res.FromTLV(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(*res), nil
+}
+
+// decodeValue decode stored value reply with tlv.Decode, empty reply give nil
+func decodeValue(typ uint8, raw tlv.TypeLengthValue) (tlv.TLVCompatible, error) {
+	if typ == tlv.EmptyType {
+		return nil, nil
+	}
+
+	return tlv.Decode(raw)
+}
+
+func sendCountRequest(conn net.Conn, cmd uint8, keys ...string) (int, error) {
+	args, err := tlv.NewStringArray(keys...)
+	if err != nil {
+		return 0, err
+	}
+
+	rawargs, err := args.ToTLV()
+	if err != nil {
+		return 0, err
+	}
+
+	body := payload.RedisRequestBody{
+		Value: rawargs,
+	}
+
+	resp, err := sendStringRequest(conn, cmd, body)
+	if err != nil {
+		return 0, err
+	}
+
+	return strconv.Atoi(resp)
+}
+
+func sendRequest(conn net.Conn, cmd uint8, body payload.RedisRequestBody) (*payload.ResponsePayload, error) {
 	rawbod, err := body.ToTLV()
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	req := payload.RequestPayload{
-		Cmd:  payload.SetCmd,
+		Cmd:  cmd,
 		Body: rawbod,
 	}
 
 	_, err = req.WriteTo(conn)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	resp, err := payload.ReadResponse(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Error reply is returned as typed *tlv.Error
+	if resp.Typ == tlv.ErrorType {
+		tlvErr := new(tlv.Error)
+		err = tlvErr.FromTLV(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return nil, tlvErr
+	}
+
+	return resp, nil
+}
+
+func sendStringRequest(conn net.Conn, cmd uint8, body payload.RedisRequestBody) (string, error) {
+	resp, err := sendRequest(conn, cmd, body)
 	if err != nil {
 		return "", err
 	}
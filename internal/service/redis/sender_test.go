@@ -2,7 +2,9 @@ package redis
 
 import (
 	"testing"
+	"time"
 
+	"bitbucket.org/non-pn/mini-redis-go/internal/db"
 	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
 	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
 	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/test"
@@ -79,3 +81,255 @@ func TestSendSetRequest(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, "OK", resp)
 }
+
+func TestSendSetExRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	key := "test_key"
+	val := tlv.String("value")
+	rawval, _ := val.ToTLV()
+
+	reqbod := payload.RedisRequestBody{
+		Key:   key,
+		Value: rawval,
+		TTL:   time.Minute,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.SetCmd, rawreqbod)
+
+	resbod := tlv.String("OK")
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.StringType, rawresbod)
+
+	resp, err := SendSetExRequest(conn, key, rawval, time.Minute)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", resp)
+}
+
+func TestSendExpireRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	key := "test_key"
+
+	reqbod := payload.RedisRequestBody{
+		Key: key,
+		TTL: time.Minute,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.ExpireCmd, rawreqbod)
+
+	resbod := tlv.String("1")
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.StringType, rawresbod)
+
+	ok, err := SendExpireRequest(conn, key, time.Minute)
+
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}
+
+func TestSendTTLRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	key := "test_key"
+
+	reqbod := payload.RedisRequestBody{
+		Key: key,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.TTLCmd, rawreqbod)
+
+	resbod := tlv.String("1500")
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.StringType, rawresbod)
+
+	ttl, err := SendTTLRequest(conn, key)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1500*time.Millisecond, ttl)
+}
+
+func TestSendTTLRequestNoExpire(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	key := "test_key"
+
+	reqbod := payload.RedisRequestBody{
+		Key: key,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.TTLCmd, rawreqbod)
+
+	resbod := tlv.String("-1")
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.StringType, rawresbod)
+
+	ttl, err := SendTTLRequest(conn, key)
+
+	assert.Nil(t, err)
+	assert.Equal(t, db.NoExpireTTL, ttl)
+}
+
+func TestSendPersistRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	key := "test_key"
+
+	reqbod := payload.RedisRequestBody{
+		Key: key,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.PersistCmd, rawreqbod)
+
+	resbod := tlv.String("0")
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.StringType, rawresbod)
+
+	ok, err := SendPersistRequest(conn, key)
+
+	assert.Nil(t, err)
+	assert.False(t, ok)
+}
+
+func TestSendDelRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	keys, _ := tlv.NewStringArray("a", "b")
+	rawkeys, _ := keys.ToTLV()
+	reqbod := payload.RedisRequestBody{
+		Value: rawkeys,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.DelCmd, rawreqbod)
+
+	resbod := tlv.String("2")
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.StringType, rawresbod)
+
+	n, err := SendDelRequest(conn, []string{"a", "b"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, n)
+}
+
+func TestSendExistsRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	keys, _ := tlv.NewStringArray("a")
+	rawkeys, _ := keys.ToTLV()
+	reqbod := payload.RedisRequestBody{
+		Value: rawkeys,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.ExistsCmd, rawreqbod)
+
+	resbod := tlv.String("0")
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.StringType, rawresbod)
+
+	n, err := SendExistsRequest(conn, []string{"a"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestSendScanRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	args, _ := tlv.NewStringArray("", "user:*", "10")
+	rawargs, _ := args.ToTLV()
+	reqbod := payload.RedisRequestBody{
+		Value: rawargs,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.ScanCmd, rawreqbod)
+
+	keys, _ := tlv.NewStringArray("user:1")
+	reply, _ := tlv.NewStringArray("user:1")
+	reply.Append(keys)
+	rawreply, _ := reply.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.ArrayType, rawreply)
+
+	cursor, found, err := SendScanRequest(conn, "", "user:*", 10)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "user:1", cursor)
+	assert.Equal(t, []string{"user:1"}, found)
+}
+
+func TestSendIncrByRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	delta := tlv.Int64(5)
+	rawdelta, _ := delta.ToTLV()
+	reqbod := payload.RedisRequestBody{
+		Key:   "counter",
+		Value: rawdelta,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.IncrByCmd, rawreqbod)
+
+	resbod := tlv.Int64(15)
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.Int64Type, rawresbod)
+
+	n, err := SendIncrByRequest(conn, "counter", 5)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(15), n)
+}
+
+func TestSendIncrRequestErrorReply(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	reqbod := payload.RedisRequestBody{
+		Key:   "counter",
+		Value: []byte{},
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.IncrCmd, rawreqbod)
+
+	resbod := tlv.NewCodeError(tlv.NotIntegerError)
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.ErrorType, rawresbod)
+
+	_, err := SendIncrRequest(conn, "counter")
+
+	tlvErr, ok := err.(*tlv.Error)
+	assert.True(t, ok)
+	assert.Equal(t, tlv.NotIntegerError, tlvErr.Code)
+}
+
+func TestSendIncrByFloatRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	delta := tlv.Float64(0.5)
+	rawdelta, _ := delta.ToTLV()
+	reqbod := payload.RedisRequestBody{
+		Key:   "counter",
+		Value: rawdelta,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.IncrByFloatCmd, rawreqbod)
+
+	resbod := tlv.Float64(1.5)
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.Float64Type, rawresbod)
+
+	f, err := SendIncrByFloatRequest(conn, "counter", 0.5)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1.5, f)
+}
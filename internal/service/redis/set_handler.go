@@ -0,0 +1,176 @@
+package redis
+
+import (
+	"sort"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/helper"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+// Members are sent as string array, reply is number of members added
+func handleSAddRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	members, err := readArgs(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if len(members) == 0 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	sadd := &model.SAddCommand{Members: members}
+	err = ctx.ExecRedis(body.Key, sadd)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	n := tlv.Int64(sadd.Added)
+	return helper.ResponseWithTLV(&n, ctx)
+}
+
+// Members are sent as string array, set left empty is deleted
+func handleSRemRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	members, err := readArgs(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	srem := &model.SRemCommand{Members: members}
+	err = ctx.ExecRedis(body.Key, srem)
+	if err != nil && err != model.ErrNoChange {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	n := tlv.Int64(srem.Removed)
+	return helper.ResponseWithTLV(&n, ctx)
+}
+
+// Member is sent as string, reply is 1 when member is in set and 0 otherwise
+func handleSIsMemberRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	member := new(tlv.String)
+	err := member.FromTLV(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.SyntaxError), ctx)
+	}
+
+	var n tlv.Int64
+	err = ctx.ViewRedis(body.Key, func(v *model.Value) error {
+		if v == nil {
+			return nil
+		}
+		if v.Type != model.SetValueType {
+			return model.ErrWrongType
+		}
+
+		if _, ok := v.Set[member.String()]; ok {
+			n = 1
+		}
+		return nil
+	})
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	return helper.ResponseWithTLV(&n, ctx)
+}
+
+// Members are replied as string array in sorted order
+func handleSMembersRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	var members []string
+	err := ctx.ViewRedis(body.Key, func(v *model.Value) error {
+		if v == nil {
+			return nil
+		}
+		if v.Type != model.SetValueType {
+			return model.ErrWrongType
+		}
+
+		members = v.Members()
+		return nil
+	})
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	reply, err := tlv.NewStringArray(members...)
+	if err != nil {
+		return err
+	}
+
+	return helper.ResponseWithTLV(reply, ctx)
+}
+
+// Keys of SINTER, SUNION and SDIFF are sent as string array, missing key is an empty set.
+// SDIFF remove members of other keys from the first one.
+func handleSetOpRequest(ctx payload.IRequestContext, cmd uint8, body *payload.RedisRequestBody) error {
+	keys, err := readArgs(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if len(keys) == 0 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	res := make(map[string]struct{})
+	err = ctx.ViewKeysRedis(keys, func(vs []*model.Value) error {
+		for _, v := range vs {
+			if v != nil && v.Type != model.SetValueType {
+				return model.ErrWrongType
+			}
+		}
+
+		if vs[0] != nil {
+			for m := range vs[0].Set {
+				res[m] = struct{}{}
+			}
+		}
+
+		for _, v := range vs[1:] {
+			switch cmd {
+			case payload.SInterCmd:
+				for m := range res {
+					if v == nil {
+						delete(res, m)
+					} else if _, ok := v.Set[m]; !ok {
+						delete(res, m)
+					}
+				}
+				break
+			case payload.SUnionCmd:
+				if v != nil {
+					for m := range v.Set {
+						res[m] = struct{}{}
+					}
+				}
+				break
+			case payload.SDiffCmd:
+				if v != nil {
+					for m := range v.Set {
+						delete(res, m)
+					}
+				}
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	members := make([]string, 0, len(res))
+	for m := range res {
+		members = append(members, m)
+	}
+	sort.Strings(members)
+
+	reply, err := tlv.NewStringArray(members...)
+	if err != nil {
+		return err
+	}
+
+	return helper.ResponseWithTLV(reply, ctx)
+}
@@ -0,0 +1,220 @@
+package redis
+
+import (
+	"testing"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
+	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
+	mockpayload "bitbucket.org/non-pn/mini-redis-go/internal/mock/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/test"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func newSetValue(members ...string) *model.Value {
+	v := model.NewSetValue()
+	for _, m := range members {
+		v.Set[m] = struct{}{}
+	}
+	return v
+}
+
+func TestHandleSAddRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	members, _ := tlv.NewStringArray("a", "b", "a")
+	rawmembers, _ := members.ToTLV()
+	bod := getRedisRequestBody("test_set", rawmembers)
+
+	set := newSetValue("a")
+	n := tlv.Int64(1)
+	rawn, _ := n.ToTLV()
+
+	expectExecRedis(ctx, "test_set", set)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawn)
+
+	err := handleSAddRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, set.Members())
+}
+
+func TestHandleSAddRequestWrongType(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	members, _ := tlv.NewStringArray("a")
+	rawmembers, _ := members.ToTLV()
+	bod := getRedisRequestBody("test_hash", rawmembers)
+
+	expectExecRedis(ctx, "test_hash", model.NewHashValue())
+	ctx.EXPECT().Error(uint16(tlv.WrongTypeError), tlv.ErrMsg[tlv.WrongTypeError]).Times(1)
+
+	err := handleSAddRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleSAddRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	rawmembers, _ := (&tlv.Array{}).ToTLV()
+	bod := getRedisRequestBody("test_set", rawmembers)
+	expectErrorReply(ctx, tlv.WrongArgumentsError)
+
+	err := handleSAddRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleSRemRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	members, _ := tlv.NewStringArray("a", "missing")
+	rawmembers, _ := members.ToTLV()
+	bod := getRedisRequestBody("test_set", rawmembers)
+
+	n := tlv.Int64(1)
+	rawn, _ := n.ToTLV()
+
+	// Set left empty is deleted by not keeping it
+	ctx.EXPECT().ExecRedis("test_set", gomock.Any()).Times(1).DoAndReturn(
+		func(k string, cmd model.Command) error {
+			_, keep, err := cmd.Apply(newSetValue("a"), true)
+			assert.False(t, keep)
+			return err
+		})
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawn)
+
+	err := handleSRemRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleSRemRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	member := tlv.String("a")
+	rawmember, _ := member.ToTLV()
+	bod := getRedisRequestBody("test_set", rawmember)
+	expectErrorReply(ctx, tlv.SyntaxError)
+
+	err := handleSRemRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleSIsMemberRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	member := tlv.String("a")
+	rawmember, _ := member.ToTLV()
+	bod := getRedisRequestBody("test_set", rawmember)
+
+	n := tlv.Int64(1)
+	rawn, _ := n.ToTLV()
+
+	expectViewRedis(ctx, "test_set", newSetValue("a"))
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawn)
+
+	err := handleSIsMemberRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleSIsMemberRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	members, _ := tlv.NewStringArray("a")
+	rawmembers, _ := members.ToTLV()
+	bod := getRedisRequestBody("test_set", rawmembers)
+	expectErrorReply(ctx, tlv.SyntaxError)
+
+	err := handleSIsMemberRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleSMembersRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("test_set", []byte{})
+
+	reply, _ := tlv.NewStringArray("a", "b")
+	rawreply, _ := reply.ToTLV()
+
+	expectViewRedis(ctx, "test_set", newSetValue("b", "a"))
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.ArrayType, rawreply)
+
+	err := handleSMembersRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleSetOpRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		cmd  uint8
+		want []string
+	}{
+		{"inter", payload.SInterCmd, []string{"b"}},
+		{"union", payload.SUnionCmd, []string{"a", "b", "c"}},
+		{"diff", payload.SDiffCmd, []string{"a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			conn := mocknet.NewMockConn(ctrl)
+			ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+			keys, _ := tlv.NewStringArray("s1", "s2")
+			rawkeys, _ := keys.ToTLV()
+			bod := getRedisRequestBody("", rawkeys)
+
+			reply, _ := tlv.NewStringArray(tt.want...)
+			rawreply, _ := reply.ToTLV()
+
+			ctx.EXPECT().ViewKeysRedis([]string{"s1", "s2"}, gomock.Any()).Times(1).DoAndReturn(
+				func(keys []string, fn func(vs []*model.Value) error) error {
+					return fn([]*model.Value{newSetValue("a", "b"), newSetValue("b", "c")})
+				})
+			ctx.EXPECT().GetConn().Times(1).Return(conn)
+			test.ExpectWriteResponseToConn(t, conn, tlv.ArrayType, rawreply)
+
+			err := handleSetOpRequest(ctx, tt.cmd, &bod)
+
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestHandleSetOpRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	rawkeys, _ := (&tlv.Array{}).ToTLV()
+	bod := getRedisRequestBody("", rawkeys)
+	expectErrorReply(ctx, tlv.WrongArgumentsError)
+
+	err := handleSetOpRequest(ctx, payload.SUnionCmd, &bod)
+
+	assert.Nil(t, err)
+}
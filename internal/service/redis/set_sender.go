@@ -0,0 +1,99 @@
+package redis
+
+import (
+	"net"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+func SendSAddRequest(conn net.Conn, key string, members []string) (int64, error) {
+	return sendMembersRequest(conn, payload.SAddCmd, key, members)
+}
+
+func SendSRemRequest(conn net.Conn, key string, members []string) (int64, error) {
+	return sendMembersRequest(conn, payload.SRemCmd, key, members)
+}
+
+func SendSIsMemberRequest(conn net.Conn, key string, member string) (bool, error) {
+	m := tlv.String(member)
+	rawmember, err := m.ToTLV()
+	if err != nil {
+		return false, err
+	}
+
+	n, err := sendInt64Request(conn, payload.SIsMemberCmd, key, rawmember)
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+func SendSMembersRequest(conn net.Conn, key string) ([]string, error) {
+	body := payload.RedisRequestBody{
+		Key:   key,
+		Value: []byte{},
+	}
+
+	return sendStringsRequest(conn, payload.SMembersCmd, body)
+}
+
+func SendSInterRequest(conn net.Conn, keys []string) ([]string, error) {
+	return sendSetOpRequest(conn, payload.SInterCmd, keys)
+}
+
+func SendSUnionRequest(conn net.Conn, keys []string) ([]string, error) {
+	return sendSetOpRequest(conn, payload.SUnionCmd, keys)
+}
+
+func SendSDiffRequest(conn net.Conn, keys []string) ([]string, error) {
+	return sendSetOpRequest(conn, payload.SDiffCmd, keys)
+}
+
+func sendMembersRequest(conn net.Conn, cmd uint8, key string, members []string) (int64, error) {
+	args, err := tlv.NewStringArray(members...)
+	if err != nil {
+		return 0, err
+	}
+
+	rawargs, err := args.ToTLV()
+	if err != nil {
+		return 0, err
+	}
+
+	return sendInt64Request(conn, cmd, key, rawargs)
+}
+
+func sendSetOpRequest(conn net.Conn, cmd uint8, keys []string) ([]string, error) {
+	args, err := tlv.NewStringArray(keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	rawargs, err := args.ToTLV()
+	if err != nil {
+		return nil, err
+	}
+
+	body := payload.RedisRequestBody{
+		Value: rawargs,
+	}
+
+	return sendStringsRequest(conn, cmd, body)
+}
+
+func sendStringsRequest(conn net.Conn, cmd uint8, body payload.RedisRequestBody) ([]string, error) {
+	resp, err := sendRequest(conn, cmd, body)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := new(tlv.Array)
+	err = reply.FromTLV(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return reply.Strings()
+}
@@ -0,0 +1,57 @@
+package redis
+
+import (
+	"testing"
+
+	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/test"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSendSInterRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	keys, _ := tlv.NewStringArray("s1", "s2")
+	rawkeys, _ := keys.ToTLV()
+	reqbod := payload.RedisRequestBody{
+		Value: rawkeys,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.SInterCmd, rawreqbod)
+
+	reply, _ := tlv.NewStringArray("a", "b")
+	rawreply, _ := reply.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.ArrayType, rawreply)
+
+	members, err := SendSInterRequest(conn, []string{"s1", "s2"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, members)
+}
+
+func TestSendSIsMemberRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	member := tlv.String("a")
+	rawmember, _ := member.ToTLV()
+	reqbod := payload.RedisRequestBody{
+		Key:   "test_set",
+		Value: rawmember,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.SIsMemberCmd, rawreqbod)
+
+	resbod := tlv.Int64(1)
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.Int64Type, rawresbod)
+
+	ok, err := SendSIsMemberRequest(conn, "test_set", "a")
+
+	assert.Nil(t, err)
+	assert.True(t, ok)
+}
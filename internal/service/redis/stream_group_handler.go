@@ -0,0 +1,359 @@
+package redis
+
+import (
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/helper"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+// Id of XREADGROUP asking for entries never delivered to group
+const streamUndeliveredID = ">"
+
+// Group create arguments are group name and id of last entry it does not deliver, $ being
+// last entry of stream. Stream is created when it does not exist.
+func handleXGroupCreateRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	args, err := readArgs(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if len(args) != 2 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	create := &model.XGroupCreateCommand{Group: args[0], New: args[1] == streamNewID}
+	if !create.New {
+		create.Last, err = model.ParseStreamID(args[1])
+		if err != nil {
+			return helper.ResponseWithError(err, ctx)
+		}
+	}
+
+	err = ctx.ExecRedis(body.Key, create)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	return helper.ResponseWithString("OK", ctx)
+}
+
+// Group is sent as string, reply is 1 when group was destroyed
+func handleXGroupDestroyRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	group := new(tlv.String)
+	err := group.FromTLV(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.SyntaxError), ctx)
+	}
+
+	err = ctx.ExecRedis(body.Key, &model.XGroupDestroyCommand{Group: group.String()})
+	if err != nil && err != model.ErrNoChange {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	var n tlv.Int64
+	if err == nil {
+		n = 1
+	}
+	return helper.ResponseWithTLV(&n, ctx)
+}
+
+// Read group arguments are group and consumer names, count and block flag as tlv.Int64,
+// keys and ids as string arrays. Id > read entries never delivered to group, other id
+// read entries pending for consumer after it. Only read of undelivered entries of every
+// key block, waiting up to ttl or forever when it is zero. Reply is the same as XREAD.
+func handleXReadGroupRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	args, err := readArray(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if len(*args) != 6 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	group, consumer := new(tlv.String), new(tlv.String)
+	err = group.FromTLV((*args)[0])
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.SyntaxError), ctx)
+	}
+	err = consumer.FromTLV((*args)[1])
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.SyntaxError), ctx)
+	}
+	count, block := new(tlv.Int64), new(tlv.Int64)
+	err = count.FromTLV((*args)[2])
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.NotIntegerError), ctx)
+	}
+	err = block.FromTLV((*args)[3])
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.NotIntegerError), ctx)
+	}
+	keys, err := readArgs((*args)[4])
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+	rawids, err := readArgs((*args)[5])
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if len(keys) == 0 || len(keys) != len(rawids) {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	// Nil id stand for undelivered entries
+	ids := make([]*model.StreamID, len(rawids))
+	undelivered := true
+	for i, s := range rawids {
+		if s == streamUndeliveredID {
+			continue
+		}
+		id, err := model.ParseStreamID(s)
+		if err != nil {
+			return helper.ResponseWithError(err, ctx)
+		}
+		ids[i] = &id
+		undelivered = false
+	}
+
+	var timeout <-chan time.Time
+	if body.TTL > 0 {
+		timer := time.NewTimer(body.TTL)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	for {
+		// Wait is registered before reading so add in between wake us up
+		wake, cancel := ctx.WaitRedis(keys...)
+
+		reply, err := readGroupStreams(ctx, group.String(), consumer.String(), keys, ids, int(*count))
+		if err != nil {
+			cancel()
+			return helper.ResponseWithError(err, ctx)
+		}
+
+		if len(*reply) > 0 {
+			cancel()
+			return helper.ResponseWithTLV(reply, ctx)
+		}
+
+		if *block == 0 || !undelivered {
+			cancel()
+			return helper.ResponseWithRaw(nil, ctx)
+		}
+
+		// Replies of requests pipelined before this one must not wait for it
+		err = ctx.Flush()
+		if err != nil {
+			cancel()
+			return err
+		}
+
+		select {
+		case <-wake:
+			cancel()
+		case <-timeout:
+			cancel()
+			return helper.ResponseWithRaw(nil, ctx)
+		case <-ctx.Done():
+			cancel()
+			return helper.ResponseWithError(tlv.NewCodeError(tlv.ShuttingDownError), ctx)
+		}
+	}
+}
+
+// Ack arguments are group followed by ids of entries, reply is number of entries that
+// were pending
+func handleXAckRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	args, err := readArgs(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if len(args) < 2 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	ids, err := parseStreamIDs(args[1:])
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	ack := &model.XAckCommand{Group: args[0], IDs: ids}
+	err = ctx.ExecRedis(body.Key, ack)
+	if err != nil && err != model.ErrNoChange {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	n := tlv.Int64(ack.Acked)
+	return helper.ResponseWithTLV(&n, ctx)
+}
+
+// Pending arguments are group and consumer, empty consumer list entries of every consumer.
+// Reply is array of pending entries ordered by id, each being array of id, consumer, idle
+// milliseconds and delivery count.
+func handleXPendingRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	args, err := readArgs(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if len(args) != 2 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	reply := &tlv.Array{}
+	err = ctx.ViewRedis(body.Key, func(v *model.Value) error {
+		if v == nil {
+			return model.ErrNoGroup
+		}
+		if v.Type != model.StreamValueType {
+			return model.ErrWrongType
+		}
+
+		g, err := v.Stream.Group(args[0])
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for _, p := range g.Pending(args[1]) {
+			item, err := tlv.NewStringArray(p.ID.String(), p.Consumer)
+			if err != nil {
+				return err
+			}
+			idle, count := tlv.Int64(now.Sub(p.Delivered).Milliseconds()), tlv.Int64(p.Count)
+			err = item.Append(&idle)
+			if err != nil {
+				return err
+			}
+			err = item.Append(&count)
+			if err != nil {
+				return err
+			}
+			err = reply.Append(item)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	return helper.ResponseWithTLV(reply, ctx)
+}
+
+// Claim arguments are group and consumer names, count as tlv.Int64 and ids as string
+// array, ttl is min idle time of claimed entries. Without ids any idle pending entry is
+// claimed up to count. Reply is claimed entries same as XRANGE.
+func handleXClaimRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	args, err := readArray(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if len(*args) != 4 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	group, consumer := new(tlv.String), new(tlv.String)
+	err = group.FromTLV((*args)[0])
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.SyntaxError), ctx)
+	}
+	err = consumer.FromTLV((*args)[1])
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.SyntaxError), ctx)
+	}
+	count := new(tlv.Int64)
+	err = count.FromTLV((*args)[2])
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.NotIntegerError), ctx)
+	}
+	rawids, err := readArgs((*args)[3])
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+	ids, err := parseStreamIDs(rawids)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	claim := &model.XClaimCommand{
+		Group:    group.String(),
+		Consumer: consumer.String(),
+		MinIdle:  body.TTL,
+		IDs:      ids,
+		Count:    int(*count),
+		Now:      time.Now(),
+	}
+	err = ctx.ExecRedis(body.Key, claim)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	reply, err := streamEntriesToTLV(claim.Entries)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	return helper.ResponseWithTLV(reply, ctx)
+}
+
+// readGroupStreams read entries of each key for consumer of group, nil id read undelivered
+// entries and other id read pending entries after it. Keys without such entries are left
+// out, and are not written to.
+func readGroupStreams(ctx payload.IRequestContext, group string, consumer string, keys []string, ids []*model.StreamID, count int) (*tlv.Array, error) {
+	reply := &tlv.Array{}
+	for i, k := range keys {
+		read := &model.XReadGroupCommand{Group: group, Consumer: consumer, After: ids[i], Count: count, Now: time.Now()}
+		err := ctx.ExecRedis(k, read)
+		if err == model.ErrNoChange {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries, err := streamEntriesToTLV(read.Entries)
+		if err != nil {
+			return nil, err
+		}
+
+		key := tlv.String(k)
+		item := tlv.Array{}
+		err = item.Append(&key)
+		if err != nil {
+			return nil, err
+		}
+		err = item.Append(entries)
+		if err != nil {
+			return nil, err
+		}
+		err = reply.Append(&item)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return reply, nil
+}
+
+func parseStreamIDs(rawids []string) ([]model.StreamID, error) {
+	ids := make([]model.StreamID, 0, len(rawids))
+	for _, s := range rawids {
+		id, err := model.ParseStreamID(s)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,360 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
+	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
+	mockpayload "bitbucket.org/non-pn/mini-redis-go/internal/mock/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/test"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func getXReadGroupArgs(group string, consumer string, count int64, block int64, keys []string, ids []string) tlv.TypeLengthValue {
+	args, _ := tlv.NewStringArray(group, consumer)
+	n, b := tlv.Int64(count), tlv.Int64(block)
+	rawkeys, _ := tlv.NewStringArray(keys...)
+	rawids, _ := tlv.NewStringArray(ids...)
+	args.Append(&n)
+	args.Append(&b)
+	args.Append(rawkeys)
+	args.Append(rawids)
+	raw, _ := args.ToTLV()
+	return raw
+}
+
+func getXClaimArgs(group string, consumer string, count int64, ids []string) tlv.TypeLengthValue {
+	args, _ := tlv.NewStringArray(group, consumer)
+	n := tlv.Int64(count)
+	rawids, _ := tlv.NewStringArray(ids...)
+	args.Append(&n)
+	args.Append(rawids)
+	raw, _ := args.ToTLV()
+	return raw
+}
+
+func TestHandleXGroupCreateRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	args, _ := tlv.NewStringArray("test_group", "$")
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("test_stream", rawargs)
+	stream := newTestStream(model.StreamID{Ms: 1}, model.StreamID{Ms: 2})
+	ok := tlv.String("OK")
+	rawok, _ := ok.ToTLV()
+
+	expectExecRedis(ctx, "test_stream", stream)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.StringType, rawok)
+
+	err := handleXGroupCreateRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+	// $ make group start after last entry
+	g, err := stream.Stream.Group("test_group")
+	assert.Nil(t, err)
+	assert.Equal(t, model.StreamID{Ms: 2}, g.LastID())
+}
+
+func TestHandleXGroupCreateRequestExists(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	args, _ := tlv.NewStringArray("test_group", "0")
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("test_stream", rawargs)
+	stream := newTestStream(model.StreamID{Ms: 1})
+	stream.Stream.CreateGroup("test_group", model.StreamID{})
+
+	expectExecRedis(ctx, "test_stream", stream)
+	ctx.EXPECT().Error(uint16(tlv.GroupExistsError), tlv.ErrMsg[tlv.GroupExistsError]).Times(1)
+
+	err := handleXGroupCreateRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleXGroupCreateRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	args, _ := tlv.NewStringArray("g")
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("test_stream", rawargs)
+	expectErrorReply(ctx, tlv.WrongArgumentsError)
+
+	err := handleXGroupCreateRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+
+	group := tlv.String("g")
+	rawgroup, _ := group.ToTLV()
+	bod = getRedisRequestBody("test_stream", rawgroup)
+	expectErrorReply(ctx, tlv.SyntaxError)
+
+	err = handleXGroupCreateRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleXGroupDestroyRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	args, _ := tlv.NewStringArray("g")
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("test_stream", rawargs)
+	expectErrorReply(ctx, tlv.SyntaxError)
+
+	err := handleXGroupDestroyRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleXReadGroupRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("", getXReadGroupArgs("test_group", "alice", 1, 0, []string{"test_stream"}, []string{">"}))
+	stream := newTestStream(model.StreamID{Ms: 1}, model.StreamID{Ms: 2})
+	stream.Stream.CreateGroup("test_group", model.StreamID{})
+
+	entries, _ := streamEntriesToTLV(stream.Stream.Range(model.StreamID{Ms: 1}, model.StreamID{Ms: 1}, 0))
+	key := tlv.String("test_stream")
+	item := tlv.Array{}
+	item.Append(&key)
+	item.Append(entries)
+	reply := tlv.Array{}
+	reply.Append(&item)
+	rawreply, _ := reply.ToTLV()
+
+	ctx.EXPECT().WaitRedis("test_stream").Times(1).Return(make(chan struct{}), func() {})
+	expectExecRedis(ctx, "test_stream", stream)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.ArrayType, rawreply)
+
+	err := handleXReadGroupRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+	// Read entry is pending for consumer and no more undelivered
+	g, _ := stream.Stream.Group("test_group")
+	pending := g.Pending("alice")
+	assert.Equal(t, 1, len(pending))
+	assert.Equal(t, model.StreamID{Ms: 1}, pending[0].ID)
+	assert.Equal(t, model.StreamID{Ms: 1}, g.LastID())
+}
+
+func TestHandleXReadGroupRequestPendingDoesNotBlock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("", getXReadGroupArgs("test_group", "alice", 0, 1, []string{"test_stream"}, []string{"0"}))
+	stream := newTestStream(model.StreamID{Ms: 1})
+	stream.Stream.CreateGroup("test_group", model.StreamID{})
+
+	ctx.EXPECT().WaitRedis("test_stream").Times(1).Return(make(chan struct{}), func() {})
+	expectExecRedis(ctx, "test_stream", stream)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.EmptyType, nil)
+
+	err := handleXReadGroupRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleXReadGroupRequestNoGroup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("", getXReadGroupArgs("test_group", "alice", 0, 0, []string{"test_stream"}, []string{">"}))
+
+	ctx.EXPECT().WaitRedis("test_stream").Times(1).Return(make(chan struct{}), func() {})
+	expectExecRedis(ctx, "test_stream", nil)
+	ctx.EXPECT().Error(uint16(tlv.NoGroupError), tlv.ErrMsg[tlv.NoGroupError]).Times(1)
+
+	err := handleXReadGroupRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleXReadGroupRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("", getXReadGroupArgs("g", "c", 0, 0, []string{"a"}, []string{">", ">"}))
+	expectErrorReply(ctx, tlv.WrongArgumentsError)
+
+	err := handleXReadGroupRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+
+	args, _ := tlv.NewStringArray("g", "c", "10", "0", "a", ">")
+	rawargs, _ := args.ToTLV()
+	bod = getRedisRequestBody("", rawargs)
+	expectErrorReply(ctx, tlv.NotIntegerError)
+
+	err = handleXReadGroupRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleXAckRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	args, _ := tlv.NewStringArray("test_group", "1", "3")
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("test_stream", rawargs)
+	stream := newTestStream(model.StreamID{Ms: 1}, model.StreamID{Ms: 2})
+	stream.Stream.CreateGroup("test_group", model.StreamID{})
+	stream.Stream.ReadGroup("test_group", "alice", 0, time.Now())
+	n := tlv.Int64(1)
+	rawn, _ := n.ToTLV()
+
+	expectExecRedis(ctx, "test_stream", stream)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawn)
+
+	err := handleXAckRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+	g, _ := stream.Stream.Group("test_group")
+	pending := g.Pending("")
+	assert.Equal(t, 1, len(pending))
+	assert.Equal(t, model.StreamID{Ms: 2}, pending[0].ID)
+}
+
+func TestHandleXAckRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	args, _ := tlv.NewStringArray("g")
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("test_stream", rawargs)
+	expectErrorReply(ctx, tlv.WrongArgumentsError)
+
+	err := handleXAckRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+
+	args, _ = tlv.NewStringArray("g", "first")
+	rawargs, _ = args.ToTLV()
+	bod = getRedisRequestBody("test_stream", rawargs)
+	expectErrorReply(ctx, tlv.InvalidStreamIDError)
+
+	err = handleXAckRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleXPendingRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	args, _ := tlv.NewStringArray("test_group", "alice")
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("test_stream", rawargs)
+	stream := newTestStream(model.StreamID{Ms: 1}, model.StreamID{Ms: 2})
+	stream.Stream.CreateGroup("test_group", model.StreamID{})
+	stream.Stream.ReadGroup("test_group", "alice", 1, time.Now())
+	stream.Stream.ReadGroup("test_group", "bob", 1, time.Now())
+
+	expectViewRedis(ctx, "test_stream", stream)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	conn.EXPECT().Write(gomock.Any()).Times(1).DoAndReturn(func(b []byte) (int, error) {
+		resp := payload.ResponsePayload{}
+		assert.Nil(t, resp.FromTLV(b))
+		reply := new(tlv.Array)
+		assert.Nil(t, reply.FromTLV(resp.Body))
+		// Only entry pending for alice is listed
+		assert.Equal(t, 1, len(*reply))
+		item := new(tlv.Array)
+		assert.Nil(t, item.FromTLV((*reply)[0]))
+		id, consumer, count := new(tlv.String), new(tlv.String), new(tlv.Int64)
+		id.FromTLV((*item)[0])
+		consumer.FromTLV((*item)[1])
+		count.FromTLV((*item)[3])
+		assert.Equal(t, "1-0", id.String())
+		assert.Equal(t, "alice", consumer.String())
+		assert.Equal(t, tlv.Int64(1), *count)
+		return len(b), nil
+	})
+
+	err := handleXPendingRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleXPendingRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	args, _ := tlv.NewStringArray("g")
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("test_stream", rawargs)
+	expectErrorReply(ctx, tlv.WrongArgumentsError)
+
+	err := handleXPendingRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleXClaimRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("test_stream", getXClaimArgs("test_group", "bob", 0, []string{}))
+	bod.TTL = time.Minute
+	stream := newTestStream(model.StreamID{Ms: 1}, model.StreamID{Ms: 2})
+	stream.Stream.CreateGroup("test_group", model.StreamID{})
+	// First entry is idle long enough, second was just delivered
+	stream.Stream.ReadGroup("test_group", "alice", 1, time.Now().Add(-time.Hour))
+	stream.Stream.ReadGroup("test_group", "alice", 1, time.Now())
+
+	entries, _ := streamEntriesToTLV(stream.Stream.Range(model.StreamID{Ms: 1}, model.StreamID{Ms: 1}, 0))
+	rawentries, _ := entries.ToTLV()
+
+	expectExecRedis(ctx, "test_stream", stream)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.ArrayType, rawentries)
+
+	err := handleXClaimRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+	g, _ := stream.Stream.Group("test_group")
+	pending := g.Pending("bob")
+	assert.Equal(t, 1, len(pending))
+	assert.Equal(t, 2, pending[0].Count)
+}
+
+func TestHandleXClaimRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	args, _ := tlv.NewStringArray("g", "c", "1", "0-1")
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("test_stream", rawargs)
+	expectErrorReply(ctx, tlv.NotIntegerError)
+
+	err := handleXClaimRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+
+	bod = getRedisRequestBody("test_stream", getXClaimArgs("g", "c", 1, []string{"first"}))
+	expectErrorReply(ctx, tlv.InvalidStreamIDError)
+
+	err = handleXClaimRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
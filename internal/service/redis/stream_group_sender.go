@@ -0,0 +1,213 @@
+package redis
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+// StreamPending is entry delivered to consumer of group that was not acknowledged yet
+type StreamPending struct {
+	ID       string
+	Consumer string
+	// Time since entry was last delivered
+	Idle  time.Duration
+	Count int64
+}
+
+// SendXGroupCreateRequest create group delivering entries after id, $ being last entry of
+// stream. Stream is created when it does not exist.
+func SendXGroupCreateRequest(conn net.Conn, key string, group string, id string) (string, error) {
+	args, err := tlv.NewStringArray(group, id)
+	if err != nil {
+		return "", err
+	}
+
+	rawargs, err := args.ToTLV()
+	if err != nil {
+		return "", err
+	}
+
+	body := payload.RedisRequestBody{
+		Key:   key,
+		Value: rawargs,
+	}
+
+	return sendStringRequest(conn, payload.XGroupCreateCmd, body)
+}
+
+func SendXGroupDestroyRequest(conn net.Conn, key string, group string) (bool, error) {
+	name := tlv.String(group)
+	rawname, err := name.ToTLV()
+	if err != nil {
+		return false, err
+	}
+
+	n, err := sendInt64Request(conn, payload.XGroupDestroyCmd, key, rawname)
+	return n == 1, err
+}
+
+// SendXReadGroupRequest read entries of each key for consumer of group. Id > read entries
+// never delivered to group, other id read entries pending for consumer after it. Blocking
+// read of undelivered entries wait up to timeout, zero timeout block forever. It return nil
+// when there is no entry to read.
+func SendXReadGroupRequest(conn net.Conn, group string, consumer string, keys []string, ids []string, count int64, block bool, timeout time.Duration) ([]StreamRead, error) {
+	args, err := tlv.NewStringArray(group, consumer)
+	if err != nil {
+		return nil, err
+	}
+	n, b := tlv.Int64(count), tlv.Int64(0)
+	if block {
+		b = 1
+	}
+	rawkeys, err := tlv.NewStringArray(keys...)
+	if err != nil {
+		return nil, err
+	}
+	rawids, err := tlv.NewStringArray(ids...)
+	if err != nil {
+		return nil, err
+	}
+	for _, arg := range []tlv.TLVCompatible{&n, &b, rawkeys, rawids} {
+		err = args.Append(arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rawargs, err := args.ToTLV()
+	if err != nil {
+		return nil, err
+	}
+
+	body := payload.RedisRequestBody{
+		Value: rawargs,
+		TTL:   timeout,
+	}
+
+	resp, err := sendRequest(conn, payload.XReadGroupCmd, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeStreamReads(resp)
+}
+
+// SendXAckRequest acknowledge entries and return how many of them were pending
+func SendXAckRequest(conn net.Conn, key string, group string, ids []string) (int64, error) {
+	args, err := tlv.NewStringArray(append([]string{group}, ids...)...)
+	if err != nil {
+		return 0, err
+	}
+
+	rawargs, err := args.ToTLV()
+	if err != nil {
+		return 0, err
+	}
+
+	return sendInt64Request(conn, payload.XAckCmd, key, rawargs)
+}
+
+// SendXPendingRequest return entries pending for consumer of group ordered by id, empty
+// consumer return entries of every consumer
+func SendXPendingRequest(conn net.Conn, key string, group string, consumer string) ([]StreamPending, error) {
+	args, err := tlv.NewStringArray(group, consumer)
+	if err != nil {
+		return nil, err
+	}
+
+	rawargs, err := args.ToTLV()
+	if err != nil {
+		return nil, err
+	}
+
+	body := payload.RedisRequestBody{
+		Key:   key,
+		Value: rawargs,
+	}
+
+	resp, err := sendRequest(conn, payload.XPendingCmd, body)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := new(tlv.Array)
+	err = reply.FromTLV(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]StreamPending, 0, len(*reply))
+	for _, raw := range *reply {
+		item := new(tlv.Array)
+		err = item.FromTLV(raw)
+		if err != nil {
+			return nil, err
+		}
+		if len(*item) != 4 {
+			return nil, errors.New("Invalid xpending reply")
+		}
+
+		id, consumer := new(tlv.String), new(tlv.String)
+		idle, count := new(tlv.Int64), new(tlv.Int64)
+		for i, v := range []tlv.TLVCompatible{id, consumer, idle, count} {
+			err = v.FromTLV((*item)[i])
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		res = append(res, StreamPending{
+			ID:       id.String(),
+			Consumer: consumer.String(),
+			Idle:     time.Duration(*idle) * time.Millisecond,
+			Count:    int64(*count),
+		})
+	}
+
+	return res, nil
+}
+
+// SendXClaimRequest hand to consumer pending entries of group idle for at least minIdle
+// and return them. Without ids any idle pending entry is claimed, up to count when it is
+// positive.
+func SendXClaimRequest(conn net.Conn, key string, group string, consumer string, minIdle time.Duration, count int64, ids []string) ([]StreamEntry, error) {
+	args, err := tlv.NewStringArray(group, consumer)
+	if err != nil {
+		return nil, err
+	}
+	n := tlv.Int64(count)
+	err = args.Append(&n)
+	if err != nil {
+		return nil, err
+	}
+	rawids, err := tlv.NewStringArray(ids...)
+	if err != nil {
+		return nil, err
+	}
+	err = args.Append(rawids)
+	if err != nil {
+		return nil, err
+	}
+
+	rawargs, err := args.ToTLV()
+	if err != nil {
+		return nil, err
+	}
+
+	body := payload.RedisRequestBody{
+		Key:   key,
+		Value: rawargs,
+		TTL:   minIdle,
+	}
+
+	resp, err := sendRequest(conn, payload.XClaimCmd, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeStreamEntries(resp.Body)
+}
@@ -0,0 +1,142 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
+	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/test"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSendXGroupCreateRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	args, _ := tlv.NewStringArray("test_group", "$")
+	rawargs, _ := args.ToTLV()
+	reqbod := payload.RedisRequestBody{
+		Key:   "test_stream",
+		Value: rawargs,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.XGroupCreateCmd, rawreqbod)
+
+	resbod := tlv.String("OK")
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.StringType, rawresbod)
+
+	res, err := SendXGroupCreateRequest(conn, "test_stream", "test_group", "$")
+
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", res)
+}
+
+func TestSendXReadGroupRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	reqbod := payload.RedisRequestBody{
+		Value: getXReadGroupArgs("test_group", "alice", 1, 1, []string{"test_stream"}, []string{">"}),
+		TTL:   time.Second,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.XReadGroupCmd, rawreqbod)
+
+	stream := newTestStream(model.StreamID{Ms: 3})
+	entries, _ := streamEntriesToTLV(stream.Stream.After(model.StreamID{}, 0))
+	key := tlv.String("test_stream")
+	item := tlv.Array{}
+	item.Append(&key)
+	item.Append(entries)
+	resbod := tlv.Array{}
+	resbod.Append(&item)
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.ArrayType, rawresbod)
+
+	reads, err := SendXReadGroupRequest(conn, "test_group", "alice", []string{"test_stream"}, []string{">"}, 1, true, time.Second)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(reads))
+	assert.Equal(t, "test_stream", reads[0].Key)
+	assert.Equal(t, "3-0", reads[0].Entries[0].ID)
+}
+
+func TestSendXAckRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	args, _ := tlv.NewStringArray("test_group", "1-0", "2-0")
+	rawargs, _ := args.ToTLV()
+	reqbod := payload.RedisRequestBody{
+		Key:   "test_stream",
+		Value: rawargs,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.XAckCmd, rawreqbod)
+
+	resbod := tlv.Int64(2)
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.Int64Type, rawresbod)
+
+	n, err := SendXAckRequest(conn, "test_stream", "test_group", []string{"1-0", "2-0"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(2), n)
+}
+
+func TestSendXPendingRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	args, _ := tlv.NewStringArray("test_group", "")
+	rawargs, _ := args.ToTLV()
+	reqbod := payload.RedisRequestBody{
+		Key:   "test_stream",
+		Value: rawargs,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.XPendingCmd, rawreqbod)
+
+	item, _ := tlv.NewStringArray("1-0", "alice")
+	idle, count := tlv.Int64(1500), tlv.Int64(2)
+	item.Append(&idle)
+	item.Append(&count)
+	resbod := tlv.Array{}
+	resbod.Append(item)
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.ArrayType, rawresbod)
+
+	pending, err := SendXPendingRequest(conn, "test_stream", "test_group", "")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []StreamPending{{ID: "1-0", Consumer: "alice", Idle: 1500 * time.Millisecond, Count: 2}}, pending)
+}
+
+func TestSendXClaimRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	reqbod := payload.RedisRequestBody{
+		Key:   "test_stream",
+		Value: getXClaimArgs("test_group", "bob", 0, []string{"1-0"}),
+		TTL:   time.Minute,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.XClaimCmd, rawreqbod)
+
+	stream := newTestStream(model.StreamID{Ms: 1})
+	resbod, _ := streamEntriesToTLV(stream.Stream.After(model.StreamID{}, 0))
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.ArrayType, rawresbod)
+
+	entries, err := SendXClaimRequest(conn, "test_stream", "test_group", "bob", time.Minute, 0, []string{"1-0"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, "1-0", entries[0].ID)
+}
@@ -0,0 +1,370 @@
+package redis
+
+import (
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/helper"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+const (
+	// Id of XADD asking server to generate one
+	streamAutoID = "*"
+	// Range bounds meaning first and last entry
+	streamMinID = "-"
+	streamMaxID = "+"
+	// Id of XREAD meaning entries added after the call
+	streamNewID = "$"
+)
+
+// Add arguments are id, fields as map and max length as tlv.Int64, ttl is max age of entries.
+// Stream is trimmed after the add, reply is id of added entry.
+func handleXAddRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	args, err := readArray(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if len(*args) != 3 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	rawid := new(tlv.String)
+	err = rawid.FromTLV((*args)[0])
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.SyntaxError), ctx)
+	}
+	fields := new(tlv.Map)
+	err = fields.FromTLV((*args)[1])
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.SyntaxError), ctx)
+	}
+	if len(*fields) == 0 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+	maxlen := new(tlv.Int64)
+	err = maxlen.FromTLV((*args)[2])
+	if err != nil || *maxlen < 0 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.NotIntegerError), ctx)
+	}
+
+	auto := rawid.String() == streamAutoID
+	var id model.StreamID
+	if !auto {
+		id, err = model.ParseStreamID(rawid.String())
+		if err != nil {
+			return helper.ResponseWithError(err, ctx)
+		}
+	}
+
+	entry := make(map[string][]byte, len(*fields))
+	for f, raw := range *fields {
+		entry[f] = raw
+	}
+
+	xadd := &model.XAddCommand{
+		ID:     id,
+		Auto:   auto,
+		Fields: entry,
+		Trim:   model.StreamTrim{MaxLen: int(*maxlen), MaxAge: body.TTL},
+		Now:    time.Now(),
+	}
+	err = ctx.ExecRedis(body.Key, xadd)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	return helper.ResponseWithString(xadd.Added.String(), ctx)
+}
+
+// Range arguments are start and end ids, where - and + are first and last entry, and
+// count as tlv.Int64, zero count reply every entry in range
+func handleXRangeRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	args, err := readArray(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if len(*args) != 3 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	bounds := make([]model.StreamID, 2)
+	for i, def := range []model.StreamID{{}, model.MaxStreamID} {
+		raw := new(tlv.String)
+		err = raw.FromTLV((*args)[i])
+		if err != nil {
+			return helper.ResponseWithError(tlv.NewCodeError(tlv.SyntaxError), ctx)
+		}
+
+		bounds[i] = def
+		if s := raw.String(); s != streamMinID && s != streamMaxID {
+			bounds[i], err = model.ParseStreamID(s)
+			if err != nil {
+				return helper.ResponseWithError(err, ctx)
+			}
+		}
+	}
+	count := new(tlv.Int64)
+	err = count.FromTLV((*args)[2])
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.NotIntegerError), ctx)
+	}
+
+	var reply *tlv.Array
+	err = ctx.ViewRedis(body.Key, func(v *model.Value) error {
+		if v == nil {
+			reply = &tlv.Array{}
+			return nil
+		}
+		if v.Type != model.StreamValueType {
+			return model.ErrWrongType
+		}
+
+		reply, err = streamEntriesToTLV(v.Stream.Range(bounds[0], bounds[1], int(*count)))
+		return err
+	})
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	return helper.ResponseWithTLV(reply, ctx)
+}
+
+// Read arguments are count and block flag as tlv.Int64 followed by keys and ids as string
+// arrays, each key is read after id at same position. Id $ read only entries added once
+// request arrived. Blocking read wait up to ttl for entries, zero ttl wait forever.
+// Reply is array of key and its entries for each key that has some, or empty reply when
+// there is none.
+func handleXReadRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	args, err := readArray(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if len(*args) != 4 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	count, block := new(tlv.Int64), new(tlv.Int64)
+	err = count.FromTLV((*args)[0])
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.NotIntegerError), ctx)
+	}
+	err = block.FromTLV((*args)[1])
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.NotIntegerError), ctx)
+	}
+	keys, err := readArgs((*args)[2])
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+	rawids, err := readArgs((*args)[3])
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if len(keys) == 0 || len(keys) != len(rawids) {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	ids, err := streamReadIDs(ctx, keys, rawids)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	var timeout <-chan time.Time
+	if body.TTL > 0 {
+		timer := time.NewTimer(body.TTL)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	for {
+		// Wait is registered before reading so add in between wake us up
+		wake, cancel := ctx.WaitRedis(keys...)
+
+		reply, err := readStreams(ctx, keys, ids, int(*count))
+		if err != nil {
+			cancel()
+			return helper.ResponseWithError(err, ctx)
+		}
+
+		if len(*reply) > 0 {
+			cancel()
+			return helper.ResponseWithTLV(reply, ctx)
+		}
+
+		if *block == 0 {
+			cancel()
+			return helper.ResponseWithRaw(nil, ctx)
+		}
+
+		// Replies of requests pipelined before this one must not wait for it
+		err = ctx.Flush()
+		if err != nil {
+			cancel()
+			return err
+		}
+
+		select {
+		case <-wake:
+			cancel()
+		case <-timeout:
+			cancel()
+			return helper.ResponseWithRaw(nil, ctx)
+		case <-ctx.Done():
+			cancel()
+			return helper.ResponseWithError(tlv.NewCodeError(tlv.ShuttingDownError), ctx)
+		}
+	}
+}
+
+func handleXLenRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	var n tlv.Int64
+	err := ctx.ViewRedis(body.Key, func(v *model.Value) error {
+		if v == nil {
+			return nil
+		}
+		if v.Type != model.StreamValueType {
+			return model.ErrWrongType
+		}
+
+		n = tlv.Int64(v.Stream.Len())
+		return nil
+	})
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	return helper.ResponseWithTLV(&n, ctx)
+}
+
+// Trim argument is max length as tlv.Int64 and ttl is max age of entries, reply is number
+// of entries removed. Stream trimmed empty is kept so its ids keep increasing.
+func handleXTrimRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	maxlen := new(tlv.Int64)
+	err := maxlen.FromTLV(body.Value)
+	if err != nil || *maxlen < 0 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.NotIntegerError), ctx)
+	}
+
+	xtrim := &model.XTrimCommand{Trim: model.StreamTrim{MaxLen: int(*maxlen), MaxAge: body.TTL}, Now: time.Now()}
+	err = ctx.ExecRedis(body.Key, xtrim)
+	if err != nil && err != model.ErrNoChange {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	n := tlv.Int64(xtrim.Removed)
+	return helper.ResponseWithTLV(&n, ctx)
+}
+
+// streamReadIDs parse ids of read, $ is resolved to last id of stream at the time
+func streamReadIDs(ctx payload.IRequestContext, keys []string, rawids []string) ([]model.StreamID, error) {
+	ids := make([]model.StreamID, len(rawids))
+	for i, s := range rawids {
+		if s != streamNewID {
+			id, err := model.ParseStreamID(s)
+			if err != nil {
+				return nil, err
+			}
+			ids[i] = id
+			continue
+		}
+
+		err := ctx.ViewRedis(keys[i], func(v *model.Value) error {
+			if v == nil {
+				return nil
+			}
+			if v.Type != model.StreamValueType {
+				return model.ErrWrongType
+			}
+
+			ids[i] = v.Stream.LastID()
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return ids, nil
+}
+
+// readStreams read entries after id of each key, keys without such entries are left out
+func readStreams(ctx payload.IRequestContext, keys []string, ids []model.StreamID, count int) (*tlv.Array, error) {
+	reply := &tlv.Array{}
+	for i, k := range keys {
+		var entries *tlv.Array
+		err := ctx.ViewRedis(k, func(v *model.Value) error {
+			if v == nil {
+				return nil
+			}
+			if v.Type != model.StreamValueType {
+				return model.ErrWrongType
+			}
+
+			read := v.Stream.After(ids[i], count)
+			if len(read) == 0 {
+				return nil
+			}
+
+			var err error
+			entries, err = streamEntriesToTLV(read)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		if entries == nil {
+			continue
+		}
+
+		key := tlv.String(k)
+		item := tlv.Array{}
+		err = item.Append(&key)
+		if err != nil {
+			return nil, err
+		}
+		err = item.Append(entries)
+		if err != nil {
+			return nil, err
+		}
+		err = reply.Append(&item)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return reply, nil
+}
+
+// streamEntriesToTLV write each entry as array of its id and map of its fields
+func streamEntriesToTLV(entries []model.StreamEntry) (*tlv.Array, error) {
+	res := &tlv.Array{}
+	for _, e := range entries {
+		id := tlv.String(e.ID.String())
+		fields := tlv.Map{}
+		for f, raw := range e.Fields {
+			fields[f] = raw
+		}
+
+		item := tlv.Array{}
+		err := item.Append(&id)
+		if err != nil {
+			return nil, err
+		}
+		err = item.Append(&fields)
+		if err != nil {
+			return nil, err
+		}
+		err = res.Append(&item)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
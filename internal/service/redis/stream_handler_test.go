@@ -0,0 +1,394 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
+	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
+	mockpayload "bitbucket.org/non-pn/mini-redis-go/internal/mock/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/test"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func getXAddArgs(id string, field string, val string, maxlen int64) tlv.TypeLengthValue {
+	args, _ := tlv.NewStringArray(id)
+	v := tlv.String(val)
+	fields := tlv.Map{}
+	fields.Put(field, &v)
+	args.Append(&fields)
+	n := tlv.Int64(maxlen)
+	args.Append(&n)
+	raw, _ := args.ToTLV()
+	return raw
+}
+
+func getXReadArgs(count int64, block int64, keys []string, ids []string) tlv.TypeLengthValue {
+	args := tlv.Array{}
+	n, b := tlv.Int64(count), tlv.Int64(block)
+	rawkeys, _ := tlv.NewStringArray(keys...)
+	rawids, _ := tlv.NewStringArray(ids...)
+	args.Append(&n)
+	args.Append(&b)
+	args.Append(rawkeys)
+	args.Append(rawids)
+	raw, _ := args.ToTLV()
+	return raw
+}
+
+// newTestStream return stream value with one entry of field f for each id
+func newTestStream(ids ...model.StreamID) *model.Value {
+	v := model.NewStreamValue()
+	for _, id := range ids {
+		s := tlv.String(id.String())
+		raw, _ := s.ToTLV()
+		v.Stream.Add(id, false, map[string][]byte{"f": raw}, time.Time{})
+	}
+	return v
+}
+
+func TestHandleXAddRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("test_stream", getXAddArgs("5-1", "f", "v", 2))
+	stream := newTestStream(model.StreamID{Ms: 1}, model.StreamID{Ms: 5})
+	id := tlv.String("5-1")
+	rawid, _ := id.ToTLV()
+
+	expectExecRedis(ctx, "test_stream", stream)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.StringType, rawid)
+
+	err := handleXAddRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+	// Oldest entry is trimmed to keep max length
+	entries := stream.Stream.Range(model.StreamID{}, model.MaxStreamID, 0)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, model.StreamID{Ms: 5, Seq: 1}, entries[1].ID)
+	v := tlv.String("v")
+	rawv, _ := v.ToTLV()
+	assert.Equal(t, []byte(rawv), entries[1].Fields["f"])
+}
+
+func TestHandleXAddRequestAutoID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("test_stream", getXAddArgs("*", "f", "v", 0))
+	// Id in the future make generated id follow it
+	future := model.StreamID{Ms: uint64(time.Now().Add(time.Hour).UnixMilli())}
+	stream := newTestStream(future)
+	id := tlv.String(future.Next().String())
+	rawid, _ := id.ToTLV()
+
+	expectExecRedis(ctx, "test_stream", stream)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.StringType, rawid)
+
+	err := handleXAddRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, stream.Stream.Len())
+}
+
+func TestHandleXAddRequestIDTooSmall(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("test_stream", getXAddArgs("5", "f", "v", 0))
+	stream := newTestStream(model.StreamID{Ms: 5})
+
+	expectExecRedis(ctx, "test_stream", stream)
+	ctx.EXPECT().Error(uint16(tlv.StreamIDTooSmallError), tlv.ErrMsg[tlv.StreamIDTooSmallError]).Times(1)
+
+	err := handleXAddRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, stream.Stream.Len())
+}
+
+func TestHandleXAddRequestInvalidID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("test_stream", getXAddArgs("abc", "f", "v", 0))
+
+	ctx.EXPECT().Error(uint16(tlv.InvalidStreamIDError), tlv.ErrMsg[tlv.InvalidStreamIDError]).Times(1)
+
+	err := handleXAddRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleXAddRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("test_stream", getXAddArgs("*", "f", "v", -1))
+	expectErrorReply(ctx, tlv.NotIntegerError)
+
+	err := handleXAddRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+
+	// Fields not sent as map
+	args, _ := tlv.NewStringArray("*", "f", "v")
+	rawargs, _ := args.ToTLV()
+	bod = getRedisRequestBody("test_stream", rawargs)
+	expectErrorReply(ctx, tlv.SyntaxError)
+
+	err = handleXAddRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+
+	args, _ = tlv.NewStringArray("*")
+	rawargs, _ = args.ToTLV()
+	bod = getRedisRequestBody("test_stream", rawargs)
+	expectErrorReply(ctx, tlv.WrongArgumentsError)
+
+	err = handleXAddRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleXRangeRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	args, _ := tlv.NewStringArray("2", "+")
+	count := tlv.Int64(1)
+	args.Append(&count)
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("test_stream", rawargs)
+
+	stream := newTestStream(model.StreamID{Ms: 1}, model.StreamID{Ms: 2}, model.StreamID{Ms: 3})
+	reply, _ := streamEntriesToTLV(stream.Stream.Range(model.StreamID{Ms: 2}, model.StreamID{Ms: 2}, 0))
+	rawreply, _ := reply.ToTLV()
+
+	expectViewRedis(ctx, "test_stream", stream)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.ArrayType, rawreply)
+
+	err := handleXRangeRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleXRangeRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	args, _ := tlv.NewStringArray("-", "+", "10")
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("test_stream", rawargs)
+	expectErrorReply(ctx, tlv.NotIntegerError)
+
+	err := handleXRangeRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+
+	args, _ = tlv.NewStringArray("-", "+")
+	rawargs, _ = args.ToTLV()
+	bod = getRedisRequestBody("test_stream", rawargs)
+	expectErrorReply(ctx, tlv.WrongArgumentsError)
+
+	err = handleXRangeRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleXReadRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("", getXReadArgs(0, 0, []string{"stream_a", "stream_b"}, []string{"1", "1"}))
+	a := newTestStream(model.StreamID{Ms: 1}, model.StreamID{Ms: 2})
+	b := newTestStream(model.StreamID{Ms: 1})
+
+	// Only entries after given id are read, key without such entries is left out
+	entries, _ := streamEntriesToTLV(a.Stream.After(model.StreamID{Ms: 1}, 0))
+	key := tlv.String("stream_a")
+	item := tlv.Array{}
+	item.Append(&key)
+	item.Append(entries)
+	reply := tlv.Array{}
+	reply.Append(&item)
+	rawreply, _ := reply.ToTLV()
+
+	ctx.EXPECT().WaitRedis("stream_a", "stream_b").Times(1).Return(make(chan struct{}), func() {})
+	expectViewRedis(ctx, "stream_a", a)
+	expectViewRedis(ctx, "stream_b", b)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.ArrayType, rawreply)
+
+	err := handleXReadRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleXReadRequestEmpty(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("", getXReadArgs(0, 0, []string{"test_stream"}, []string{"$"}))
+	stream := newTestStream(model.StreamID{Ms: 1})
+
+	// $ is resolved once, then read find nothing after it
+	ctx.EXPECT().ViewRedis("test_stream", gomock.Any()).Times(2).DoAndReturn(
+		func(k string, fn func(v *model.Value) error) error {
+			return fn(stream)
+		})
+	ctx.EXPECT().WaitRedis("test_stream").Times(1).Return(make(chan struct{}), func() {})
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.EmptyType, nil)
+
+	err := handleXReadRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleXReadRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	// Each key needs an id
+	bod := getRedisRequestBody("", getXReadArgs(0, 0, []string{"a", "b"}, []string{"0-0"}))
+	expectErrorReply(ctx, tlv.WrongArgumentsError)
+
+	err := handleXReadRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+
+	bod = getRedisRequestBody("", getXReadArgs(0, 0, []string{"a"}, []string{"first"}))
+	expectErrorReply(ctx, tlv.InvalidStreamIDError)
+
+	err = handleXReadRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleXReadRequestBlockTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("", getXReadArgs(0, 1, []string{"test_stream"}, []string{"0"}))
+	bod.TTL = 10 * time.Millisecond
+
+	ctx.EXPECT().WaitRedis("test_stream").Times(1).Return(make(chan struct{}), func() {})
+	expectViewRedis(ctx, "test_stream", nil)
+	ctx.EXPECT().Done().AnyTimes().Return(nil)
+	ctx.EXPECT().Flush().Times(1).Return(nil)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.EmptyType, nil)
+
+	err := handleXReadRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleXReadRequestWakeUp(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("", getXReadArgs(0, 1, []string{"test_stream"}, []string{"0"}))
+	stream := newTestStream(model.StreamID{Ms: 1})
+
+	entries, _ := streamEntriesToTLV(stream.Stream.After(model.StreamID{}, 0))
+	key := tlv.String("test_stream")
+	item := tlv.Array{}
+	item.Append(&key)
+	item.Append(entries)
+	reply := tlv.Array{}
+	reply.Append(&item)
+	rawreply, _ := reply.ToTLV()
+
+	// First read find no stream, second read after wake up find added entry
+	woken := make(chan struct{})
+	close(woken)
+	gomock.InOrder(
+		ctx.EXPECT().WaitRedis("test_stream").Times(1).Return(woken, func() {}),
+		ctx.EXPECT().ViewRedis("test_stream", gomock.Any()).Times(1).DoAndReturn(
+			func(k string, fn func(v *model.Value) error) error {
+				return fn(nil)
+			}),
+		ctx.EXPECT().WaitRedis("test_stream").Times(1).Return(make(chan struct{}), func() {}),
+		ctx.EXPECT().ViewRedis("test_stream", gomock.Any()).Times(1).DoAndReturn(
+			func(k string, fn func(v *model.Value) error) error {
+				return fn(stream)
+			}),
+	)
+	ctx.EXPECT().Done().AnyTimes().Return(nil)
+	ctx.EXPECT().Flush().Times(1).Return(nil)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.ArrayType, rawreply)
+
+	err := handleXReadRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleXLenRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	bod := getRedisRequestBody("test_stream", []byte{})
+	n := tlv.Int64(2)
+	rawn, _ := n.ToTLV()
+
+	expectViewRedis(ctx, "test_stream", newTestStream(model.StreamID{Ms: 1}, model.StreamID{Ms: 2}))
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawn)
+
+	err := handleXLenRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleXTrimRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	maxlen := tlv.Int64(1)
+	rawmaxlen, _ := maxlen.ToTLV()
+	bod := getRedisRequestBody("test_stream", rawmaxlen)
+	stream := newTestStream(model.StreamID{Ms: 1}, model.StreamID{Ms: 2}, model.StreamID{Ms: 3})
+	n := tlv.Int64(2)
+	rawn, _ := n.ToTLV()
+
+	expectExecRedis(ctx, "test_stream", stream)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawn)
+
+	err := handleXTrimRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, stream.Stream.Len())
+	assert.Equal(t, model.StreamID{Ms: 3}, stream.Stream.LastID())
+}
+
+func TestHandleXTrimRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	n := tlv.Int64(-1)
+	rawn, _ := n.ToTLV()
+	bod := getRedisRequestBody("test_stream", rawn)
+	expectErrorReply(ctx, tlv.NotIntegerError)
+
+	err := handleXTrimRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
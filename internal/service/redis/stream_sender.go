@@ -0,0 +1,243 @@
+package redis
+
+import (
+	"errors"
+	"net"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+// StreamEntry is entry of stream as replied to client
+type StreamEntry struct {
+	ID     string
+	Fields map[string]tlv.TLVCompatible
+}
+
+// StreamRead is entries read from one stream
+type StreamRead struct {
+	Key     string
+	Entries []StreamEntry
+}
+
+// SendXAddRequest add entry with id, or with generated one when id is *, then trim stream
+// to trim limits. It return id of added entry.
+func SendXAddRequest(conn net.Conn, key string, id string, fields tlv.Map, trim model.StreamTrim) (string, error) {
+	args, err := tlv.NewStringArray(id)
+	if err != nil {
+		return "", err
+	}
+	err = args.Append(&fields)
+	if err != nil {
+		return "", err
+	}
+	maxlen := tlv.Int64(trim.MaxLen)
+	err = args.Append(&maxlen)
+	if err != nil {
+		return "", err
+	}
+
+	rawargs, err := args.ToTLV()
+	if err != nil {
+		return "", err
+	}
+
+	body := payload.RedisRequestBody{
+		Key:   key,
+		Value: rawargs,
+		TTL:   trim.MaxAge,
+	}
+
+	return sendStringRequest(conn, payload.XAddCmd, body)
+}
+
+// SendXRangeRequest return entries with id between start and end inclusive, - and + are
+// first and last entry. Zero count return every entry in range.
+func SendXRangeRequest(conn net.Conn, key string, start string, end string, count int64) ([]StreamEntry, error) {
+	args, err := tlv.NewStringArray(start, end)
+	if err != nil {
+		return nil, err
+	}
+	n := tlv.Int64(count)
+	err = args.Append(&n)
+	if err != nil {
+		return nil, err
+	}
+
+	rawargs, err := args.ToTLV()
+	if err != nil {
+		return nil, err
+	}
+
+	body := payload.RedisRequestBody{
+		Key:   key,
+		Value: rawargs,
+	}
+
+	resp, err := sendRequest(conn, payload.XRangeCmd, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeStreamEntries(resp.Body)
+}
+
+// SendXReadRequest read entries after id of each key, $ read entries added after the call.
+// Blocking read wait up to timeout for entries, zero timeout block forever. It return nil
+// when there is no entry to read.
+func SendXReadRequest(conn net.Conn, keys []string, ids []string, count int64, block bool, timeout time.Duration) ([]StreamRead, error) {
+	args := tlv.Array{}
+	n, b := tlv.Int64(count), tlv.Int64(0)
+	if block {
+		b = 1
+	}
+	rawkeys, err := tlv.NewStringArray(keys...)
+	if err != nil {
+		return nil, err
+	}
+	rawids, err := tlv.NewStringArray(ids...)
+	if err != nil {
+		return nil, err
+	}
+	for _, arg := range []tlv.TLVCompatible{&n, &b, rawkeys, rawids} {
+		err = args.Append(arg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rawargs, err := args.ToTLV()
+	if err != nil {
+		return nil, err
+	}
+
+	body := payload.RedisRequestBody{
+		Value: rawargs,
+		TTL:   timeout,
+	}
+
+	resp, err := sendRequest(conn, payload.XReadCmd, body)
+	if err != nil {
+		return nil, err
+	}
+
+	return decodeStreamReads(resp)
+}
+
+// decodeStreamReads decode reply of read, empty reply give nil
+func decodeStreamReads(resp *payload.ResponsePayload) ([]StreamRead, error) {
+	if resp.Typ == tlv.EmptyType {
+		return nil, nil
+	}
+
+	reply := new(tlv.Array)
+	err := reply.FromTLV(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]StreamRead, 0, len(*reply))
+	for _, raw := range *reply {
+		item := new(tlv.Array)
+		err = item.FromTLV(raw)
+		if err != nil {
+			return nil, err
+		}
+		if len(*item) != 2 {
+			return nil, errors.New("Invalid xread reply")
+		}
+
+		key := new(tlv.String)
+		err = key.FromTLV((*item)[0])
+		if err != nil {
+			return nil, err
+		}
+		entries, err := decodeStreamEntries((*item)[1])
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, StreamRead{Key: key.String(), Entries: entries})
+	}
+
+	return res, nil
+}
+
+func SendXLenRequest(conn net.Conn, key string) (int64, error) {
+	return sendInt64Request(conn, payload.XLenCmd, key, []byte{})
+}
+
+// SendXTrimRequest remove entries beyond trim limits and return how many were removed
+func SendXTrimRequest(conn net.Conn, key string, trim model.StreamTrim) (int64, error) {
+	maxlen := tlv.Int64(trim.MaxLen)
+	rawmaxlen, err := maxlen.ToTLV()
+	if err != nil {
+		return 0, err
+	}
+
+	body := payload.RedisRequestBody{
+		Key:   key,
+		Value: rawmaxlen,
+		TTL:   trim.MaxAge,
+	}
+
+	resp, err := sendRequest(conn, payload.XTrimCmd, body)
+	if err != nil {
+		return 0, err
+	}
+
+	res := new(tlv.Int64)
+	err = res.FromTLV(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(*res), nil
+}
+
+// decodeStreamEntries decode array of entries, each being array of id and map of fields
+func decodeStreamEntries(raw tlv.TypeLengthValue) ([]StreamEntry, error) {
+	reply := new(tlv.Array)
+	err := reply.FromTLV(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]StreamEntry, 0, len(*reply))
+	for _, rawentry := range *reply {
+		item := new(tlv.Array)
+		err = item.FromTLV(rawentry)
+		if err != nil {
+			return nil, err
+		}
+		if len(*item) != 2 {
+			return nil, errors.New("Invalid stream entry")
+		}
+
+		id := new(tlv.String)
+		err = id.FromTLV((*item)[0])
+		if err != nil {
+			return nil, err
+		}
+		rawfields := new(tlv.Map)
+		err = rawfields.FromTLV((*item)[1])
+		if err != nil {
+			return nil, err
+		}
+
+		fields := make(map[string]tlv.TLVCompatible, len(*rawfields))
+		for f, raw := range *rawfields {
+			val, err := decodeValue(raw.GetType(), raw)
+			if err != nil {
+				return nil, err
+			}
+			fields[f] = val
+		}
+
+		entries = append(entries, StreamEntry{ID: id.String(), Fields: fields})
+	}
+
+	return entries, nil
+}
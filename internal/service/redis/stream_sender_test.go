@@ -0,0 +1,138 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
+	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/test"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSendXAddRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	reqbod := payload.RedisRequestBody{
+		Key:   "test_stream",
+		Value: getXAddArgs("*", "f", "v", 10),
+		TTL:   time.Minute,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.XAddCmd, rawreqbod)
+
+	resbod := tlv.String("1-0")
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.StringType, rawresbod)
+
+	v := tlv.String("v")
+	fields := tlv.Map{}
+	fields.Put("f", &v)
+	id, err := SendXAddRequest(conn, "test_stream", "*", fields, model.StreamTrim{MaxLen: 10, MaxAge: time.Minute})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "1-0", id)
+}
+
+func TestSendXRangeRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	args, _ := tlv.NewStringArray("-", "+")
+	count := tlv.Int64(0)
+	args.Append(&count)
+	rawargs, _ := args.ToTLV()
+	reqbod := payload.RedisRequestBody{
+		Key:   "test_stream",
+		Value: rawargs,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.XRangeCmd, rawreqbod)
+
+	stream := newTestStream(model.StreamID{Ms: 1}, model.StreamID{Ms: 2, Seq: 3})
+	resbod, _ := streamEntriesToTLV(stream.Stream.Range(model.StreamID{}, model.MaxStreamID, 0))
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.ArrayType, rawresbod)
+
+	entries, err := SendXRangeRequest(conn, "test_stream", "-", "+", 0)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(entries))
+	assert.Equal(t, "2-3", entries[1].ID)
+	assert.Equal(t, "2-3", entries[1].Fields["f"].String())
+}
+
+func TestSendXReadRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	reqbod := payload.RedisRequestBody{
+		Value: getXReadArgs(5, 1, []string{"test_stream"}, []string{"$"}),
+		TTL:   time.Second,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.XReadCmd, rawreqbod)
+
+	stream := newTestStream(model.StreamID{Ms: 7})
+	entries, _ := streamEntriesToTLV(stream.Stream.After(model.StreamID{}, 0))
+	key := tlv.String("test_stream")
+	item := tlv.Array{}
+	item.Append(&key)
+	item.Append(entries)
+	resbod := tlv.Array{}
+	resbod.Append(&item)
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.ArrayType, rawresbod)
+
+	res, err := SendXReadRequest(conn, []string{"test_stream"}, []string{"$"}, 5, true, time.Second)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(res))
+	assert.Equal(t, "test_stream", res[0].Key)
+	assert.Equal(t, "7-0", res[0].Entries[0].ID)
+}
+
+func TestSendXReadRequestEmpty(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	reqbod := payload.RedisRequestBody{
+		Value: getXReadArgs(0, 0, []string{"test_stream"}, []string{"0"}),
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.XReadCmd, rawreqbod)
+	test.ExpectReadResponseFromConn(t, conn, tlv.EmptyType, nil)
+
+	res, err := SendXReadRequest(conn, []string{"test_stream"}, []string{"0"}, 0, false, 0)
+
+	assert.Nil(t, err)
+	assert.Nil(t, res)
+}
+
+func TestSendXTrimRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	maxlen := tlv.Int64(0)
+	rawmaxlen, _ := maxlen.ToTLV()
+	reqbod := payload.RedisRequestBody{
+		Key:   "test_stream",
+		Value: rawmaxlen,
+		TTL:   time.Hour,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.XTrimCmd, rawreqbod)
+
+	resbod := tlv.Int64(3)
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.Int64Type, rawresbod)
+
+	n, err := SendXTrimRequest(conn, "test_stream", model.StreamTrim{MaxAge: time.Hour})
+
+	assert.Nil(t, err)
+	assert.Equal(t, int64(3), n)
+}
@@ -0,0 +1,247 @@
+package redis
+
+import (
+	"math"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/helper"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+// Members are sent as map of member to tlv.Float64 score, reply is number of members added.
+// Scores must be finite so sorted set can be persisted.
+func handleZAddRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	members := new(tlv.Map)
+	err := members.FromTLV(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.SyntaxError), ctx)
+	}
+
+	if len(*members) == 0 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	scores := make(map[string]float64, len(*members))
+	for m, raw := range *members {
+		score := new(tlv.Float64)
+		err = score.FromTLV(raw)
+		if err != nil || !isFinite(float64(*score)) {
+			return helper.ResponseWithError(tlv.NewCodeError(tlv.NotFloatError), ctx)
+		}
+		scores[m] = float64(*score)
+	}
+
+	zadd := &model.ZAddCommand{Scores: scores}
+	err = ctx.ExecRedis(body.Key, zadd)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	n := tlv.Int64(zadd.Added)
+	return helper.ResponseWithTLV(&n, ctx)
+}
+
+// Members are sent as string array, sorted set left empty is deleted
+func handleZRemRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	members, err := readArgs(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	zrem := &model.ZRemCommand{Members: members}
+	err = ctx.ExecRedis(body.Key, zrem)
+	if err != nil && err != model.ErrNoChange {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	n := tlv.Int64(zrem.Removed)
+	return helper.ResponseWithTLV(&n, ctx)
+}
+
+// Member is sent as string, missing member is replied as empty response
+func handleZScoreRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	member := new(tlv.String)
+	err := member.FromTLV(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.SyntaxError), ctx)
+	}
+
+	var score *tlv.Float64
+	err = ctx.ViewRedis(body.Key, func(v *model.Value) error {
+		if v == nil {
+			return nil
+		}
+		if v.Type != model.ZSetValueType {
+			return model.ErrWrongType
+		}
+
+		if s, ok := v.ZSet.Score(member.String()); ok {
+			f := tlv.Float64(s)
+			score = &f
+		}
+		return nil
+	})
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if score == nil {
+		return helper.ResponseWithRaw(nil, ctx)
+	}
+	return helper.ResponseWithTLV(score, ctx)
+}
+
+// Range arguments are start and stop rank as tlv.Int64
+func handleZRangeRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	args, err := readArray(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if len(*args) != 2 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	start, stop := new(tlv.Int64), new(tlv.Int64)
+	err = start.FromTLV((*args)[0])
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.NotIntegerError), ctx)
+	}
+	err = stop.FromTLV((*args)[1])
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.NotIntegerError), ctx)
+	}
+
+	return viewZRange(ctx, body.Key, func(z *model.ZSet) []model.ZMember {
+		return z.Range(int(*start), int(*stop))
+	})
+}
+
+// Range arguments are min and max score as tlv.Float64, both inclusive
+func handleZRangeByScoreRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	args, err := readArray(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if len(*args) != 2 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	min, max := new(tlv.Float64), new(tlv.Float64)
+	err = min.FromTLV((*args)[0])
+	if err != nil || math.IsNaN(float64(*min)) {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.NotFloatError), ctx)
+	}
+	err = max.FromTLV((*args)[1])
+	if err != nil || math.IsNaN(float64(*max)) {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.NotFloatError), ctx)
+	}
+
+	return viewZRange(ctx, body.Key, func(z *model.ZSet) []model.ZMember {
+		return z.RangeByScore(float64(*min), float64(*max))
+	})
+}
+
+// Member is sent as string, missing member is replied as empty response
+func handleZRankRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	member := new(tlv.String)
+	err := member.FromTLV(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.SyntaxError), ctx)
+	}
+
+	var rank *tlv.Int64
+	err = ctx.ViewRedis(body.Key, func(v *model.Value) error {
+		if v == nil {
+			return nil
+		}
+		if v.Type != model.ZSetValueType {
+			return model.ErrWrongType
+		}
+
+		if r, ok := v.ZSet.Rank(member.String()); ok {
+			i := tlv.Int64(r)
+			rank = &i
+		}
+		return nil
+	})
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if rank == nil {
+		return helper.ResponseWithRaw(nil, ctx)
+	}
+	return helper.ResponseWithTLV(rank, ctx)
+}
+
+// Arguments are member as tlv.String and increment as tlv.Float64
+func handleZIncrByRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	args, err := readArray(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if len(*args) != 2 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	member, delta := new(tlv.String), new(tlv.Float64)
+	err = member.FromTLV((*args)[0])
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.SyntaxError), ctx)
+	}
+	err = delta.FromTLV((*args)[1])
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.NotFloatError), ctx)
+	}
+
+	incr := &model.ZIncrByCommand{Member: member.String(), Delta: float64(*delta)}
+	err = ctx.ExecRedis(body.Key, incr)
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	res := tlv.Float64(incr.Score)
+	return helper.ResponseWithTLV(&res, ctx)
+}
+
+// viewZRange reply members selected by fn as flat array of member tlv.String and score tlv.Float64
+func viewZRange(ctx payload.IRequestContext, key string, fn func(z *model.ZSet) []model.ZMember) error {
+	var members []model.ZMember
+	err := ctx.ViewRedis(key, func(v *model.Value) error {
+		if v == nil {
+			return nil
+		}
+		if v.Type != model.ZSetValueType {
+			return model.ErrWrongType
+		}
+
+		members = fn(v.ZSet)
+		return nil
+	})
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	reply := tlv.Array{}
+	for _, m := range members {
+		member, score := tlv.String(m.Member), tlv.Float64(m.Score)
+		err = reply.Append(&member)
+		if err != nil {
+			return err
+		}
+		err = reply.Append(&score)
+		if err != nil {
+			return err
+		}
+	}
+
+	return helper.ResponseWithTLV(&reply, ctx)
+}
+
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
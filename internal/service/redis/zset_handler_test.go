@@ -0,0 +1,283 @@
+package redis
+
+import (
+	"math"
+	"testing"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
+	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
+	mockpayload "bitbucket.org/non-pn/mini-redis-go/internal/mock/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/test"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func newZSetValue(members ...model.ZMember) *model.Value {
+	v := model.NewZSetValue()
+	for _, m := range members {
+		v.ZSet.Add(m.Member, m.Score)
+	}
+	return v
+}
+
+func TestHandleZAddRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	s1, s2 := tlv.Float64(1), tlv.Float64(5)
+	members := tlv.Map{}
+	members.Put("a", &s1)
+	members.Put("b", &s2)
+	rawmembers, _ := members.ToTLV()
+	bod := getRedisRequestBody("test_zset", rawmembers)
+
+	zset := newZSetValue(model.ZMember{Member: "a", Score: 3})
+	n := tlv.Int64(1)
+	rawn, _ := n.ToTLV()
+
+	expectExecRedis(ctx, "test_zset", zset)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawn)
+
+	err := handleZAddRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []model.ZMember{{Member: "a", Score: 1}, {Member: "b", Score: 5}}, zset.ZSet.Range(0, -1))
+}
+
+func TestHandleZAddRequestInvalidScore(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	s := tlv.Float64(math.Inf(1))
+	members := tlv.Map{}
+	members.Put("a", &s)
+	rawmembers, _ := members.ToTLV()
+	bod := getRedisRequestBody("test_zset", rawmembers)
+
+	ctx.EXPECT().Error(uint16(tlv.NotFloatError), tlv.ErrMsg[tlv.NotFloatError]).Times(1)
+
+	err := handleZAddRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleZAddRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	// Members not sent as map
+	members, _ := tlv.NewStringArray("a", "1")
+	rawmembers, _ := members.ToTLV()
+	bod := getRedisRequestBody("test_zset", rawmembers)
+	expectErrorReply(ctx, tlv.SyntaxError)
+
+	err := handleZAddRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+
+	rawempty, _ := (&tlv.Map{}).ToTLV()
+	bod = getRedisRequestBody("test_zset", rawempty)
+	expectErrorReply(ctx, tlv.WrongArgumentsError)
+
+	err = handleZAddRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleZRemRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	member := tlv.String("a")
+	rawmember, _ := member.ToTLV()
+	bod := getRedisRequestBody("test_zset", rawmember)
+	expectErrorReply(ctx, tlv.SyntaxError)
+
+	err := handleZRemRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleZScoreRequestMissing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	member := tlv.String("missing")
+	rawmember, _ := member.ToTLV()
+	bod := getRedisRequestBody("test_zset", rawmember)
+
+	expectViewRedis(ctx, "test_zset", newZSetValue(model.ZMember{Member: "a", Score: 1}))
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.EmptyType, []byte{})
+
+	err := handleZScoreRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleZScoreRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	score := tlv.Float64(1)
+	rawscore, _ := score.ToTLV()
+	bod := getRedisRequestBody("test_zset", rawscore)
+	expectErrorReply(ctx, tlv.SyntaxError)
+
+	err := handleZScoreRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleZRangeRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	args, _ := tlv.NewStringArray("0", "-1")
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("test_zset", rawargs)
+	expectErrorReply(ctx, tlv.NotIntegerError)
+
+	err := handleZRangeRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+
+	rawargs, _ = (&tlv.Array{}).ToTLV()
+	bod = getRedisRequestBody("test_zset", rawargs)
+	expectErrorReply(ctx, tlv.WrongArgumentsError)
+
+	err = handleZRangeRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleZRangeByScoreRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	min, max := tlv.Float64(2), tlv.Float64(math.Inf(1))
+	args := tlv.Array{}
+	args.Append(&min)
+	args.Append(&max)
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("test_zset", rawargs)
+
+	member, score := tlv.String("b"), tlv.Float64(2)
+	reply := tlv.Array{}
+	reply.Append(&member)
+	reply.Append(&score)
+	rawreply, _ := reply.ToTLV()
+
+	zset := newZSetValue(model.ZMember{Member: "a", Score: 1}, model.ZMember{Member: "b", Score: 2})
+	expectViewRedis(ctx, "test_zset", zset)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.ArrayType, rawreply)
+
+	err := handleZRangeByScoreRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleZRangeByScoreRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	args, _ := tlv.NewStringArray("-inf", "+inf")
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("test_zset", rawargs)
+	expectErrorReply(ctx, tlv.NotFloatError)
+
+	err := handleZRangeByScoreRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleZRankRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	member := tlv.String("b")
+	rawmember, _ := member.ToTLV()
+	bod := getRedisRequestBody("test_zset", rawmember)
+
+	rank := tlv.Int64(1)
+	rawrank, _ := rank.ToTLV()
+
+	zset := newZSetValue(model.ZMember{Member: "a", Score: 1}, model.ZMember{Member: "b", Score: 2})
+	expectViewRedis(ctx, "test_zset", zset)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.Int64Type, rawrank)
+
+	err := handleZRankRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleZRankRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	rawmembers, _ := (&tlv.Array{}).ToTLV()
+	bod := getRedisRequestBody("test_zset", rawmembers)
+	expectErrorReply(ctx, tlv.SyntaxError)
+
+	err := handleZRankRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleZIncrByRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	member, delta := tlv.String("a"), tlv.Float64(2.5)
+	args := tlv.Array{}
+	args.Append(&member)
+	args.Append(&delta)
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("test_zset", rawargs)
+
+	res := tlv.Float64(3.5)
+	rawres, _ := res.ToTLV()
+
+	zset := newZSetValue(model.ZMember{Member: "a", Score: 1})
+	expectExecRedis(ctx, "test_zset", zset)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.Float64Type, rawres)
+
+	err := handleZIncrByRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+	score, _ := zset.ZSet.Score("a")
+	assert.Equal(t, 3.5, score)
+}
+
+func TestHandleZIncrByRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+
+	args, _ := tlv.NewStringArray("a", "1.5")
+	rawargs, _ := args.ToTLV()
+	bod := getRedisRequestBody("test_zset", rawargs)
+	expectErrorReply(ctx, tlv.NotFloatError)
+
+	err := handleZIncrByRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+
+	args, _ = tlv.NewStringArray("a")
+	rawargs, _ = args.ToTLV()
+	bod = getRedisRequestBody("test_zset", rawargs)
+	expectErrorReply(ctx, tlv.WrongArgumentsError)
+
+	err = handleZIncrByRequest(ctx, &bod)
+
+	assert.Nil(t, err)
+}
@@ -0,0 +1,189 @@
+package redis
+
+import (
+	"errors"
+	"net"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+func SendZAddRequest(conn net.Conn, key string, members map[string]float64) (int64, error) {
+	args := tlv.Map{}
+	for m, score := range members {
+		s := tlv.Float64(score)
+		err := args.Put(m, &s)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	rawargs, err := args.ToTLV()
+	if err != nil {
+		return 0, err
+	}
+
+	return sendInt64Request(conn, payload.ZAddCmd, key, rawargs)
+}
+
+func SendZRemRequest(conn net.Conn, key string, members []string) (int64, error) {
+	return sendMembersRequest(conn, payload.ZRemCmd, key, members)
+}
+
+// SendZScoreRequest return false when member is not in sorted set
+func SendZScoreRequest(conn net.Conn, key string, member string) (float64, bool, error) {
+	resp, err := sendMemberRequest(conn, payload.ZScoreCmd, key, member)
+	if err != nil || resp.Typ == tlv.EmptyType {
+		return 0, false, err
+	}
+
+	score := new(tlv.Float64)
+	err = score.FromTLV(resp.Body)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return float64(*score), true, nil
+}
+
+func SendZRangeRequest(conn net.Conn, key string, start int64, stop int64) ([]model.ZMember, error) {
+	args := tlv.Array{}
+	begin, end := tlv.Int64(start), tlv.Int64(stop)
+	err := args.Append(&begin)
+	if err != nil {
+		return nil, err
+	}
+	err = args.Append(&end)
+	if err != nil {
+		return nil, err
+	}
+
+	return sendZRangeRequest(conn, payload.ZRangeCmd, key, args)
+}
+
+func SendZRangeByScoreRequest(conn net.Conn, key string, min float64, max float64) ([]model.ZMember, error) {
+	args := tlv.Array{}
+	lo, hi := tlv.Float64(min), tlv.Float64(max)
+	err := args.Append(&lo)
+	if err != nil {
+		return nil, err
+	}
+	err = args.Append(&hi)
+	if err != nil {
+		return nil, err
+	}
+
+	return sendZRangeRequest(conn, payload.ZRangeByScoreCmd, key, args)
+}
+
+// SendZRankRequest return false when member is not in sorted set
+func SendZRankRequest(conn net.Conn, key string, member string) (int64, bool, error) {
+	resp, err := sendMemberRequest(conn, payload.ZRankCmd, key, member)
+	if err != nil || resp.Typ == tlv.EmptyType {
+		return 0, false, err
+	}
+
+	rank := new(tlv.Int64)
+	err = rank.FromTLV(resp.Body)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return int64(*rank), true, nil
+}
+
+func SendZIncrByRequest(conn net.Conn, key string, member string, delta float64) (float64, error) {
+	args := tlv.Array{}
+	m, d := tlv.String(member), tlv.Float64(delta)
+	err := args.Append(&m)
+	if err != nil {
+		return 0, err
+	}
+	err = args.Append(&d)
+	if err != nil {
+		return 0, err
+	}
+
+	rawargs, err := args.ToTLV()
+	if err != nil {
+		return 0, err
+	}
+
+	body := payload.RedisRequestBody{
+		Key:   key,
+		Value: rawargs,
+	}
+
+	resp, err := sendRequest(conn, payload.ZIncrByCmd, body)
+	if err != nil {
+		return 0, err
+	}
+
+	res := new(tlv.Float64)
+	err = res.FromTLV(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	return float64(*res), nil
+}
+
+func sendMemberRequest(conn net.Conn, cmd uint8, key string, member string) (*payload.ResponsePayload, error) {
+	m := tlv.String(member)
+	rawmember, err := m.ToTLV()
+	if err != nil {
+		return nil, err
+	}
+
+	body := payload.RedisRequestBody{
+		Key:   key,
+		Value: rawmember,
+	}
+
+	return sendRequest(conn, cmd, body)
+}
+
+// Reply is flat array of member tlv.String followed by its score tlv.Float64
+func sendZRangeRequest(conn net.Conn, cmd uint8, key string, args tlv.Array) ([]model.ZMember, error) {
+	rawargs, err := args.ToTLV()
+	if err != nil {
+		return nil, err
+	}
+
+	body := payload.RedisRequestBody{
+		Key:   key,
+		Value: rawargs,
+	}
+
+	resp, err := sendRequest(conn, cmd, body)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := new(tlv.Array)
+	err = reply.FromTLV(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(*reply)%2 != 0 {
+		return nil, errors.New("Invalid zrange reply")
+	}
+
+	members := make([]model.ZMember, 0, len(*reply)/2)
+	for i := 0; i < len(*reply); i += 2 {
+		member, score := new(tlv.String), new(tlv.Float64)
+		err = member.FromTLV((*reply)[i])
+		if err != nil {
+			return nil, err
+		}
+		err = score.FromTLV((*reply)[i+1])
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, model.ZMember{Member: member.String(), Score: float64(*score)})
+	}
+
+	return members, nil
+}
@@ -0,0 +1,62 @@
+package redis
+
+import (
+	"testing"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
+	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/test"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSendZRangeRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	start, stop := tlv.Int64(0), tlv.Int64(-1)
+	args := tlv.Array{}
+	args.Append(&start)
+	args.Append(&stop)
+	rawargs, _ := args.ToTLV()
+	reqbod := payload.RedisRequestBody{
+		Key:   "test_zset",
+		Value: rawargs,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.ZRangeCmd, rawreqbod)
+
+	member, score := tlv.String("a"), tlv.Float64(1.5)
+	reply := tlv.Array{}
+	reply.Append(&member)
+	reply.Append(&score)
+	rawreply, _ := reply.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.ArrayType, rawreply)
+
+	members, err := SendZRangeRequest(conn, "test_zset", 0, -1)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []model.ZMember{{Member: "a", Score: 1.5}}, members)
+}
+
+func TestSendZScoreRequestMissing(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	member := tlv.String("a")
+	rawmember, _ := member.ToTLV()
+	reqbod := payload.RedisRequestBody{
+		Key:   "test_zset",
+		Value: rawmember,
+	}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.ZScoreCmd, rawreqbod)
+	test.ExpectReadResponseFromConn(t, conn, tlv.EmptyType, []byte{})
+
+	_, ok, err := SendZScoreRequest(conn, "test_zset", "a")
+
+	assert.Nil(t, err)
+	assert.False(t, ok)
+}
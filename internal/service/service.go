@@ -2,25 +2,98 @@ package service
 
 import (
 	"net"
+	"time"
 
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
 	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
 	"bitbucket.org/non-pn/mini-redis-go/internal/service/pingpong"
 	"bitbucket.org/non-pn/mini-redis-go/internal/service/pubsub"
 	"bitbucket.org/non-pn/mini-redis-go/internal/service/redis"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/transaction"
 	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
 )
 
 type ServiceHandler interface {
 	HandleRequest(ctx payload.IRequestContext) error
-	HandleDisconnected(conn net.Conn) error
+	HandleDisconnected(ctx payload.IRequestContext) error
 }
 
 type ServiceRequester interface {
 	SendPingRequest(conn net.Conn, msg *string) (*tlv.String, error)
 	SendGetRequest(conn net.Conn, key string) (tlv.TLVCompatible, error)
 	SendSetRequest(conn net.Conn, key string, val tlv.TypeLengthValue) (string, error)
-	SendSubRequest(conn net.Conn, topic string) (*pubsub.Subscriber, error)
-	SendPubRequest(conn net.Conn, topic string, val tlv.TypeLengthValue) (string, error)
+	SendSetExRequest(conn net.Conn, key string, val tlv.TypeLengthValue, ttl time.Duration) (string, error)
+	SendExpireRequest(conn net.Conn, key string, ttl time.Duration) (bool, error)
+	SendTTLRequest(conn net.Conn, key string) (time.Duration, error)
+	SendPersistRequest(conn net.Conn, key string) (bool, error)
+	SendDelRequest(conn net.Conn, keys []string) (int, error)
+	SendExistsRequest(conn net.Conn, keys []string) (int, error)
+	SendScanRequest(conn net.Conn, cursor string, match string, count int) (string, []string, error)
+	SendIncrRequest(conn net.Conn, key string) (int64, error)
+	SendDecrRequest(conn net.Conn, key string) (int64, error)
+	SendIncrByRequest(conn net.Conn, key string, delta int64) (int64, error)
+	SendDecrByRequest(conn net.Conn, key string, delta int64) (int64, error)
+	SendIncrByFloatRequest(conn net.Conn, key string, delta float64) (float64, error)
+	SendLPushRequest(conn net.Conn, key string, vals []tlv.TypeLengthValue) (int64, error)
+	SendRPushRequest(conn net.Conn, key string, vals []tlv.TypeLengthValue) (int64, error)
+	SendLPopRequest(conn net.Conn, key string) (tlv.TLVCompatible, error)
+	SendRPopRequest(conn net.Conn, key string) (tlv.TLVCompatible, error)
+	SendLRangeRequest(conn net.Conn, key string, start int64, stop int64) ([]tlv.TLVCompatible, error)
+	SendLLenRequest(conn net.Conn, key string) (int64, error)
+	SendBLPopRequest(conn net.Conn, keys []string, timeout time.Duration) (string, tlv.TLVCompatible, error)
+	SendHSetRequest(conn net.Conn, key string, fields tlv.Map) (int64, error)
+	SendHGetRequest(conn net.Conn, key string, field string) (tlv.TLVCompatible, error)
+	SendHDelRequest(conn net.Conn, key string, fields []string) (int64, error)
+	SendHGetAllRequest(conn net.Conn, key string) (map[string]tlv.TLVCompatible, error)
+	SendHIncrByRequest(conn net.Conn, key string, field string, delta int64) (int64, error)
+	SendHLenRequest(conn net.Conn, key string) (int64, error)
+	SendSAddRequest(conn net.Conn, key string, members []string) (int64, error)
+	SendSRemRequest(conn net.Conn, key string, members []string) (int64, error)
+	SendSIsMemberRequest(conn net.Conn, key string, member string) (bool, error)
+	SendSMembersRequest(conn net.Conn, key string) ([]string, error)
+	SendSInterRequest(conn net.Conn, keys []string) ([]string, error)
+	SendSUnionRequest(conn net.Conn, keys []string) ([]string, error)
+	SendSDiffRequest(conn net.Conn, keys []string) ([]string, error)
+	SendZAddRequest(conn net.Conn, key string, members map[string]float64) (int64, error)
+	SendZRemRequest(conn net.Conn, key string, members []string) (int64, error)
+	SendZScoreRequest(conn net.Conn, key string, member string) (float64, bool, error)
+	SendZRangeRequest(conn net.Conn, key string, start int64, stop int64) ([]model.ZMember, error)
+	SendZRangeByScoreRequest(conn net.Conn, key string, min float64, max float64) ([]model.ZMember, error)
+	SendZRankRequest(conn net.Conn, key string, member string) (int64, bool, error)
+	SendZIncrByRequest(conn net.Conn, key string, member string, delta float64) (float64, error)
+	SendBGRewriteAOFRequest(conn net.Conn) (string, error)
+	SendSaveRequest(conn net.Conn) (string, error)
+	SendBGSaveRequest(conn net.Conn) (string, error)
+	SendClientListRequest(conn net.Conn) (string, error)
+	SendClientKillRequest(conn net.Conn, filter string, value string) (int64, error)
+	SendClientSetNameRequest(conn net.Conn, name string) (string, error)
+	SendClientGetNameRequest(conn net.Conn) (string, error)
+	SendXAddRequest(conn net.Conn, key string, id string, fields tlv.Map, trim model.StreamTrim) (string, error)
+	SendXRangeRequest(conn net.Conn, key string, start string, end string, count int64) ([]redis.StreamEntry, error)
+	SendXReadRequest(conn net.Conn, keys []string, ids []string, count int64, block bool, timeout time.Duration) ([]redis.StreamRead, error)
+	SendXLenRequest(conn net.Conn, key string) (int64, error)
+	SendXTrimRequest(conn net.Conn, key string, trim model.StreamTrim) (int64, error)
+	SendXGroupCreateRequest(conn net.Conn, key string, group string, id string) (string, error)
+	SendXGroupDestroyRequest(conn net.Conn, key string, group string) (bool, error)
+	SendXReadGroupRequest(conn net.Conn, group string, consumer string, keys []string, ids []string, count int64, block bool, timeout time.Duration) ([]redis.StreamRead, error)
+	SendXAckRequest(conn net.Conn, key string, group string, ids []string) (int64, error)
+	SendXPendingRequest(conn net.Conn, key string, group string, consumer string) ([]redis.StreamPending, error)
+	SendXClaimRequest(conn net.Conn, key string, group string, consumer string, minIdle time.Duration, count int64, ids []string) ([]redis.StreamEntry, error)
+	SendSetArgsRequest(conn net.Conn, key string, val tlv.TypeLengthValue, opts redis.SetOptions) (bool, tlv.TLVCompatible, error)
+	SendCASRequest(conn net.Conn, key string, expected tlv.TypeLengthValue, val tlv.TypeLengthValue, ttl time.Duration) (uint64, error)
+	SendCASVersionRequest(conn net.Conn, key string, version uint64, val tlv.TypeLengthValue, ttl time.Duration) (uint64, error)
+	SendGetVersionRequest(conn net.Conn, key string) (uint64, error)
+	SendMGetRequest(conn net.Conn, keys []string) ([]tlv.TLVCompatible, error)
+	SendMSetRequest(conn net.Conn, pairs tlv.Map) (string, error)
+	SendMSetNXRequest(conn net.Conn, pairs tlv.Map) (bool, error)
+	SendMultiRequest(conn net.Conn) (string, error)
+	SendExecRequest(conn net.Conn) ([]tlv.TypeLengthValue, error)
+	SendDiscardRequest(conn net.Conn) (string, error)
+	SendWatchRequest(conn net.Conn, keys []string) (string, error)
+	SendUnwatchRequest(conn net.Conn) (string, error)
+	SendSubRequest(conn net.Conn, topics ...string) (*pubsub.Subscriber, error)
+	SendPSubRequest(conn net.Conn, patterns ...string) (*pubsub.Subscriber, error)
+	SendPubRequest(conn net.Conn, topic string, val tlv.TypeLengthValue) (int, error)
 }
 
 type IService interface {
@@ -33,6 +106,12 @@ type Service struct{}
 func (serv *Service) HandleRequest(ctx payload.IRequestContext) error {
 	pl := ctx.GetPayload()
 
+	// Requests of client in transaction wait for EXEC, except the ones controlling it
+	client := ctx.GetClient()
+	if client != nil && client.InTransaction() && !transaction.IsControl(pl.Cmd) {
+		return transaction.QueueRequest(ctx)
+	}
+
 	switch pl.Cmd {
 	case payload.PingCmd:
 		err := pingpong.HandleRequest(ctx)
@@ -46,7 +125,24 @@ func (serv *Service) HandleRequest(ctx payload.IRequestContext) error {
 			return err
 		}
 		break
-	case payload.SetCmd:
+	case payload.SetCmd, payload.ExpireCmd, payload.TTLCmd, payload.PersistCmd,
+		payload.DelCmd, payload.ExistsCmd, payload.ScanCmd,
+		payload.IncrCmd, payload.DecrCmd, payload.IncrByCmd, payload.DecrByCmd, payload.IncrByFloatCmd,
+		payload.LPushCmd, payload.RPushCmd, payload.LPopCmd, payload.RPopCmd,
+		payload.LRangeCmd, payload.LLenCmd, payload.BLPopCmd,
+		payload.HSetCmd, payload.HGetCmd, payload.HDelCmd, payload.HGetAllCmd,
+		payload.HIncrByCmd, payload.HLenCmd,
+		payload.SAddCmd, payload.SRemCmd, payload.SIsMemberCmd, payload.SMembersCmd,
+		payload.SInterCmd, payload.SUnionCmd, payload.SDiffCmd,
+		payload.ZAddCmd, payload.ZRemCmd, payload.ZScoreCmd, payload.ZRangeCmd,
+		payload.ZRangeByScoreCmd, payload.ZRankCmd, payload.ZIncrByCmd,
+		payload.BGRewriteAOFCmd, payload.SaveCmd, payload.BGSaveCmd,
+		payload.ClientListCmd, payload.ClientKillCmd, payload.ClientSetNameCmd, payload.ClientGetNameCmd,
+		payload.XAddCmd, payload.XRangeCmd, payload.XReadCmd, payload.XLenCmd, payload.XTrimCmd,
+		payload.XGroupCreateCmd, payload.XGroupDestroyCmd, payload.XReadGroupCmd,
+		payload.XAckCmd, payload.XPendingCmd, payload.XClaimCmd,
+		payload.SetArgsCmd, payload.CASCmd, payload.CASVersionCmd, payload.GetVersionCmd,
+		payload.MGetCmd, payload.MSetCmd, payload.MSetNXCmd:
 		err := redis.HandleRequest(ctx)
 		if err != nil {
 			return err
@@ -58,19 +154,37 @@ func (serv *Service) HandleRequest(ctx payload.IRequestContext) error {
 			return err
 		}
 		break
+	case payload.UnsubCmd, payload.PSubCmd, payload.PUnsubCmd:
+		err := pubsub.HandleRequest(ctx)
+		if err != nil {
+			return err
+		}
+		break
 	case payload.PubCmd:
 		err := pubsub.HandleRequest(ctx)
 		if err != nil {
 			return err
 		}
 		break
+	case payload.MultiCmd, payload.ExecCmd, payload.DiscardCmd, payload.WatchCmd, payload.UnwatchCmd:
+		err := transaction.HandleRequest(ctx, serv.HandleRequest)
+		if err != nil {
+			return err
+		}
+		break
 	}
 
 	return nil
 }
 
-func (serv *Service) HandleDisconnected(conn net.Conn) error {
-	return nil
+// HandleDisconnected clean up after client whose connection is gone
+func (serv *Service) HandleDisconnected(ctx payload.IRequestContext) error {
+	err := transaction.HandleDisconnected(ctx)
+	if err != nil {
+		return err
+	}
+
+	return pubsub.HandleDisconnected(ctx)
 }
 
 func (serv *Service) SendPingRequest(conn net.Conn, msg *string) (*tlv.String, error) {
@@ -85,11 +199,291 @@ func (serv *Service) SendSetRequest(conn net.Conn, key string, val tlv.TypeLengt
 	return redis.SendSetRequest(conn, key, val)
 }
 
-func (serv *Service) SendSubRequest(conn net.Conn, topic string) (*pubsub.Subscriber, error) {
-	return pubsub.SendSubRequest(conn, topic)
+func (serv *Service) SendSetExRequest(conn net.Conn, key string, val tlv.TypeLengthValue, ttl time.Duration) (string, error) {
+	return redis.SendSetExRequest(conn, key, val, ttl)
+}
+
+func (serv *Service) SendExpireRequest(conn net.Conn, key string, ttl time.Duration) (bool, error) {
+	return redis.SendExpireRequest(conn, key, ttl)
+}
+
+func (serv *Service) SendTTLRequest(conn net.Conn, key string) (time.Duration, error) {
+	return redis.SendTTLRequest(conn, key)
+}
+
+func (serv *Service) SendPersistRequest(conn net.Conn, key string) (bool, error) {
+	return redis.SendPersistRequest(conn, key)
+}
+
+func (serv *Service) SendDelRequest(conn net.Conn, keys []string) (int, error) {
+	return redis.SendDelRequest(conn, keys)
+}
+
+func (serv *Service) SendExistsRequest(conn net.Conn, keys []string) (int, error) {
+	return redis.SendExistsRequest(conn, keys)
+}
+
+func (serv *Service) SendScanRequest(conn net.Conn, cursor string, match string, count int) (string, []string, error) {
+	return redis.SendScanRequest(conn, cursor, match, count)
+}
+
+func (serv *Service) SendIncrRequest(conn net.Conn, key string) (int64, error) {
+	return redis.SendIncrRequest(conn, key)
+}
+
+func (serv *Service) SendDecrRequest(conn net.Conn, key string) (int64, error) {
+	return redis.SendDecrRequest(conn, key)
+}
+
+func (serv *Service) SendIncrByRequest(conn net.Conn, key string, delta int64) (int64, error) {
+	return redis.SendIncrByRequest(conn, key, delta)
+}
+
+func (serv *Service) SendDecrByRequest(conn net.Conn, key string, delta int64) (int64, error) {
+	return redis.SendDecrByRequest(conn, key, delta)
+}
+
+func (serv *Service) SendIncrByFloatRequest(conn net.Conn, key string, delta float64) (float64, error) {
+	return redis.SendIncrByFloatRequest(conn, key, delta)
+}
+
+func (serv *Service) SendLPushRequest(conn net.Conn, key string, vals []tlv.TypeLengthValue) (int64, error) {
+	return redis.SendLPushRequest(conn, key, vals)
+}
+
+func (serv *Service) SendRPushRequest(conn net.Conn, key string, vals []tlv.TypeLengthValue) (int64, error) {
+	return redis.SendRPushRequest(conn, key, vals)
+}
+
+func (serv *Service) SendLPopRequest(conn net.Conn, key string) (tlv.TLVCompatible, error) {
+	return redis.SendLPopRequest(conn, key)
+}
+
+func (serv *Service) SendRPopRequest(conn net.Conn, key string) (tlv.TLVCompatible, error) {
+	return redis.SendRPopRequest(conn, key)
+}
+
+func (serv *Service) SendLRangeRequest(conn net.Conn, key string, start int64, stop int64) ([]tlv.TLVCompatible, error) {
+	return redis.SendLRangeRequest(conn, key, start, stop)
+}
+
+func (serv *Service) SendLLenRequest(conn net.Conn, key string) (int64, error) {
+	return redis.SendLLenRequest(conn, key)
+}
+
+func (serv *Service) SendBLPopRequest(conn net.Conn, keys []string, timeout time.Duration) (string, tlv.TLVCompatible, error) {
+	return redis.SendBLPopRequest(conn, keys, timeout)
+}
+
+func (serv *Service) SendHSetRequest(conn net.Conn, key string, fields tlv.Map) (int64, error) {
+	return redis.SendHSetRequest(conn, key, fields)
+}
+
+func (serv *Service) SendHGetRequest(conn net.Conn, key string, field string) (tlv.TLVCompatible, error) {
+	return redis.SendHGetRequest(conn, key, field)
+}
+
+func (serv *Service) SendHDelRequest(conn net.Conn, key string, fields []string) (int64, error) {
+	return redis.SendHDelRequest(conn, key, fields)
+}
+
+func (serv *Service) SendHGetAllRequest(conn net.Conn, key string) (map[string]tlv.TLVCompatible, error) {
+	return redis.SendHGetAllRequest(conn, key)
+}
+
+func (serv *Service) SendHIncrByRequest(conn net.Conn, key string, field string, delta int64) (int64, error) {
+	return redis.SendHIncrByRequest(conn, key, field, delta)
+}
+
+func (serv *Service) SendHLenRequest(conn net.Conn, key string) (int64, error) {
+	return redis.SendHLenRequest(conn, key)
+}
+
+func (serv *Service) SendSAddRequest(conn net.Conn, key string, members []string) (int64, error) {
+	return redis.SendSAddRequest(conn, key, members)
+}
+
+func (serv *Service) SendSRemRequest(conn net.Conn, key string, members []string) (int64, error) {
+	return redis.SendSRemRequest(conn, key, members)
+}
+
+func (serv *Service) SendSIsMemberRequest(conn net.Conn, key string, member string) (bool, error) {
+	return redis.SendSIsMemberRequest(conn, key, member)
+}
+
+func (serv *Service) SendSMembersRequest(conn net.Conn, key string) ([]string, error) {
+	return redis.SendSMembersRequest(conn, key)
+}
+
+func (serv *Service) SendSInterRequest(conn net.Conn, keys []string) ([]string, error) {
+	return redis.SendSInterRequest(conn, keys)
+}
+
+func (serv *Service) SendSUnionRequest(conn net.Conn, keys []string) ([]string, error) {
+	return redis.SendSUnionRequest(conn, keys)
+}
+
+func (serv *Service) SendSDiffRequest(conn net.Conn, keys []string) ([]string, error) {
+	return redis.SendSDiffRequest(conn, keys)
+}
+
+func (serv *Service) SendZAddRequest(conn net.Conn, key string, members map[string]float64) (int64, error) {
+	return redis.SendZAddRequest(conn, key, members)
+}
+
+func (serv *Service) SendZRemRequest(conn net.Conn, key string, members []string) (int64, error) {
+	return redis.SendZRemRequest(conn, key, members)
+}
+
+func (serv *Service) SendZScoreRequest(conn net.Conn, key string, member string) (float64, bool, error) {
+	return redis.SendZScoreRequest(conn, key, member)
+}
+
+func (serv *Service) SendZRangeRequest(conn net.Conn, key string, start int64, stop int64) ([]model.ZMember, error) {
+	return redis.SendZRangeRequest(conn, key, start, stop)
+}
+
+func (serv *Service) SendZRangeByScoreRequest(conn net.Conn, key string, min float64, max float64) ([]model.ZMember, error) {
+	return redis.SendZRangeByScoreRequest(conn, key, min, max)
+}
+
+func (serv *Service) SendZRankRequest(conn net.Conn, key string, member string) (int64, bool, error) {
+	return redis.SendZRankRequest(conn, key, member)
+}
+
+func (serv *Service) SendZIncrByRequest(conn net.Conn, key string, member string, delta float64) (float64, error) {
+	return redis.SendZIncrByRequest(conn, key, member, delta)
+}
+
+func (serv *Service) SendBGRewriteAOFRequest(conn net.Conn) (string, error) {
+	return redis.SendBGRewriteAOFRequest(conn)
+}
+
+func (serv *Service) SendSaveRequest(conn net.Conn) (string, error) {
+	return redis.SendSaveRequest(conn)
+}
+
+func (serv *Service) SendBGSaveRequest(conn net.Conn) (string, error) {
+	return redis.SendBGSaveRequest(conn)
+}
+
+func (serv *Service) SendClientListRequest(conn net.Conn) (string, error) {
+	return redis.SendClientListRequest(conn)
+}
+
+func (serv *Service) SendClientKillRequest(conn net.Conn, filter string, value string) (int64, error) {
+	return redis.SendClientKillRequest(conn, filter, value)
+}
+
+func (serv *Service) SendClientSetNameRequest(conn net.Conn, name string) (string, error) {
+	return redis.SendClientSetNameRequest(conn, name)
+}
+
+func (serv *Service) SendClientGetNameRequest(conn net.Conn) (string, error) {
+	return redis.SendClientGetNameRequest(conn)
+}
+
+func (serv *Service) SendXAddRequest(conn net.Conn, key string, id string, fields tlv.Map, trim model.StreamTrim) (string, error) {
+	return redis.SendXAddRequest(conn, key, id, fields, trim)
+}
+
+func (serv *Service) SendXRangeRequest(conn net.Conn, key string, start string, end string, count int64) ([]redis.StreamEntry, error) {
+	return redis.SendXRangeRequest(conn, key, start, end, count)
+}
+
+func (serv *Service) SendXReadRequest(conn net.Conn, keys []string, ids []string, count int64, block bool, timeout time.Duration) ([]redis.StreamRead, error) {
+	return redis.SendXReadRequest(conn, keys, ids, count, block, timeout)
+}
+
+func (serv *Service) SendXLenRequest(conn net.Conn, key string) (int64, error) {
+	return redis.SendXLenRequest(conn, key)
+}
+
+func (serv *Service) SendXTrimRequest(conn net.Conn, key string, trim model.StreamTrim) (int64, error) {
+	return redis.SendXTrimRequest(conn, key, trim)
+}
+
+func (serv *Service) SendXGroupCreateRequest(conn net.Conn, key string, group string, id string) (string, error) {
+	return redis.SendXGroupCreateRequest(conn, key, group, id)
+}
+
+func (serv *Service) SendXGroupDestroyRequest(conn net.Conn, key string, group string) (bool, error) {
+	return redis.SendXGroupDestroyRequest(conn, key, group)
+}
+
+func (serv *Service) SendXReadGroupRequest(conn net.Conn, group string, consumer string, keys []string, ids []string, count int64, block bool, timeout time.Duration) ([]redis.StreamRead, error) {
+	return redis.SendXReadGroupRequest(conn, group, consumer, keys, ids, count, block, timeout)
+}
+
+func (serv *Service) SendXAckRequest(conn net.Conn, key string, group string, ids []string) (int64, error) {
+	return redis.SendXAckRequest(conn, key, group, ids)
+}
+
+func (serv *Service) SendXPendingRequest(conn net.Conn, key string, group string, consumer string) ([]redis.StreamPending, error) {
+	return redis.SendXPendingRequest(conn, key, group, consumer)
+}
+
+func (serv *Service) SendXClaimRequest(conn net.Conn, key string, group string, consumer string, minIdle time.Duration, count int64, ids []string) ([]redis.StreamEntry, error) {
+	return redis.SendXClaimRequest(conn, key, group, consumer, minIdle, count, ids)
+}
+
+func (serv *Service) SendSetArgsRequest(conn net.Conn, key string, val tlv.TypeLengthValue, opts redis.SetOptions) (bool, tlv.TLVCompatible, error) {
+	return redis.SendSetArgsRequest(conn, key, val, opts)
+}
+
+func (serv *Service) SendCASRequest(conn net.Conn, key string, expected tlv.TypeLengthValue, val tlv.TypeLengthValue, ttl time.Duration) (uint64, error) {
+	return redis.SendCASRequest(conn, key, expected, val, ttl)
+}
+
+func (serv *Service) SendCASVersionRequest(conn net.Conn, key string, version uint64, val tlv.TypeLengthValue, ttl time.Duration) (uint64, error) {
+	return redis.SendCASVersionRequest(conn, key, version, val, ttl)
+}
+
+func (serv *Service) SendGetVersionRequest(conn net.Conn, key string) (uint64, error) {
+	return redis.SendGetVersionRequest(conn, key)
+}
+
+func (serv *Service) SendMGetRequest(conn net.Conn, keys []string) ([]tlv.TLVCompatible, error) {
+	return redis.SendMGetRequest(conn, keys)
+}
+
+func (serv *Service) SendMSetRequest(conn net.Conn, pairs tlv.Map) (string, error) {
+	return redis.SendMSetRequest(conn, pairs)
+}
+
+func (serv *Service) SendMSetNXRequest(conn net.Conn, pairs tlv.Map) (bool, error) {
+	return redis.SendMSetNXRequest(conn, pairs)
+}
+
+func (serv *Service) SendMultiRequest(conn net.Conn) (string, error) {
+	return transaction.SendMultiRequest(conn)
+}
+
+func (serv *Service) SendExecRequest(conn net.Conn) ([]tlv.TypeLengthValue, error) {
+	return transaction.SendExecRequest(conn)
+}
+
+func (serv *Service) SendDiscardRequest(conn net.Conn) (string, error) {
+	return transaction.SendDiscardRequest(conn)
+}
+
+func (serv *Service) SendWatchRequest(conn net.Conn, keys []string) (string, error) {
+	return transaction.SendWatchRequest(conn, keys)
+}
+
+func (serv *Service) SendUnwatchRequest(conn net.Conn) (string, error) {
+	return transaction.SendUnwatchRequest(conn)
+}
+
+func (serv *Service) SendSubRequest(conn net.Conn, topics ...string) (*pubsub.Subscriber, error) {
+	return pubsub.SendSubRequest(conn, topics...)
+}
+
+func (serv *Service) SendPSubRequest(conn net.Conn, patterns ...string) (*pubsub.Subscriber, error) {
+	return pubsub.SendPSubRequest(conn, patterns...)
 }
 
-func (serv *Service) SendPubRequest(conn net.Conn, topic string, val tlv.TypeLengthValue) (string, error) {
+func (serv *Service) SendPubRequest(conn net.Conn, topic string, val tlv.TypeLengthValue) (int, error) {
 	return pubsub.SendPubRequest(conn, topic, val)
 }
 
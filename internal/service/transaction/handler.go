@@ -0,0 +1,248 @@
+package transaction
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/helper"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+// Handler run one request, EXEC use it to run queued requests
+type Handler func(ctx payload.IRequestContext) error
+
+// IsControl is true for commands handled at once while client is in transaction
+func IsControl(cmd uint8) bool {
+	switch cmd {
+	case payload.MultiCmd, payload.ExecCmd, payload.DiscardCmd, payload.WatchCmd:
+		return true
+	default:
+		return false
+	}
+}
+
+// queueable is false for commands that cannot run with store locked, that change mode of
+// connection or whose reply is not a response frame
+func queueable(cmd uint8) bool {
+	switch cmd {
+	case payload.PingCmd,
+		payload.SubCmd, payload.UnsubCmd, payload.PSubCmd, payload.PUnsubCmd,
+		payload.BGRewriteAOFCmd, payload.SaveCmd, payload.BGSaveCmd:
+		return false
+	default:
+		return true
+	}
+}
+
+func HandleRequest(ctx payload.IRequestContext, handle Handler) error {
+	pl := ctx.GetPayload()
+
+	body := &payload.RedisRequestBody{Version: pl.Version}
+	_, err := body.ReadFrom(bytes.NewReader(pl.Body))
+	if err != nil {
+		return err
+	}
+
+	switch pl.Cmd {
+	case payload.MultiCmd:
+		return handleMultiRequest(ctx)
+	case payload.ExecCmd:
+		return handleExecRequest(ctx, handle)
+	case payload.DiscardCmd:
+		return handleDiscardRequest(ctx)
+	case payload.WatchCmd:
+		return handleWatchRequest(ctx, body)
+	case payload.UnwatchCmd:
+		return handleUnwatchRequest(ctx)
+	}
+
+	return nil
+}
+
+// QueueRequest queue request of client in transaction until EXEC. Request that cannot be
+// queued is replied with error and make EXEC discard transaction.
+func QueueRequest(ctx payload.IRequestContext) error {
+	pl := ctx.GetPayload()
+	client := ctx.GetClient()
+
+	if !queueable(pl.Cmd) {
+		client.FailTransaction()
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.NotAllowedInMultiError), ctx)
+	}
+
+	client.Queue(model.QueuedRequest{
+		Version: pl.Version,
+		Cmd:     pl.Cmd,
+		Body:    pl.Body,
+	})
+
+	return helper.ResponseWithString("QUEUED", ctx)
+}
+
+// HandleDisconnected drop transaction and watched keys of client whose connection is gone
+func HandleDisconnected(ctx payload.IRequestContext) error {
+	client := ctx.GetClient()
+	client.EndTransaction()
+	unwatch(ctx, client.Unwatch())
+
+	return nil
+}
+
+func handleMultiRequest(ctx payload.IRequestContext) error {
+	if !ctx.GetClient().Multi() {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.NestedMultiError), ctx)
+	}
+
+	return helper.ResponseWithString("OK", ctx)
+}
+
+// Queued requests are run one after another with store locked and their replies are sent
+// as one array. Reply is empty when a watched key changed since it was watched, nothing
+// is run then. Keys are no longer watched after EXEC whatever its outcome.
+func handleExecRequest(ctx payload.IRequestContext, handle Handler) error {
+	client := ctx.GetClient()
+	tx := client.EndTransaction()
+	watched := client.Unwatch()
+	defer unwatch(ctx, watched)
+
+	if tx == nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.NotInMultiError), ctx)
+	}
+	if tx.Failed {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.ExecAbortError), ctx)
+	}
+
+	reply := &tlv.Array{}
+	aborted := false
+	err := ctx.AtomicRedis(func(txctx payload.IRequestContext) error {
+		for k, version := range watched {
+			if txctx.VersionRedis(k) != version {
+				aborted = true
+				return nil
+			}
+		}
+
+		for _, req := range tx.Queued {
+			pl := &payload.RequestPayload{
+				Version: req.Version,
+				Cmd:     req.Cmd,
+				Body:    req.Body,
+			}
+			conn := &replyConn{Conn: ctx.GetConn()}
+			err := handle(txctx.WithRequest(pl, conn))
+			if err != nil {
+				log.Println("exec:", err)
+			}
+
+			*reply = append(*reply, conn.reply())
+		}
+		return nil
+	})
+	if err != nil {
+		return helper.ResponseWithError(err, ctx)
+	}
+
+	if aborted {
+		return helper.ResponseWithRaw(nil, ctx)
+	}
+
+	return helper.ResponseWithTLV(reply, ctx)
+}
+
+func handleDiscardRequest(ctx payload.IRequestContext) error {
+	client := ctx.GetClient()
+	tx := client.EndTransaction()
+	unwatch(ctx, client.Unwatch())
+
+	if tx == nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.NotInMultiError), ctx)
+	}
+
+	return helper.ResponseWithString("OK", ctx)
+}
+
+// Watch keys are sent as string array, EXEC is aborted when any of them changed until then
+func handleWatchRequest(ctx payload.IRequestContext, body *payload.RedisRequestBody) error {
+	client := ctx.GetClient()
+	if client.InTransaction() {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WatchInMultiError), ctx)
+	}
+
+	args := new(tlv.Array)
+	err := args.FromTLV(body.Value)
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.SyntaxError), ctx)
+	}
+	keys, err := args.Strings()
+	if err != nil {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.SyntaxError), ctx)
+	}
+	if len(keys) == 0 {
+		return helper.ResponseWithError(tlv.NewCodeError(tlv.WrongArgumentsError), ctx)
+	}
+
+	versions := ctx.WatchRedis(keys...)
+	for i, k := range keys {
+		// Key watched already keep its first version
+		if !client.Watch(k, versions[i]) {
+			ctx.UnwatchRedis(k)
+		}
+	}
+
+	return helper.ResponseWithString("OK", ctx)
+}
+
+func handleUnwatchRequest(ctx payload.IRequestContext) error {
+	unwatch(ctx, ctx.GetClient().Unwatch())
+
+	return helper.ResponseWithString("OK", ctx)
+}
+
+// unwatch release keys client no longer watch
+func unwatch(ctx payload.IRequestContext, watched map[string]uint64) {
+	if len(watched) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(watched))
+	for k := range watched {
+		keys = append(keys, k)
+	}
+	ctx.UnwatchRedis(keys...)
+}
+
+// replyConn keep reply of request run by EXEC instead of sending it
+type replyConn struct {
+	net.Conn
+	buf bytes.Buffer
+}
+
+func (c *replyConn) Write(b []byte) (int, error) {
+	return c.buf.Write(b)
+}
+
+// reply return body of reply written to conn, request that wrote none or gave up blocking
+// get empty reply
+func (c *replyConn) reply() tlv.TypeLengthValue {
+	resp, err := payload.ReadResponse(&c.buf)
+	if err != nil {
+		if err != io.EOF {
+			log.Println("exec:", err)
+		}
+		return emptyReply()
+	}
+	if len(resp.Body) == 0 {
+		return emptyReply()
+	}
+
+	return resp.Body
+}
+
+// emptyReply is empty tlv with its header so it can be an element of array
+func emptyReply() tlv.TypeLengthValue {
+	return tlv.TypeLengthValue{tlv.EmptyType, 0, 0, 0, 0}
+}
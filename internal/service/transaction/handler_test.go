@@ -0,0 +1,282 @@
+package transaction
+
+import (
+	"testing"
+	"time"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
+	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
+	mockpayload "bitbucket.org/non-pn/mini-redis-go/internal/mock/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/helper"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/test"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func getRequestPayload(cmd uint8, val tlv.TypeLengthValue) *payload.RequestPayload {
+	bod := payload.RedisRequestBody{Value: val}
+	rawbod, _ := bod.ToTLV()
+	return &payload.RequestPayload{
+		Cmd:  cmd,
+		Body: rawbod,
+	}
+}
+
+func newTestClient(conn *mocknet.MockConn) *model.Client {
+	return model.NewClientRegistry().Register(nil, conn, time.Now())
+}
+
+func TestHandleMultiRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+	client := newTestClient(conn)
+	ok := tlv.String("OK")
+	rawok, _ := ok.ToTLV()
+
+	ctx.EXPECT().GetPayload().Times(1).Return(getRequestPayload(payload.MultiCmd, nil))
+	ctx.EXPECT().GetClient().Times(1).Return(client)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.StringType, rawok)
+
+	err := HandleRequest(ctx, nil)
+
+	assert.Nil(t, err)
+	assert.True(t, client.InTransaction())
+}
+
+func TestHandleMultiRequestNested(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+	client := newTestClient(conn)
+	client.Multi()
+
+	ctx.EXPECT().GetPayload().Times(1).Return(getRequestPayload(payload.MultiCmd, nil))
+	ctx.EXPECT().GetClient().Times(1).Return(client)
+	ctx.EXPECT().Error(uint16(tlv.NestedMultiError), tlv.ErrMsg[tlv.NestedMultiError]).Times(1)
+
+	err := HandleRequest(ctx, nil)
+
+	assert.Nil(t, err)
+}
+
+func TestQueueRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+	client := newTestClient(conn)
+	client.Multi()
+	queued := tlv.String("QUEUED")
+	rawqueued, _ := queued.ToTLV()
+	pl := getRequestPayload(payload.IncrCmd, nil)
+
+	ctx.EXPECT().GetPayload().Times(1).Return(pl)
+	ctx.EXPECT().GetClient().Times(1).Return(client)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.StringType, rawqueued)
+
+	err := QueueRequest(ctx)
+
+	assert.Nil(t, err)
+	tx := client.EndTransaction()
+	assert.Equal(t, []model.QueuedRequest{{Cmd: payload.IncrCmd, Body: pl.Body}}, tx.Queued)
+	assert.False(t, tx.Failed)
+}
+
+func TestQueueRequestNotAllowed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+	client := newTestClient(conn)
+	client.Multi()
+
+	ctx.EXPECT().GetPayload().Times(1).Return(getRequestPayload(payload.SaveCmd, nil))
+	ctx.EXPECT().GetClient().Times(1).Return(client)
+	ctx.EXPECT().Error(uint16(tlv.NotAllowedInMultiError), tlv.ErrMsg[tlv.NotAllowedInMultiError]).Times(1)
+
+	err := QueueRequest(ctx)
+
+	assert.Nil(t, err)
+	assert.True(t, client.EndTransaction().Failed)
+}
+
+func TestHandleExecRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+	txctx := mockpayload.NewMockIRequestContext(ctrl)
+	reqctx := mockpayload.NewMockIRequestContext(ctrl)
+	client := newTestClient(conn)
+	client.Multi()
+	client.Queue(model.QueuedRequest{Cmd: payload.IncrCmd})
+	client.Queue(model.QueuedRequest{Cmd: payload.GetCmd})
+	client.Watch("test_key", 1)
+
+	one := tlv.Int64(1)
+	rawone, _ := one.ToTLV()
+	reply := tlv.Array{rawone, emptyReply()}
+	rawreply, _ := reply.ToTLV()
+
+	ctx.EXPECT().GetPayload().Times(1).Return(getRequestPayload(payload.ExecCmd, nil))
+	ctx.EXPECT().GetClient().Times(1).Return(client)
+	ctx.EXPECT().AtomicRedis(gomock.Any()).Times(1).DoAndReturn(func(fn func(ctx payload.IRequestContext) error) error {
+		return fn(txctx)
+	})
+	txctx.EXPECT().VersionRedis("test_key").Times(1).Return(uint64(1))
+	ctx.EXPECT().GetConn().Times(3).Return(conn)
+	var replyConns []*replyConn
+	txctx.EXPECT().WithRequest(gomock.Any(), gomock.Any()).Times(2).DoAndReturn(func(pl *payload.RequestPayload, c *replyConn) payload.IRequestContext {
+		replyConns = append(replyConns, c)
+		return reqctx
+	})
+	reqctx.EXPECT().GetConn().Times(1).DoAndReturn(func() *replyConn {
+		return replyConns[len(replyConns)-1]
+	})
+	ctx.EXPECT().UnwatchRedis("test_key").Times(1)
+	test.ExpectWriteResponseToConn(t, conn, tlv.ArrayType, rawreply)
+
+	// Second request write no reply and is replied as empty
+	handled := 0
+	handle := func(ctx payload.IRequestContext) error {
+		handled++
+		if handled > 1 {
+			return nil
+		}
+		return helper.ResponseWithRaw(rawone, ctx)
+	}
+	err := HandleRequest(ctx, handle)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, handled)
+	assert.False(t, client.InTransaction())
+	assert.Nil(t, client.Unwatch())
+}
+
+func TestHandleExecRequestWatchedKeyChanged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+	client := newTestClient(conn)
+	client.Watch("test_key", 1)
+	client.Multi()
+	client.Queue(model.QueuedRequest{Cmd: payload.IncrCmd})
+
+	ctx.EXPECT().GetPayload().Times(1).Return(getRequestPayload(payload.ExecCmd, nil))
+	ctx.EXPECT().GetClient().Times(1).Return(client)
+	ctx.EXPECT().AtomicRedis(gomock.Any()).Times(1).DoAndReturn(func(fn func(ctx payload.IRequestContext) error) error {
+		return fn(ctx)
+	})
+	ctx.EXPECT().VersionRedis("test_key").Times(1).Return(uint64(2))
+	ctx.EXPECT().UnwatchRedis("test_key").Times(1)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.EmptyType, nil)
+
+	handle := func(ctx payload.IRequestContext) error {
+		t.Error("Queued request should not run")
+		return nil
+	}
+	err := HandleRequest(ctx, handle)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleExecRequestWithoutMulti(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+	client := newTestClient(conn)
+
+	ctx.EXPECT().GetPayload().Times(1).Return(getRequestPayload(payload.ExecCmd, nil))
+	ctx.EXPECT().GetClient().Times(1).Return(client)
+	ctx.EXPECT().Error(uint16(tlv.NotInMultiError), tlv.ErrMsg[tlv.NotInMultiError]).Times(1)
+
+	err := HandleRequest(ctx, nil)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleDiscardRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+	client := newTestClient(conn)
+	client.Watch("test_key", 1)
+	client.Multi()
+	client.Queue(model.QueuedRequest{Cmd: payload.IncrCmd})
+	ok := tlv.String("OK")
+	rawok, _ := ok.ToTLV()
+
+	ctx.EXPECT().GetPayload().Times(1).Return(getRequestPayload(payload.DiscardCmd, nil))
+	ctx.EXPECT().GetClient().Times(1).Return(client)
+	ctx.EXPECT().UnwatchRedis("test_key").Times(1)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.StringType, rawok)
+
+	err := HandleRequest(ctx, nil)
+
+	assert.Nil(t, err)
+	assert.False(t, client.InTransaction())
+}
+
+func TestHandleWatchRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+	client := newTestClient(conn)
+	client.Watch("test_key_1", 1)
+	args, _ := tlv.NewStringArray("test_key_1", "test_key_2")
+	rawargs, _ := args.ToTLV()
+	ok := tlv.String("OK")
+	rawok, _ := ok.ToTLV()
+
+	ctx.EXPECT().GetPayload().Times(1).Return(getRequestPayload(payload.WatchCmd, rawargs))
+	ctx.EXPECT().GetClient().Times(1).Return(client)
+	ctx.EXPECT().WatchRedis("test_key_1", "test_key_2").Times(1).Return([]uint64{2, 3})
+	// Key watched already is released at once
+	ctx.EXPECT().UnwatchRedis("test_key_1").Times(1)
+	ctx.EXPECT().GetConn().Times(1).Return(conn)
+	test.ExpectWriteResponseToConn(t, conn, tlv.StringType, rawok)
+
+	err := HandleRequest(ctx, nil)
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]uint64{"test_key_1": 1, "test_key_2": 3}, client.Unwatch())
+}
+
+func TestHandleWatchRequestInMulti(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+	client := newTestClient(conn)
+	client.Multi()
+	args, _ := tlv.NewStringArray("test_key")
+	rawargs, _ := args.ToTLV()
+
+	ctx.EXPECT().GetPayload().Times(1).Return(getRequestPayload(payload.WatchCmd, rawargs))
+	ctx.EXPECT().GetClient().Times(1).Return(client)
+	ctx.EXPECT().Error(uint16(tlv.WatchInMultiError), tlv.ErrMsg[tlv.WatchInMultiError]).Times(1)
+
+	err := HandleRequest(ctx, nil)
+
+	assert.Nil(t, err)
+}
+
+func TestHandleWatchRequestInvalidArgs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+	ctx := mockpayload.NewMockIRequestContext(ctrl)
+	client := newTestClient(conn)
+	key := tlv.String("test_key")
+	rawkey, _ := key.ToTLV()
+
+	ctx.EXPECT().GetPayload().Times(1).Return(getRequestPayload(payload.WatchCmd, rawkey))
+	ctx.EXPECT().GetClient().Times(1).Return(client)
+	ctx.EXPECT().Error(uint16(tlv.SyntaxError), tlv.ErrMsg[tlv.SyntaxError]).Times(1)
+
+	err := HandleRequest(ctx, nil)
+
+	assert.Nil(t, err)
+}
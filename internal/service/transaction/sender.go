@@ -0,0 +1,106 @@
+package transaction
+
+import (
+	"net"
+
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+)
+
+// SendMultiRequest start transaction, requests that follow are replied QUEUED until EXEC
+func SendMultiRequest(conn net.Conn) (string, error) {
+	return sendStringRequest(conn, payload.MultiCmd, payload.RedisRequestBody{})
+}
+
+// SendExecRequest run queued requests and return their replies in order, each being tlv
+// with its header. It return nil when a watched key changed and nothing was run.
+func SendExecRequest(conn net.Conn) ([]tlv.TypeLengthValue, error) {
+	resp, err := sendRequest(conn, payload.ExecCmd, payload.RedisRequestBody{})
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.Typ == tlv.EmptyType {
+		return nil, nil
+	}
+
+	replies := new(tlv.Array)
+	err = replies.FromTLV(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return *replies, nil
+}
+
+func SendDiscardRequest(conn net.Conn) (string, error) {
+	return sendStringRequest(conn, payload.DiscardCmd, payload.RedisRequestBody{})
+}
+
+// SendWatchRequest make next EXEC abort when any of keys change until then
+func SendWatchRequest(conn net.Conn, keys []string) (string, error) {
+	args, err := tlv.NewStringArray(keys...)
+	if err != nil {
+		return "", err
+	}
+
+	rawargs, err := args.ToTLV()
+	if err != nil {
+		return "", err
+	}
+
+	return sendStringRequest(conn, payload.WatchCmd, payload.RedisRequestBody{Value: rawargs})
+}
+
+func SendUnwatchRequest(conn net.Conn) (string, error) {
+	return sendStringRequest(conn, payload.UnwatchCmd, payload.RedisRequestBody{})
+}
+
+func sendRequest(conn net.Conn, cmd uint8, body payload.RedisRequestBody) (*payload.ResponsePayload, error) {
+	rawbod, err := body.ToTLV()
+	if err != nil {
+		return nil, err
+	}
+
+	req := payload.RequestPayload{
+		Cmd:  cmd,
+		Body: rawbod,
+	}
+
+	_, err = req.WriteTo(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := payload.ReadResponse(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Error reply is returned as typed *tlv.Error
+	if resp.Typ == tlv.ErrorType {
+		tlvErr := new(tlv.Error)
+		err = tlvErr.FromTLV(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		return nil, tlvErr
+	}
+
+	return resp, nil
+}
+
+func sendStringRequest(conn net.Conn, cmd uint8, body payload.RedisRequestBody) (string, error) {
+	resp, err := sendRequest(conn, cmd, body)
+	if err != nil {
+		return "", err
+	}
+
+	msg := new(tlv.String)
+	err = msg.FromTLV(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	return string(*msg), nil
+}
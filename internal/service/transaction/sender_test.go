@@ -0,0 +1,102 @@
+package transaction
+
+import (
+	"testing"
+
+	mocknet "bitbucket.org/non-pn/mini-redis-go/internal/mock/net"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
+	"bitbucket.org/non-pn/mini-redis-go/internal/service/internal/test"
+	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestSendMultiRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	reqbod := payload.RedisRequestBody{}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.MultiCmd, rawreqbod)
+
+	resbod := tlv.String("OK")
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.StringType, rawresbod)
+
+	res, err := SendMultiRequest(conn)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", res)
+}
+
+func TestSendExecRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	reqbod := payload.RedisRequestBody{}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.ExecCmd, rawreqbod)
+
+	one := tlv.Int64(1)
+	rawone, _ := one.ToTLV()
+	resbod := tlv.Array{rawone, emptyReply()}
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.ArrayType, rawresbod)
+
+	replies, err := SendExecRequest(conn)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []tlv.TypeLengthValue{rawone, emptyReply()}, replies)
+}
+
+func TestSendExecRequestAborted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	reqbod := payload.RedisRequestBody{}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.ExecCmd, rawreqbod)
+	test.ExpectReadResponseFromConn(t, conn, tlv.EmptyType, nil)
+
+	replies, err := SendExecRequest(conn)
+
+	assert.Nil(t, err)
+	assert.Nil(t, replies)
+}
+
+func TestSendExecRequestWithoutMulti(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	reqbod := payload.RedisRequestBody{}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.ExecCmd, rawreqbod)
+
+	resbod := tlv.NewCodeError(tlv.NotInMultiError)
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.ErrorType, rawresbod)
+
+	_, err := SendExecRequest(conn)
+
+	assert.Equal(t, tlv.NewCodeError(tlv.NotInMultiError), err)
+}
+
+func TestSendWatchRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	conn := mocknet.NewMockConn(ctrl)
+
+	args, _ := tlv.NewStringArray("test_key_1", "test_key_2")
+	rawargs, _ := args.ToTLV()
+	reqbod := payload.RedisRequestBody{Value: rawargs}
+	rawreqbod, _ := reqbod.ToTLV()
+	test.ExpectWriteRequestToConn(t, conn, payload.WatchCmd, rawreqbod)
+
+	resbod := tlv.String("OK")
+	rawresbod, _ := resbod.ToTLV()
+	test.ExpectReadResponseFromConn(t, conn, tlv.StringType, rawresbod)
+
+	res, err := SendWatchRequest(conn, []string{"test_key_1", "test_key_2"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "OK", res)
+}
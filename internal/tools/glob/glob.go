@@ -0,0 +1,101 @@
+package glob
+
+// Match report whether s matches redis style glob pattern.
+// Supported syntax are *, ?, [abc], [^abc], [a-z] and \ to escape special character.
+func Match(pattern string, s string) bool {
+	var (
+		p, i = 0, 0
+		// Position to backtrack to when a later part of pattern does not match
+		starp, stari = -1, -1
+	)
+
+	for i < len(s) {
+		if p < len(pattern) {
+			switch pattern[p] {
+			case '*':
+				starp, stari = p, i
+				p++
+				continue
+			case '?':
+				p++
+				i++
+				continue
+			case '[':
+				if next, ok := matchClass(pattern, p, s[i]); ok {
+					p = next
+					i++
+					continue
+				}
+			case '\\':
+				if p+1 < len(pattern) && pattern[p+1] == s[i] {
+					p += 2
+					i++
+					continue
+				}
+			default:
+				if pattern[p] == s[i] {
+					p++
+					i++
+					continue
+				}
+			}
+		}
+
+		if starp < 0 {
+			return false
+		}
+
+		// Let the last star consume one more character
+		stari++
+		p, i = starp+1, stari
+	}
+
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+
+	return p == len(pattern)
+}
+
+// matchClass match c against character class start at pattern[p] which is '['.
+// It return position right after the class and whether c is matched.
+func matchClass(pattern string, p int, c byte) (int, bool) {
+	p++
+	negate := false
+	if p < len(pattern) && pattern[p] == '^' {
+		negate = true
+		p++
+	}
+
+	matched := false
+	for p < len(pattern) && pattern[p] != ']' {
+		switch {
+		case pattern[p] == '\\' && p+1 < len(pattern):
+			if pattern[p+1] == c {
+				matched = true
+			}
+			p += 2
+		case p+2 < len(pattern) && pattern[p+1] == '-' && pattern[p+2] != ']':
+			lo, hi := pattern[p], pattern[p+2]
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			if c >= lo && c <= hi {
+				matched = true
+			}
+			p += 3
+		default:
+			if pattern[p] == c {
+				matched = true
+			}
+			p++
+		}
+	}
+
+	// Unterminated class never match
+	if p >= len(pattern) {
+		return p, false
+	}
+
+	return p + 1, matched != negate
+}
@@ -0,0 +1,187 @@
+package tlv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+)
+
+// Array is a sequence of type length values, element can be an array itself
+type Array []TypeLengthValue
+
+func (a *Array) ReadFrom(r io.Reader) (int64, error) {
+	var (
+		typ uint8
+		len uint32
+		buf []byte
+		n   int64
+
+		err error
+	)
+
+	err = binary.Read(r, binary.BigEndian, &typ)
+	if err != nil {
+		return 0, err
+	}
+
+	n += 1
+
+	if typ != ArrayType {
+		return n, errors.New("Invalid Array")
+	}
+
+	err = binary.Read(r, binary.BigEndian, &len)
+	if err != nil {
+		return n, err
+	}
+
+	n += 4
+
+	buf = make([]byte, len)
+	o, err := io.ReadFull(r, buf)
+	if err != nil {
+		return n, err
+	}
+
+	n += int64(o)
+
+	elems := Array{}
+	br := bytes.NewReader(buf)
+	for br.Len() > 0 {
+		elem, err := ReadTLV(br)
+		if err != nil {
+			return n, err
+		}
+		elems = append(elems, elem)
+	}
+
+	*a = elems
+
+	return n, nil
+}
+
+func (a *Array) WriteTo(w io.Writer) (int64, error) {
+	var (
+		typ = ArrayType
+		n   int64
+
+		err error
+	)
+
+	buf := new(bytes.Buffer)
+	for _, elem := range *a {
+		buf.Write(elem)
+	}
+
+	err = binary.Write(w, binary.BigEndian, typ)
+	if err != nil {
+		return 0, err
+	}
+
+	n += 1
+
+	err = binary.Write(w, binary.BigEndian, uint32(buf.Len()))
+	if err != nil {
+		return n, err
+	}
+
+	n += 4
+
+	o, err := w.Write(buf.Bytes())
+	if err != nil {
+		return n, err
+	}
+
+	n += int64(o)
+
+	return n, nil
+}
+
+func (a *Array) FromTLV(tlv TypeLengthValue) error {
+	r := bytes.NewReader(tlv)
+	_, err := a.ReadFrom(r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (a *Array) ToTLV() (TypeLengthValue, error) {
+	tlv := new(bytes.Buffer)
+	_, err := a.WriteTo(tlv)
+	if err != nil {
+		return nil, err
+	}
+
+	return TypeLengthValue(tlv.Bytes()), nil
+}
+
+func (a *Array) String() string {
+	if a == nil {
+		return "[]"
+	}
+
+	elems := make([]string, 0, len(*a))
+	for _, elem := range *a {
+		elems = append(elems, decodeString(elem))
+	}
+
+	return "[" + strings.Join(elems, ", ") + "]"
+}
+
+// Append encode v and add it to the end of array
+func (a *Array) Append(v TLVCompatible) error {
+	raw, err := v.ToTLV()
+	if err != nil {
+		return err
+	}
+
+	*a = append(*a, raw)
+
+	return nil
+}
+
+// Values decode every element with Decode
+func (a *Array) Values() ([]TLVCompatible, error) {
+	vs := make([]TLVCompatible, 0, len(*a))
+	for _, elem := range *a {
+		v, err := Decode(elem)
+		if err != nil {
+			return nil, err
+		}
+		vs = append(vs, v)
+	}
+
+	return vs, nil
+}
+
+// Strings decode every element as String
+func (a *Array) Strings() ([]string, error) {
+	ss := make([]string, 0, len(*a))
+	for _, elem := range *a {
+		s := new(String)
+		err := s.FromTLV(elem)
+		if err != nil {
+			return nil, err
+		}
+		ss = append(ss, s.String())
+	}
+
+	return ss, nil
+}
+
+func NewStringArray(ss ...string) (*Array, error) {
+	a := make(Array, 0, len(ss))
+	for _, s := range ss {
+		str := String(s)
+		err := a.Append(&str)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &a, nil
+}
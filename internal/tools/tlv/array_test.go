@@ -0,0 +1,102 @@
+package tlv
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArrayReadFrom(t *testing.T) {
+	testa := []byte{14, 0, 0, 0, 15, 2, 0, 0, 0, 1, 97, 1, 0, 0, 0, 4, 116, 101, 115, 116}
+	testreader := bytes.NewReader(testa)
+
+	a := new(Array)
+	n, err := a.ReadFrom(testreader)
+
+	assert.Equal(t, len(testa), int(n))
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(*a))
+	assert.Equal(t, TypeLengthValue(testa[5:11]), (*a)[0])
+	assert.Equal(t, TypeLengthValue(testa[11:]), (*a)[1])
+}
+
+func TestArrayReadFromInvalid(t *testing.T) {
+	testa := []byte{2, 0, 0, 0, 6, 2, 0, 0, 0, 1, 97}
+	testreader := bytes.NewReader(testa)
+
+	a := new(Array)
+	n, err := a.ReadFrom(testreader)
+
+	assert.Equal(t, 1, int(n))
+	assert.NotNil(t, err)
+}
+
+func TestArrayReadFromTruncatedElement(t *testing.T) {
+	testa := []byte{14, 0, 0, 0, 6, 2, 0, 0, 0, 4, 97}
+	testreader := bytes.NewReader(testa)
+
+	a := new(Array)
+	_, err := a.ReadFrom(testreader)
+
+	assert.NotNil(t, err)
+}
+
+func TestArrayWriteTo(t *testing.T) {
+	testa := []byte{14, 0, 0, 0, 15, 2, 0, 0, 0, 1, 97, 1, 0, 0, 0, 4, 116, 101, 115, 116}
+	testwriter := new(bytes.Buffer)
+
+	a := Array{testa[5:11], testa[11:]}
+	n, err := a.WriteTo(testwriter)
+
+	assert.Equal(t, len(testa), int(n))
+	assert.Nil(t, err)
+	assert.Equal(t, testa, testwriter.Bytes())
+}
+
+func TestEmptyArrayToTLV(t *testing.T) {
+	a := Array{}
+
+	tlv, err := a.ToTLV()
+
+	assert.Nil(t, err)
+	assert.Equal(t, []byte{14, 0, 0, 0, 0}, []byte(tlv))
+}
+
+func TestNestedArray(t *testing.T) {
+	inner, _ := NewStringArray("b", "c")
+	outer, _ := NewStringArray("a")
+	err := outer.Append(inner)
+
+	assert.Nil(t, err)
+
+	raw, err := outer.ToTLV()
+
+	assert.Nil(t, err)
+
+	a := new(Array)
+	err = a.FromTLV(raw)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(*a))
+	assert.Equal(t, "[a, [b, c]]", a.String())
+}
+
+func TestArrayStrings(t *testing.T) {
+	a, err := NewStringArray("a", "b")
+
+	assert.Nil(t, err)
+
+	ss, err := a.Strings()
+
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, ss)
+}
+
+func TestArrayStringsInvalidElement(t *testing.T) {
+	a := Array{[]byte{1, 0, 0, 0, 1, 97}}
+
+	_, err := a.Strings()
+
+	assert.NotNil(t, err)
+}
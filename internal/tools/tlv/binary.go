@@ -38,7 +38,7 @@ func (b *Binary) ReadFrom(r io.Reader) (int64, error) {
 	n += 4
 
 	buf = make([]byte, len)
-	o, err := r.Read(buf)
+	o, err := io.ReadFull(r, buf)
 	if err != nil {
 		return n, err
 	}
@@ -0,0 +1,121 @@
+package tlv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+)
+
+const BoolDataLength uint32 = 1
+
+type Bool bool
+
+func (b *Bool) ReadFrom(r io.Reader) (int64, error) {
+	var (
+		typ uint8
+		len uint32
+		val uint8
+		n   int64
+
+		err error
+	)
+
+	err = binary.Read(r, binary.BigEndian, &typ)
+	if err != nil {
+		return 0, err
+	}
+	n += 1
+
+	if typ != BoolType {
+		return n, errors.New("Invalid Bool")
+	}
+
+	err = binary.Read(r, binary.BigEndian, &len)
+	if err != nil {
+		return n, err
+	}
+	n += 4
+
+	if len != BoolDataLength {
+		return n, errors.New("Invalid Bool length")
+	}
+
+	err = binary.Read(r, binary.BigEndian, &val)
+	if err != nil {
+		return n, err
+	}
+	n += int64(BoolDataLength)
+
+	if val > 1 {
+		return n, errors.New("Invalid Bool value")
+	}
+
+	*b = Bool(val == 1)
+
+	return n, nil
+}
+
+func (b *Bool) WriteTo(w io.Writer) (int64, error) {
+	var (
+		typ = BoolType
+		val uint8
+		n   int64
+
+		err error
+	)
+
+	err = binary.Write(w, binary.BigEndian, typ)
+	if err != nil {
+		return 0, err
+	}
+
+	n += 1
+
+	err = binary.Write(w, binary.BigEndian, BoolDataLength)
+	if err != nil {
+		return n, err
+	}
+
+	n += 4
+
+	if *b {
+		val = 1
+	}
+	err = binary.Write(w, binary.BigEndian, val)
+	if err != nil {
+		return n, err
+	}
+
+	n += int64(BoolDataLength)
+
+	return n, nil
+}
+
+func (b *Bool) FromTLV(tlv TypeLengthValue) error {
+	r := bytes.NewReader(tlv)
+	_, err := b.ReadFrom(r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (b *Bool) ToTLV() (TypeLengthValue, error) {
+	tlv := new(bytes.Buffer)
+	_, err := b.WriteTo(tlv)
+	if err != nil {
+		return nil, err
+	}
+
+	return TypeLengthValue(tlv.Bytes()), nil
+}
+
+func (b *Bool) String() string {
+	if b == nil {
+		return ""
+	}
+	return strconv.FormatBool(bool(*b))
+}
@@ -0,0 +1,48 @@
+package tlv
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoolReadFrom(t *testing.T) {
+	tests := []byte{18, 0, 0, 0, 1, 1}
+	testreader := bytes.NewReader(tests)
+
+	b := new(Bool)
+	n, err := b.ReadFrom(testreader)
+
+	assert.Equal(t, len(tests), int(n))
+	assert.Nil(t, err)
+	assert.Equal(t, Bool(true), *b)
+}
+
+func TestBoolReadFromInvalidValue(t *testing.T) {
+	tests := []byte{18, 0, 0, 0, 1, 2}
+	testreader := bytes.NewReader(tests)
+
+	b := new(Bool)
+	_, err := b.ReadFrom(testreader)
+
+	assert.NotNil(t, err)
+}
+
+func TestBoolWriteTo(t *testing.T) {
+	tests := []byte{18, 0, 0, 0, 1, 0}
+	testwriter := new(bytes.Buffer)
+
+	b := Bool(false)
+	n, err := b.WriteTo(testwriter)
+
+	assert.Equal(t, len(tests), int(n))
+	assert.Nil(t, err)
+	assert.Equal(t, tests, testwriter.Bytes())
+}
+
+func TestBoolString(t *testing.T) {
+	b := Bool(true)
+
+	assert.Equal(t, "true", b.String())
+}
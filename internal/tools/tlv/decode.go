@@ -0,0 +1,63 @@
+package tlv
+
+import (
+	"errors"
+	"strconv"
+)
+
+// New return zero value of type typ to read a tlv of that type into, nil for empty type
+func New(typ uint8) (TLVCompatible, error) {
+	switch typ {
+	case EmptyType:
+		return nil, nil
+	case StringType:
+		return new(String), nil
+	case BinaryType:
+		return new(Binary), nil
+	case ErrorType:
+		return new(Error), nil
+	case ArrayType:
+		return new(Array), nil
+	case Int64Type:
+		return new(Int64), nil
+	case Float64Type:
+		return new(Float64), nil
+	case MapType:
+		return new(Map), nil
+	case BoolType:
+		return new(Bool), nil
+	case NilType:
+		return new(Nil), nil
+	default:
+		return nil, errors.New("Unknown tlv type " + strconv.Itoa(int(typ)))
+	}
+}
+
+// Decode read tlv into value of its own type, empty tlv give nil. Elements of collections
+// stay encoded, Values of collection decode them.
+func Decode(tlv TypeLengthValue) (TLVCompatible, error) {
+	v, err := New(tlv.GetType())
+	if err != nil || v == nil {
+		return nil, err
+	}
+
+	err = v.FromTLV(tlv)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// decodeString decode tlv and return its string, raw value when it cannot be decoded
+func decodeString(tlv TypeLengthValue) string {
+	v, err := Decode(tlv)
+	if err != nil {
+		return string(tlv.GetValue())
+	}
+	if v == nil {
+		return ""
+	}
+
+	return v.String()
+}
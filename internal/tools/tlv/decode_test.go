@@ -0,0 +1,61 @@
+package tlv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+	s, i, f, b := String("a"), Int64(1), Float64(1.5), Bool(true)
+	tests := []TLVCompatible{&s, &i, &f, &b, &Nil{}, NewCodeError(WrongTypeError)}
+
+	for _, test := range tests {
+		raw, _ := test.ToTLV()
+
+		v, err := Decode(raw)
+
+		assert.Nil(t, err)
+		assert.Equal(t, test, v)
+	}
+}
+
+func TestDecodeEmpty(t *testing.T) {
+	v, err := Decode(nil)
+
+	assert.Nil(t, err)
+	assert.Nil(t, v)
+}
+
+func TestDecodeUnknownType(t *testing.T) {
+	_, err := Decode(TypeLengthValue{255, 0, 0, 0, 0})
+
+	assert.NotNil(t, err)
+}
+
+func TestDecodeNestedCollections(t *testing.T) {
+	n, b := Int64(2), Bool(false)
+	inner := Map{}
+	inner.Put("n", &n)
+	outer, _ := NewStringArray("a")
+	outer.Append(&inner)
+	outer.Append(&b)
+	raw, _ := outer.ToTLV()
+
+	v, err := Decode(raw)
+	assert.Nil(t, err)
+	a, ok := v.(*Array)
+	assert.True(t, ok)
+
+	vs, err := a.Values()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(vs))
+	assert.Equal(t, &b, vs[2])
+
+	m, ok := vs[1].(*Map)
+	assert.True(t, ok)
+	fields, err := m.Values()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]TLVCompatible{"n": &n}, fields)
+	assert.Equal(t, "[a, {n: 2}, false]", a.String())
+}
@@ -11,10 +11,46 @@ type ErrCode uint16
 
 const (
 	DataTransformError ErrCode = iota
+	NotIntegerError
+	NotFloatError
+	InvalidIncrementError
+	WrongTypeError
+	PersistenceError
+	ShuttingDownError
+	InvalidClientNameError
+	InvalidStreamIDError
+	StreamIDTooSmallError
+	NoGroupError
+	GroupExistsError
+	NotInMultiError
+	NestedMultiError
+	WatchInMultiError
+	NotAllowedInMultiError
+	ExecAbortError
+	SyntaxError
+	WrongArgumentsError
 )
 
 var ErrMsg = map[ErrCode]string{
-	DataTransformError: "Data transform error",
+	DataTransformError:     "Data transform error",
+	NotIntegerError:        "Value is not an integer or out of range",
+	NotFloatError:          "Value is not a valid float",
+	InvalidIncrementError:  "Increment would produce NaN or Infinity",
+	WrongTypeError:         "WRONGTYPE Operation against a key holding the wrong kind of value",
+	PersistenceError:       "Persistence error",
+	ShuttingDownError:      "Server is shutting down",
+	InvalidClientNameError: "Client names cannot contain spaces, newlines or special characters",
+	InvalidStreamIDError:   "Invalid stream ID specified as stream command argument",
+	StreamIDTooSmallError:  "The ID specified in XADD is equal or smaller than the target stream top item",
+	NoGroupError:           "NOGROUP No such key or consumer group",
+	GroupExistsError:       "BUSYGROUP Consumer Group name already exists",
+	NotInMultiError:        "EXEC or DISCARD without MULTI",
+	NestedMultiError:       "MULTI calls can not be nested",
+	WatchInMultiError:      "WATCH inside MULTI is not allowed",
+	NotAllowedInMultiError: "Command is not allowed inside a transaction",
+	ExecAbortError:         "EXECABORT Transaction discarded because of previous errors",
+	SyntaxError:            "ERR syntax error",
+	WrongArgumentsError:    "ERR wrong number of arguments",
 }
 
 type Error struct {
@@ -51,7 +87,7 @@ func (e *Error) ReadFrom(r io.Reader) (int64, error) {
 	n += 4
 
 	buf = make([]byte, len)
-	o, err := r.Read(buf)
+	o, err := io.ReadFull(r, buf)
 	if err != nil {
 		return n, err
 	}
@@ -134,9 +170,23 @@ func (e *Error) ToTLV() (TypeLengthValue, error) {
 	return TypeLengthValue(tlv.Bytes()), nil
 }
 
+func (e *Error) Error() string {
+	return e.Msg
+}
+
+func (e *Error) String() string {
+	return e.Msg
+}
+
 func NewError(code uint16, msg string) Error {
 	return Error{
 		Code: ErrCode(code),
 		Msg:  msg,
 	}
 }
+
+// NewCodeError create error with message of known code
+func NewCodeError(code ErrCode) *Error {
+	e := NewError(uint16(code), ErrMsg[code])
+	return &e
+}
@@ -66,3 +66,11 @@ func TestErrorToTLV(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, testtlv, []byte(tlv))
 }
+
+func TestErrorImplementsError(t *testing.T) {
+	e := NewError(uint16(NotIntegerError), ErrMsg[NotIntegerError])
+
+	var err error = &e
+
+	assert.Equal(t, ErrMsg[NotIntegerError], err.Error())
+}
@@ -0,0 +1,113 @@
+package tlv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+)
+
+const Float64DataLength uint32 = 8
+
+type Float64 float64
+
+func (f *Float64) ReadFrom(r io.Reader) (int64, error) {
+	var (
+		typ uint8
+		len uint32
+		val float64
+		n   int64
+
+		err error
+	)
+
+	err = binary.Read(r, binary.BigEndian, &typ)
+	if err != nil {
+		return 0, err
+	}
+	n += 1
+
+	if typ != Float64Type {
+		return n, errors.New("Invalid Float64")
+	}
+
+	err = binary.Read(r, binary.BigEndian, &len)
+	if err != nil {
+		return n, err
+	}
+	n += 4
+
+	if len != Float64DataLength {
+		return n, errors.New("Invalid Float64 length")
+	}
+
+	err = binary.Read(r, binary.BigEndian, &val)
+	if err != nil {
+		return n, err
+	}
+	n += int64(Float64DataLength)
+
+	*f = Float64(val)
+
+	return n, nil
+}
+
+func (f *Float64) WriteTo(w io.Writer) (int64, error) {
+	var (
+		typ = Float64Type
+		n   int64
+
+		err error
+	)
+
+	err = binary.Write(w, binary.BigEndian, typ)
+	if err != nil {
+		return 0, err
+	}
+
+	n += 1
+
+	err = binary.Write(w, binary.BigEndian, Float64DataLength)
+	if err != nil {
+		return n, err
+	}
+
+	n += 4
+
+	err = binary.Write(w, binary.BigEndian, float64(*f))
+	if err != nil {
+		return n, err
+	}
+
+	n += int64(Float64DataLength)
+
+	return n, nil
+}
+
+func (f *Float64) FromTLV(tlv TypeLengthValue) error {
+	r := bytes.NewReader(tlv)
+	_, err := f.ReadFrom(r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (f *Float64) ToTLV() (TypeLengthValue, error) {
+	tlv := new(bytes.Buffer)
+	_, err := f.WriteTo(tlv)
+	if err != nil {
+		return nil, err
+	}
+
+	return TypeLengthValue(tlv.Bytes()), nil
+}
+
+func (f *Float64) String() string {
+	if f == nil {
+		return ""
+	}
+	return strconv.FormatFloat(float64(*f), 'f', -1, 64)
+}
@@ -0,0 +1,47 @@
+package tlv
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFloat64ReadFrom(t *testing.T) {
+	tests := []byte{16, 0, 0, 0, 8, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}
+	testreader := bytes.NewReader(tests)
+
+	f := new(Float64)
+	n, err := f.ReadFrom(testreader)
+
+	assert.Equal(t, len(tests), int(n))
+	assert.Nil(t, err)
+	assert.Equal(t, Float64(1.5), *f)
+}
+
+func TestFloat64ReadFromInvalid(t *testing.T) {
+	tests := []byte{15, 0, 0, 0, 8, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}
+	testreader := bytes.NewReader(tests)
+
+	f := new(Float64)
+	n, err := f.ReadFrom(testreader)
+
+	assert.Equal(t, 1, int(n))
+	assert.NotNil(t, err)
+}
+
+func TestFloat64ToTLV(t *testing.T) {
+	tests := []byte{16, 0, 0, 0, 8, 0x3f, 0xf8, 0, 0, 0, 0, 0, 0}
+
+	f := Float64(1.5)
+	tlv, err := f.ToTLV()
+
+	assert.Nil(t, err)
+	assert.Equal(t, tests, []byte(tlv))
+}
+
+func TestFloat64String(t *testing.T) {
+	f := Float64(10.5)
+
+	assert.Equal(t, "10.5", f.String())
+}
@@ -0,0 +1,113 @@
+package tlv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+)
+
+const Int64DataLength uint32 = 8
+
+type Int64 int64
+
+func (i *Int64) ReadFrom(r io.Reader) (int64, error) {
+	var (
+		typ uint8
+		len uint32
+		val int64
+		n   int64
+
+		err error
+	)
+
+	err = binary.Read(r, binary.BigEndian, &typ)
+	if err != nil {
+		return 0, err
+	}
+	n += 1
+
+	if typ != Int64Type {
+		return n, errors.New("Invalid Int64")
+	}
+
+	err = binary.Read(r, binary.BigEndian, &len)
+	if err != nil {
+		return n, err
+	}
+	n += 4
+
+	if len != Int64DataLength {
+		return n, errors.New("Invalid Int64 length")
+	}
+
+	err = binary.Read(r, binary.BigEndian, &val)
+	if err != nil {
+		return n, err
+	}
+	n += int64(Int64DataLength)
+
+	*i = Int64(val)
+
+	return n, nil
+}
+
+func (i *Int64) WriteTo(w io.Writer) (int64, error) {
+	var (
+		typ = Int64Type
+		n   int64
+
+		err error
+	)
+
+	err = binary.Write(w, binary.BigEndian, typ)
+	if err != nil {
+		return 0, err
+	}
+
+	n += 1
+
+	err = binary.Write(w, binary.BigEndian, Int64DataLength)
+	if err != nil {
+		return n, err
+	}
+
+	n += 4
+
+	err = binary.Write(w, binary.BigEndian, int64(*i))
+	if err != nil {
+		return n, err
+	}
+
+	n += int64(Int64DataLength)
+
+	return n, nil
+}
+
+func (i *Int64) FromTLV(tlv TypeLengthValue) error {
+	r := bytes.NewReader(tlv)
+	_, err := i.ReadFrom(r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i *Int64) ToTLV() (TypeLengthValue, error) {
+	tlv := new(bytes.Buffer)
+	_, err := i.WriteTo(tlv)
+	if err != nil {
+		return nil, err
+	}
+
+	return TypeLengthValue(tlv.Bytes()), nil
+}
+
+func (i *Int64) String() string {
+	if i == nil {
+		return ""
+	}
+	return strconv.FormatInt(int64(*i), 10)
+}
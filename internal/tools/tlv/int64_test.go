@@ -0,0 +1,59 @@
+package tlv
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInt64ReadFrom(t *testing.T) {
+	tests := []byte{15, 0, 0, 0, 8, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe}
+	testreader := bytes.NewReader(tests)
+
+	i := new(Int64)
+	n, err := i.ReadFrom(testreader)
+
+	assert.Equal(t, len(tests), int(n))
+	assert.Nil(t, err)
+	assert.Equal(t, Int64(-2), *i)
+}
+
+func TestInt64ReadFromInvalid(t *testing.T) {
+	tests := []byte{2, 0, 0, 0, 8, 0, 0, 0, 0, 0, 0, 0, 1}
+	testreader := bytes.NewReader(tests)
+
+	i := new(Int64)
+	n, err := i.ReadFrom(testreader)
+
+	assert.Equal(t, 1, int(n))
+	assert.NotNil(t, err)
+}
+
+func TestInt64ReadFromInvalidLength(t *testing.T) {
+	tests := []byte{15, 0, 0, 0, 4, 0, 0, 0, 1}
+	testreader := bytes.NewReader(tests)
+
+	i := new(Int64)
+	_, err := i.ReadFrom(testreader)
+
+	assert.NotNil(t, err)
+}
+
+func TestInt64WriteTo(t *testing.T) {
+	tests := []byte{15, 0, 0, 0, 8, 0, 0, 0, 0, 0, 0, 1, 0}
+	testwriter := new(bytes.Buffer)
+
+	i := Int64(256)
+	n, err := i.WriteTo(testwriter)
+
+	assert.Equal(t, len(tests), int(n))
+	assert.Nil(t, err)
+	assert.Equal(t, tests, testwriter.Bytes())
+}
+
+func TestInt64String(t *testing.T) {
+	i := Int64(-42)
+
+	assert.Equal(t, "-42", i.String())
+}
@@ -0,0 +1,188 @@
+package tlv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Map is a set of string keys each holding a type length value, value can be a collection itself.
+// It is encoded as key String followed by its value for every pair, in key order.
+type Map map[string]TypeLengthValue
+
+func (m *Map) ReadFrom(r io.Reader) (int64, error) {
+	var (
+		typ uint8
+		len uint32
+		buf []byte
+		n   int64
+
+		err error
+	)
+
+	err = binary.Read(r, binary.BigEndian, &typ)
+	if err != nil {
+		return 0, err
+	}
+
+	n += 1
+
+	if typ != MapType {
+		return n, errors.New("Invalid Map")
+	}
+
+	err = binary.Read(r, binary.BigEndian, &len)
+	if err != nil {
+		return n, err
+	}
+
+	n += 4
+
+	buf = make([]byte, len)
+	o, err := io.ReadFull(r, buf)
+	if err != nil {
+		return n, err
+	}
+
+	n += int64(o)
+
+	pairs := Map{}
+	br := bytes.NewReader(buf)
+	for br.Len() > 0 {
+		k := new(String)
+		_, err := k.ReadFrom(br)
+		if err != nil {
+			return n, err
+		}
+
+		v, err := ReadTLV(br)
+		if err != nil {
+			return n, err
+		}
+
+		pairs[k.String()] = v
+	}
+
+	*m = pairs
+
+	return n, nil
+}
+
+func (m *Map) WriteTo(w io.Writer) (int64, error) {
+	var (
+		typ = MapType
+		n   int64
+
+		err error
+	)
+
+	buf := new(bytes.Buffer)
+	for _, k := range m.Keys() {
+		key := String(k)
+		_, err = key.WriteTo(buf)
+		if err != nil {
+			return 0, err
+		}
+		buf.Write((*m)[k])
+	}
+
+	err = binary.Write(w, binary.BigEndian, typ)
+	if err != nil {
+		return 0, err
+	}
+
+	n += 1
+
+	err = binary.Write(w, binary.BigEndian, uint32(buf.Len()))
+	if err != nil {
+		return n, err
+	}
+
+	n += 4
+
+	o, err := w.Write(buf.Bytes())
+	if err != nil {
+		return n, err
+	}
+
+	n += int64(o)
+
+	return n, nil
+}
+
+func (m *Map) FromTLV(tlv TypeLengthValue) error {
+	r := bytes.NewReader(tlv)
+	_, err := m.ReadFrom(r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (m *Map) ToTLV() (TypeLengthValue, error) {
+	tlv := new(bytes.Buffer)
+	_, err := m.WriteTo(tlv)
+	if err != nil {
+		return nil, err
+	}
+
+	return TypeLengthValue(tlv.Bytes()), nil
+}
+
+func (m *Map) String() string {
+	if m == nil {
+		return "{}"
+	}
+
+	pairs := make([]string, 0, len(*m))
+	for _, k := range m.Keys() {
+		v := (*m)[k]
+		pairs = append(pairs, k+": "+decodeString(v))
+	}
+
+	return "{" + strings.Join(pairs, ", ") + "}"
+}
+
+// Keys return keys of map in sorted order
+func (m *Map) Keys() []string {
+	keys := make([]string, 0, len(*m))
+	for k := range *m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// Values decode value of every key with Decode
+func (m *Map) Values() (map[string]TLVCompatible, error) {
+	vs := make(map[string]TLVCompatible, len(*m))
+	for k, raw := range *m {
+		v, err := Decode(raw)
+		if err != nil {
+			return nil, err
+		}
+		vs[k] = v
+	}
+
+	return vs, nil
+}
+
+// Put encode v and set it as value of k
+func (m *Map) Put(k string, v TLVCompatible) error {
+	raw, err := v.ToTLV()
+	if err != nil {
+		return err
+	}
+
+	if *m == nil {
+		*m = Map{}
+	}
+	(*m)[k] = raw
+
+	return nil
+}
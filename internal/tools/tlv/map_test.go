@@ -0,0 +1,74 @@
+package tlv
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMapWriteTo(t *testing.T) {
+	tests := []byte{17, 0, 0, 0, 23,
+		2, 0, 0, 0, 1, 97, 2, 0, 0, 0, 1, 49,
+		2, 0, 0, 0, 1, 98, 1, 0, 0, 0, 0,
+	}
+	testwriter := new(bytes.Buffer)
+
+	m := Map{}
+	a, b := String("1"), Binary{}
+	m.Put("b", &b)
+	m.Put("a", &a)
+	n, err := m.WriteTo(testwriter)
+
+	assert.Equal(t, len(tests), int(n))
+	assert.Nil(t, err)
+	assert.Equal(t, tests, testwriter.Bytes())
+}
+
+func TestMapReadFrom(t *testing.T) {
+	tests := []byte{17, 0, 0, 0, 23,
+		2, 0, 0, 0, 1, 97, 2, 0, 0, 0, 1, 49,
+		2, 0, 0, 0, 1, 98, 1, 0, 0, 0, 0,
+	}
+	testreader := bytes.NewReader(tests)
+
+	m := new(Map)
+	n, err := m.ReadFrom(testreader)
+
+	assert.Equal(t, len(tests), int(n))
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a", "b"}, m.Keys())
+	assert.Equal(t, TypeLengthValue{2, 0, 0, 0, 1, 49}, (*m)["a"])
+}
+
+func TestMapReadFromInvalid(t *testing.T) {
+	tests := []byte{14, 0, 0, 0, 0}
+	testreader := bytes.NewReader(tests)
+
+	m := new(Map)
+	n, err := m.ReadFrom(testreader)
+
+	assert.Equal(t, 1, int(n))
+	assert.NotNil(t, err)
+}
+
+func TestMapNested(t *testing.T) {
+	inner := Map{}
+	s := String("v")
+	inner.Put("k", &s)
+
+	outer := Map{}
+	outer.Put("inner", &inner)
+	raw, err := outer.ToTLV()
+	assert.Nil(t, err)
+
+	res := new(Map)
+	err = res.FromTLV(raw)
+	assert.Nil(t, err)
+
+	resinner := new(Map)
+	err = resinner.FromTLV((*res)["inner"])
+
+	assert.Nil(t, err)
+	assert.Equal(t, "{k: v}", resinner.String())
+}
@@ -0,0 +1,95 @@
+package tlv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Nil is an explicit missing value, it has no data
+type Nil struct{}
+
+// NilValue is encoded Nil, element of collection that has no value
+var NilValue = TypeLengthValue{NilType, 0, 0, 0, 0}
+
+func (v *Nil) ReadFrom(r io.Reader) (int64, error) {
+	var (
+		typ uint8
+		len uint32
+		n   int64
+
+		err error
+	)
+
+	err = binary.Read(r, binary.BigEndian, &typ)
+	if err != nil {
+		return 0, err
+	}
+	n += 1
+
+	if typ != NilType {
+		return n, errors.New("Invalid Nil")
+	}
+
+	err = binary.Read(r, binary.BigEndian, &len)
+	if err != nil {
+		return n, err
+	}
+	n += 4
+
+	if len != 0 {
+		return n, errors.New("Invalid Nil length")
+	}
+
+	return n, nil
+}
+
+func (v *Nil) WriteTo(w io.Writer) (int64, error) {
+	var (
+		typ = NilType
+		n   int64
+
+		err error
+	)
+
+	err = binary.Write(w, binary.BigEndian, typ)
+	if err != nil {
+		return 0, err
+	}
+
+	n += 1
+
+	err = binary.Write(w, binary.BigEndian, uint32(0))
+	if err != nil {
+		return n, err
+	}
+
+	n += 4
+
+	return n, nil
+}
+
+func (v *Nil) FromTLV(tlv TypeLengthValue) error {
+	r := bytes.NewReader(tlv)
+	_, err := v.ReadFrom(r)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (v *Nil) ToTLV() (TypeLengthValue, error) {
+	tlv := new(bytes.Buffer)
+	_, err := v.WriteTo(tlv)
+	if err != nil {
+		return nil, err
+	}
+
+	return TypeLengthValue(tlv.Bytes()), nil
+}
+
+func (v *Nil) String() string {
+	return "(nil)"
+}
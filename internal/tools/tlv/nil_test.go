@@ -0,0 +1,48 @@
+package tlv
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNilReadFrom(t *testing.T) {
+	tests := []byte{19, 0, 0, 0, 0}
+	testreader := bytes.NewReader(tests)
+
+	v := new(Nil)
+	n, err := v.ReadFrom(testreader)
+
+	assert.Equal(t, len(tests), int(n))
+	assert.Nil(t, err)
+}
+
+func TestNilReadFromInvalidLength(t *testing.T) {
+	tests := []byte{19, 0, 0, 0, 1, 0}
+	testreader := bytes.NewReader(tests)
+
+	v := new(Nil)
+	_, err := v.ReadFrom(testreader)
+
+	assert.NotNil(t, err)
+}
+
+func TestNilWriteTo(t *testing.T) {
+	tests := []byte{19, 0, 0, 0, 0}
+	testwriter := new(bytes.Buffer)
+
+	v := Nil{}
+	n, err := v.WriteTo(testwriter)
+
+	assert.Equal(t, len(tests), int(n))
+	assert.Nil(t, err)
+	assert.Equal(t, tests, testwriter.Bytes())
+}
+
+func TestNilValue(t *testing.T) {
+	raw, err := (&Nil{}).ToTLV()
+
+	assert.Nil(t, err)
+	assert.Equal(t, raw, NilValue)
+}
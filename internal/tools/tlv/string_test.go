@@ -77,3 +77,13 @@ func TestNilStringToString(t *testing.T) {
 
 	assert.Equal(t, "", ss)
 }
+
+func TestEmptyStringFromTLV(t *testing.T) {
+	tlv := []byte{2, 0, 0, 0, 0}
+	s := String("not_empty")
+
+	err := s.FromTLV(tlv)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "", s.String())
+}
@@ -2,6 +2,7 @@ package tlv
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 )
@@ -27,6 +28,23 @@ const (
 	PubSuccessType
 	MsgType
 
+	// Redis request carrying time to live
+	RedisTTLRequestPayloadType
+
+	// Collection
+	ArrayType
+
+	// Number
+	Int64Type
+	Float64Type
+
+	// Collection of string key and value pairs
+	MapType
+
+	BoolType
+	// Explicit nil, unlike empty type it is a value of its own
+	NilType
+
 	TypeDataLength  uint8  = 1
 	LengthDataLegth uint8  = 4
 	MaxPayloadSize  uint32 = 10 << 20
@@ -62,3 +80,26 @@ func (tlv *TypeLengthValue) GetValue() []byte {
 	}
 	return (*tlv)[5:]
 }
+
+// ReadTLV read one whole type length value from r
+func ReadTLV(r io.Reader) (TypeLengthValue, error) {
+	header := make([]byte, TypeDataLength+LengthDataLegth)
+	_, err := io.ReadFull(r, header)
+	if err != nil {
+		return nil, err
+	}
+
+	len := binary.BigEndian.Uint32(header[TypeDataLength:])
+	if len > MaxPayloadSize {
+		return nil, errors.New("Payload size exceed limit")
+	}
+
+	tlv := make([]byte, uint32(TypeDataLength+LengthDataLegth)+len)
+	copy(tlv, header)
+	_, err = io.ReadFull(r, tlv[TypeDataLength+LengthDataLegth:])
+	if err != nil {
+		return nil, err
+	}
+
+	return TypeLengthValue(tlv), nil
+}
@@ -1,6 +1,7 @@
 package tlv
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -47,3 +48,26 @@ func TestGetEmptyTLVValue(t *testing.T) {
 
 	assert.Equal(t, []byte{}, sval)
 }
+
+func TestReadTLV(t *testing.T) {
+	raw := []byte{2, 0, 0, 0, 4, 116, 101, 115, 116, 2, 0, 0, 0, 1, 97}
+	r := bytes.NewReader(raw)
+
+	first, err := ReadTLV(r)
+
+	assert.Nil(t, err)
+	assert.Equal(t, TypeLengthValue(raw[:9]), first)
+
+	second, err := ReadTLV(r)
+
+	assert.Nil(t, err)
+	assert.Equal(t, TypeLengthValue(raw[9:]), second)
+}
+
+func TestReadTLVTruncated(t *testing.T) {
+	raw := []byte{2, 0, 0, 0, 4, 116}
+
+	_, err := ReadTLV(bytes.NewReader(raw))
+
+	assert.NotNil(t, err)
+}
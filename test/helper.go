@@ -53,13 +53,13 @@ func getClientCert() (string, string) {
 
 func createTestServer() *network.Server {
 	cert, key := getServerCert()
-	serv, err := network.NewServer(constant.Protocol, ":"+constant.DefaultServerPort, cert, key)
+	serv, err := network.NewServer(constant.Protocol, ":"+constant.DefaultServerPort, cert, key, network.ServerOptions{})
 	if err != nil {
 		panic(err)
 	}
 	go func() {
-		err = serv.Start()
-		if err != nil {
+		err := serv.Start()
+		if err != nil && err != network.ErrServerClosed {
 			panic(err)
 		}
 	}()
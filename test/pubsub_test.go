@@ -42,8 +42,8 @@ func Test_receive_message_subscribed_topic(t *testing.T) {
 			t.Errorf("Got err = %v", err)
 		}
 
-		if resp != "OK" {
-			t.Errorf("Expect want = %v got = %v", "OK", resp)
+		if resp != 1 {
+			t.Errorf("Expect want = %v got = %v", 1, resp)
 		}
 
 		rec, err := subscriber.NextMessage()
@@ -51,7 +51,7 @@ func Test_receive_message_subscribed_topic(t *testing.T) {
 			t.Errorf("Async() err = %v", err)
 		}
 
-		if resp != "OK" {
+		if rec.String() != msg {
 			t.Errorf("Expect want = %v got = %v", msg, rec)
 		}
 	})
@@ -75,8 +75,9 @@ func Test_receive_message_subscribed_topic(t *testing.T) {
 			t.Errorf("Got err = %v", err)
 		}
 
-		if resp != "OK" {
-			t.Errorf("Expect want = %v got = %v", "OK", resp)
+		// Subscriber of first test is still subscribed
+		if resp != 3 {
+			t.Errorf("Expect want = %v got = %v", 3, resp)
 		}
 
 		rec1, err := sub1.NextMessage()
@@ -95,5 +96,31 @@ func Test_receive_message_subscribed_topic(t *testing.T) {
 			t.Errorf("Expect want = %v got = %v", msg, rec2)
 		}
 	})
+	t.Run("it should receive published message on topic longer than 16 bytes", func(t *testing.T) {
+		longtopic := "orders.eu.created.priority"
+		c := createTestClient(t)
+		defer c.Close()
 
+		sub, err := c.Sub(longtopic)
+		if err != nil {
+			t.Errorf("Got err = %v", err)
+		}
+
+		resp, err := pubclient.Pub(longtopic, msg)
+		if err != nil {
+			t.Errorf("Got err = %v", err)
+		}
+
+		if resp != 1 {
+			t.Errorf("Expect want = %v got = %v", 1, resp)
+		}
+
+		rec, err := sub.NextMessage()
+		if err != nil {
+			t.Errorf("Async() err = %v", err)
+		}
+		if rec.String() != msg {
+			t.Errorf("Expect want = %v got = %v", msg, rec)
+		}
+	})
 }
@@ -1,10 +1,15 @@
 package test
 
 import (
+	"math"
 	"testing"
+	"time"
 
 	"bitbucket.org/non-pn/mini-redis-go/internal/constant"
+	"bitbucket.org/non-pn/mini-redis-go/internal/db"
+	"bitbucket.org/non-pn/mini-redis-go/internal/db/model"
 	"bitbucket.org/non-pn/mini-redis-go/internal/network"
+	"bitbucket.org/non-pn/mini-redis-go/internal/payload"
 	"bitbucket.org/non-pn/mini-redis-go/internal/tools/tlv"
 	"github.com/stretchr/testify/assert"
 )
@@ -55,4 +60,660 @@ func Test_redis(t *testing.T) {
 		val, err := client.Get(k)
 		assert.Equal(t, v.String(), val.String())
 	})
+	t.Run("it should expire key after ttl", func(t *testing.T) {
+		k := "test_ttl"
+		v := tlv.String("test_ttl")
+		resp, err := client.SetEx(k, &v, 50*time.Millisecond)
+		if err != nil {
+			t.Errorf("Got err = %v", err)
+		}
+
+		assert.Equal(t, "OK", resp)
+
+		ttl, err := client.TTL(k)
+		if err != nil {
+			t.Errorf("Got err = %v", err)
+		}
+
+		assert.Greater(t, ttl, time.Duration(0))
+
+		time.Sleep(100 * time.Millisecond)
+
+		val, err := client.Get(k)
+		assert.Equal(t, "", val.String())
+
+		ttl, err = client.TTL(k)
+		assert.Equal(t, db.KeyNotFoundTTL, ttl)
+	})
+	t.Run("it should expire and persist existing key", func(t *testing.T) {
+		k := "test_persist"
+		v := tlv.String("test_persist")
+		_, err := client.Set(k, &v)
+		if err != nil {
+			t.Errorf("Got err = %v", err)
+		}
+
+		ok, err := client.Expire(k, time.Minute)
+		if err != nil {
+			t.Errorf("Got err = %v", err)
+		}
+
+		assert.True(t, ok)
+
+		ok, err = client.Persist(k)
+		if err != nil {
+			t.Errorf("Got err = %v", err)
+		}
+
+		assert.True(t, ok)
+
+		ttl, err := client.TTL(k)
+		assert.Equal(t, db.NoExpireTTL, ttl)
+	})
+	t.Run("it should delete and check existence of keys", func(t *testing.T) {
+		v := tlv.String("test")
+		client.Set("test_del1", &v)
+		client.Set("test_del2", &v)
+
+		n, err := client.Exists("test_del1", "test_del2", "unset")
+		if err != nil {
+			t.Errorf("Got err = %v", err)
+		}
+
+		assert.Equal(t, 2, n)
+
+		n, err = client.Del("test_del1", "test_del2", "unset")
+		if err != nil {
+			t.Errorf("Got err = %v", err)
+		}
+
+		assert.Equal(t, 2, n)
+
+		n, err = client.Exists("test_del1", "test_del2")
+		assert.Equal(t, 0, n)
+	})
+	t.Run("it should scan all matching keys", func(t *testing.T) {
+		v := tlv.String("test")
+		client.Set("scan:1", &v)
+		client.Set("scan:2", &v)
+		client.Set("scan:3", &v)
+
+		found := []string{}
+		cursor := ""
+		for {
+			next, keys, err := client.Scan(cursor, "scan:*", 1)
+			if err != nil {
+				t.Errorf("Got err = %v", err)
+				break
+			}
+			found = append(found, keys...)
+			if next == "" {
+				break
+			}
+			cursor = next
+		}
+
+		assert.Equal(t, []string{"scan:1", "scan:2", "scan:3"}, found)
+	})
+	t.Run("it should set and get key longer than 16 bytes", func(t *testing.T) {
+		k := "session:0123456789abcdef0123456789abcdef"
+		v := tlv.String("long")
+		resp, err := client.Set(k, &v)
+		if err != nil {
+			t.Errorf("Got err = %v", err)
+		}
+
+		assert.Equal(t, "OK", resp)
+
+		val, err := client.Get(k)
+		assert.Equal(t, v.String(), val.String())
+
+		n, err := client.Exists(k)
+		assert.Equal(t, 1, n)
+	})
+	t.Run("it should increment and decrement counter atomically", func(t *testing.T) {
+		k := "test_counter"
+		client.Del(k)
+
+		n, err := client.Incr(k)
+		if err != nil {
+			t.Errorf("Got err = %v", err)
+		}
+
+		assert.Equal(t, int64(1), n)
+
+		n, err = client.IncrBy(k, 10)
+		assert.Equal(t, int64(11), n)
+
+		n, err = client.Decr(k)
+		assert.Equal(t, int64(10), n)
+
+		n, err = client.DecrBy(k, 4)
+		assert.Equal(t, int64(6), n)
+
+		f, err := client.IncrByFloat(k, 0.5)
+		if err != nil {
+			t.Errorf("Got err = %v", err)
+		}
+
+		assert.Equal(t, 6.5, f)
+
+		val, err := client.Get(k)
+		assert.Equal(t, "6.5", val.String())
+	})
+	t.Run("it should reject increment of non numeric value", func(t *testing.T) {
+		k := "test_not_counter"
+		v := tlv.String("abc")
+		client.Set(k, &v)
+
+		_, err := client.Incr(k)
+
+		tlvErr, ok := err.(*tlv.Error)
+		assert.True(t, ok)
+		assert.Equal(t, tlv.NotIntegerError, tlvErr.Code)
+
+		// Connection is still usable after error reply
+		val, err := client.Get(k)
+		assert.Equal(t, "abc", val.String())
+	})
+	t.Run("it should push, pop and range list", func(t *testing.T) {
+		k := "test_list"
+		client.Del(k)
+		a, b, c := tlv.String("a"), tlv.String("b"), tlv.String("c")
+
+		n, err := client.RPush(k, &b, &c)
+		if err != nil {
+			t.Errorf("Got err = %v", err)
+		}
+
+		assert.Equal(t, int64(2), n)
+
+		n, err = client.LPush(k, &a)
+		assert.Equal(t, int64(3), n)
+
+		vals, err := client.LRange(k, 0, -1)
+		if err != nil {
+			t.Errorf("Got err = %v", err)
+		}
+
+		assert.Equal(t, 3, len(vals))
+		assert.Equal(t, "a", vals[0].String())
+		assert.Equal(t, "c", vals[2].String())
+
+		val, err := client.RPop(k)
+		assert.Equal(t, "c", val.String())
+
+		n, err = client.LLen(k)
+		assert.Equal(t, int64(2), n)
+
+		client.LPop(k)
+		client.LPop(k)
+
+		val, err = client.LPop(k)
+		assert.Nil(t, err)
+		assert.Nil(t, val)
+
+		// Emptied list is removed
+		cnt, err := client.Exists(k)
+		assert.Equal(t, 0, cnt)
+	})
+	t.Run("it should reject wrong type access", func(t *testing.T) {
+		k := "test_list_wrongtype"
+		v := tlv.String("v")
+		client.Del(k)
+		client.RPush(k, &v)
+
+		_, err := client.Get(k)
+		tlvErr, ok := err.(*tlv.Error)
+		assert.True(t, ok)
+		assert.Equal(t, tlv.WrongTypeError, tlvErr.Code)
+
+		_, err = client.Incr(k)
+		tlvErr, ok = err.(*tlv.Error)
+		assert.True(t, ok)
+		assert.Equal(t, tlv.WrongTypeError, tlvErr.Code)
+	})
+	t.Run("it should time out blocking pop on empty list", func(t *testing.T) {
+		k, val, err := client.BLPop(50*time.Millisecond, "test_blpop_empty")
+		if err != nil {
+			t.Errorf("Got err = %v", err)
+		}
+
+		assert.Equal(t, "", k)
+		assert.Nil(t, val)
+	})
+	t.Run("it should wake blocking pop when element is pushed", func(t *testing.T) {
+		k := "test_blpop"
+		client.Del(k)
+		pusher := createTestClient(t)
+		defer pusher.Close()
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			v := tlv.String("job")
+			pusher.RPush(k, &v)
+		}()
+
+		key, val, err := client.BLPop(time.Second, "test_blpop_other", k)
+		if err != nil {
+			t.Errorf("Got err = %v", err)
+		}
+
+		assert.Equal(t, k, key)
+		assert.Equal(t, "job", val.String())
+	})
+	t.Run("it should set, get and delete hash fields", func(t *testing.T) {
+		k := "test_hash"
+		client.Del(k)
+		name, age := tlv.String("alice"), tlv.String("30")
+
+		n, err := client.HSet(k, map[string]tlv.TLVCompatible{"name": &name, "age": &age})
+		if err != nil {
+			t.Errorf("Got err = %v", err)
+		}
+
+		assert.Equal(t, int64(2), n)
+
+		val, err := client.HGet(k, "name")
+		assert.Equal(t, "alice", val.String())
+
+		n, err = client.HIncrBy(k, "age", 1)
+		if err != nil {
+			t.Errorf("Got err = %v", err)
+		}
+
+		assert.Equal(t, int64(31), n)
+
+		fields, err := client.HGetAll(k)
+		if err != nil {
+			t.Errorf("Got err = %v", err)
+		}
+
+		assert.Equal(t, 2, len(fields))
+		assert.Equal(t, "31", fields["age"].String())
+
+		n, err = client.HDel(k, "name", "missing")
+		assert.Equal(t, int64(1), n)
+
+		n, err = client.HLen(k)
+		assert.Equal(t, int64(1), n)
+
+		val, err = client.HGet(k, "name")
+		assert.Nil(t, err)
+		assert.Nil(t, val)
+	})
+	t.Run("it should add members and combine sets", func(t *testing.T) {
+		client.Del("test_set1", "test_set2")
+
+		n, err := client.SAdd("test_set1", "a", "b", "c")
+		if err != nil {
+			t.Errorf("Got err = %v", err)
+		}
+
+		assert.Equal(t, int64(3), n)
+
+		client.SAdd("test_set2", "b", "c", "d")
+
+		ok, err := client.SIsMember("test_set1", "a")
+		assert.Nil(t, err)
+		assert.True(t, ok)
+
+		members, err := client.SInter("test_set1", "test_set2")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"b", "c"}, members)
+
+		members, err = client.SUnion("test_set1", "test_set2")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a", "b", "c", "d"}, members)
+
+		members, err = client.SDiff("test_set1", "test_set2")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"a"}, members)
+
+		n, err = client.SRem("test_set1", "a", "b", "c")
+		assert.Equal(t, int64(3), n)
+
+		members, err = client.SMembers("test_set1")
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(members))
+	})
+	t.Run("it should rank members of sorted set by score", func(t *testing.T) {
+		k := "test_leaderboard"
+		client.Del(k)
+
+		n, err := client.ZAdd(k, map[string]float64{"alice": 30, "bob": 10, "carol": 20})
+		if err != nil {
+			t.Errorf("Got err = %v", err)
+		}
+
+		assert.Equal(t, int64(3), n)
+
+		score, err := client.ZIncrBy(k, "bob", 25)
+		assert.Nil(t, err)
+		assert.Equal(t, float64(35), score)
+
+		members, err := client.ZRange(k, 0, -1)
+		assert.Nil(t, err)
+		assert.Equal(t, []model.ZMember{{Member: "carol", Score: 20}, {Member: "alice", Score: 30}, {Member: "bob", Score: 35}}, members)
+
+		members, err = client.ZRangeByScore(k, 25, math.Inf(1))
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(members))
+
+		rank, ok, err := client.ZRank(k, "alice")
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, int64(1), rank)
+
+		client.ZRem(k, "alice")
+		_, ok, err = client.ZScore(k, "alice")
+		assert.Nil(t, err)
+		assert.False(t, ok)
+
+		_, err = client.SAdd(k, "x")
+		assert.Equal(t, model.ErrWrongType.Error(), err.Error())
+	})
+	t.Run("it should add, range and trim stream entries", func(t *testing.T) {
+		k := "test_stream"
+		client.Del(k)
+		v := tlv.String("1")
+
+		id, err := client.XAdd(k, "1-1", map[string]tlv.TLVCompatible{"n": &v}, model.StreamTrim{})
+		if err != nil {
+			t.Errorf("Got err = %v", err)
+		}
+
+		assert.Equal(t, "1-1", id)
+
+		_, err = client.XAdd(k, "1-0", map[string]tlv.TLVCompatible{"n": &v}, model.StreamTrim{})
+		assert.Equal(t, tlv.ErrMsg[tlv.StreamIDTooSmallError], err.Error())
+
+		for i := 0; i < 3; i++ {
+			_, err = client.XAdd(k, "*", map[string]tlv.TLVCompatible{"n": &v}, model.StreamTrim{MaxLen: 3})
+			assert.Nil(t, err)
+		}
+
+		n, err := client.XLen(k)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), n)
+
+		entries, err := client.XRange(k, "-", "+", 2)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(entries))
+		assert.Equal(t, "1", entries[0].Fields["n"].String())
+
+		n, err = client.XTrim(k, model.StreamTrim{MaxLen: 1})
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), n)
+	})
+	t.Run("it should wake blocking stream read when entry is added", func(t *testing.T) {
+		k := "test_xread"
+		client.Del(k)
+		v := tlv.String("event")
+		client.XAdd(k, "*", map[string]tlv.TLVCompatible{"e": &v}, model.StreamTrim{})
+
+		res, err := client.XRead(0, []string{k}, []string{"$"})
+		assert.Nil(t, err)
+		assert.Nil(t, res)
+
+		adder := createTestClient(t)
+		defer adder.Close()
+
+		go func() {
+			time.Sleep(50 * time.Millisecond)
+			adder.XAdd(k, "*", map[string]tlv.TLVCompatible{"e": &v}, model.StreamTrim{})
+		}()
+
+		res, err = client.XReadBlock(time.Second, 0, []string{k}, []string{"$"})
+		if err != nil {
+			t.Errorf("Got err = %v", err)
+		}
+
+		assert.Equal(t, 1, len(res))
+		assert.Equal(t, k, res[0].Key)
+		assert.Equal(t, 1, len(res[0].Entries))
+		assert.Equal(t, "event", res[0].Entries[0].Fields["e"].String())
+
+		// Reading from start replay every entry
+		res, err = client.XRead(0, []string{k}, []string{"0"})
+		assert.Nil(t, err)
+		assert.Equal(t, 2, len(res[0].Entries))
+	})
+	t.Run("it should share stream among consumers of group with acknowledgements", func(t *testing.T) {
+		k := "test_group_stream"
+		client.Del(k)
+		v := tlv.String("job")
+
+		res, err := client.XGroupCreate(k, "workers", "$")
+		if err != nil {
+			t.Errorf("Got err = %v", err)
+		}
+		assert.Equal(t, "OK", res)
+
+		_, err = client.XGroupCreate(k, "workers", "0")
+		assert.Equal(t, tlv.ErrMsg[tlv.GroupExistsError], err.Error())
+
+		for i := 0; i < 2; i++ {
+			client.XAdd(k, "*", map[string]tlv.TLVCompatible{"j": &v}, model.StreamTrim{})
+		}
+
+		// Each consumer get its own entry
+		a, err := client.XReadGroup("workers", "alice", 1, []string{k}, []string{">"})
+		assert.Nil(t, err)
+		b, err := client.XReadGroup("workers", "bob", 1, []string{k}, []string{">"})
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(a[0].Entries))
+		assert.Equal(t, 1, len(b[0].Entries))
+		assert.NotEqual(t, a[0].Entries[0].ID, b[0].Entries[0].ID)
+
+		none, err := client.XReadGroup("workers", "alice", 0, []string{k}, []string{">"})
+		assert.Nil(t, err)
+		assert.Nil(t, none)
+
+		n, err := client.XAck(k, "workers", a[0].Entries[0].ID)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), n)
+
+		pending, err := client.XPending(k, "workers", "")
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(pending))
+		assert.Equal(t, "bob", pending[0].Consumer)
+
+		// Entry left unacknowledged by bob is claimed by alice
+		time.Sleep(20 * time.Millisecond)
+		claimed, err := client.XClaim(k, "workers", "alice", 10*time.Millisecond, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(claimed))
+		assert.Equal(t, b[0].Entries[0].ID, claimed[0].ID)
+
+		pending, err = client.XPending(k, "workers", "alice")
+		assert.Nil(t, err)
+		assert.Equal(t, 1, len(pending))
+		assert.Equal(t, int64(2), pending[0].Count)
+
+		ok, err := client.XGroupDestroy(k, "workers")
+		assert.Nil(t, err)
+		assert.True(t, ok)
+
+		_, err = client.XReadGroup("workers", "alice", 0, []string{k}, []string{">"})
+		assert.Equal(t, tlv.ErrMsg[tlv.NoGroupError], err.Error())
+	})
+	t.Run("it should set conditionally and compare and set by value or version", func(t *testing.T) {
+		k := "test_cas"
+		client.Del(k)
+		owner, other := tlv.String("owner"), tlv.String("other")
+
+		set, err := client.SetNX(k, &owner)
+		assert.Nil(t, err)
+		assert.True(t, set)
+		set, err = client.SetNX(k, &other)
+		assert.Nil(t, err)
+		assert.False(t, set)
+
+		set, err = client.SetXX("test_cas_missing", &other)
+		assert.Nil(t, err)
+		assert.False(t, set)
+
+		prev, err := client.GetSet(k, &owner)
+		assert.Nil(t, err)
+		assert.Equal(t, "owner", prev.String())
+
+		// Value compared is not the one held
+		version, err := client.CAS(k, &other, &other, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(0), version)
+
+		version, err = client.CAS(k, &owner, &other, time.Second)
+		assert.Nil(t, err)
+		assert.NotEqual(t, uint64(0), version)
+
+		cur, err := client.GetVersion(k)
+		assert.Nil(t, err)
+		assert.Equal(t, version, cur)
+
+		// Stale version no longer match once key is written
+		_, err = client.CASVersion(k, version, &owner, 0)
+		assert.Nil(t, err)
+		stale, err := client.CASVersion(k, version, &other, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(0), stale)
+
+		val, err := client.Get(k)
+		assert.Nil(t, err)
+		assert.Equal(t, "owner", val.String())
+
+		// Conditional set run by EXEC already hold store lock
+		results, err := client.TxPipeline().Do(func(c *network.Client) (any, error) {
+			return c.SetNX(k, &other)
+		}).Exec()
+		assert.Nil(t, err)
+		assert.Equal(t, false, results[0].Val)
+	})
+	t.Run("it should get and set many keys in one request", func(t *testing.T) {
+		client.Del("test_m1", "test_m2", "test_m3")
+		s, b := tlv.String("flag"), tlv.Binary("bin")
+
+		resp, err := client.MSet(map[string]tlv.TLVCompatible{"test_m1": &s, "test_m2": &b})
+		assert.Nil(t, err)
+		assert.Equal(t, "OK", resp)
+
+		vals, err := client.MGet("test_m1", "test_m3", "test_m2")
+		assert.Nil(t, err)
+		assert.Equal(t, []tlv.TLVCompatible{&s, nil, &b}, vals)
+
+		// Nothing is set when one of keys exists
+		set, err := client.MSetNX(map[string]tlv.TLVCompatible{"test_m1": &b, "test_m3": &b})
+		assert.Nil(t, err)
+		assert.False(t, set)
+		n, _ := client.Exists("test_m3")
+		assert.Equal(t, 0, n)
+
+		set, err = client.MSetNX(map[string]tlv.TLVCompatible{"test_m3": &b})
+		assert.Nil(t, err)
+		assert.True(t, set)
+	})
+	t.Run("it should reply error to malformed arguments and keep connection open", func(t *testing.T) {
+		args, _ := tlv.NewStringArray("0", "*", "ten")
+		rawargs, _ := args.ToTLV()
+		bod := payload.RedisRequestBody{Value: rawargs}
+		rawbod, _ := bod.ToTLV()
+		req := payload.RequestPayload{Cmd: payload.ScanCmd, Body: rawbod}
+		_, err := req.WriteTo(client.Connection)
+		assert.Nil(t, err)
+
+		resp, err := payload.ReadResponse(client.Connection)
+		assert.Nil(t, err)
+		assert.Equal(t, tlv.ErrorType, resp.Typ)
+
+		_, err = client.Ping(nil)
+		assert.Nil(t, err)
+	})
+	t.Run("it should get back typed values it set", func(t *testing.T) {
+		b, n := tlv.Bool(true), tlv.Int64(3)
+		m := tlv.Map{}
+		m.Put("n", &n)
+		arr, _ := tlv.NewStringArray("a")
+		arr.Append(&m)
+
+		for _, v := range []tlv.TLVCompatible{&b, &tlv.Nil{}, &m, arr} {
+			_, err := client.Set("test_typed", v)
+			assert.Nil(t, err)
+
+			resp, err := client.Get("test_typed")
+			assert.Nil(t, err)
+			assert.Equal(t, v.String(), resp.String())
+		}
+
+		vals, err := client.MGet("test_typed")
+		assert.Nil(t, err)
+		assert.Equal(t, "[a, {n: 3}]", vals[0].String())
+	})
+	t.Run("it should run queued commands as one transaction unless watched key changed", func(t *testing.T) {
+		k := "test_tx"
+		client.Del(k)
+
+		results, err := client.TxPipeline().Incr(k).IncrBy(k, 2).Get(k).Exec()
+		assert.Nil(t, err)
+		assert.Equal(t, 3, len(results))
+		assert.Equal(t, int64(1), results[0].Val)
+		assert.Equal(t, int64(3), results[1].Val)
+		assert.Nil(t, results[2].Err)
+
+		other := network.NewClient(constant.Protocol, constant.DefaultServerHost, ":"+constant.DefaultServerPort, "", "")
+		err = other.Connect()
+		assert.Nil(t, err)
+		defer other.Close()
+
+		// Key changed by other client after WATCH abort transaction
+		resp, err := client.Watch(k)
+		assert.Nil(t, err)
+		assert.Equal(t, "OK", resp)
+		other.Incr(k)
+
+		_, err = client.TxPipeline().Incr(k).Exec()
+		assert.Equal(t, network.ErrTxAborted, err)
+		n, _ := client.Incr(k)
+		assert.Equal(t, int64(5), n)
+
+		// Keys are no longer watched after EXEC
+		results, err = client.TxPipeline().Incr(k).Exec()
+		assert.Nil(t, err)
+		assert.Equal(t, int64(6), results[0].Val)
+
+		// Transaction with refused command is discarded
+		_, err = client.TxPipeline().Incr(k).Do(func(c *network.Client) (any, error) {
+			return c.Save()
+		}).Exec()
+		assert.Equal(t, tlv.ErrMsg[tlv.ExecAbortError], err.Error())
+		n, _ = client.Incr(k)
+		assert.Equal(t, int64(7), n)
+	})
+	t.Run("it should name, list and kill clients", func(t *testing.T) {
+		other := network.NewClient(constant.Protocol, constant.DefaultServerHost, ":"+constant.DefaultServerPort, "", "")
+		err := other.Connect()
+		assert.Nil(t, err)
+		defer other.Close()
+
+		resp, err := other.ClientSetName("other")
+		assert.Nil(t, err)
+		assert.Equal(t, "OK", resp)
+
+		name, err := other.ClientGetName()
+		assert.Nil(t, err)
+		assert.Equal(t, "other", name)
+
+		_, err = other.ClientSetName("has space")
+		assert.Equal(t, tlv.ErrMsg[tlv.InvalidClientNameError], err.Error())
+
+		list, err := client.ClientList()
+		assert.Nil(t, err)
+		assert.Contains(t, list, "addr="+other.Connection.LocalAddr().String()+" name=other ")
+
+		n, err := client.ClientKill("addr", other.Connection.LocalAddr().String())
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), n)
+
+		_, err = other.Ping(nil)
+		assert.NotNil(t, err)
+	})
 }